@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nodeABIs maps Node.js major lines to their NODE_MODULE_VERSION
+// nodeABIs 将 Node.js 主版本线映射到其 NODE_MODULE_VERSION
+var nodeABIs = map[string]int{
+	"0.10": 11, "0.12": 14, "4": 46, "5": 47, "6": 48, "7": 51, "8": 57, "9": 59,
+	"10": 64, "11": 67, "12": 72, "13": 79, "14": 83, "15": 88, "16": 93, "17": 102,
+	"18": 108, "19": 111, "20": 115, "21": 120, "22": 127, "23": 131, "24": 137, "25": 141,
+}
+
+// ElectronInfo describes an Electron major release and the Node.js it embeds
+// ElectronInfo 描述一个 Electron 主版本及其内置的 Node.js
+type ElectronInfo struct {
+	Electron          string
+	NodeVersion       string
+	NodeModuleVersion int
+}
+
+// electronReleases is the bundled table of Electron majors, their embedded Node.js and ABI
+// electronReleases 是内置的 Electron 主版本、内置 Node.js 及 ABI 对照表
+var electronReleases = []ElectronInfo{
+	{"20", "16.15.0", 107}, {"21", "16.16.0", 109}, {"22", "16.17.1", 110},
+	{"23", "18.12.1", 113}, {"24", "18.14.0", 114}, {"25", "18.15.0", 116},
+	{"26", "18.16.1", 116}, {"27", "18.17.1", 118}, {"28", "18.18.2", 119},
+	{"29", "20.9.0", 121}, {"30", "20.11.1", 123}, {"31", "20.14.0", 125},
+	{"32", "20.16.0", 128}, {"33", "20.18.0", 130}, {"34", "20.18.1", 132},
+	{"35", "22.14.0", 133}, {"36", "22.14.0", 135}, {"37", "22.16.0", 136},
+}
+
+// AbiInfo is the native addon ABI information of a Node.js version
+// AbiInfo 表示某个 Node.js 版本的原生模块 ABI 信息
+type AbiInfo struct {
+	Version           string
+	NodeModuleVersion int
+	Source            string // runtime 表示从已安装的 node.exe 读取 / runtime means read from the installed node.exe
+	Electron          []ElectronInfo
+}
+
+// abiLine returns the key used in nodeABIs for a version
+// abiLine 返回版本在 nodeABIs 中对应的键
+func abiLine(version string) string {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if parts[0] == "0" && len(parts) > 1 {
+		return "0." + parts[1]
+	}
+	return parts[0]
+}
+
+// GetAbiInfo maps a Node.js version to its NODE_MODULE_VERSION and the Electron releases embedding the same Node.js line
+// GetAbiInfo 返回 Node.js 版本对应的 NODE_MODULE_VERSION 以及内置相同 Node.js 版本线的 Electron 版本
+func (a *App) GetAbiInfo(version string) (AbiInfo, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	info := AbiInfo{Version: version, Source: "table"}
+
+	// Installed versions can report their real ABI
+	// 已安装的版本可以直接报告真实的 ABI
+	if env, dir, err := a.versionEnv(version); err == nil {
+		spec := CommandSpec{Name: filepath.Join(dir, "node.exe"), Args: []string{"-p", "process.versions.modules"}, Env: env, Hidden: true}
+		if output, err := a.outputOf(spec); err == nil {
+			if abi, err := strconv.Atoi(strings.TrimSpace(string(output))); err == nil {
+				info.NodeModuleVersion = abi
+				info.Source = "runtime"
+			}
+		}
+	}
+	if info.NodeModuleVersion == 0 {
+		abi, ok := nodeABIs[abiLine(version)]
+		if !ok {
+			return info, fmt.Errorf("Unknown ABI for Node.js %s", version)
+		}
+		info.NodeModuleVersion = abi
+	}
+
+	for _, e := range electronReleases {
+		if abiLine(e.NodeVersion) == abiLine(version) {
+			info.Electron = append(info.Electron, e)
+		}
+	}
+	return info, nil
+}
+
+// FindNodeForAbi returns the Node.js lines (newest first) and Electron releases built for a NODE_MODULE_VERSION,
+// e.g. the number quoted in a "was compiled against a different Node.js version" error
+// FindNodeForAbi 返回针对某个 NODE_MODULE_VERSION 构建的 Node.js 版本线（从新到旧）和 Electron 版本，
+// 例如 "was compiled against a different Node.js version" 错误中给出的数字
+func (a *App) FindNodeForAbi(moduleVersion int) ([]string, []ElectronInfo) {
+	var lines []string
+	for line, abi := range nodeABIs {
+		if abi == moduleVersion {
+			lines = append(lines, line)
+		}
+	}
+	// 映射的遍历顺序不固定，按版本从新到旧排列
+	// Map iteration order is random, list the lines newest first
+	sort.Slice(lines, func(i, j int) bool { return compareSemver(lines[i], lines[j]) > 0 })
+	var electron []ElectronInfo
+	for _, e := range electronReleases {
+		if e.NodeModuleVersion == moduleVersion {
+			electron = append(electron, e)
+		}
+	}
+	return lines, electron
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// accessibilityPollInterval is how often the OS accessibility settings are checked for changes
+// accessibilityPollInterval 是检查系统辅助功能设置变化的间隔
+const accessibilityPollInterval = 5 * time.Second
+
+// AccessibilityHints are the OS accessibility settings the frontend adapts to
+// AccessibilityHints 是前端需要适配的系统辅助功能设置
+type AccessibilityHints struct {
+	HighContrast  bool
+	ReducedMotion bool
+}
+
+// GetAccessibilityHints returns the current high contrast and reduced motion settings of Windows
+// GetAccessibilityHints 返回 Windows 当前的高对比度和减少动画设置
+func (a *App) GetAccessibilityHints() AccessibilityHints {
+	return AccessibilityHints{
+		HighContrast:  highContrastEnabled(),
+		ReducedMotion: animationsDisabled(),
+	}
+}
+
+// watchAccessibility emits "accessibility-changed" whenever the hints change, so views do not poll the OS themselves
+// watchAccessibility 在辅助功能设置变化时发送 "accessibility-changed" 事件，使各视图无需自行轮询系统
+func (a *App) watchAccessibility() {
+	ticker := time.NewTicker(accessibilityPollInterval)
+	defer ticker.Stop()
+
+	last := a.GetAccessibilityHints()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if hints := a.GetAccessibilityHints(); hints != last {
+				last = hints
+				runtime.EventsEmit(a.ctx, "accessibility-changed", hints)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"unsafe"
+)
+
+var procSystemParametersInfoW = moduser32.NewProc("SystemParametersInfoW")
+
+const (
+	spiGetHighContrast        = 0x0042
+	spiGetClientAreaAnimation = 0x1042
+	hcfHighContrastOn         = 0x00000001
+)
+
+// highContrast mirrors the Win32 HIGHCONTRASTW structure
+// highContrast 对应 Win32 的 HIGHCONTRASTW 结构体
+type highContrast struct {
+	cbSize            uint32
+	dwFlags           uint32
+	lpszDefaultScheme *uint16
+}
+
+// highContrastEnabled reports whether a Windows high contrast theme is active
+// highContrastEnabled 判断是否启用了 Windows 高对比度主题
+func highContrastEnabled() bool {
+	hc := highContrast{}
+	hc.cbSize = uint32(unsafe.Sizeof(hc))
+	ret, _, _ := procSystemParametersInfoW.Call(spiGetHighContrast, uintptr(hc.cbSize), uintptr(unsafe.Pointer(&hc)), 0)
+	return ret != 0 && hc.dwFlags&hcfHighContrastOn != 0
+}
+
+// animationsDisabled reports whether "Show animations in Windows" is turned off
+// animationsDisabled 判断是否关闭了“在 Windows 中显示动画”
+func animationsDisabled() bool {
+	var enabled int32
+	ret, _, _ := procSystemParametersInfoW.Call(spiGetClientAreaAnimation, 0, uintptr(unsafe.Pointer(&enabled)), 0)
+	return ret != 0 && enabled == 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// extractedNodeDirRegex matches the folder names of the official zip archives, such as "node-v20.11.0-win-x64"
+// extractedNodeDirRegex 匹配官方 zip 压缩包解压出的目录名，例如 "node-v20.11.0-win-x64"
+var extractedNodeDirRegex = regexp.MustCompile(`(?i)^node-v(\d+\.\d+\.\d+)-win-(x64|x86|arm64)$`)
+
+// AdoptableInstall is a manually extracted Node.js folder that can be adopted into the nvm root
+// AdoptableInstall 表示可以纳入 nvm 根目录管理的手动解压的 Node.js 目录
+type AdoptableInstall struct {
+	Path      string
+	Version   string
+	Arch      string
+	Size      int64
+	SizeText  string
+	Installed bool // nvm 中已有该版本 / nvm already has this version
+}
+
+// extractedNodeDir returns the directory holding node.exe, descending into the single nested folder that
+// extracting a zip into a folder of the same name produces
+// extractedNodeDir 返回包含 node.exe 的目录；解压到同名目录时会多出一层嵌套目录，此时进入该目录
+func extractedNodeDir(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "node.exe")); err == nil {
+		return path
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return path
+	}
+	return filepath.Join(path, entries[0].Name())
+}
+
+// adoptionSearchDirs returns the folders where users usually extract downloaded archives
+// adoptionSearchDirs 返回用户通常解压下载文件的目录
+func adoptionSearchDirs() []string {
+	home, _ := os.UserHomeDir()
+	dirs := []string{os.Getenv("SystemDrive") + `\`}
+	if home != "" {
+		dirs = append(dirs, home, filepath.Join(home, "Downloads"), filepath.Join(home, "Desktop"), filepath.Join(home, "Documents"))
+	}
+	return dirs
+}
+
+// FindAdoptableInstalls looks for folders extracted from the official Node.js zip archives in the downloads,
+// desktop, documents and home folders and at the root of the system drive
+// FindAdoptableInstalls 在下载、桌面、文档、主目录以及系统盘根目录中查找由官方 Node.js zip 压缩包解压出的目录
+func (a *App) FindAdoptableInstalls() ([]AdoptableInstall, error) {
+	root := a.nvmRoot()
+	if root == "" {
+		return nil, fmt.Errorf("nvm root not found")
+	}
+
+	f := a.formatter()
+	var found []AdoptableInstall
+	seen := map[string]bool{}
+	for _, dir := range adoptionSearchDirs() {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			m := extractedNodeDirRegex.FindStringSubmatch(entry.Name())
+			path := filepath.Join(dir, entry.Name())
+			if m == nil || !entry.IsDir() || seen[strings.ToLower(path)] {
+				continue
+			}
+			seen[strings.ToLower(path)] = true
+			if diagnoseVersionDir(extractedNodeDir(path)) != "" {
+				continue
+			}
+			install := AdoptableInstall{Path: path, Version: m[1], Arch: normalizeArch(m[2]), Size: dirSize(path)}
+			install.SizeText = f.size(install.Size)
+			if _, err := os.Stat(versionDir(root, install.Version)); err == nil {
+				install.Installed = true
+			}
+			found = append(found, install)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return compareSemver(found[i].Version, found[j].Version) > 0 })
+	return found, nil
+}
+
+// AdoptExistingInstall validates a manually extracted Node.js folder and moves it into the nvm root under its
+// version's name, so nvm can switch to it without downloading it again. A folder on another drive is linked
+// with a directory junction instead of being copied
+// AdoptExistingInstall 校验手动解压的 Node.js 目录，并以其版本名移动到 nvm 根目录，使 nvm 无需重新下载即可切换。
+// 位于其他磁盘的目录通过目录联接链接，而不是复制
+func (a *App) AdoptExistingInstall(path string) (string, error) {
+	path = extractedNodeDir(filepath.Clean(strings.Trim(strings.TrimSpace(path), `"`)))
+	if problem := diagnoseVersionDir(path); problem != "" {
+		return "", fmt.Errorf("%s is not a complete Node.js folder: %s", path, problem)
+	}
+	version := a.nodeExeVersion(path)
+	if version == "" {
+		return "", fmt.Errorf("No working node.exe found in %s", path)
+	}
+	root := a.nvmRoot()
+	if root == "" {
+		return "", fmt.Errorf("nvm root not found")
+	}
+	if strings.HasPrefix(strings.ToLower(path), strings.ToLower(filepath.Clean(root))+`\`) {
+		return "", fmt.Errorf("%s is already inside the nvm root", path)
+	}
+	target := versionDir(root, version)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("Node.js %s is already installed", version)
+	}
+
+	method := "moved"
+	if !strings.EqualFold(filepath.VolumeName(path), filepath.VolumeName(root)) {
+		// 跨磁盘移动需要复制全部文件，目录联接可立即完成且不占用额外空间
+		// Moving across drives copies every file, a junction is instant and takes no extra space
+		if output, err := a.runHidden("cmd.exe", "/D", "/C", "mklink", "/J", target, path); err != nil {
+			a.audit("adopt-node", path, "failed")
+			return "", fmt.Errorf("Error linking %s: %s", path, output)
+		}
+		method = "linked"
+	} else if err := moveTree(a.fs, path, target); err != nil {
+		os.RemoveAll(longPath(target))
+		a.audit("adopt-node", path, "failed")
+		return "", fmt.Errorf("Error moving %s: %v", path, err)
+	}
+
+	a.audit("adopt-node", fmt.Sprintf("%s -> %s (%s)", path, target, method), "success")
+	a.invalidateAvailableVersions()
+	a.invalidateVersionMetadata(version)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	return fmt.Sprintf("Adopted Node.js %s from %s (%s)", version, path, method), nil
+}
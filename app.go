@@ -1,18 +1,18 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -24,6 +24,19 @@ type NodeVersionInfo struct {
 	Version    string
 	Status     string
 	NpmVersion string // 新增字段，表示 npm 版本
+	Source     string // 版本信息来源：官方地址、镜像地址、nvm 或额外来源名称
+	Date       string // 发布日期 YYYY-MM-DD / Release date as YYYY-MM-DD
+	DateText   string // 按区域设置格式化的发布日期 / Release date formatted for the locale
+	V8         string // 内置 V8 版本 / Bundled V8 version
+	OpenSSL    string // 内置 OpenSSL 版本 / Bundled OpenSSL version
+	Corepack   bool   // 是否附带 corepack / Whether corepack is bundled
+	Npx        bool   // 是否附带 npx / Whether npx is bundled
+	StatusText string // 按区域设置和标签文件显示的状态 / Status text for the locale and labels file
+	Labels     []VersionLabel
+	Note       string // 用户记录的安装原因 / Why the user keeps this version
+	Favorite   bool   // 是否已收藏 / Whether the version is starred
+	LTS        string // LTS 代号，非 LTS 为空 / LTS codename, empty when not LTS
+	Group      string // 按主版本分组时的分组标题 / group title when grouped by major
 }
 
 // NodeVersion represents an installed Node.js version
@@ -31,6 +44,7 @@ type NodeVersionInfo struct {
 type NodeVersion struct {
 	Version   string
 	IsCurrent bool
+	Favorite  bool
 }
 
 // NodeAPIResponse represents the structure from Node.js API response
@@ -49,15 +63,90 @@ type App struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	debugMode   bool
+	safeMode    bool
 	enableLogs  bool
 	logFilePath string
 	lastActive  time.Time
 	mu          sync.RWMutex
+
+	settingsPath string
+	settings     Settings
+	settingsMu   sync.RWMutex
+	saveMu       sync.Mutex // 串行化设置文件的写入 / serializes writes of the settings file
+
+	webhookQueue chan webhookDelivery
+
+	startupIssues []StartupIssue
+	issuesMu      sync.Mutex
+
+	auditPath string
+	auditMu   sync.Mutex
+
+	pendingPlans map[string]ElevationPlan
+	plansMu      sync.Mutex
+
+	pendingConfirmations map[string]PendingConfirmation
+	confirmMu            sync.Mutex
+
+	npmAuditCache map[string]NpmAuditSummary
+	auditCacheMu  sync.Mutex
+
+	lastIndexFetch IndexFetchInfo
+	indexMu        sync.Mutex
+
+	metrics   *Metrics
+	apiServer *http.Server
+	apiMu     sync.Mutex
+
+	apiToken   localAPIToken
+	apiTokenMu sync.Mutex
+	apiLimiter rateLimiter
+
+	connectedApps  connectedApps
+	focus          focusMode
+	power          powerState
+	inventory      inventoryState
+	commands       runningCommands
+	awake          keepAwake
+	downloadSlots  downloadSlots
+	progress       installTrackers
+	summaries      releaseSummaryStore
+	jobs           scheduledJobs
+	versionDetails versionDetailsCache
+
+	switchFailureMu   sync.Mutex
+	lastSwitchFailure *SwitchFailure
+
+	secretsMu sync.Mutex
+
+	availableCache availableVersionsCache
+
+	eventHub   eventHub
+	indexCache indexCache
+	nvmFlight  flightGroup
+	tasks      taskPool
+
+	versionMeta versionMetadataStore
+	npmVersions npmVersionCache
+
+	recordCorpus atomic.Bool
+	mockBackend  bool
+
+	executor   Executor
+	clock      Clock
+	fs         FileSystem
+	httpClient *http.Client
 }
 
 // NewApp creates a new App application struct
 // NewApp 创建一个新的 App 应用程序结构体
 func NewApp() *App {
+	return newAppWithDependencies(defaultDependencies())
+}
+
+// newAppWithDependencies creates an App using the given executor, clock, file system and HTTP client
+// newAppWithDependencies 使用指定的命令执行器、时钟、文件系统和 HTTP 客户端创建 App
+func newAppWithDependencies(deps Dependencies) *App {
 	// 获取可执行文件所在目录
 	// Get the directory of the executable file
 	execPath, err := os.Executable()
@@ -66,12 +155,35 @@ func NewApp() *App {
 		logPath = filepath.Join(filepath.Dir(execPath), "nvm-switcher.log")
 	}
 
-	return &App{
-		debugMode:   false,
-		enableLogs:  false,
-		logFilePath: logPath,
-		lastActive:  time.Now(),
+	app := &App{
+		debugMode:     false,
+		enableLogs:    false,
+		logFilePath:   logPath,
+		lastActive:    deps.Clock.Now(),
+		settingsPath:  settingsFilePath(),
+		settings:      defaultSettings(),
+		webhookQueue:  make(chan webhookDelivery, webhookQueueSize),
+		metrics:       &Metrics{bandwidth: bandwidthLedger{fs: deps.FS, clock: deps.Clock}},
+		auditPath:     auditFilePath(),
+		pendingPlans:  make(map[string]ElevationPlan),
+		npmAuditCache: make(map[string]NpmAuditSummary),
+		executor:      deps.Executor,
+		clock:         deps.Clock,
+		fs:            deps.FS,
+		httpClient:    deps.HTTPClient,
 	}
+
+	// 加载用户设置，失败时保留默认设置
+	// Load user settings, keeping the defaults on failure
+	if err := app.loadSettings(); err != nil {
+		fmt.Printf("Debug: %v\n", err)
+	}
+
+	// 将旧版本明文保存的凭据移入加密存储
+	// Move credentials stored in plain text by older versions into the encrypted store
+	app.migratePlainSecrets()
+
+	return app
 }
 
 // updateLastActive updates the last active timestamp for the application
@@ -79,7 +191,7 @@ func NewApp() *App {
 func (a *App) updateLastActive() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.lastActive = time.Now()
+	a.lastActive = a.clock.Now()
 }
 
 // checkHealth checks if the application has been active recently
@@ -87,7 +199,7 @@ func (a *App) updateLastActive() {
 func (a *App) checkHealth() bool {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	return time.Since(a.lastActive) < time.Minute*5
+	return a.clock.Now().Sub(a.lastActive) < time.Minute*5
 }
 
 // startup initializes the application when it starts
@@ -100,9 +212,11 @@ func (a *App) startup(ctx context.Context) {
 	a.updateLastActive()
 	a.logToFile("Application started")
 
-	// 启动健康检查
-	// Start health check
-	go a.healthCheck()
+	if a.safeMode {
+		a.logToFile("Running in safe mode: networking, watchers and hooks are disabled")
+	}
+	// 其余子系统在窗口显示后由 runDeferredStartup 初始化
+	// The remaining subsystems are initialized by runDeferredStartup once the window is shown
 }
 
 // healthCheck periodically checks if the application is still healthy
@@ -134,6 +248,9 @@ func (a *App) shutdown(ctx context.Context) {
 	}
 	a.mu.Unlock()
 
+	// 上下文已取消，restartLocalAPI 只会停止本地接口
+	// The context is cancelled, so restartLocalAPI only stops the local API
+	a.restartLocalAPI()
 	a.logToFile("Application shutting down")
 }
 
@@ -143,6 +260,7 @@ func (a *App) beforeClose() bool {
 	if a.enableLogs {
 		a.logToFile("Application closing initiated")
 	}
+	a.saveWindowGeometry()
 	return false
 }
 
@@ -157,7 +275,7 @@ func (a *App) logToFile(message string) {
 	defer a.mu.Unlock()
 
 	logDir := filepath.Dir(a.logFilePath)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
+	if err := a.fs.MkdirAll(logDir, 0755); err != nil {
 		fmt.Printf("Failed to create log directory: %v\n", err)
 		userHome, err := os.UserHomeDir()
 		if err == nil {
@@ -168,32 +286,77 @@ func (a *App) logToFile(message string) {
 		}
 	}
 
-	f, err := os.OpenFile(a.logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-
-	timeStamp := time.Now().Format("2006-01-02 15:04:05")
+	timeStamp := a.clock.Now().Format("2006-01-02 15:04:05")
 	logMessage := fmt.Sprintf("[%s] %s\n", timeStamp, message)
-	f.Write([]byte(logMessage))
+	a.fs.AppendFile(a.logFilePath, []byte(logMessage), 0644)
 }
 
-// executeNvmCommand runs the specified NVM command with provided arguments and returns the output
-// executeNvmCommand 运行指定的 NVM 命令并返回其输出
+// executeNvmCommand runs the specified NVM command with provided arguments and returns the output.
+// Identical read-only commands issued in quick succession share a single nvm invocation
+// executeNvmCommand 运行指定的 NVM 命令并返回其输出。短时间内重复的只读命令共享同一次 nvm 调用
 func (a *App) executeNvmCommand(args ...string) ([]byte, error) {
 	a.updateLastActive()
 
-	cmd := exec.Command("nvm", args...)
+	if key := nvmCommandKey(args); key != "" {
+		return a.nvmFlight.do(key, nvmResultReuseWindow, func() ([]byte, error) {
+			return a.runNvmCommand(args)
+		})
+	}
+	// 切换、安装等命令会改变 nvm 的状态，之后的只读命令需要重新执行
+	// Commands such as use and install change nvm's state, so later read-only commands must run again
+	defer a.nvmFlight.forget()
+	return a.runNvmCommand(args)
+}
 
-	if !a.debugMode {
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			HideWindow: true,
-		}
+// nvmCommand describes the command running nvm with args. The mock backend scripts nvm by name, so nothing is
+// resolved there
+// nvmCommand 描述以 args 运行 nvm 的命令。模拟后端按名称模拟 nvm，因此不做解析
+func (a *App) nvmCommand(args []string) (CommandSpec, error) {
+	if a.mockBackend {
+		return CommandSpec{Name: "nvm", Args: args}, nil
+	}
+	spec, err := nvmCommandSpec(nvmExecutable(), args)
+	spec.Hidden = !a.debugMode
+	return spec, err
+}
+
+// runNvmCommand invokes nvm once, decoding, recording and logging its output
+// runNvmCommand 调用一次 nvm，并对输出进行解码、录制和日志记录
+func (a *App) runNvmCommand(args []string) ([]byte, error) {
+	spec, err := a.nvmCommand(args)
+	var output []byte
+	if err == nil {
+		output, err = a.executor.CombinedOutput(spec)
 	}
+	// 中文系统上 nvm 的输出可能是 GBK 编码
+	// nvm output may be GBK encoded on Chinese Windows
+	output = decodeConsoleOutput(output)
+	a.recordNvmOutput(args, output, err)
+	if err != nil {
+		a.metrics.recordError()
+		a.logToFile(fmt.Sprintf("Command failed: nvm %v\nError: %v\nOutput: %s\n",
+			args, err, string(output)))
+	}
+
+	return output, err
+}
+
+// executeNvmCommandStreaming runs an NVM command like executeNvmCommand, passing each output line to onLine as it arrives.
+// Ending ctx terminates nvm together with the processes it started
+// executeNvmCommandStreaming 与 executeNvmCommand 相同地运行 NVM 命令，并在输出到达时逐行传给 onLine。
+// ctx 结束时终止 nvm 及其启动的进程
+func (a *App) executeNvmCommandStreaming(ctx context.Context, onLine func(string), args ...string) ([]byte, error) {
+	a.updateLastActive()
 
-	output, err := cmd.CombinedOutput()
+	spec, err := a.nvmCommand(args)
+	var output []byte
+	if err == nil {
+		output, err = a.runStreaming(ctx, spec, onLine)
+	}
+	a.recordNvmOutput(args, output, err)
+	a.nvmFlight.forget()
 	if err != nil {
+		a.metrics.recordError()
 		a.logToFile(fmt.Sprintf("Command failed: nvm %v\nError: %v\nOutput: %s\n",
 			args, err, string(output)))
 	}
@@ -201,63 +364,250 @@ func (a *App) executeNvmCommand(args ...string) ([]byte, error) {
 	return output, err
 }
 
+// runStreaming runs a command with stdout and stderr combined, passing each line to onLine as it arrives.
+// Ending ctx terminates the command together with the processes it started
+// runStreaming 运行命令并合并标准输出和标准错误，在输出到达时逐行传给 onLine。ctx 结束时终止该命令及其启动的进程
+func (a *App) runStreaming(ctx context.Context, spec CommandSpec, onLine func(string)) ([]byte, error) {
+	pr, pw := io.Pipe()
+
+	var output bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(io.TeeReader(pr, &output))
+		scanner.Split(scanLinesOrCR)
+		for scanner.Scan() {
+			onLine(string(decodeConsoleOutput(scanner.Bytes())))
+		}
+		// 扫描出错时继续读取剩余输出，避免命令阻塞
+		// Keep draining on scanner errors so the command never blocks
+		io.Copy(io.Discard, pr)
+	}()
+
+	err := a.executor.Run(ctx, spec, pw, pw)
+	pw.Close()
+	<-done
+	return decodeConsoleOutput(output.Bytes()), err
+}
+
 // InstallNodeVersion installs the specified Node.js version
 // InstallNodeVersion 安装指定的 Node.js 版本
 func (a *App) InstallNodeVersion(version string) string {
+	if msg, ok := a.requireConfirmation(OperationInstall, version); !ok {
+		return msg
+	}
+	return a.installNodeVersionWithinCap(version)
+}
+
+// installNodeVersionWithinCap installs a version that needs no further confirmation unless it would exceed the
+// soft monthly bandwidth cap, still checking the disk space
+// installNodeVersionWithinCap 在不超出每月流量软上限时安装无需再确认的版本，仍会检查磁盘空间
+func (a *App) installNodeVersionWithinCap(version string) string {
+	// 超出流量上限时暂缓安装，前端可确认后调用 InstallNodeVersionOverBandwidthCap
+	// Hold back the install over the cap, the frontend may confirm and call InstallNodeVersionOverBandwidthCap
+	if reason := a.bandwidthCapWarning(version); reason != "" {
+		errMsg := fmt.Sprintf("Error installing Node.js %s: %s", version, reason)
+		a.logToFile(errMsg)
+		a.emitInstallProgress(InstallProgress{Version: version, Phase: PhaseFailed, Percent: -1, Message: errMsg})
+		return errMsg
+	}
+	return a.installNodeVersionIfSpace(version)
+}
+
+// installNodeVersionIfSpace installs a version unless the nvm root drive is low on space
+// installNodeVersionIfSpace 在 nvm 根目录所在磁盘空间充足时安装指定版本
+func (a *App) installNodeVersionIfSpace(version string) string {
+	// 剩余空间不足时阻止安装，前端可确认后调用 InstallNodeVersionIgnoringDiskSpace
+	// Block the install on low disk space, the frontend may confirm and call InstallNodeVersionIgnoringDiskSpace
+	if check := a.checkInstallSpace(); !check.Sufficient {
+		errMsg := fmt.Sprintf("Error installing Node.js %s: %s", version, check.Message)
+		a.logToFile(errMsg)
+		a.emitInstallProgress(InstallProgress{Version: version, Phase: PhaseFailed, Percent: -1, Message: errMsg})
+		return errMsg
+	}
+	return a.installNodeVersion(version)
+}
+
+// installNodeVersion runs the install without the disk space check
+// installNodeVersion 执行安装，不检查磁盘空间
+func (a *App) installNodeVersion(version string) string {
+	defer a.beginInteractive("install " + version)()
+	// 防止笔记本在下载中途睡眠导致安装不完整
+	// Keep laptops from sleeping mid-download and leaving a half-finished install
+	defer a.stayAwake("install " + version)()
 	a.logToFile(fmt.Sprintf("Attempting to install Node.js version: %s", version))
-	output, err := a.executeNvmCommand("install", version)
+	args := []string{"install", version}
+	arch, warning := a.installArch(version)
+	if arch != "" {
+		args = append(args, nvmArchArg(arch))
+	}
+	if warning != "" {
+		a.logToFile(warning)
+	}
+	tracker := a.startProgressTracking(version)
+	defer a.stopProgressTracking(version)
+	a.emitInstallProgress(InstallProgress{Version: version, Phase: PhaseStarted, Percent: -1, Message: warning})
+	release := a.acquireDownloadSlot()
+	defer release()
+	ctx, done := a.beginCommand("install "+version, installTimeout)
+	defer done()
+	output, err := a.executeNvmCommandStreaming(ctx, func(line string) {
+		tracker.observeLine(line)
+		if progress, ok := parseInstallLine(version, line); ok {
+			a.emitInstallProgress(progress)
+		}
+	}, args...)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error installing Node.js %s: %s", version, string(output))
+		errMsg := a.withKnownIssueGuidance(OperationInstall, fmt.Sprintf("Error installing Node.js %s: %s", version, string(output)))
 		a.logToFile(errMsg)
+		a.emitInstallProgress(InstallProgress{Version: version, Phase: PhaseFailed, Percent: -1, Message: errMsg})
+		a.metrics.recordInstall(false)
+		a.notifyWebhooks(WebhookEventInstall, version, false, errMsg)
 		return errMsg
 	}
 	successMsg := fmt.Sprintf("Successfully installed Node.js %s", version)
 	a.logToFile(successMsg)
+	a.emitInstallProgress(InstallProgress{Version: version, Phase: PhaseCompleted, Percent: 100, Message: successMsg})
+	a.metrics.recordInstall(true)
+	a.recordInstallDownload(version)
+	a.notifyWebhooks(WebhookEventInstall, version, true, successMsg)
+	a.invalidateAvailableVersions()
+	a.invalidateVersionMetadata(version)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("project-shims", TaskPriorityBackground, a.refreshProjectShims)
 	return successMsg
 }
 
 // UninstallNodeVersion uninstalls the specified Node.js version
 // UninstallNodeVersion 卸载指定的 Node.js 版本
 func (a *App) UninstallNodeVersion(version string) string {
+	if msg, ok := a.requireConfirmation(OperationUninstall, version); !ok {
+		return msg
+	}
+	return a.uninstallNodeVersionWithinCap(version)
+}
+
+// uninstallNodeVersionWithinCap uninstalls a version that needs no further confirmation, still checking for running processes
+// uninstallNodeVersionWithinCap 卸载无需再确认的版本，仍会检查正在运行的进程
+func (a *App) uninstallNodeVersionWithinCap(version string) string {
+	// 仍有进程在使用该版本时阻止卸载，前端可确认后调用 UninstallNodeVersionIgnoringProcesses
+	// Block the uninstall while processes use the version, the frontend may confirm and call UninstallNodeVersionIgnoringProcesses
+	if processes := a.processesUsingVersion(version); len(processes) > 0 {
+		var list []string
+		for _, p := range processes {
+			list = append(list, fmt.Sprintf("PID %d %s", p.PID, p.Path))
+		}
+		errMsg := fmt.Sprintf("Error uninstalling Node.js %s: %d 个进程正在使用该版本 / %d running processes use this version:\n%s",
+			version, len(processes), len(processes), strings.Join(list, "\n"))
+		a.logToFile(errMsg)
+		return errMsg
+	}
+	return a.uninstallNodeVersion(version)
+}
+
+// uninstallNodeVersion runs the uninstall without the running process check
+// uninstallNodeVersion 执行卸载，不检查正在运行的进程
+func (a *App) uninstallNodeVersion(version string) string {
+	defer a.beginInteractive("uninstall " + version)()
 	a.logToFile(fmt.Sprintf("Attempting to uninstall Node.js version: %s", version))
+	// 默认将版本目录移入回收站，以便误删后恢复；失败时仍由 nvm 删除
+	// By default the version directory goes to the Recycle Bin so a mistake can be undone, nvm deletes it on failure
+	if !a.mockBackend && !a.currentSettings().PermanentUninstall {
+		err := a.trashVersion(strings.TrimPrefix(version, "v"))
+		if err == nil {
+			successMsg := fmt.Sprintf("Successfully uninstalled Node.js %s (moved to the Recycle Bin)", version)
+			a.logToFile(successMsg)
+			a.audit("uninstall-to-trash", version, "success")
+			a.afterUninstall(version)
+			return successMsg
+		}
+		a.logToFile(fmt.Sprintf("Error moving Node.js %s to the Recycle Bin, deleting it instead: %v", version, err))
+	}
 	output, err := a.executeNvmCommand("uninstall", version)
 	if err != nil {
-		errMsg := fmt.Sprintf("Error uninstalling Node.js %s: %s", version, string(output))
+		errMsg := a.withKnownIssueGuidance(OperationUninstall, fmt.Sprintf("Error uninstalling Node.js %s: %s", version, string(output)))
 		a.logToFile(errMsg)
 		return errMsg
 	}
 	successMsg := fmt.Sprintf("Successfully uninstalled Node.js %s", version)
 	a.logToFile(successMsg)
+	a.afterUninstall(version)
 	return successMsg
 }
 
+// afterUninstall refreshes the caches and lists that include the removed version
+// afterUninstall 刷新包含已删除版本的缓存和列表
+func (a *App) afterUninstall(version string) {
+	a.invalidateAvailableVersions()
+	a.invalidateVersionMetadata(version)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("project-shims", TaskPriorityBackground, a.refreshProjectShims)
+}
+
 // SwitchNodeVersion switches to the specified Node.js version
 // SwitchNodeVersion 切换到指定的 Node.js 版本
 func (a *App) SwitchNodeVersion(version string) string {
+	if msg, ok := a.requireConfirmation(OperationSwitch, version); !ok {
+		return msg
+	}
+	return a.switchNodeVersion(version)
+}
+
+// switchNodeVersion switches without consulting the confirmation policy
+// switchNodeVersion 不经确认策略直接切换版本
+func (a *App) switchNodeVersion(version string) string {
+	defer a.beginInteractive("switch " + version)()
 	a.logToFile(fmt.Sprintf("Attempting to switch to Node.js version: %s", version))
+	// 切换前记录进程，此时通过符号链接启动的进程仍属于旧版本
+	// Snapshot the processes first, while those started through the symlink still belong to the old version
+	before, _ := a.GetRunningNodeProcesses()
+	previous, _ := a.currentNodeVersion()
 	output, err := a.executeNvmCommand("use", version)
+	// 即使切换失败或被撤销，当前版本也可能已改变
+	// The current version may have changed even when the switch failed or was reverted
+	a.clearVersionDetails()
 	if err != nil {
-		errMsg := fmt.Sprintf("Error switching to Node.js %s: %s", version, string(output))
+		errMsg := a.withKnownIssueGuidance(OperationSwitch, fmt.Sprintf("Error switching to Node.js %s: %s", version, string(output)))
 		a.logToFile(errMsg)
+		a.metrics.recordSwitch(false)
+		a.notifyWebhooks(WebhookEventSwitch, version, false, errMsg)
 		return errMsg
 	}
+	// nvm use 可能在符号链接未更新时仍报告成功，因此确认 node -v 与目标一致，否则切换回原版本
+	// nvm use may report success although the symlink was not updated, so confirm node -v matches or switch back
+	if !a.mockBackend {
+		if observed, problem := a.verifySwitch(version); problem != "" {
+			failure := a.revertFailedSwitch(strings.TrimPrefix(version, "v"), strings.TrimPrefix(previous, "v"), observed, problem)
+			errMsg := a.withKnownIssueGuidance(OperationSwitch, fmt.Sprintf("Error switching to Node.js %s: %s", version, problem))
+			if failure.Reverted {
+				errMsg += fmt.Sprintf(" (reverted to Node.js %s)", failure.Previous)
+			}
+			a.logToFile(errMsg)
+			a.metrics.recordSwitch(false)
+			a.notifyWebhooks(WebhookEventSwitch, version, false, errMsg)
+			return errMsg
+		}
+	}
 
 	successMsg := fmt.Sprintf("Successfully switched to Node.js %s", version)
 	a.logToFile(successMsg)
+	a.metrics.recordSwitch(true)
+	a.notifyWebhooks(WebhookEventSwitch, version, true, successMsg)
+	a.recordVersionUsed(version)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("tray-tooltip", TaskPriorityNormal, a.refreshTrayTooltip)
+	a.submitTask("tray-recent", TaskPriorityNormal, a.refreshTrayRecent)
+	a.submitTask("post-switch-rebuilds", TaskPriorityNormal, a.runPostSwitchRebuilds)
+	a.submitTask("corepack-repair", TaskPriorityNormal, a.runPostSwitchCorepackRepair)
+	a.submitTask("engine-warnings "+version, TaskPriorityNormal, func() { a.reportEngineWarnings(version) })
+	a.submitTask("stale-processes "+version, TaskPriorityNormal, func() { a.reportStaleProcesses(before, version) })
 	return successMsg
 }
 
-// GetInstalledNodeVersions retrieves the Node.js versions installed via NVM on the system
-// GetInstalledNodeVersions 获取系统上通过 NVM 安装的 Node.js 版本
-func (a *App) GetInstalledNodeVersions() ([]NodeVersion, error) {
-	a.logToFile("Fetching installed Node.js versions")
-	output, err := a.executeNvmCommand("ls")
-	if err != nil {
-		a.logToFile(fmt.Sprintf("Error fetching installed versions: %v", err))
-		return nil, fmt.Errorf("Error fetching installed versions: %v", err)
-	}
-
-	lines := strings.Split(string(output), "\n")
+// parseNvmList parses the output of "nvm ls" into the installed versions, marking the one prefixed with *
+// parseNvmList 将 "nvm ls" 的输出解析为已安装版本，并标记以 * 开头的当前版本
+func parseNvmList(output string) []NodeVersion {
+	lines := strings.Split(output, "\n")
 	var versions []NodeVersion
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -275,6 +625,39 @@ func (a *App) GetInstalledNodeVersions() ([]NodeVersion, error) {
 		version := strings.Fields(line)[0]
 		versions = append(versions, NodeVersion{Version: version, IsCurrent: isCurrent})
 	}
+	return versions
+}
+
+// nvmAvailableVersionRegex matches the versions in the "nvm ls available" table
+// nvmAvailableVersionRegex 匹配 "nvm ls available" 表格中的版本号
+var nvmAvailableVersionRegex = regexp.MustCompile(`\b\d+\.\d+\.\d+\b`)
+
+// parseNvmAvailable extracts the versions from the table printed by "nvm ls available"
+// parseNvmAvailable 从 "nvm ls available" 输出的表格中提取版本号
+func parseNvmAvailable(output string) []string {
+	var versions []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, "CURRENT") || strings.Contains(line, "-") {
+			continue
+		}
+		versions = append(versions, nvmAvailableVersionRegex.FindAllString(line, -1)...)
+	}
+	return versions
+}
+
+// GetInstalledNodeVersions retrieves the Node.js versions installed via NVM on the system
+// GetInstalledNodeVersions 获取系统上通过 NVM 安装的 Node.js 版本
+func (a *App) GetInstalledNodeVersions() ([]NodeVersion, error) {
+	a.logToFile("Fetching installed Node.js versions")
+	output, err := a.executeNvmCommand("ls")
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error fetching installed versions: %v", err))
+		return nil, fmt.Errorf("Error fetching installed versions: %v", err)
+	}
+
+	pref := a.currentSettings().VersionSort
+	versions := a.installedWithFavorites(sortInstalled(parseNvmList(string(output)), pref, a.installedLTSLines(pref)))
 
 	if a.debugMode {
 		fmt.Println("Installed Versions:")
@@ -288,7 +671,6 @@ func (a *App) GetInstalledNodeVersions() ([]NodeVersion, error) {
 	}
 
 	a.logToFile(fmt.Sprintf("Found %d installed versions", len(versions)))
-	a.logToFile(fmt.Sprintf("Found %d installed versions", versions))
 	return versions, nil
 }
 
@@ -299,67 +681,64 @@ func (a *App) GetInstalledNodeVersions() ([]NodeVersion, error) {
 func (a *App) GetAvailableNodeVersions() ([]NodeVersionInfo, error) {
 	a.logToFile("Fetching available Node.js versions")
 
-	// Attempt to fetch available versions from Node.js API
-	// 尝试从 Node.js 官方 API 获取可用版本信息
-	resp, err := http.Get("https://nodejs.org/dist/index.json")
-	if err == nil && resp.StatusCode == http.StatusOK {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
+	// Attempt to fetch available versions from the Node.js API, failing over to the configured mirrors
+	// 尝试从 Node.js 官方 API 获取可用版本信息，失败时依次切换到配置的镜像
+	nodeVersions, fetchInfo, err := a.fetchDistIndex()
+	if err == nil {
+		// Process API data to generate available versions
+		// 处理 API 数据以生成可用版本列表
+		var versions []NodeVersionInfo
+		installedVersions, err := a.GetInstalledNodeVersions()
 		if err != nil {
-			a.logToFile(fmt.Sprintf("Error reading API response: %v", err))
-		} else {
-			var nodeVersions []NodeAPIResponse
-			err = json.Unmarshal(body, &nodeVersions)
-			if err != nil {
-				a.logToFile(fmt.Sprintf("Error parsing JSON response: %v", err))
-			} else {
-				// Process API data to generate available versions
-				// 处理 API 数据以生成可用版本列表
-				var versions []NodeVersionInfo
-				installedVersions, err := a.GetInstalledNodeVersions()
-				if err != nil {
-					a.logToFile(fmt.Sprintf("Error fetching installed versions: %s", err))
-					return nil, fmt.Errorf("Error fetching installed versions: %s", err)
-				}
-				installedMap := make(map[string]bool)
-				for _, installed := range installedVersions {
-					installedMap[installed.Version] = true
-				}
-
-				for _, versionInfo := range nodeVersions {
-					cleanVersion := strings.TrimPrefix(versionInfo.Version, "v")
-					status := "Not Installed"
-					if installedMap[cleanVersion] {
-						status = "Installed"
-					}
-
-					// 处理 LTS 字段：可以是 bool 或字符串
-					// ltsValue := "No"
-					// switch v := versionInfo.LTS.(type) {
-					// case bool:
-					// 	if v {
-					// 		ltsValue = "Yes"
-					// 	}
-					// case string:
-					// 	ltsValue = v
-					// }
-
-					versions = append(versions, NodeVersionInfo{
-						Version:    cleanVersion,
-						Status:     status,
-						NpmVersion: versionInfo.Npm, // 新增字段，将 npm 版本信息添加到结果中
-					})
-
-					// a.logToFile(fmt.Sprintf("Version: %s, Status: %s, LTS: %s, NPM: %s", versionInfo.Version, status, ltsValue, versionInfo.Npm))
-				}
-
-				a.logToFile(fmt.Sprintf("Found %d available versions from Node.js API", len(versions)))
-				return versions, nil
+			a.logToFile(fmt.Sprintf("Error fetching installed versions: %s", err))
+			return nil, fmt.Errorf("Error fetching installed versions: %s", err)
+		}
+		installedMap := make(map[string]bool)
+		for _, installed := range installedVersions {
+			installedMap[installed.Version] = true
+		}
+
+		f := a.formatter()
+		for _, versionInfo := range nodeVersions {
+			cleanVersion := strings.TrimPrefix(versionInfo.Version, "v")
+			status := "Not Installed"
+			if installedMap[cleanVersion] {
+				status = "Installed"
 			}
+
+			// 处理 LTS 字段：可以是 bool 或字符串
+			// ltsValue := "No"
+			// switch v := versionInfo.LTS.(type) {
+			// case bool:
+			// 	if v {
+			// 		ltsValue = "Yes"
+			// 	}
+			// case string:
+			// 	ltsValue = v
+			// }
+
+			versions = append(versions, NodeVersionInfo{
+				Version:    cleanVersion,
+				Status:     status,
+				NpmVersion: versionInfo.Npm, // 新增字段，将 npm 版本信息添加到结果中
+				Source:     fetchInfo.Source,
+				Date:       versionInfo.Date,
+				DateText:   f.dateString(versionInfo.Date),
+				V8:         versionInfo.V8,
+				OpenSSL:    versionInfo.OpenSSL,
+				Corepack:   corepackBundled(cleanVersion),
+				Npx:        npxBundled(versionInfo.Npm),
+				LTS:        versionInfo.LTS,
+			})
+
+			// a.logToFile(fmt.Sprintf("Version: %s, Status: %s, LTS: %s, NPM: %s", versionInfo.Version, status, ltsValue, versionInfo.Npm))
 		}
-	} else {
-		a.logToFile(fmt.Sprintf("Failed to fetch versions from Node.js API: %v", err))
+
+		a.logToFile(fmt.Sprintf("Found %d available versions from %s", len(versions), fetchInfo.Source))
+		versions = a.withInstalledToolVersions(a.mergeDistSources(versions, installedMap))
+		return a.withFavorites(a.withVersionSort(a.withNotes(a.withLabels(versions)))), nil
 	}
+	a.logToFile(fmt.Sprintf("Failed to fetch versions from Node.js API and mirrors: %v", err))
 
 	// Fallback to using nvm command if API fails
 	// 如果 API 请求失败，则回退到使用 nvm 命令
@@ -370,10 +749,7 @@ func (a *App) GetAvailableNodeVersions() ([]NodeVersionInfo, error) {
 		return nil, fmt.Errorf("Error fetching available versions: %v", err)
 	}
 
-	lines := strings.Split(string(output), "\n")
 	var versions []NodeVersionInfo
-	versionRegex := regexp.MustCompile(`\b\d+\.\d+\.\d+\b`)
-
 	installedVersions, err := a.GetInstalledNodeVersions()
 	if err != nil {
 		a.logToFile(fmt.Sprintf("Error fetching installed versions: %s", err))
@@ -386,24 +762,18 @@ func (a *App) GetAvailableNodeVersions() ([]NodeVersionInfo, error) {
 
 	// Extract available versions using regex and update their installation status
 	// 使用正则表达式提取可用版本并更新其安装状态
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.Contains(line, "CURRENT") || strings.Contains(line, "-") {
-			continue
-		}
-
-		matches := versionRegex.FindAllString(line, -1)
-		for _, version := range matches {
-			status := "Not Installed"
-			if installedMap[version] {
-				status = "Installed"
-			}
-			versions = append(versions, NodeVersionInfo{
-				Version:    version,
-				Status:     status,
-				NpmVersion: "unknown", // 如果使用 nvm 获取的版本信息，不包含 npm，设置为未知
-			})
+	for _, version := range parseNvmAvailable(string(output)) {
+		status := "Not Installed"
+		if installedMap[version] {
+			status = "Installed"
 		}
+		versions = append(versions, NodeVersionInfo{
+			Version:    version,
+			Status:     status,
+			NpmVersion: "unknown", // 如果使用 nvm 获取的版本信息，不包含 npm，设置为未知
+			Source:     "nvm",
+			Corepack:   corepackBundled(version),
+		})
 	}
 
 	if a.debugMode {
@@ -414,5 +784,6 @@ func (a *App) GetAvailableNodeVersions() ([]NodeVersionInfo, error) {
 	}
 
 	a.logToFile(fmt.Sprintf("Found %d available versions from nvm", len(versions)))
-	return versions, nil
+	versions = a.withInstalledToolVersions(a.mergeDistSources(versions, installedMap))
+	return a.withFavorites(a.withVersionSort(a.withNotes(a.withLabels(versions)))), nil
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNvmList(t *testing.T) {
+	output := "\r\n    22.11.0\r\n  * 20.18.0 (Currently using 64-bit executable)\r\n    18.20.4\r\n\r\n"
+	want := []NodeVersion{
+		{Version: "22.11.0"},
+		{Version: "20.18.0", IsCurrent: true},
+		{Version: "18.20.4"},
+	}
+	if got := parseNvmList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNvmList = %+v, want %+v", got, want)
+	}
+	if got := parseNvmList("\r\n\r\n"); len(got) != 0 {
+		t.Errorf("parseNvmList of empty output = %+v, want none", got)
+	}
+}
+
+func TestParseNvmAvailable(t *testing.T) {
+	output := "\r\n|   CURRENT    |     LTS      |  OLD STABLE  | OLD UNSTABLE |\r\n" +
+		"|--------------|--------------|--------------|--------------|\r\n" +
+		"|    23.3.0    |   22.11.0    |   0.12.18    |   0.11.16    |\r\n" +
+		"|    23.2.0    |   20.18.1    |   0.12.17    |   0.11.15    |\r\n\r\n" +
+		"This is a partial list. For a complete list, visit https://nodejs.org/en/download/releases\r\n"
+	want := []string{"23.3.0", "22.11.0", "0.12.18", "0.11.16", "23.2.0", "20.18.1", "0.12.17", "0.11.15"}
+	if got := parseNvmAvailable(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNvmAvailable = %v, want %v", got, want)
+	}
+}
+
+func TestGetInstalledNodeVersionsUsesExecutor(t *testing.T) {
+	a := newTestApp(t, nil)
+	spec, err := a.nvmCommand([]string{"ls"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := &FakeExecutor{Responses: map[string]FakeResponse{
+		strings.TrimSpace(spec.Name + " " + strings.Join(spec.Args, " ")): {Output: "    18.20.4\r\n  * 20.18.0 (Currently using 64-bit executable)\r\n"},
+	}}
+	a.executor = executor
+
+	versions, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := ""
+	for _, v := range versions {
+		if v.IsCurrent {
+			current = v.Version
+		}
+	}
+	if len(versions) != 2 || current != "20.18.0" {
+		t.Errorf("GetInstalledNodeVersions = %+v, want 2 versions with 20.18.0 current", versions)
+	}
+	if len(executor.Calls) != 1 || executor.Calls[0].Name != spec.Name {
+		t.Errorf("executor calls = %+v, want a single call of %s", executor.Calls, spec.Name)
+	}
+}
+
+func TestSafeModeKeepsTheSettingsFile(t *testing.T) {
+	a := newTestApp(t, &FakeExecutor{})
+	files := a.fs.(*mockFS)
+	files.WriteFile(a.settingsPath, []byte(`{"favorites":["18.20.4"]}`), 0644)
+	a.enterSafeMode()
+
+	if _, err := a.ToggleFavorite("20.18.0"); err != nil {
+		t.Fatal(err)
+	}
+	a.recordVersionUsed("20.18.0")
+	if data, _ := files.ReadFile(a.settingsPath); string(data) != `{"favorites":["18.20.4"]}` {
+		t.Errorf("safe mode rewrote the settings file: %s", data)
+	}
+}
+
+func TestCheckHealthFollowsTheInjectedClock(t *testing.T) {
+	a := newTestApp(t, &FakeExecutor{})
+	clock := a.clock.(*FakeClock)
+	a.updateLastActive()
+	if !a.checkHealth() {
+		t.Fatal("checkHealth = false right after activity")
+	}
+	clock.Advance(6 * time.Minute)
+	if a.checkHealth() {
+		t.Error("checkHealth = true after six idle minutes on the injected clock")
+	}
+}
+
+func TestAuditTrailUsesTheInjectedFileSystem(t *testing.T) {
+	a := newTestApp(t, &FakeExecutor{})
+	a.audit("switch", "20.18.0", "success")
+
+	data, err := a.fs.ReadFile(a.auditPath)
+	if err != nil {
+		t.Fatalf("audit entry not written to the injected file system: %v", err)
+	}
+	if !strings.Contains(string(data), `"time":"2024-11-20T09:00:00Z"`) {
+		t.Errorf("audit entry = %s, want the injected clock's time", data)
+	}
+	entries, err := a.GetAuditLog(10)
+	if err != nil || len(entries) != 1 || entries[0].Action != "switch" {
+		t.Errorf("GetAuditLog = %+v, %v, want the single switch entry", entries, err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"debug/pe"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Architectures reported for Node.js binaries
+// Node.js 二进制文件的架构
+const (
+	ArchX86     = "x86"
+	ArchX64     = "x64"
+	ArchARM64   = "arm64"
+	ArchUnknown = "unknown"
+)
+
+// maxNativeModulesScanned caps how many native addons are inspected per project
+// maxNativeModulesScanned 限制每个项目检查的原生模块数量
+const maxNativeModulesScanned = 200
+
+// VersionArch is the architecture of one installed version
+// VersionArch 表示一个已安装版本的架构
+type VersionArch struct {
+	Version string
+	Arch    string
+}
+
+// ArchReport summarizes the architectures of all installed versions
+// ArchReport 汇总所有已安装版本的架构
+type ArchReport struct {
+	HostArch string
+	Versions []VersionArch
+	Mixed    bool
+}
+
+// peArch reads the target machine of a PE executable or DLL (.exe / .node)
+// peArch 读取 PE 可执行文件或 DLL（.exe / .node）的目标架构
+func peArch(path string) string {
+	f, err := pe.Open(path)
+	if err != nil {
+		return ArchUnknown
+	}
+	defer f.Close()
+
+	switch f.FileHeader.Machine {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return ArchX86
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return ArchX64
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return ArchARM64
+	default:
+		return ArchUnknown
+	}
+}
+
+// hostArch returns the architecture of the operating system, not of this process
+// hostArch 返回操作系统的架构，而不是当前进程的架构
+func hostArch() string {
+	// 在 ARM64 上以 x64 模拟运行时环境变量报告的是 AMD64，优先询问系统
+	// Under x64 emulation on ARM64 the environment reports AMD64, so ask the system first
+	if arch := nativeMachineArch(); arch != ArchUnknown {
+		return arch
+	}
+	// A 32-bit process on a 64-bit OS sees the real architecture in PROCESSOR_ARCHITEW6432
+	// 64 位系统上的 32 位进程需从 PROCESSOR_ARCHITEW6432 读取真实架构
+	arch := os.Getenv("PROCESSOR_ARCHITEW6432")
+	if arch == "" {
+		arch = os.Getenv("PROCESSOR_ARCHITECTURE")
+	}
+	return normalizeArch(arch)
+}
+
+// normalizeArch maps the various spellings (64, amd64, x86_64, 32, ...) to the Arch constants
+// normalizeArch 将各种写法（64、amd64、x86_64、32 等）统一为架构常量
+func normalizeArch(arch string) string {
+	switch strings.ToLower(strings.TrimSpace(arch)) {
+	case "x86", "386", "32", "ia32":
+		return ArchX86
+	case "x64", "amd64", "x86_64", "64":
+		return ArchX64
+	case "arm64", "aarch64":
+		return ArchARM64
+	default:
+		return ArchUnknown
+	}
+}
+
+// GetInstalledArchitectures reports the architecture of each installed version and flags mixed installs
+// GetInstalledArchitectures 报告每个已安装版本的架构，并标记混合架构的情况
+func (a *App) GetInstalledArchitectures() (ArchReport, error) {
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return ArchReport{}, err
+	}
+	root := a.nvmRoot()
+
+	report := ArchReport{HostArch: hostArch()}
+	seen := map[string]bool{}
+	for _, v := range installed {
+		arch := peArch(filepath.Join(versionDir(root, v.Version), "node.exe"))
+		report.Versions = append(report.Versions, VersionArch{Version: v.Version, Arch: arch})
+		if arch != ArchUnknown {
+			seen[arch] = true
+		}
+	}
+	report.Mixed = len(seen) > 1
+	return report, nil
+}
+
+// nativeModuleArchs returns the native addons under a project's node_modules keyed by path, with their architecture
+// nativeModuleArchs 返回项目 node_modules 中的原生模块及其架构，以路径为键
+func nativeModuleArchs(projectDir string) map[string]string {
+	result := map[string]string{}
+	root := filepath.Join(projectDir, "node_modules")
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(result) >= maxNativeModulesScanned {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".node") {
+			rel, _ := filepath.Rel(root, path)
+			result[rel] = peArch(path)
+		}
+		return nil
+	})
+	return result
+}
+
+// CheckInstallArch warns when installing version for arch would not match the native modules
+// of registered projects pinned to that version
+// CheckInstallArch 当为固定到该版本的已登记项目安装指定架构时，如与其原生模块不匹配则给出警告
+func (a *App) CheckInstallArch(version, arch string) []string {
+	version = strings.TrimPrefix(version, "v")
+	arch = normalizeArch(arch)
+	var warnings []string
+
+	if host := hostArch(); arch == ArchX64 && host == ArchX86 {
+		warnings = append(warnings, "64 位 Node.js 无法在 32 位系统上运行 / A 64-bit Node.js cannot run on a 32-bit OS")
+	}
+
+	for _, project := range a.GetProjects() {
+		pin := strings.TrimPrefix(project.PinnedVersion, "v")
+		if pin == "" || (pin != version && !strings.HasPrefix(version, pin+".")) {
+			continue
+		}
+		modules := nativeModuleArchs(project.Path)
+		names := make([]string, 0, len(modules))
+		for module := range modules {
+			names = append(names, module)
+		}
+		sort.Strings(names)
+		for _, module := range names {
+			if moduleArch := modules[module]; moduleArch != ArchUnknown && moduleArch != arch {
+				warnings = append(warnings, fmt.Sprintf("%s: native module %s is built for %s, not %s (run npm rebuild after installing)",
+					project.Name, module, moduleArch, arch))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEntry is one record of the audit trail
+// AuditEntry 表示审计记录中的一条记录
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Detail  string    `json:"detail"`
+	Outcome string    `json:"outcome"`
+}
+
+// auditFilePath returns the location of the audit trail next to the executable
+// auditFilePath 返回可执行文件同目录下的审计记录文件路径
+func auditFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-audit.jsonl"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-audit.jsonl")
+}
+
+// audit appends an entry to the audit trail; unlike logToFile it is always written
+// audit 向审计记录追加一条记录；与 logToFile 不同，它总是会被写入
+func (a *App) audit(action, detail, outcome string) {
+	entry := AuditEntry{
+		Time:    a.clock.Now(),
+		Action:  action,
+		Detail:  detail,
+		Outcome: outcome,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+
+	if err := a.fs.AppendFile(a.auditPath, append(data, '\n'), 0644); err != nil {
+		fmt.Printf("Debug: Failed to write audit trail: %v\n", err)
+	}
+}
+
+// GetAuditLog returns up to limit of the most recent audit entries, newest first
+// GetAuditLog 返回最多 limit 条最新的审计记录，最新的排在最前
+func (a *App) GetAuditLog(limit int) ([]AuditEntry, error) {
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+
+	data, err := a.fs.ReadFile(a.auditPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, fmt.Errorf("Error reading audit trail: %v", err)
+	}
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	// 倒序排列，最新的记录在前
+	// Reverse so the newest entry comes first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// bandwidthMonthLayout formats the month a download is counted in
+// bandwidthMonthLayout 是下载量所计入月份的格式
+const bandwidthMonthLayout = "2006-01"
+
+// maxBandwidthMonths is how many months of history are kept
+// maxBandwidthMonths 是保留的历史月份数量
+const maxBandwidthMonths = 12
+
+// bandwidthLedger counts the bytes downloaded per month and mirror host, persisted next to the executable
+// bandwidthLedger 按月份和镜像主机统计下载的字节数，并保存在可执行文件同目录下
+type bandwidthLedger struct {
+	mu     sync.Mutex
+	loaded bool
+	dirty  bool
+	months map[string]map[string]int64
+	fs     FileSystem // 保存记录的文件系统，与 App 相同 / file system holding the ledger, the App's
+	clock  Clock      // 决定记录所属月份的时钟，与 App 相同 / clock deciding the month of a record, the App's
+}
+
+// MirrorUsage is the data downloaded from one mirror host in a month
+// MirrorUsage 是某月从一个镜像主机下载的数据量
+type MirrorUsage struct {
+	Host      string
+	Bytes     int64
+	BytesText string
+}
+
+// MonthUsage is the total downloaded in one month
+// MonthUsage 是某月下载的总量
+type MonthUsage struct {
+	Month     string
+	Bytes     int64
+	BytesText string
+}
+
+// BandwidthStats reports this month's downloads against the optional soft monthly cap
+// BandwidthStats 报告本月的下载量及其与可选的每月软上限的对比
+type BandwidthStats struct {
+	Month     string
+	Bytes     int64
+	BytesText string
+	ByMirror  []MirrorUsage
+	CapBytes  int64 // 0 表示未设置上限 / 0 means no cap
+	CapText   string
+	OverCap   bool
+	History   []MonthUsage
+}
+
+// bandwidthFilePath returns the location of the bandwidth ledger next to the executable
+// bandwidthFilePath 返回可执行文件同目录下的流量记录文件路径
+func bandwidthFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-bandwidth.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-bandwidth.json")
+}
+
+// load reads the ledger from disk once, the caller holds l.mu
+// load 从磁盘读取一次流量记录，调用方需持有 l.mu
+func (l *bandwidthLedger) load() {
+	if l.loaded {
+		return
+	}
+	l.loaded = true
+	l.months = map[string]map[string]int64{}
+	if data, err := l.fs.ReadFile(bandwidthFilePath()); err == nil {
+		json.Unmarshal(data, &l.months)
+	}
+}
+
+// add counts n bytes from host in the current month
+// add 将从 host 下载的 n 个字节计入当月
+func (l *bandwidthLedger) add(host string, n int64) {
+	if n <= 0 {
+		return
+	}
+	if host == "" {
+		host = "unknown"
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load()
+	month := l.clock.Now().Format(bandwidthMonthLayout)
+	if l.months[month] == nil {
+		l.months[month] = map[string]int64{}
+	}
+	l.months[month][host] += n
+	l.dirty = true
+}
+
+// flush writes the ledger to disk when it changed, dropping months beyond the history
+// flush 在流量记录变化时写入磁盘，并丢弃超出保留范围的月份
+func (l *bandwidthLedger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.dirty {
+		return
+	}
+	months := make([]string, 0, len(l.months))
+	for month := range l.months {
+		months = append(months, month)
+	}
+	sort.Strings(months)
+	for len(months) > maxBandwidthMonths {
+		delete(l.months, months[0])
+		months = months[1:]
+	}
+	if data, err := json.MarshalIndent(l.months, "", "  "); err == nil && l.fs.WriteFile(bandwidthFilePath(), data, 0644) == nil {
+		l.dirty = false
+	}
+}
+
+// monthTotal returns the bytes downloaded in a month, the caller holds l.mu
+// monthTotal 返回某月下载的字节数，调用方需持有 l.mu
+func (l *bandwidthLedger) monthTotal(month string) int64 {
+	var total int64
+	for _, n := range l.months[month] {
+		total += n
+	}
+	return total
+}
+
+// urlHost returns the host of a download URL
+// urlHost 返回下载地址的主机名
+func urlHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+// bandwidthCap returns the soft monthly cap in bytes, 0 when none is set
+// bandwidthCap 返回以字节为单位的每月软上限，未设置时为 0
+func (a *App) bandwidthCap() int64 {
+	return int64(a.currentSettings().BandwidthCapMB) << 20
+}
+
+// GetBandwidthStats returns the data downloaded this month by mirror, the monthly history and the soft cap
+// GetBandwidthStats 返回本月按镜像统计的下载量、每月历史记录以及软上限
+func (a *App) GetBandwidthStats() BandwidthStats {
+	f := a.formatter()
+	l := &a.metrics.bandwidth
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.load()
+
+	month := a.clock.Now().Format(bandwidthMonthLayout)
+	stats := BandwidthStats{Month: month, Bytes: l.monthTotal(month), CapBytes: a.bandwidthCap()}
+	stats.BytesText = f.size(stats.Bytes)
+	if stats.CapBytes > 0 {
+		stats.CapText = f.size(stats.CapBytes)
+		stats.OverCap = stats.Bytes >= stats.CapBytes
+	}
+	for host, n := range l.months[month] {
+		stats.ByMirror = append(stats.ByMirror, MirrorUsage{Host: host, Bytes: n, BytesText: f.size(n)})
+	}
+	sort.Slice(stats.ByMirror, func(i, j int) bool { return stats.ByMirror[i].Bytes > stats.ByMirror[j].Bytes })
+	for m := range l.months {
+		total := l.monthTotal(m)
+		stats.History = append(stats.History, MonthUsage{Month: m, Bytes: total, BytesText: f.size(total)})
+	}
+	sort.Slice(stats.History, func(i, j int) bool { return stats.History[i].Month > stats.History[j].Month })
+	return stats
+}
+
+// recordInstallDownload counts the archive nvm downloaded for an install, since nvm fetches it outside the app
+// recordInstallDownload 记录 nvm 为安装下载的压缩包，因为该下载不经过本应用
+func (a *App) recordInstallDownload(version string) {
+	preview, err := a.GetInstallPreview(version, "")
+	if err != nil || preview.Size <= 0 {
+		return
+	}
+	a.metrics.bandwidth.add(urlHost(preview.URL), preview.Size)
+	a.metrics.bandwidth.flush()
+}
+
+// bandwidthCapWarning returns why an install would exceed the soft monthly cap, or "" when it fits
+// bandwidthCapWarning 返回安装将超出每月软上限的原因，未超出时返回空字符串
+func (a *App) bandwidthCapWarning(version string) string {
+	limit := a.bandwidthCap()
+	if limit <= 0 {
+		return ""
+	}
+	preview, err := a.GetInstallPreview(version, "")
+	if err != nil {
+		return ""
+	}
+	stats := a.GetBandwidthStats()
+	if stats.Bytes+preview.Size <= limit {
+		return ""
+	}
+	f := a.formatter()
+	size := f.size(preview.Size)
+	return fmt.Sprintf("本月已下载 %s，下载 %s 将超出每月 %s 的流量上限 / %s downloaded this month, the %s download would exceed the monthly cap of %s",
+		stats.BytesText, size, stats.CapText, stats.BytesText, size, stats.CapText)
+}
+
+// InstallNodeVersionOverBandwidthCap installs a version even though it exceeds the soft monthly cap
+// InstallNodeVersionOverBandwidthCap 即使超出每月软上限也安装指定版本
+func (a *App) InstallNodeVersionOverBandwidthCap(version string) string {
+	run := func() string {
+		a.audit("install-bandwidth-override", version, "confirmed")
+		return a.installNodeVersionIfSpace(version)
+	}
+	if msg, ok := a.requireConfirmationThen(OperationInstall, version, run); !ok {
+		return msg
+	}
+	return run()
+}
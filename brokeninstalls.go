@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// minNodeExeSize is the smallest plausible node.exe; anything smaller was truncated by an interrupted install
+// minNodeExeSize 是 node.exe 可能的最小大小，小于该值说明安装中断导致文件不完整
+const minNodeExeSize = 5 << 20
+
+// versionDirRegex matches the version directories nvm creates under its root
+// versionDirRegex 匹配 nvm 在根目录下创建的版本目录
+var versionDirRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+// BrokenInstall is a version directory left behind by a crashed or partial install
+// BrokenInstall 表示崩溃或未完成的安装遗留的版本目录
+type BrokenInstall struct {
+	Version string
+	Path    string
+	Reason  string
+	Size    int64
+}
+
+// diagnoseVersionDir returns why a version directory is broken, or "" when it looks healthy
+// diagnoseVersionDir 返回版本目录损坏的原因，目录正常时返回空字符串
+func diagnoseVersionDir(dir string) string {
+	info, err := os.Stat(filepath.Join(dir, "node.exe"))
+	switch {
+	case err != nil:
+		return "node.exe 缺失 / node.exe is missing"
+	case info.Size() < minNodeExeSize:
+		return fmt.Sprintf("node.exe 只有 %d 字节 / node.exe is only %d bytes", info.Size(), info.Size())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "node_modules", "npm", "package.json")); err != nil {
+		return "npm 缺失 / npm is missing"
+	}
+	return ""
+}
+
+// GetBrokenInstalls scans the nvm root for version directories that are missing files or truncated
+// GetBrokenInstalls 扫描 nvm 根目录中文件缺失或不完整的版本目录
+func (a *App) GetBrokenInstalls() ([]BrokenInstall, error) {
+	root := a.nvmRoot()
+	if root == "" {
+		return nil, fmt.Errorf("nvm root not found")
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading nvm root: %v", err)
+	}
+
+	var broken []BrokenInstall
+	for _, entry := range entries {
+		if !entry.IsDir() || !versionDirRegex.MatchString(entry.Name()) {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if reason := diagnoseVersionDir(dir); reason != "" {
+			broken = append(broken, BrokenInstall{
+				Version: strings.TrimPrefix(entry.Name(), "v"),
+				Path:    dir,
+				Reason:  reason,
+				Size:    dirSize(dir),
+			})
+		}
+	}
+	a.logToFile(fmt.Sprintf("Found %d broken installs", len(broken)))
+	return broken, nil
+}
+
+// RepairOrRemoveBroken deletes every broken install and, when redownload is set, installs it again.
+// It returns the installs that could not be fixed
+// RepairOrRemoveBroken 删除所有损坏的安装，redownload 为真时重新安装。
+// 返回未能修复的安装
+func (a *App) RepairOrRemoveBroken(redownload bool) ([]BrokenInstall, error) {
+	broken, err := a.GetBrokenInstalls()
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []BrokenInstall
+	for _, install := range broken {
+		if err := os.RemoveAll(longPath(install.Path)); err != nil {
+			install.Reason = fmt.Sprintf("Error removing directory: %v", err)
+			failed = append(failed, install)
+			a.audit("remove-broken", install.Version, "failed")
+			continue
+		}
+		a.audit("remove-broken", install.Version, "success")
+
+		if !redownload {
+			continue
+		}
+		a.installNodeVersionWithinCap(install.Version)
+		if reason := diagnoseVersionDir(install.Path); reason != "" {
+			install.Reason = reason
+			failed = append(failed, install)
+			a.audit("reinstall-broken", install.Version, "failed")
+			continue
+		}
+		a.audit("reinstall-broken", install.Version, "success")
+	}
+
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("project-shims", TaskPriorityBackground, a.refreshProjectShims)
+	return failed, nil
+}
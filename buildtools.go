@@ -0,0 +1,194 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BuildToolCheck is the result of checking one native build prerequisite
+// BuildToolCheck 表示一项原生模块构建前置条件的检查结果
+type BuildToolCheck struct {
+	Name    string
+	Found   bool
+	Version string
+	Path    string
+	OK      bool
+	Message string
+	FixURL  string
+}
+
+// BuildToolsReport lists the native build prerequisites for the current Node.js version
+// BuildToolsReport 列出当前 Node.js 版本所需的原生模块构建前置条件
+type BuildToolsReport struct {
+	NodeVersion string
+	Checks      []BuildToolCheck
+	Ready       bool
+}
+
+// buildRequirements describes what node-gyp bundled with a Node.js major line needs
+// buildRequirements 描述某个 Node.js 主版本线自带的 node-gyp 所需的环境
+type buildRequirements struct {
+	python2OK      bool // 是否接受 Python 2.7 / Python 2.7 accepted
+	maxPythonMinor int  // 支持的最高 Python 3 次版本，0 表示不限 / newest supported Python 3 minor, 0 = no limit
+	minVS          int
+	maxVS          int
+}
+
+var versionNumberRegex = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// requirementsFor returns the build requirements of a Node.js version
+// requirementsFor 返回指定 Node.js 版本的构建要求
+func requirementsFor(version string) buildRequirements {
+	major, _ := strconv.Atoi(strings.Split(version, ".")[0])
+	switch {
+	case version == "" || (major >= 20 && compareSemver(version, "20.10.0") >= 0):
+		// npm 10 ships node-gyp 10 which works with Python 3.12
+		// npm 10 自带的 node-gyp 10 支持 Python 3.12
+		return buildRequirements{minVS: 2017, maxVS: 2022}
+	case major >= 16:
+		// Older node-gyp still imports distutils, which Python 3.12 removed
+		// 旧版 node-gyp 仍依赖 Python 3.12 已移除的 distutils
+		return buildRequirements{maxPythonMinor: 11, minVS: 2017, maxVS: 2022}
+	case major >= 12:
+		return buildRequirements{python2OK: true, maxPythonMinor: 11, minVS: 2015, maxVS: 2019}
+	default:
+		return buildRequirements{python2OK: true, maxPythonMinor: -1, minVS: 2015, maxVS: 2017}
+	}
+}
+
+// checkPython looks for a Python interpreter node-gyp can use
+// checkPython 查找 node-gyp 可以使用的 Python 解释器
+func (a *App) checkPython(req buildRequirements) BuildToolCheck {
+	check := BuildToolCheck{Name: "Python", FixURL: "https://www.python.org/downloads/windows/"}
+
+	// node-gyp honours npm_config_python / PYTHON before searching PATH
+	// node-gyp 会优先使用 npm_config_python / PYTHON，然后才搜索 PATH
+	candidates := []string{os.Getenv("npm_config_python"), os.Getenv("PYTHON"), "python", "python3", "py"}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			continue
+		}
+		output, err := a.runHidden(path, "--version")
+		if err != nil {
+			continue
+		}
+		m := versionNumberRegex.FindStringSubmatch(output)
+		if m == nil {
+			continue
+		}
+		check.Found = true
+		check.Path = path
+		check.Version = m[0]
+		pyMajor, _ := strconv.Atoi(m[1])
+		pyMinor, _ := strconv.Atoi(m[2])
+
+		switch {
+		case pyMajor == 2 && !req.python2OK:
+			check.Message = "此 Node.js 版本的 node-gyp 需要 Python 3 / node-gyp for this Node.js version requires Python 3"
+		case pyMajor == 3 && req.maxPythonMinor < 0:
+			check.Message = "此 Node.js 版本的 node-gyp 仅支持 Python 2.7 / node-gyp for this Node.js version only supports Python 2.7"
+		case pyMajor == 3 && req.maxPythonMinor > 0 && pyMinor > req.maxPythonMinor:
+			check.Message = "Python 3.12+ 已移除 distutils，请安装 Python 3.11 或升级 npm / Python 3.12+ removed distutils, install Python 3.11 or upgrade npm"
+		case pyMajor == 3 && pyMinor < 6:
+			check.Message = "需要 Python 3.6 或更高版本 / Python 3.6 or later is required"
+		default:
+			check.OK = true
+			check.Message = "OK"
+		}
+		return check
+	}
+
+	check.Message = "未找到 Python / Python was not found"
+	return check
+}
+
+// checkVisualStudio uses vswhere to find a Visual Studio install with the C++ toolset
+// checkVisualStudio 使用 vswhere 查找包含 C++ 工具集的 Visual Studio
+func (a *App) checkVisualStudio(req buildRequirements) BuildToolCheck {
+	check := BuildToolCheck{
+		Name:   "Visual Studio Build Tools",
+		FixURL: "https://visualstudio.microsoft.com/visual-cpp-build-tools/",
+	}
+
+	vswhere := filepath.Join(os.Getenv("ProgramFiles(x86)"), "Microsoft Visual Studio", "Installer", "vswhere.exe")
+	if _, err := os.Stat(vswhere); err != nil {
+		check.Message = "未找到 Visual Studio 安装程序 / The Visual Studio installer was not found"
+		return check
+	}
+
+	output, err := a.runHidden(vswhere, "-products", "*", "-latest",
+		"-requires", "Microsoft.VisualStudio.Component.VC.Tools.x86.x64",
+		"-property", "catalog_productLineVersion")
+	if err != nil || output == "" {
+		check.Message = "未找到带有 C++ 生成工具的 Visual Studio / No Visual Studio with the C++ build tools was found"
+		return check
+	}
+
+	check.Found = true
+	check.Version = output
+	path, _ := a.runHidden(vswhere, "-products", "*", "-latest", "-property", "installationPath")
+	check.Path = path
+
+	year, _ := strconv.Atoi(output)
+	if year < req.minVS || year > req.maxVS {
+		check.Message = "此 Node.js 版本的 node-gyp 需要 Visual Studio " + strconv.Itoa(req.minVS) + "-" + strconv.Itoa(req.maxVS) +
+			" / node-gyp for this Node.js version needs Visual Studio " + strconv.Itoa(req.minVS) + "-" + strconv.Itoa(req.maxVS)
+		return check
+	}
+	check.OK = true
+	check.Message = "OK"
+	return check
+}
+
+// checkWindowsBuildTools reports leftovers of the deprecated windows-build-tools package
+// checkWindowsBuildTools 检查已弃用的 windows-build-tools 包的残留
+func checkWindowsBuildTools() BuildToolCheck {
+	check := BuildToolCheck{
+		Name:   "windows-build-tools",
+		OK:     true,
+		FixURL: "https://github.com/nodejs/node-gyp#on-windows",
+	}
+	home, _ := os.UserHomeDir()
+	dir := filepath.Join(home, ".windows-build-tools")
+	if _, err := os.Stat(dir); err == nil {
+		check.Found = true
+		check.Path = dir
+		check.Message = "windows-build-tools 已弃用，建议改用 Visual Studio Build Tools / windows-build-tools is deprecated, prefer the Visual Studio Build Tools"
+	} else {
+		check.Message = "未安装（无需安装）/ Not installed (not required)"
+	}
+	return check
+}
+
+// CheckBuildTools verifies the native module build prerequisites for the current Node.js version
+// CheckBuildTools 检查当前 Node.js 版本构建原生模块所需的环境
+func (a *App) CheckBuildTools() (BuildToolsReport, error) {
+	// 没有正在使用的版本时按最新的要求检查
+	// Without an active version the newest requirements are checked
+	current, _ := a.currentNodeVersion()
+	report := BuildToolsReport{NodeVersion: current}
+
+	req := requirementsFor(report.NodeVersion)
+	report.Checks = []BuildToolCheck{
+		a.checkPython(req),
+		a.checkVisualStudio(req),
+		checkWindowsBuildTools(),
+	}
+
+	report.Ready = true
+	for _, check := range report.Checks {
+		if !check.OK {
+			report.Ready = false
+		}
+	}
+	a.logToFile("Build tools check completed, ready: " + strconv.FormatBool(report.Ready))
+	return report, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var enginesNodeRegex = regexp.MustCompile(`("engines"\s*:\s*\{[^}]*?"node"\s*:\s*")([^"]*)(")`)
+var rangePrefixRegex = regexp.MustCompile(`^\s*(>=|\^|~|>|=)?\s*v?`)
+
+// PinChange is one file rewrite planned or performed by a bulk pin update
+// PinChange 表示批量更新固定版本时计划或执行的一处文件修改
+type PinChange struct {
+	File     string
+	OldValue string
+	NewValue string
+}
+
+// PinUpdate is the preview or result of updating one project
+// PinUpdate 表示单个项目更新的预览或结果
+type PinUpdate struct {
+	Project  string
+	Changes  []PinChange
+	GitDirty bool
+	Skipped  string
+	Applied  bool
+}
+
+// gitFileDirty reports whether a file of the project directory has uncommitted changes in its git repository.
+// The pathspec is resolved against the project directory, so this also works when the project is a
+// subdirectory of the repository
+// gitFileDirty 判断项目目录中的某个文件在其 git 仓库中是否有未提交的修改。
+// 路径相对于项目目录解析，因此项目是仓库子目录时同样有效
+func (a *App) gitFileDirty(dir, file string) bool {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false
+	}
+	output, err := a.runHidden("git", "-C", dir, "status", "--porcelain", "-z", "--", file)
+	if err != nil {
+		// 不是 git 仓库
+		// Not a git repository
+		return false
+	}
+	return output != ""
+}
+
+// planPinChanges computes the rewrites needed to pin a project to target
+// planPinChanges 计算将项目固定到目标版本所需的修改
+func planPinChanges(dir, target string) []PinChange {
+	var changes []PinChange
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		old := strings.TrimSpace(string(data))
+		newValue := target
+		if strings.HasPrefix(old, "v") {
+			newValue = "v" + target
+		}
+		if old != newValue {
+			changes = append(changes, PinChange{File: name, OldValue: old, NewValue: newValue})
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		if m := enginesNodeRegex.FindSubmatch(data); m != nil {
+			old := string(m[2])
+			// 保留原有的范围运算符，例如 ">=" 或 "^"
+			// Keep the existing range operator such as ">=" or "^"
+			prefix := strings.TrimSpace(rangePrefixRegex.FindString(old))
+			prefix = strings.TrimSuffix(prefix, "v")
+			newValue := prefix + target
+			if old != newValue {
+				changes = append(changes, PinChange{File: "package.json", OldValue: old, NewValue: newValue})
+			}
+		}
+	}
+	return changes
+}
+
+// applyPinChange writes one planned change to disk
+// applyPinChange 将一处计划的修改写入磁盘
+func applyPinChange(dir string, change PinChange) error {
+	path := filepath.Join(dir, change.File)
+	if change.File != "package.json" {
+		return os.WriteFile(path, []byte(change.NewValue+"\n"), 0644)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated := enginesNodeRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+		m := enginesNodeRegex.FindSubmatch(match)
+		return []byte(string(m[1]) + change.NewValue + string(m[3]))
+	})
+	return os.WriteFile(path, updated, 0644)
+}
+
+// previewPinUpdates plans the pin updates of every registered project
+// previewPinUpdates 为所有已登记项目计划固定版本的更新
+func (a *App) previewPinUpdates(target string) []PinUpdate {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "v")
+	var updates []PinUpdate
+	for _, project := range a.GetProjects() {
+		update := PinUpdate{Project: project.Path, Changes: planPinChanges(project.Path, target)}
+
+		for _, change := range update.Changes {
+			if a.gitFileDirty(project.Path, change.File) {
+				update.GitDirty = true
+			}
+		}
+
+		switch {
+		case len(update.Changes) == 0 && project.PinnedVersion == "":
+			update.Skipped = "项目未固定版本 / The project does not pin a version"
+		case len(update.Changes) == 0:
+			update.Skipped = "已是目标版本 / Already pinned to the target"
+		case update.GitDirty:
+			update.Skipped = "固定版本文件有未提交的修改 / Pin files have uncommitted changes"
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// PreviewBulkPinUpdate shows, per registered project, which pin files BulkUpdatePins would rewrite
+// PreviewBulkPinUpdate 按已登记项目展示 BulkUpdatePins 将要修改的固定版本文件
+func (a *App) PreviewBulkPinUpdate(target string) []PinUpdate {
+	return a.previewPinUpdates(target)
+}
+
+// BulkUpdatePins rewrites .nvmrc/.node-version/package.json engines of all registered projects to target,
+// skipping projects whose pin files have uncommitted git changes
+// BulkUpdatePins 将所有已登记项目的 .nvmrc/.node-version/package.json engines 改为目标版本，
+// 跳过固定版本文件有未提交 git 修改的项目
+func (a *App) BulkUpdatePins(target string) ([]PinUpdate, error) {
+	if strings.TrimSpace(target) == "" {
+		return nil, fmt.Errorf("No target version given")
+	}
+
+	updates := a.previewPinUpdates(target)
+	for i := range updates {
+		update := &updates[i]
+		if update.Skipped != "" {
+			continue
+		}
+		var failed []string
+		for _, change := range update.Changes {
+			if err := applyPinChange(update.Project, change); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", change.File, err))
+			}
+		}
+		if len(failed) > 0 {
+			update.Skipped = strings.Join(failed, "; ")
+			a.logToFile(fmt.Sprintf("Pin update for %s failed: %s", update.Project, update.Skipped))
+			continue
+		}
+		update.Applied = true
+		a.logToFile(fmt.Sprintf("Updated pins of %s to %s", update.Project, target))
+	}
+	a.audit("bulk-pin-update", target, fmt.Sprintf("%d projects processed", len(updates)))
+	return updates, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheDir returns the directory used for downloaded data next to the executable
+// cacheDir 返回可执行文件同目录下用于缓存下载数据的目录
+func cacheDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "cache"
+	}
+	return filepath.Join(filepath.Dir(execPath), "cache")
+}
+
+// cacheFilePath returns the path of a named cache file
+// cacheFilePath 返回指定缓存文件的路径
+func cacheFilePath(name string) string {
+	return filepath.Join(cacheDir(), name)
+}
+
+// fetchCached returns the cached copy of url if it is younger than ttl, otherwise downloads and caches it.
+// When the download fails a stale cached copy is returned instead of an error
+// fetchCached 如果缓存未超过 ttl 则返回缓存内容，否则重新下载并缓存。
+// 下载失败时返回过期的缓存内容而不是错误
+func (a *App) fetchCached(name, url string, ttl time.Duration) ([]byte, bool, error) {
+	path := cacheFilePath(name)
+	info, statErr := a.fs.Stat(path)
+	if statErr == nil && a.clock.Now().Sub(info.ModTime()) < ttl {
+		if data, err := a.fs.ReadFile(path); err == nil {
+			return data, true, nil
+		}
+	}
+
+	data, err := a.download(url)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error downloading %s: %v", url, err))
+		if cached, readErr := a.fs.ReadFile(path); readErr == nil {
+			return cached, true, nil
+		}
+		return nil, false, err
+	}
+
+	if err := a.fs.MkdirAll(cacheDir(), 0755); err == nil {
+		a.fs.WriteFile(path, data, 0644)
+	}
+	return data, false, nil
+}
+
+// download fetches a small resource, counting its bytes in the metrics
+// download 下载一个较小的资源，并将其字节数计入指标
+func (a *App) download(url string) ([]byte, error) {
+	if a.safeMode {
+		return nil, fmt.Errorf("networking disabled in safe mode")
+	}
+
+	resp, err := a.httpClient.Get(url)
+	if err != nil {
+		a.metrics.recordError()
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.recordError()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(&countingReader{r: resp.Body, metrics: a.metrics, host: resp.Request.URL.Host})
+}
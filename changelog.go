@@ -0,0 +1,115 @@
+package main
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+)
+
+//go:embed CHANGELOG.md
+var bundledChangelog string
+
+// changelogHeadingRegex matches release headings such as "## v0.0.5 - 2024-11-01"
+// changelogHeadingRegex 匹配 "## v0.0.5 - 2024-11-01" 等版本标题
+var changelogHeadingRegex = regexp.MustCompile(`^##\s+v?(\d+\.\d+\.\d+[0-9A-Za-z.\-]*)\s*(?:-\s*(\S+))?`)
+
+// ChangelogSection is one "### ..." group of a release, e.g. new features or fixes
+// ChangelogSection 表示某个版本中的一个 "### ..." 分组，例如新增功能或修复
+type ChangelogSection struct {
+	Title string
+	Items []string
+}
+
+// ChangelogEntry holds the release notes of one version
+// ChangelogEntry 保存一个版本的发布说明
+type ChangelogEntry struct {
+	Version  string
+	Date     string
+	Sections []ChangelogSection
+}
+
+// WhatsNew is shown once after an update
+// WhatsNew 在更新后显示一次
+type WhatsNew struct {
+	Show    bool
+	Version string
+	Entries []ChangelogEntry
+}
+
+// parseChangelog splits CHANGELOG.md into releases, newest first as written in the file
+// parseChangelog 将 CHANGELOG.md 拆分为各个版本，顺序与文件一致（最新的在前）
+func parseChangelog(text string) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if m := changelogHeadingRegex.FindStringSubmatch(line); m != nil {
+			entries = append(entries, ChangelogEntry{Version: m[1], Date: m[2]})
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		entry := &entries[len(entries)-1]
+		switch {
+		case strings.HasPrefix(line, "### "):
+			entry.Sections = append(entry.Sections, ChangelogSection{Title: strings.TrimSpace(line[4:])})
+		case strings.HasPrefix(line, "- "):
+			item := strings.TrimSpace(line[2:])
+			// "无" 表示该分组没有内容
+			// "无" means the group is empty
+			if item == "" || item == "无" {
+				continue
+			}
+			if len(entry.Sections) == 0 {
+				entry.Sections = append(entry.Sections, ChangelogSection{})
+			}
+			section := &entry.Sections[len(entry.Sections)-1]
+			section.Items = append(section.Items, item)
+		}
+	}
+	return entries
+}
+
+// GetAppChangelog returns the bundled release notes of the versions newer than sinceVersion,
+// or all of them when sinceVersion is empty
+// GetAppChangelog 返回内置的、比 sinceVersion 新的版本发布说明，sinceVersion 为空时返回全部
+func (a *App) GetAppChangelog(sinceVersion string) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for _, entry := range parseChangelog(bundledChangelog) {
+		if sinceVersion != "" && compareAppVersion(entry.Version, sinceVersion) <= 0 {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// GetWhatsNew returns the changes since the version the user last saw, so the UI shows them once after an update
+// GetWhatsNew 返回自用户上次查看以来的变更，使界面在更新后只显示一次
+func (a *App) GetWhatsNew() WhatsNew {
+	seen := a.currentSettings().LastSeenVersion
+	whatsNew := WhatsNew{Version: appVersion}
+	// 首次运行不显示更新内容，只记录当前版本
+	// Nothing is shown on the first run, the running version is only recorded
+	if seen == "" {
+		a.MarkWhatsNewSeen()
+		return whatsNew
+	}
+	if compareAppVersion(appVersion, seen) <= 0 {
+		return whatsNew
+	}
+	whatsNew.Entries = a.GetAppChangelog(seen)
+	whatsNew.Show = len(whatsNew.Entries) > 0
+	return whatsNew
+}
+
+// MarkWhatsNewSeen records that the user has seen the changes of the running version
+// MarkWhatsNewSeen 记录用户已查看当前运行版本的变更
+func (a *App) MarkWhatsNewSeen() error {
+	settings := a.currentSettings()
+	if settings.LastSeenVersion == appVersion {
+		return nil
+	}
+	settings.LastSeenVersion = appVersion
+	return a.SetSettings(settings)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SnippetAzurePipelines is the Azure Pipelines matrix template; GitHub Actions uses SnippetGitHubActions
+// SnippetAzurePipelines 是 Azure Pipelines 矩阵模板，GitHub Actions 使用 SnippetGitHubActions
+const SnippetAzurePipelines = "azure-pipelines"
+
+// GenerateCIMatrix emits a ready-to-paste CI matrix for the given versions, or for all installed versions
+// when none are selected, so the pipeline tests what the team runs locally
+// GenerateCIMatrix 为指定版本生成可直接粘贴的 CI 矩阵，未选择版本时使用所有已安装版本，
+// 使流水线测试的版本与团队本地使用的一致
+func (a *App) GenerateCIMatrix(versions []string, template string) (string, error) {
+	if len(versions) == 0 {
+		installed, err := a.GetInstalledNodeVersions()
+		if err != nil {
+			return "", err
+		}
+		for _, v := range installed {
+			versions = append(versions, v.Version)
+		}
+	}
+
+	seen := map[string]bool{}
+	var cleaned []string
+	for _, v := range versions {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if v != "" && !seen[v] {
+			seen[v] = true
+			cleaned = append(cleaned, v)
+		}
+	}
+	if len(cleaned) == 0 {
+		return "", fmt.Errorf("No versions selected")
+	}
+	sort.Slice(cleaned, func(i, j int) bool { return compareSemver(cleaned[i], cleaned[j]) < 0 })
+
+	var b strings.Builder
+	switch template {
+	case SnippetGitHubActions, "":
+		quoted := make([]string, len(cleaned))
+		for i, v := range cleaned {
+			quoted[i] = "'" + v + "'"
+		}
+		b.WriteString("strategy:\n")
+		b.WriteString("  matrix:\n")
+		fmt.Fprintf(&b, "    node-version: [%s]\n", strings.Join(quoted, ", "))
+		b.WriteString("steps:\n")
+		b.WriteString("  - uses: actions/checkout@v4\n")
+		b.WriteString("  - uses: actions/setup-node@v4\n")
+		b.WriteString("    with:\n")
+		b.WriteString("      node-version: ${{ matrix.node-version }}\n")
+	case SnippetAzurePipelines:
+		b.WriteString("strategy:\n")
+		b.WriteString("  matrix:\n")
+		for _, v := range cleaned {
+			fmt.Fprintf(&b, "    node_%s:\n", strings.ReplaceAll(v, ".", "_"))
+			fmt.Fprintf(&b, "      node_version: '%s'\n", v)
+		}
+		b.WriteString("steps:\n")
+		b.WriteString("- task: NodeTool@0\n")
+		b.WriteString("  inputs:\n")
+		b.WriteString("    versionSpec: $(node_version)\n")
+	default:
+		return "", fmt.Errorf("Unsupported CI template: %s", template)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// nvmResultReuseWindow is how long the output of a read-only nvm command is reused after it completes
+// nvmResultReuseWindow 是只读 nvm 命令完成后其输出被复用的时长
+const nvmResultReuseWindow = time.Second
+
+// readOnlyNvmCommands are the nvm commands whose concurrent or back-to-back calls can share one result
+// readOnlyNvmCommands 是可以让并发或连续调用共享同一结果的 nvm 命令
+var readOnlyNvmCommands = map[string]bool{
+	"ls":             true,
+	"list":           true,
+	"ls available":   true,
+	"list available": true,
+	"root":           true,
+	"current":        true,
+	"version":        true,
+	"v":              true,
+}
+
+// flightCall is an nvm invocation in flight or recently completed
+// flightCall 表示正在执行或刚完成的一次 nvm 调用
+type flightCall struct {
+	wg     sync.WaitGroup
+	output []byte
+	err    error
+	done   time.Time
+}
+
+// flightGroup coalesces identical requests: callers arriving while one is running wait for its result,
+// and callers arriving shortly after reuse it
+// flightGroup 合并相同的请求：执行期间到达的调用等待其结果，稍后到达的调用直接复用结果
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn for key unless an identical call is running or finished within window
+// do 为 key 执行 fn，除非相同的调用正在执行或在 window 时间内已完成
+func (g *flightGroup) do(key string, window time.Duration, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	if call, ok := g.calls[key]; ok && (call.done.IsZero() || time.Since(call.done) < window) {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return append([]byte{}, call.output...), call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.output, call.err = fn()
+
+	g.mu.Lock()
+	call.done = time.Now()
+	if call.err != nil && g.calls[key] == call {
+		// 失败的结果不复用
+		// Failures are not reused
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	call.wg.Done()
+	return append([]byte{}, call.output...), call.err
+}
+
+// forget drops every call, including those in flight, so callers arriving after a change start a fresh
+// invocation instead of joining one that may have read the old state
+// forget 丢弃所有调用（包括正在执行的），使变更后到达的调用重新执行，而不是加入可能读取了旧状态的调用
+func (g *flightGroup) forget() {
+	g.mu.Lock()
+	g.calls = nil
+	g.mu.Unlock()
+}
+
+// nvmCommandKey returns the coalescing key of a read-only nvm command, or "" for commands that change state
+// nvmCommandKey 返回只读 nvm 命令的合并键，会改变状态的命令返回空字符串
+func nvmCommandKey(args []string) string {
+	key := strings.ToLower(strings.Join(args, " "))
+	if readOnlyNvmCommands[key] {
+		return key
+	}
+	return ""
+}
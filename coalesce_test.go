@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlightGroupForgetDropsCallsInFlight(t *testing.T) {
+	var g flightGroup
+	started, release := make(chan struct{}), make(chan struct{})
+	go g.do("ls", time.Minute, func() ([]byte, error) {
+		close(started)
+		<-release
+		return []byte("old"), nil
+	})
+	<-started
+
+	g.forget()
+	output, err := g.do("ls", time.Minute, func() ([]byte, error) { return []byte("new"), nil })
+	close(release)
+	if err != nil || string(output) != "new" {
+		t.Errorf("do after forget = %q, %v, want a fresh call returning \"new\"", output, err)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var (
+	modkernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procGetOEMCP            = modkernel32.NewProc("GetOEMCP")
+	procGetACP              = modkernel32.NewProc("GetACP")
+	procMultiByteToWideChar = modkernel32.NewProc("MultiByteToWideChar")
+	procWideCharToMultiByte = modkernel32.NewProc("WideCharToMultiByte")
+)
+
+// codePageUTF8 is the Windows code page identifier of UTF-8
+// codePageUTF8 是 UTF-8 的 Windows 代码页标识
+const codePageUTF8 = 65001
+
+// mbErrInvalidChars makes MultiByteToWideChar fail instead of substituting invalid bytes
+// mbErrInvalidChars 使 MultiByteToWideChar 在遇到无效字节时失败而不是替换
+const mbErrInvalidChars = 0x8
+
+// maxShortPath is the length above which Win32 APIs need the \\?\ prefix
+// maxShortPath 是 Win32 API 需要 \\?\ 前缀的路径长度
+const maxShortPath = 240
+
+// oemCodePage returns the code page used by console programs, e.g. 936 (GBK) on Chinese Windows
+// oemCodePage 返回控制台程序使用的代码页，例如中文 Windows 上的 936（GBK）
+func oemCodePage() uint32 {
+	cp, _, _ := procGetOEMCP.Call()
+	return uint32(cp)
+}
+
+// ansiCodePage returns the ANSI code page used by GUI programs and most Windows tools
+// ansiCodePage 返回 GUI 程序和大多数 Windows 工具使用的 ANSI 代码页
+func ansiCodePage() uint32 {
+	cp, _, _ := procGetACP.Call()
+	return uint32(cp)
+}
+
+// detectCodePage guesses the code page of console output: UTF-8 when the bytes are valid UTF-8,
+// otherwise the first of the OEM and ANSI code pages that decodes them without errors, or 0 when none does
+// detectCodePage 推测控制台输出的代码页：字节为有效 UTF-8 时返回 UTF-8，
+// 否则返回 OEM 和 ANSI 代码页中第一个能无错解码的代码页，都不能时返回 0
+func detectCodePage(b []byte) uint32 {
+	if utf8.Valid(b) {
+		return codePageUTF8
+	}
+	for _, cp := range []uint32{oemCodePage(), ansiCodePage()} {
+		if cp == codePageUTF8 {
+			continue
+		}
+		if n, _, _ := procMultiByteToWideChar.Call(uintptr(cp), mbErrInvalidChars, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), 0, 0); n != 0 {
+			return cp
+		}
+	}
+	return 0
+}
+
+// decodeCodePage converts bytes in the given code page to UTF-8
+// decodeCodePage 将指定代码页的字节转换为 UTF-8
+func decodeCodePage(b []byte, cp uint32) ([]byte, bool) {
+	n, _, _ := procMultiByteToWideChar.Call(uintptr(cp), 0, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), 0, 0)
+	if n == 0 {
+		return b, false
+	}
+	wide := make([]uint16, n)
+	n, _, _ = procMultiByteToWideChar.Call(uintptr(cp), 0, uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)), uintptr(unsafe.Pointer(&wide[0])), n)
+	if n == 0 {
+		return b, false
+	}
+	return []byte(string(utf16.Decode(wide[:n]))), true
+}
+
+// decodeConsoleOutput converts console output to UTF-8. Output that already is valid UTF-8 is returned as is,
+// anything else is decoded from the detected code page, so GBK output such as paths under a Chinese user name parses correctly
+// decodeConsoleOutput 将控制台输出转换为 UTF-8。已是有效 UTF-8 的输出原样返回，
+// 否则按检测到的代码页解码，使中文用户名路径等 GBK 输出能够被正确解析
+func decodeConsoleOutput(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	cp := detectCodePage(b)
+	if cp == codePageUTF8 {
+		return b
+	}
+	if cp == 0 {
+		// 无法识别时按 OEM 代码页尽量解码
+		// Best effort with the OEM code page when nothing matched
+		cp = oemCodePage()
+	}
+	decoded, _ := decodeCodePage(b, cp)
+	return decoded
+}
+
+// encodeConsoleText converts UTF-8 text to the OEM code page that cmd.exe uses to read batch files.
+// Text that cannot be represented is returned unchanged
+// encodeConsoleText 将 UTF-8 文本转换为 cmd.exe 读取批处理文件时使用的 OEM 代码页。
+// 无法表示的文本原样返回
+func encodeConsoleText(s string) []byte {
+	if s == "" || isASCII(s) {
+		return []byte(s)
+	}
+
+	wide := utf16.Encode([]rune(s))
+	cp := uintptr(oemCodePage())
+	var usedDefault int32
+	n, _, _ := procWideCharToMultiByte.Call(cp, 0, uintptr(unsafe.Pointer(&wide[0])), uintptr(len(wide)), 0, 0, 0, uintptr(unsafe.Pointer(&usedDefault)))
+	if n == 0 || usedDefault != 0 {
+		return []byte(s)
+	}
+	out := make([]byte, n)
+	n, _, _ = procWideCharToMultiByte.Call(cp, 0, uintptr(unsafe.Pointer(&wide[0])), uintptr(len(wide)), uintptr(unsafe.Pointer(&out[0])), n, 0, 0)
+	if n == 0 {
+		return []byte(s)
+	}
+	return out[:n]
+}
+
+// isASCII reports whether s only contains ASCII characters
+// isASCII 判断 s 是否只包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// longPath adds the \\?\ prefix to long absolute paths so deep node_modules trees can be walked and deleted
+// longPath 为较长的绝对路径添加 \\?\ 前缀，使深层 node_modules 目录树能够被遍历和删除
+func longPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	if strings.HasPrefix(path, `\\`) {
+		return `\\?\UNC\` + path[2:]
+	}
+	if len(path) > 2 && path[1] == ':' {
+		return `\\?\` + path
+	}
+	return path
+}
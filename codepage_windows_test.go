@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// userPaths are nvm roots below user names that broke the parsing before, e.g. Chinese or accented names
+// userPaths 是曾导致解析出错的用户名下的 nvm 根目录，例如中文或带重音符号的用户名
+var userPaths = []string{
+	`C:\Users\张三\AppData\Roaming\nvm`,
+	`C:\Users\Zoë Müller\AppData\Roaming\nvm`,
+	`C:\Users\José Ñúñez (Dev)\AppData\Roaming\nvm`,
+	`D:\工具\nvm`,
+}
+
+func TestLongPath(t *testing.T) {
+	deep := `C:\Users\张三\project` + strings.Repeat(`\node_modules\pkg`, 20)
+	unc := `\\server\share\nvm` + strings.Repeat(`\node_modules\pkg`, 20)
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"short", `C:\Users\张三\nvm\v18.17.0`, `C:\Users\张三\nvm\v18.17.0`},
+		{"long drive path", deep, `\\?\` + deep},
+		{"long UNC path", unc, `\\?\UNC\` + unc[2:]},
+		{"already prefixed", `\\?\` + deep, `\\?\` + deep},
+		{"relative", strings.Repeat(`node_modules\`, 30), strings.Repeat(`node_modules\`, 30)},
+	}
+	for _, tt := range tests {
+		if got := longPath(tt.in); got != tt.want {
+			t.Errorf("%s: longPath(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeConsoleOutputKeepsUTF8(t *testing.T) {
+	for _, path := range userPaths {
+		in := []byte("  * 18.17.0 (Currently using 64-bit executable)\r\n" + path + "\r\n")
+		if got := decodeConsoleOutput(in); string(got) != string(in) {
+			t.Errorf("decodeConsoleOutput changed UTF-8 output %q to %q", in, got)
+		}
+	}
+	if got := decodeConsoleOutput(nil); len(got) != 0 {
+		t.Errorf("decodeConsoleOutput(nil) = %q, want empty", got)
+	}
+}
+
+func TestConsoleTextRoundTrip(t *testing.T) {
+	// 无论 OEM 代码页能否表示这些字符，写入批处理文件后再读回都应得到原始路径
+	// Whether or not the OEM code page can represent the characters, a batch file written and read back
+	// yields the original path
+	for _, path := range userPaths {
+		if got := string(decodeConsoleOutput(encodeConsoleText(path))); got != path {
+			t.Errorf("round trip of %q gave %q", path, got)
+		}
+	}
+	if got := encodeConsoleText(`C:\nvm\v20.11.0`); string(got) != `C:\nvm\v20.11.0` {
+		t.Errorf("ASCII text was re-encoded to %q", got)
+	}
+}
+
+func TestIsASCII(t *testing.T) {
+	tests := map[string]bool{
+		`C:\Users\dev\nvm`:   true,
+		"":                   true,
+		`C:\Users\张三\nvm`:    false,
+		`C:\Users\Zoë\nvm`:   false,
+		"%APPDATA%\\nvm & x": true,
+	}
+	for in, want := range tests {
+		if got := isASCII(in); got != want {
+			t.Errorf("isASCII(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestDirSizeBelowNonASCIIDeepPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "用户 Zoë", "v18.17.0")
+	deep := filepath.Join(append([]string{dir}, strings.Split(strings.Repeat("node_modules/包/", 15), "/")...)...)
+	if err := os.MkdirAll(longPath(deep), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]int{
+		filepath.Join(dir, "node.exe"):    100,
+		filepath.Join(deep, "index.js"):   20,
+		filepath.Join(deep, "说明 & 1%.md"): 3,
+	}
+	var want int64
+	for path, n := range files {
+		if err := os.WriteFile(longPath(path), make([]byte, n), 0644); err != nil {
+			t.Fatal(err)
+		}
+		want += int64(n)
+	}
+	if got := dirSize(dir); got != want {
+		t.Errorf("dirSize = %d, want %d", got, want)
+	}
+}
+
+func TestVersionDirUnderUserPaths(t *testing.T) {
+	for _, root := range userPaths {
+		want := root + `\v20.11.0`
+		for _, version := range []string{"20.11.0", "v20.11.0"} {
+			if got := versionDir(root, version); got != want {
+				t.Errorf("versionDir(%q, %q) = %q, want %q", root, version, got, want)
+			}
+		}
+	}
+}
+
+func TestCmdShimQuotesUserPaths(t *testing.T) {
+	for _, root := range userPaths {
+		versionPath := versionDir(root, "20.11.0")
+		shim := cmdShim(versionPath, "node.exe")
+		if !strings.Contains(shim, `SET "PATH=`+versionPath+`;%PATH%"`) {
+			t.Errorf("shim for %q does not quote PATH:\n%s", versionPath, shim)
+		}
+		if !strings.Contains(shim, `"`+versionPath+`\node.exe" %*`) {
+			t.Errorf("shim for %q does not quote the executable:\n%s", versionPath, shim)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modole32             = syscall.NewLazyDLL("ole32.dll")
+	procCoInitializeEx   = modole32.NewProc("CoInitializeEx")
+	procCoUninitialize   = modole32.NewProc("CoUninitialize")
+	procCoCreateInstance = modole32.NewProc("CoCreateInstance")
+)
+
+const (
+	coinitApartmentThreaded = 0x2
+	clsctxInprocServer      = 0x1
+)
+
+// comObject is a raw COM interface pointer whose first field is the vtable
+// comObject 是原始的 COM 接口指针，其第一个字段为虚函数表
+type comObject struct {
+	vtbl *[64]uintptr
+}
+
+// call invokes the vtable method at index with the given arguments and checks the HRESULT
+// call 调用虚函数表中指定索引的方法并检查 HRESULT
+func (o *comObject) call(index int, args ...uintptr) error {
+	callArgs := append([]uintptr{uintptr(unsafe.Pointer(o))}, args...)
+	hr, _, _ := syscall.SyscallN(o.vtbl[index], callArgs...)
+	if int32(hr) < 0 {
+		return fmt.Errorf("COM call %d failed with HRESULT 0x%08X", index, uint32(hr))
+	}
+	return nil
+}
+
+// queryInterface returns the requested interface of the object
+// queryInterface 返回对象的指定接口
+func (o *comObject) queryInterface(iid *syscall.GUID) (*comObject, error) {
+	var out *comObject
+	if err := o.call(0, uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&out))); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// release decrements the reference count of the object
+// release 减少对象的引用计数
+func (o *comObject) release() {
+	if o != nil {
+		o.call(2)
+	}
+}
+
+// coCreateInstance creates an in-process COM object
+// coCreateInstance 创建一个进程内 COM 对象
+func coCreateInstance(clsid, iid *syscall.GUID) (*comObject, error) {
+	var out *comObject
+	hr, _, _ := procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(clsid)),
+		0,
+		clsctxInprocServer,
+		uintptr(unsafe.Pointer(iid)),
+		uintptr(unsafe.Pointer(&out)),
+	)
+	if int32(hr) < 0 {
+		return nil, fmt.Errorf("CoCreateInstance failed with HRESULT 0x%08X", uint32(hr))
+	}
+	return out, nil
+}
+
+// withCOM runs fn on the current OS thread with COM initialized as a single-threaded apartment
+// withCOM 在当前系统线程上以单线程套间方式初始化 COM 后执行 fn
+// The caller must have locked the goroutine to its OS thread
+// 调用方必须已将 goroutine 锁定到系统线程
+func withCOM(fn func() error) error {
+	hr, _, _ := procCoInitializeEx.Call(0, coinitApartmentThreaded)
+	if int32(hr) < 0 {
+		return fmt.Errorf("CoInitializeEx failed with HRESULT 0x%08X", uint32(hr))
+	}
+	defer procCoUninitialize.Call()
+	return fn()
+}
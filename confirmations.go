@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Operations covered by the confirmation policy
+// 确认策略覆盖的操作
+const (
+	OperationSwitch    = "switch"
+	OperationInstall   = "install"
+	OperationUninstall = "uninstall"
+)
+
+// confirmationTTL is how long a pending operation may wait for confirmation
+// confirmationTTL 是待确认操作等待确认的有效期
+const confirmationTTL = 5 * time.Minute
+
+// ConfirmationPolicy selects which operations must be confirmed before they run. It is enforced in the backend,
+// so the UI, launch arguments, the tray and the local API follow the same policy
+// ConfirmationPolicy 选择哪些操作在执行前必须确认。该策略在后端执行，
+// 因此界面、启动参数、托盘和本地接口都遵循相同的策略
+type ConfirmationPolicy struct {
+	Switch        bool `json:"switch"`
+	Install       bool `json:"install"`
+	Uninstall     bool `json:"uninstall"`
+	InstallOverMB int  `json:"installOverMb"` // 下载大于该值的安装需要确认，0 表示不按大小确认 / installs downloading more need confirmation, 0 disables
+}
+
+// PendingConfirmation is an operation held back until ConfirmOperation is called with its ID
+// PendingConfirmation 是在使用其 ID 调用 ConfirmOperation 之前被暂缓的操作
+type PendingConfirmation struct {
+	ID        string
+	Operation string
+	Version   string
+	Reason    string
+	ExpiresAt time.Time
+	Impact    *SwitchImpact // 切换操作的影响预览 / Impact preview of a switch
+	run       func() string // 确认后代替默认操作执行，例如忽略磁盘空间的安装 / runs instead of the plain operation, e.g. an install ignoring disk space
+}
+
+// confirmationReason returns why the policy requires confirming the operation, or an empty string
+// confirmationReason 返回策略要求确认该操作的原因，无需确认时返回空字符串
+func (a *App) confirmationReason(operation, version string) string {
+	policy := a.currentSettings().Confirmations
+	switch operation {
+	case OperationSwitch:
+		if policy.Switch {
+			return fmt.Sprintf("切换到 Node.js %s 需要确认 / Switching to Node.js %s requires confirmation", version, version)
+		}
+	case OperationUninstall:
+		if policy.Uninstall {
+			return fmt.Sprintf("卸载 Node.js %s 需要确认 / Uninstalling Node.js %s requires confirmation", version, version)
+		}
+	case OperationInstall:
+		if policy.Install {
+			return fmt.Sprintf("安装 Node.js %s 需要确认 / Installing Node.js %s requires confirmation", version, version)
+		}
+		if policy.InstallOverMB > 0 {
+			bytes, err := a.downloadSize(version)
+			if err != nil || bytes <= 0 {
+				a.logToFile(fmt.Sprintf("Download size of Node.js %s unknown, not asking for confirmation: %v", version, err))
+				return ""
+			}
+			if bytes > int64(policy.InstallOverMB)<<20 {
+				f := a.formatter()
+				size := f.size(bytes)
+				limit := f.size(int64(policy.InstallOverMB) << 20)
+				return fmt.Sprintf("Node.js %s 需要下载 %s，超过 %s，需要确认 / Installing Node.js %s downloads %s, more than %s, and requires confirmation",
+					version, size, limit, version, size, limit)
+			}
+		}
+	}
+	return ""
+}
+
+// requireConfirmation holds the operation back when the policy asks for confirmation, returning the message
+// for the caller and false; it returns true when the operation may run now
+// requireConfirmation 在策略要求确认时暂缓操作，返回给调用方的消息和 false；可以立即执行时返回 true
+func (a *App) requireConfirmation(operation, version string) (string, bool) {
+	return a.requireConfirmationThen(operation, version, nil)
+}
+
+// requireConfirmationThen is requireConfirmation for a variant of the operation, such as an override of a check;
+// once confirmed run is executed instead of the plain operation
+// requireConfirmationThen 与 requireConfirmation 相同，用于操作的变体（例如跳过某项检查）；确认后执行 run 而不是默认操作
+func (a *App) requireConfirmationThen(operation, version string, run func() string) (string, bool) {
+	reason := a.confirmationReason(operation, version)
+	if reason == "" {
+		return "", true
+	}
+
+	pending := PendingConfirmation{
+		ID:        newPlanID(),
+		Operation: operation,
+		Version:   version,
+		Reason:    reason,
+		ExpiresAt: a.clock.Now().Add(confirmationTTL),
+		run:       run,
+	}
+	if operation == OperationSwitch {
+		if impact, err := a.GetSwitchImpact(version); err == nil {
+			pending.Impact = &impact
+		}
+	}
+	a.confirmMu.Lock()
+	if a.pendingConfirmations == nil {
+		a.pendingConfirmations = map[string]PendingConfirmation{}
+	}
+	a.pendingConfirmations[pending.ID] = pending
+	a.confirmMu.Unlock()
+
+	a.audit("confirm-"+operation, fmt.Sprintf("%s [%s]", version, pending.ID), "pending")
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "confirmation-required", pending)
+	}
+	msg := fmt.Sprintf("Confirmation required: %s (ID %s)", reason, pending.ID)
+	a.logToFile(msg)
+	return msg, false
+}
+
+// GetPendingConfirmations returns the operations waiting for confirmation
+// GetPendingConfirmations 返回等待确认的操作
+func (a *App) GetPendingConfirmations() []PendingConfirmation {
+	a.confirmMu.Lock()
+	defer a.confirmMu.Unlock()
+	pending := []PendingConfirmation{}
+	for id, p := range a.pendingConfirmations {
+		if a.clock.Now().After(p.ExpiresAt) {
+			delete(a.pendingConfirmations, id)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	return pending
+}
+
+// CancelOperation discards an operation waiting for confirmation
+// CancelOperation 丢弃一个等待确认的操作
+func (a *App) CancelOperation(id string) {
+	a.confirmMu.Lock()
+	pending, ok := a.pendingConfirmations[id]
+	delete(a.pendingConfirmations, id)
+	a.confirmMu.Unlock()
+
+	if ok {
+		a.audit("confirm-"+pending.Operation, fmt.Sprintf("%s [%s]", pending.Version, id), "cancelled")
+	}
+}
+
+// ConfirmOperation runs an operation that was held back by the confirmation policy
+// ConfirmOperation 执行被确认策略暂缓的操作
+func (a *App) ConfirmOperation(id string) (string, error) {
+	a.confirmMu.Lock()
+	pending, ok := a.pendingConfirmations[strings.TrimSpace(id)]
+	delete(a.pendingConfirmations, strings.TrimSpace(id))
+	a.confirmMu.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("Unknown or already used confirmation: %s", id)
+	}
+	if a.clock.Now().After(pending.ExpiresAt) {
+		a.audit("confirm-"+pending.Operation, fmt.Sprintf("%s [%s]", pending.Version, id), "expired")
+		return "", fmt.Errorf("The confirmation has expired, please start the operation again")
+	}
+
+	a.audit("confirm-"+pending.Operation, fmt.Sprintf("%s [%s]", pending.Version, id), "confirmed")
+	if pending.run != nil {
+		return pending.run(), nil
+	}
+	switch pending.Operation {
+	case OperationSwitch:
+		return a.switchNodeVersion(pending.Version), nil
+	case OperationInstall:
+		return a.installNodeVersionWithinCap(pending.Version), nil
+	case OperationUninstall:
+		return a.uninstallNodeVersionWithinCap(pending.Version), nil
+	}
+	return "", fmt.Errorf("unknown operation %q", pending.Operation)
+}
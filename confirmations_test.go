@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOverridesRequireConfirmation(t *testing.T) {
+	executor := &FakeExecutor{}
+	a := newTestApp(t, executor)
+	settings := a.currentSettings()
+	settings.Confirmations = ConfirmationPolicy{Install: true, Uninstall: true}
+	if err := a.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides := map[string]func(string) string{
+		"install ignoring disk space":      a.InstallNodeVersionIgnoringDiskSpace,
+		"install over the bandwidth cap":   a.InstallNodeVersionOverBandwidthCap,
+		"uninstall ignoring the processes": a.UninstallNodeVersionIgnoringProcesses,
+	}
+	for name, override := range overrides {
+		if msg := override("20.11.0"); !strings.HasPrefix(msg, "Confirmation required") {
+			t.Errorf("%s ran without confirmation: %q", name, msg)
+		}
+	}
+	pending := a.GetPendingConfirmations()
+	if len(pending) != len(overrides) {
+		t.Fatalf("%d pending confirmations, want %d", len(pending), len(overrides))
+	}
+	for _, p := range pending {
+		if p.run == nil {
+			t.Errorf("pending %s of %s does not run the override once confirmed", p.Operation, p.Version)
+		}
+		a.CancelOperation(p.ID)
+	}
+	if len(executor.Calls) != 0 {
+		t.Errorf("commands ran before confirmation: %+v", executor.Calls)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pairingCodeTTL is how long a pairing code shown in the app can be redeemed
+// pairingCodeTTL 是应用中显示的配对码可被使用的有效期
+const pairingCodeTTL = 2 * time.Minute
+
+// ConnectedApp is an external tool paired with the local API. Only the hash of its token is stored
+// ConnectedApp 是与本地接口配对的外部工具，仅保存其令牌的哈希
+type ConnectedApp struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	TokenHash string    `json:"tokenHash"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// PairingCode is a one-time code the user types into an external tool to pair it
+// PairingCode 是用户输入到外部工具中用于配对的一次性代码
+type PairingCode struct {
+	Code      string
+	Scope     string
+	ExpiresAt time.Time
+}
+
+// connectedApps holds the paired tools and the pending pairing code. It is kept in its own file rather than
+// the settings, so pairing a tool neither rewrites nor rotates the settings backups
+// connectedApps 保存已配对的工具和待使用的配对码。它保存在独立文件而非设置中，
+// 因此配对工具既不会重写设置，也不会轮换设置备份
+type connectedApps struct {
+	mu      sync.Mutex
+	loaded  bool
+	apps    []ConnectedApp
+	pairing PairingCode
+}
+
+// connectedAppsFilePath returns the file holding the paired tools next to the executable
+// connectedAppsFilePath 返回可执行文件同目录下保存已配对工具的文件路径
+func connectedAppsFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-connected-apps.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-connected-apps.json")
+}
+
+// hashToken returns the hex SHA-256 of a token
+// hashToken 返回令牌的十六进制 SHA-256 值
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// load reads the paired tools once, the caller holds c.mu
+// load 读取一次已配对的工具，调用方需持有 c.mu
+func (c *connectedApps) load(fsys FileSystem) {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	if data, err := fsys.ReadFile(connectedAppsFilePath()); err == nil {
+		json.Unmarshal(data, &c.apps)
+	}
+}
+
+// save writes the paired tools, the caller holds c.mu
+// save 写入已配对的工具，调用方需持有 c.mu
+func (c *connectedApps) save(fsys FileSystem) error {
+	data, err := json.MarshalIndent(c.apps, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(connectedAppsFilePath(), data, 0600)
+}
+
+// StartPairing shows a new one-time code for pairing an external tool with the given scope (read or manage)
+// StartPairing 生成新的一次性代码，用于以指定权限（read 或 manage）配对外部工具
+func (a *App) StartPairing(scope string) (PairingCode, error) {
+	if scope != APIScopeRead && scope != APIScopeManage {
+		return PairingCode{}, fmt.Errorf("unknown scope %q", scope)
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return PairingCode{}, err
+	}
+	code := PairingCode{Code: fmt.Sprintf("%06d", n.Int64()), Scope: scope, ExpiresAt: a.clock.Now().Add(pairingCodeTTL)}
+
+	c := &a.connectedApps
+	c.mu.Lock()
+	c.pairing = code
+	c.mu.Unlock()
+	a.audit("pairing-start", scope, "pending")
+	return code, nil
+}
+
+// CancelPairing discards the pending pairing code
+// CancelPairing 丢弃待使用的配对码
+func (a *App) CancelPairing() {
+	c := &a.connectedApps
+	c.mu.Lock()
+	c.pairing = PairingCode{}
+	c.mu.Unlock()
+}
+
+// redeemPairingCode exchanges the pending code for a token scoped as the code, registering the tool
+// redeemPairingCode 使用待用配对码换取与其权限相同的令牌，并登记该工具
+func (a *App) redeemPairingCode(code, name string) (string, ConnectedApp, error) {
+	c := &a.connectedApps
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := c.pairing
+	if pending.Code == "" || a.clock.Now().After(pending.ExpiresAt) || strings.TrimSpace(code) != pending.Code {
+		return "", ConnectedApp{}, fmt.Errorf("invalid or expired pairing code")
+	}
+	// 配对码只能使用一次
+	// A pairing code works only once
+	c.pairing = PairingCode{}
+
+	c.load(a.fs)
+	token := newAPIToken()
+	app := ConnectedApp{
+		ID:        newPlanID(),
+		Name:      name,
+		Scope:     pending.Scope,
+		TokenHash: hashToken(token),
+		CreatedAt: a.clock.Now(),
+	}
+	c.apps = append(c.apps, app)
+	if err := c.save(a.fs); err != nil {
+		return "", ConnectedApp{}, fmt.Errorf("Error saving connected apps: %v", err)
+	}
+	return token, app, nil
+}
+
+// connectedAppForToken returns the paired tool owning token and records its use
+// connectedAppForToken 返回持有该令牌的已配对工具，并记录其使用时间
+func (a *App) connectedAppForToken(token string) (ConnectedApp, bool) {
+	if token == "" {
+		return ConnectedApp{}, false
+	}
+	hash := hashToken(token)
+	c := &a.connectedApps
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load(a.fs)
+	for i := range c.apps {
+		if c.apps[i].TokenHash == hash {
+			// 使用时间只保存在内存中，撤销或新配对时一并写入
+			// The last use is kept in memory and written along with the next revoke or pairing
+			c.apps[i].LastUsed = a.clock.Now()
+			return c.apps[i], true
+		}
+	}
+	return ConnectedApp{}, false
+}
+
+// GetConnectedApps returns the tools paired with the local API, for the "Connected apps" settings section
+// GetConnectedApps 返回与本地接口配对的工具，供“已连接应用”设置页使用
+func (a *App) GetConnectedApps() []ConnectedApp {
+	c := &a.connectedApps
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load(a.fs)
+	apps := make([]ConnectedApp, 0, len(c.apps))
+	for _, app := range c.apps {
+		app.TokenHash = ""
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+// RevokeConnectedApp removes a paired tool, its token stops working immediately
+// RevokeConnectedApp 移除已配对的工具，其令牌立即失效
+func (a *App) RevokeConnectedApp(id string) error {
+	c := &a.connectedApps
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load(a.fs)
+	for i, app := range c.apps {
+		if app.ID != id {
+			continue
+		}
+		c.apps = append(c.apps[:i], c.apps[i+1:]...)
+		if err := c.save(a.fs); err != nil {
+			return fmt.Errorf("Error saving connected apps: %v", err)
+		}
+		a.audit("connected-app-revoke", fmt.Sprintf("%s [%s]", app.Name, app.ID), "success")
+		return nil
+	}
+	return fmt.Errorf("Connected app not found: %s", id)
+}
+
+// servePairing handles POST /api/pair with {"code": "...", "name": "..."} and returns the issued token once
+// servePairing 处理携带 {"code": "...", "name": "..."} 的 POST /api/pair 请求，并一次性返回签发的令牌
+func (a *App) servePairing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil || body.Code == "" {
+		http.Error(w, "a JSON body with a code is required", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		name = "unnamed"
+	}
+
+	token, app, err := a.redeemPairingCode(body.Code, name)
+	if err != nil {
+		a.audit("pairing-redeem", name, "rejected")
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	a.audit("pairing-redeem", fmt.Sprintf("%s [%s] scope %s", app.Name, app.ID, app.Scope), "success")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": app.ID, "scope": app.Scope, "token": token})
+}
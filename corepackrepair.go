@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CorepackShim is the state of one corepack shim in the active version
+// CorepackShim 表示当前版本中一个 corepack 垫片的状态
+type CorepackShim struct {
+	Name     string
+	Resolves bool
+	Repaired bool
+	Error    string
+}
+
+// CorepackCheck is the result of verifying the corepack shims after a switch
+// CorepackCheck 表示切换后检查 corepack 垫片的结果
+type CorepackCheck struct {
+	Version string
+	Shims   []CorepackShim
+}
+
+// configuredPackageManagers returns the package managers corepack was enabled for in registered projects
+// configuredPackageManagers 返回已登记项目中通过 corepack 启用的包管理器
+func (a *App) configuredPackageManagers() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, project := range a.currentSettings().Projects {
+		if project.Corepack != "" && !seen[project.Corepack] {
+			seen[project.Corepack] = true
+			names = append(names, project.Corepack)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shimResolves reports whether a package manager shim exists in the version and runs
+// shimResolves 判断版本中的包管理器垫片是否存在并能运行
+func (a *App) shimResolves(version, name string) error {
+	root := a.nvmRoot()
+	if _, err := os.Stat(filepath.Join(versionDir(root, version), name+".cmd")); err != nil {
+		return fmt.Errorf("%s.cmd is missing", name)
+	}
+	home, _ := os.UserHomeDir()
+	_, err := a.runWithNodeVersion(version, home, name+".cmd", "--version")
+	return err
+}
+
+// CheckCorepackShims verifies that the pnpm/yarn shims configured through corepack resolve for the active
+// version and re-runs corepack enable for the broken ones
+// CheckCorepackShims 检查通过 corepack 配置的 pnpm/yarn 垫片在当前版本下能否解析，
+// 并对损坏的垫片重新执行 corepack enable
+func (a *App) CheckCorepackShims() (CorepackCheck, error) {
+	names := a.configuredPackageManagers()
+	if len(names) == 0 {
+		return CorepackCheck{}, nil
+	}
+	version, err := a.currentNodeVersion()
+	if err != nil {
+		return CorepackCheck{}, err
+	}
+	version = strings.TrimPrefix(version, "v")
+	check := CorepackCheck{Version: version}
+	if !corepackBundled(version) {
+		return check, fmt.Errorf("Node.js %s does not bundle corepack", version)
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, name := range names {
+		shim := CorepackShim{Name: name}
+		err := a.shimResolves(version, name)
+		if err == nil {
+			shim.Resolves = true
+			check.Shims = append(check.Shims, shim)
+			continue
+		}
+		a.logToFile(fmt.Sprintf("corepack shim %s does not resolve for Node.js %s: %v", name, version, err))
+
+		if _, err := a.runWithNodeVersion(version, home, "corepack.cmd", "enable", name); err != nil {
+			shim.Error = fmt.Sprintf("corepack enable %s failed: %v", name, err)
+		} else if err := a.shimResolves(version, name); err != nil {
+			shim.Error = fmt.Sprintf("%s still does not resolve after corepack enable: %v", name, err)
+		} else {
+			shim.Resolves = true
+			shim.Repaired = true
+		}
+		outcome := "success"
+		if !shim.Repaired {
+			outcome = "failed"
+		}
+		a.audit("repair-corepack-shim", fmt.Sprintf("%s (Node.js %s)", name, version), outcome)
+		check.Shims = append(check.Shims, shim)
+	}
+	return check, nil
+}
+
+// runPostSwitchCorepackRepair checks the corepack shims after a successful switch and reports any repairs
+// runPostSwitchCorepackRepair 在切换成功后检查 corepack 垫片并报告修复情况
+func (a *App) runPostSwitchCorepackRepair() {
+	check, err := a.CheckCorepackShims()
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Skipping corepack shim check: %v", err))
+		return
+	}
+	for _, shim := range check.Shims {
+		if shim.Repaired || shim.Error != "" {
+			a.notify("corepack-repair", check)
+			return
+		}
+	}
+}
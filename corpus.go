@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CorpusEntry is one recorded nvm command with its anonymized output
+// CorpusEntry 表示一条已录制的 nvm 命令及其匿名化后的输出
+type CorpusEntry struct {
+	Args       []string  `json:"args"`
+	Output     string    `json:"output"`
+	Error      string    `json:"error,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// CorpusReplay is the result of feeding one recorded output through its parser
+// CorpusReplay 表示将一条录制输出交给对应解析器的结果
+type CorpusReplay struct {
+	File   string
+	Args   []string
+	Parser string
+	Result interface{}
+	Error  string
+}
+
+// corpusDir returns the directory holding recorded nvm outputs
+// corpusDir 返回存放已录制 nvm 输出的目录
+func corpusDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "corpus"
+	}
+	return filepath.Join(filepath.Dir(execPath), "corpus")
+}
+
+// SetCorpusRecording turns recording of nvm command outputs into the corpus directory on or off
+// SetCorpusRecording 开启或关闭将 nvm 命令输出录制到语料目录的功能
+func (a *App) SetCorpusRecording(enabled bool) error {
+	if enabled {
+		if err := a.fs.MkdirAll(corpusDir(), 0755); err != nil {
+			return fmt.Errorf("Error creating corpus directory: %v", err)
+		}
+	}
+	a.recordCorpus.Store(enabled)
+	a.logToFile(fmt.Sprintf("nvm output recording enabled: %v", enabled))
+	return nil
+}
+
+// IsCorpusRecording reports whether nvm outputs are being recorded
+// IsCorpusRecording 返回是否正在录制 nvm 输出
+func (a *App) IsCorpusRecording() bool {
+	return a.recordCorpus.Load()
+}
+
+// recordNvmOutput saves the anonymized output of an nvm command when recording is enabled
+// recordNvmOutput 在开启录制时保存 nvm 命令匿名化后的输出
+func (a *App) recordNvmOutput(args []string, output []byte, cmdErr error) {
+	if !a.recordCorpus.Load() {
+		return
+	}
+	entry := CorpusEntry{Args: args, Output: redact(string(output)), RecordedAt: a.clock.Now()}
+	if cmdErr != nil {
+		entry.Error = redact(cmdErr.Error())
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	name := fmt.Sprintf("%s-%s.json", entry.RecordedAt.Format("20060102-150405.000"), strings.Join(append([]string{"nvm"}, args...), "-"))
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if err := a.fs.WriteFile(filepath.Join(corpusDir(), name), data, 0644); err != nil {
+		a.logToFile(fmt.Sprintf("Error recording nvm output: %v", err))
+	}
+}
+
+// replayEntry runs a recorded output through the parser used for its command
+// replayEntry 将一条录制输出交给其命令对应的解析器
+func replayEntry(entry CorpusEntry) CorpusReplay {
+	replay := CorpusReplay{Args: entry.Args}
+	command := strings.Join(entry.Args, " ")
+	switch {
+	case command == "ls" || command == "list":
+		replay.Parser = "parseNvmList"
+		replay.Result = parseNvmList(entry.Output)
+	case command == "ls available" || command == "list available":
+		replay.Parser = "parseNvmAvailable"
+		replay.Result = parseNvmAvailable(entry.Output)
+	case command == "root":
+		replay.Parser = "parseNvmRoot"
+		replay.Result = parseNvmRoot(entry.Output)
+	case len(entry.Args) == 2 && entry.Args[0] == "install":
+		replay.Parser = "parseInstallLine"
+		var progress []InstallProgress
+		for _, line := range strings.Split(entry.Output, "\n") {
+			if p, ok := parseInstallLine(entry.Args[1], line); ok {
+				progress = append(progress, p)
+			}
+		}
+		replay.Result = progress
+	default:
+		replay.Error = "no parser for this command"
+	}
+	return replay
+}
+
+// ReplayCorpus feeds every recorded output in the corpus directory through the parsers and returns what they produced
+// ReplayCorpus 将语料目录中的每条录制输出交给解析器，并返回解析结果
+func (a *App) ReplayCorpus() ([]CorpusReplay, error) {
+	entries, err := a.fs.ReadDir(corpusDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []CorpusReplay{}, nil
+		}
+		return nil, fmt.Errorf("Error reading corpus directory: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	replays := []CorpusReplay{}
+	for _, name := range names {
+		replay := CorpusReplay{File: name}
+		data, err := a.fs.ReadFile(filepath.Join(corpusDir(), name))
+		var entry CorpusEntry
+		if err == nil {
+			err = json.Unmarshal(data, &entry)
+		}
+		if err != nil {
+			replay.Error = err.Error()
+		} else {
+			replay = replayEntry(entry)
+			replay.File = name
+		}
+		replays = append(replays, replay)
+	}
+	return replays, nil
+}
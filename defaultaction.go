@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/getlantern/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Default actions run when a version row is double-clicked or picked from the tray quick list
+// 双击版本行或在托盘快捷列表中选择版本时执行的默认操作
+const (
+	DefaultActionSwitch   = "switch"
+	DefaultActionTerminal = "terminal"
+	DefaultActionDetails  = "details"
+)
+
+// trayVersionItems are the tray quick list entries, created once and relabelled as versions change
+// trayVersionItems 是托盘快捷列表条目，只创建一次，版本变化时更新标题
+var (
+	trayVersionItems    []*systray.MenuItem
+	trayVersionLabels   []string
+	trayVersionsMu      sync.Mutex
+	trayVersionsCreated bool
+)
+
+// validDefaultAction reports whether action is one of the DefaultAction constants
+// validDefaultAction 判断 action 是否为默认操作常量之一
+func validDefaultAction(action string) bool {
+	switch action {
+	case DefaultActionSwitch, DefaultActionTerminal, DefaultActionDetails:
+		return true
+	}
+	return false
+}
+
+// GetDefaultAction returns the configured default action, defaulting to switch
+// GetDefaultAction 返回配置的默认操作，默认为切换版本
+func (a *App) GetDefaultAction() string {
+	if action := a.currentSettings().DefaultAction; validDefaultAction(action) {
+		return action
+	}
+	return DefaultActionSwitch
+}
+
+// SetDefaultAction remembers the action run on double-click and from the tray quick list
+// SetDefaultAction 保存双击和托盘快捷列表执行的操作
+func (a *App) SetDefaultAction(action string) error {
+	if !validDefaultAction(action) {
+		return fmt.Errorf("unknown default action %q", action)
+	}
+	settings := a.currentSettings()
+	settings.DefaultAction = action
+	return a.SetSettings(settings)
+}
+
+// RunDefaultAction runs the configured default action on a version so the UI and the tray behave the same
+// RunDefaultAction 对指定版本执行配置的默认操作，使界面和托盘的行为保持一致
+func (a *App) RunDefaultAction(version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	switch action := a.GetDefaultAction(); action {
+	case DefaultActionTerminal:
+		if err := a.OpenTerminal(version, ShellCmd, ""); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Opened a terminal with Node.js %s", version), nil
+	case DefaultActionDetails:
+		a.showWindow()
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "show-version-details", version)
+		}
+		return fmt.Sprintf("Showing details of Node.js %s", version), nil
+	default:
+		result := a.SwitchNodeVersion(version)
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "version-switched", result)
+		}
+		return result, nil
+	}
+}
+
+// addVersionTrayItems adds the quick list submenu of installed versions to the tray, none in safe mode
+// addVersionTrayItems 在托盘中添加已安装版本的快捷列表子菜单，安全模式下不添加
+func (a *App) addVersionTrayItems() {
+	if a.safeMode {
+		return
+	}
+	trayVersionsMu.Lock()
+	defer trayVersionsMu.Unlock()
+
+	parent := systray.AddMenuItem("已安装版本", "Installed versions")
+	for i := 0; i < maxJumpListVersions; i++ {
+		item := parent.AddSubMenuItem("", "")
+		item.Hide()
+		trayVersionItems = append(trayVersionItems, item)
+		trayVersionLabels = append(trayVersionLabels, "")
+		go func(i int, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				trayVersionsMu.Lock()
+				version := trayVersionLabels[i]
+				trayVersionsMu.Unlock()
+				if version == "" {
+					continue
+				}
+				if _, err := a.RunDefaultAction(version); err != nil {
+					a.logToFile(fmt.Sprintf("Error running the default action for Node.js %s: %v", version, err))
+				}
+			}
+		}(i, item)
+	}
+	trayVersionsCreated = true
+	go a.refreshTrayVersions()
+}
+
+// refreshTrayVersions relabels the tray quick list with the installed versions, current version and favorites first
+// refreshTrayVersions 使用已安装版本更新托盘快捷列表，当前版本和已收藏版本排在最前
+func (a *App) refreshTrayVersions() {
+	go a.refreshTrayRecent()
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return
+	}
+	var versions []string
+	current := ""
+	favorites := map[string]bool{}
+	for _, v := range installed {
+		favorites[v.Version] = v.Favorite
+		if v.IsCurrent {
+			current = v.Version
+			versions = append([]string{v.Version}, versions...)
+		} else {
+			versions = append(versions, v.Version)
+		}
+	}
+
+	trayVersionsMu.Lock()
+	defer trayVersionsMu.Unlock()
+	if !trayVersionsCreated {
+		return
+	}
+	for i, item := range trayVersionItems {
+		if i >= len(versions) {
+			trayVersionLabels[i] = ""
+			item.Hide()
+			continue
+		}
+		trayVersionLabels[i] = versions[i]
+		title := "Node.js " + versions[i]
+		if favorites[versions[i]] {
+			title = "★ " + title
+		}
+		if versions[i] == current {
+			title += " (当前)"
+		}
+		item.SetTitle(title)
+		item.Show()
+	}
+}
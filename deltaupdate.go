@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// blockManifestSuffix is appended to the executable asset name for its block manifest
+// blockManifestSuffix 追加在可执行文件资源名之后，表示其分块清单
+const blockManifestSuffix = ".blocks.json"
+
+// maxManifestBlockSize and maxManifestSize bound the values accepted from a block manifest
+// maxManifestBlockSize 和 maxManifestSize 限制从分块清单中接受的数值
+const (
+	maxManifestBlockSize = 16 << 20
+	maxManifestSize      = 1 << 30
+)
+
+// blockManifest lists the SHA256 of every fixed-size block of a release executable. Weak holds the rsync style
+// rolling checksum of each block, which lets blocks be found at any offset of the running executable, so code
+// inserted before them does not make them download again. Without it only blocks at the same offset are reused
+// blockManifest 列出发布的可执行文件中每个固定大小分块的 SHA256。Weak 保存每个分块的 rsync 式滚动校验和，
+// 使分块可以在当前可执行文件的任意偏移处被找到，其前面插入的代码不会导致它们重新下载。没有它时只复用偏移相同的分块
+type blockManifest struct {
+	BlockSize int      `json:"blockSize"`
+	Size      int64    `json:"size"`
+	SHA256    string   `json:"sha256"`
+	Blocks    []string `json:"blocks"`
+	Weak      []uint32 `json:"weak,omitempty"`
+}
+
+// validate checks that the blocks exactly cover the declared size before anything is assembled from them
+// validate 在据此组装文件之前，检查分块是否恰好覆盖声明的大小
+func (m blockManifest) validate() error {
+	if m.BlockSize <= 0 || m.BlockSize > maxManifestBlockSize || m.Size <= 0 || m.Size > maxManifestSize {
+		return fmt.Errorf("invalid block manifest: block size %d, size %d", m.BlockSize, m.Size)
+	}
+	if want := (m.Size + int64(m.BlockSize) - 1) / int64(m.BlockSize); int64(len(m.Blocks)) != want {
+		return fmt.Errorf("invalid block manifest: %d blocks, expected %d", len(m.Blocks), want)
+	}
+	if len(m.Weak) != 0 && len(m.Weak) != len(m.Blocks) {
+		return fmt.Errorf("invalid block manifest: %d weak checksums for %d blocks", len(m.Weak), len(m.Blocks))
+	}
+	for _, hash := range append([]string{m.SHA256}, m.Blocks...) {
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("invalid block manifest: bad SHA256 %q", hash)
+		}
+	}
+	return nil
+}
+
+// bounds returns the byte range [start, end) of block i
+// bounds 返回第 i 个分块的字节范围 [start, end)
+func (m blockManifest) bounds(i int) (int64, int64) {
+	start := int64(i) * int64(m.BlockSize)
+	return start, min(start+int64(m.BlockSize), m.Size)
+}
+
+// blockRange is a run of consecutive blocks that has to be downloaded
+// blockRange 表示一段需要下载的连续分块
+type blockRange struct {
+	first, last int
+}
+
+// hashBlock returns the hex SHA256 of a block
+// hashBlock 返回分块的十六进制 SHA256
+func hashBlock(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// weakSum is the rsync rolling checksum of a window: a is the byte sum, b the sum weighted by distance to the end
+// weakSum 是窗口的 rsync 滚动校验和：a 为字节和，b 为按到末尾距离加权的和
+type weakSum struct {
+	a, b uint32
+	n    uint32
+}
+
+// newWeakSum computes the checksum of a window from scratch
+// newWeakSum 从头计算窗口的校验和
+func newWeakSum(window []byte) weakSum {
+	s := weakSum{n: uint32(len(window))}
+	for i, c := range window {
+		s.a += uint32(c)
+		s.b += uint32(len(window)-i) * uint32(c)
+	}
+	return s
+}
+
+// roll moves the window one byte forward, dropping out and adding in
+// roll 将窗口向前移动一个字节，移出 out 并加入 in
+func (s *weakSum) roll(out, in byte) {
+	s.a += uint32(in) - uint32(out)
+	s.b += s.a - s.n*uint32(out)
+}
+
+// value returns the 32-bit checksum stored in the manifest
+// value 返回保存在清单中的 32 位校验和
+func (s weakSum) value() uint32 {
+	return s.a&0xffff | s.b<<16
+}
+
+// localBlocks finds the manifest blocks present in the running executable and returns them by block index.
+// With weak checksums every offset is tried, otherwise only the aligned blocks
+// localBlocks 查找当前可执行文件中已有的清单分块，并按分块序号返回。
+// 有弱校验和时尝试每个偏移，否则只比较对齐的分块
+func localBlocks(data []byte, manifest blockManifest) map[int][]byte {
+	found := map[int][]byte{}
+	byHash := map[string][]int{}
+	for i, hash := range manifest.Blocks {
+		byHash[hash] = append(byHash[hash], i)
+	}
+	match := func(window []byte) bool {
+		matched := false
+		for _, i := range byHash[hashBlock(window)] {
+			if start, end := manifest.bounds(i); int64(len(window)) == end-start && found[i] == nil {
+				found[i] = window
+				matched = true
+			}
+		}
+		return matched
+	}
+
+	blockSize := manifest.BlockSize
+	for offset := 0; offset < len(data); offset += blockSize {
+		match(data[offset:min(offset+blockSize, len(data))])
+	}
+	// 最后一个分块可能较短，按其长度比较文件末尾
+	// The last block may be shorter, compare the end of the file at its length
+	if start, end := manifest.bounds(len(manifest.Blocks) - 1); int(end-start) <= len(data) {
+		match(data[len(data)-int(end-start):])
+	}
+	if len(manifest.Weak) == 0 || len(data) < blockSize {
+		return found
+	}
+
+	byWeak := map[uint32]bool{}
+	for _, weak := range manifest.Weak {
+		byWeak[weak] = true
+	}
+	sum := newWeakSum(data[:blockSize])
+	for offset := 0; ; {
+		// 弱校验和命中时再用 SHA256 确认
+		// A weak hit is confirmed with SHA256
+		if byWeak[sum.value()] && match(data[offset:offset+blockSize]) {
+			offset += blockSize
+			if offset+blockSize > len(data) {
+				break
+			}
+			sum = newWeakSum(data[offset : offset+blockSize])
+			continue
+		}
+		if offset+blockSize >= len(data) {
+			break
+		}
+		sum.roll(data[offset], data[offset+blockSize])
+		offset++
+	}
+	return found
+}
+
+// missingRanges groups the blocks not found locally into ranges so each run is fetched with one request
+// missingRanges 将本地不存在的分块合并为区间，使每段连续分块只需一次请求
+func missingRanges(manifest blockManifest, local map[int][]byte) []blockRange {
+	var ranges []blockRange
+	for i := range manifest.Blocks {
+		if _, ok := local[i]; ok {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].last == i-1 {
+			ranges[n-1].last = i
+			continue
+		}
+		ranges = append(ranges, blockRange{first: i, last: i})
+	}
+	return ranges
+}
+
+// fetchRange downloads bytes [start, end] of url with an HTTP range request
+// fetchRange 通过 HTTP Range 请求下载 url 的 [start, end] 字节
+func fetchRange(client *http.Client, metrics *Metrics, url string, start, end int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("range request not supported: %s", resp.Status)
+	}
+	data, err := io.ReadAll(&countingReader{r: resp.Body, metrics: metrics, host: resp.Request.URL.Host})
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != end-start+1 {
+		return nil, fmt.Errorf("short range response: got %d bytes, expected %d", len(data), end-start+1)
+	}
+	return data, nil
+}
+
+// downloadDelta rebuilds the new executable from the blocks it shares with the running one,
+// downloading only the changed blocks. It returns the new executable and the number of bytes downloaded
+// downloadDelta 利用与当前可执行文件相同的分块重建新的可执行文件，只下载发生变化的分块。
+// 返回新的可执行文件及下载的字节数
+func (a *App) downloadDelta(update AppUpdate, exePath string) ([]byte, int64, error) {
+	data, err := a.download(update.ManifestURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error downloading block manifest: %v", err)
+	}
+	var manifest blockManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, 0, fmt.Errorf("Error parsing block manifest: %v", err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, 0, err
+	}
+
+	current, err := os.ReadFile(exePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading current executable: %v", err)
+	}
+	local := localBlocks(current, manifest)
+
+	result := make([]byte, manifest.Size)
+	for i, block := range local {
+		start, _ := manifest.bounds(i)
+		copy(result[start:], block)
+	}
+
+	client := a.client(2 * time.Minute)
+	var downloaded int64
+	for _, r := range missingRanges(manifest, local) {
+		start, _ := manifest.bounds(r.first)
+		_, end := manifest.bounds(r.last)
+		chunk, err := fetchRange(client, a.metrics, update.AssetURL, start, end-1)
+		if err != nil {
+			return nil, downloaded, err
+		}
+		copy(result[start:], chunk)
+		downloaded += int64(len(chunk))
+	}
+
+	if hashBlock(result) != manifest.SHA256 {
+		return nil, downloaded, fmt.Errorf("patched executable does not match the expected SHA256")
+	}
+	return result, downloaded, nil
+}
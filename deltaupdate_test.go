@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// testManifest builds the manifest of data with weak checksums
+// testManifest 构建带弱校验和的 data 清单
+func testManifest(data []byte, blockSize int) blockManifest {
+	m := blockManifest{BlockSize: blockSize, Size: int64(len(data)), SHA256: hashBlock(data)}
+	for offset := 0; offset < len(data); offset += blockSize {
+		block := data[offset:min(offset+blockSize, len(data))]
+		m.Blocks = append(m.Blocks, hashBlock(block))
+		m.Weak = append(m.Weak, newWeakSum(block).value())
+	}
+	return m
+}
+
+func TestBlockManifestValidate(t *testing.T) {
+	data := bytes.Repeat([]byte("node"), 100)
+	m := testManifest(data, 64)
+	if err := m.validate(); err != nil {
+		t.Fatalf("valid manifest rejected: %v", err)
+	}
+
+	short := m
+	short.Blocks = m.Blocks[1:]
+	badHash := m
+	badHash.Blocks = append([]string{"zz"}, m.Blocks[1:]...)
+	weak := m
+	weak.Weak = m.Weak[1:]
+	huge := m
+	huge.BlockSize = maxManifestBlockSize + 1
+	for name, manifest := range map[string]blockManifest{"missing block": short, "bad hash": badHash, "weak count": weak, "block size": huge} {
+		if manifest.validate() == nil {
+			t.Errorf("%s: invalid manifest accepted", name)
+		}
+	}
+}
+
+func TestWeakSumRoll(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	sum := newWeakSum(data[:8])
+	for offset := 1; offset+8 <= len(data); offset++ {
+		sum.roll(data[offset-1], data[offset+7])
+		if want := newWeakSum(data[offset : offset+8]).value(); sum.value() != want {
+			t.Fatalf("rolled checksum at %d = %x, want %x", offset, sum.value(), want)
+		}
+	}
+}
+
+func TestLocalBlocksFindsShiftedBlocks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	old := make([]byte, 64*40+17)
+	rng.Read(old)
+	// 新版本在开头插入了代码，其余分块整体后移
+	// The new release inserts code at the start, shifting every other block
+	release := append(append([]byte{}, []byte("inserted code")...), old...)
+	manifest := testManifest(release, 64)
+
+	local := localBlocks(old, manifest)
+	if len(local) < len(manifest.Blocks)-2 {
+		t.Errorf("found %d of %d blocks, want all but the first and the last", len(local), len(manifest.Blocks))
+	}
+	for i, block := range local {
+		if hashBlock(block) != manifest.Blocks[i] {
+			t.Errorf("block %d does not match the manifest", i)
+		}
+	}
+
+	manifest.Weak = nil
+	if aligned := localBlocks(old, manifest); len(aligned) > 1 {
+		t.Errorf("aligned matching found %d shifted blocks", len(aligned))
+	}
+}
+
+func TestMissingRanges(t *testing.T) {
+	manifest := blockManifest{Blocks: make([]string, 8)}
+	local := map[int][]byte{0: nil, 3: nil, 4: nil, 7: nil}
+	want := []blockRange{{first: 1, last: 2}, {first: 5, last: 6}}
+	if got := missingRanges(manifest, local); !reflect.DeepEqual(got, want) {
+		t.Errorf("missingRanges = %+v, want %+v", got, want)
+	}
+	if got := missingRanges(manifest, map[int][]byte{}); !reflect.DeepEqual(got, []blockRange{{first: 0, last: 7}}) {
+		t.Errorf("missingRanges without local blocks = %+v", got)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CommandSpec describes an external command to run
+// CommandSpec 描述要运行的外部命令
+type CommandSpec struct {
+	Name   string
+	Args   []string
+	Dir    string
+	Env    []string // 为空时继承当前进程环境 / inherits the process environment when empty
+	Hidden bool     // 不显示控制台窗口 / run without a console window
+	// 原样交给 CreateProcess 的完整命令行，用于 cmd.exe 这类自行解析引号的程序
+	// Full command line handed verbatim to CreateProcess, for programs such as cmd.exe that parse quotes themselves
+	CmdLine    string
+	NewConsole bool // 在新的控制台窗口中运行 / run in a new console window
+}
+
+// Executor runs external commands, so the parsing around them can be exercised with recorded output
+// instead of a real nvm
+// Executor 运行外部命令，使其周围的解析逻辑可以使用录制的输出而不是真实的 nvm 进行验证
+type Executor interface {
+	// CombinedOutput runs the command and returns its stdout and stderr combined
+	// CombinedOutput 运行命令并返回合并后的标准输出和标准错误
+	CombinedOutput(spec CommandSpec) ([]byte, error)
+	// Run runs the command with the given writers; when ctx ends the command and every process it started are terminated
+	// Run 使用给定的输出运行命令；ctx 结束时终止该命令及其启动的所有进程
+	Run(ctx context.Context, spec CommandSpec, stdout, stderr io.Writer) error
+	// Start launches a command that keeps running on its own, such as a terminal window
+	// Start 启动一个独立运行的命令，例如终端窗口
+	Start(spec CommandSpec) error
+}
+
+// Clock provides the current time
+// Clock 提供当前时间
+type Clock interface {
+	Now() time.Time
+}
+
+// FileSystem is the subset of file operations used for the settings and the other data files of the app
+// FileSystem 是设置文件及应用其他数据文件所用文件操作的子集
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+	Rename(oldpath, newpath string) error
+	Stat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+	RemoveAll(path string) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+	AppendFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// Dependencies are the collaborators injected into App
+// Dependencies 是注入到 App 中的依赖
+type Dependencies struct {
+	Executor   Executor
+	Clock      Clock
+	FS         FileSystem
+	HTTPClient *http.Client
+}
+
+// osExecutor runs commands with os/exec
+// osExecutor 使用 os/exec 运行命令
+type osExecutor struct{}
+
+// command builds the exec.Cmd described by spec
+// command 构建 spec 描述的 exec.Cmd
+func (osExecutor) command(spec CommandSpec) *exec.Cmd {
+	cmd := exec.Command(spec.Name, spec.Args...)
+	cmd.Dir = spec.Dir
+	if len(spec.Env) > 0 {
+		cmd.Env = spec.Env
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: spec.Hidden, CmdLine: spec.CmdLine}
+	if spec.NewConsole {
+		cmd.SysProcAttr.CreationFlags = createNewConsole
+	}
+	return cmd
+}
+
+func (e osExecutor) CombinedOutput(spec CommandSpec) ([]byte, error) {
+	return e.command(spec).CombinedOutput()
+}
+
+func (e osExecutor) Run(ctx context.Context, spec CommandSpec, stdout, stderr io.Writer) error {
+	cmd := e.command(spec)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return runInJob(ctx, cmd)
+}
+
+func (e osExecutor) Start(spec CommandSpec) error {
+	cmd := e.command(spec)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// 命令由用户或其自身结束，这里只回收进程句柄
+	// The user or the command itself ends it, we only reap the process handle
+	go cmd.Wait()
+	return nil
+}
+
+// systemClock reads the system time
+// systemClock 读取系统时间
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// osFS uses the real file system
+// osFS 使用真实的文件系统
+type osFS struct{}
+
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (osFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (osFS) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) RemoveAll(path string) error                  { return os.RemoveAll(path) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (osFS) AppendFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// client returns a copy of the injected HTTP client with the given timeout
+// client 返回注入的 HTTP 客户端的副本，并设置指定的超时
+func (a *App) client(timeout time.Duration) *http.Client {
+	c := *a.httpClient
+	c.Timeout = timeout
+	return &c
+}
+
+// runHidden runs a helper program without a console window and returns its trimmed output
+// runHidden 在不显示控制台窗口的情况下运行辅助程序并返回去除空白的输出
+func (a *App) runHidden(name string, args ...string) (string, error) {
+	output, err := a.executor.CombinedOutput(CommandSpec{Name: name, Args: args, Hidden: true})
+	return strings.TrimSpace(string(decodeConsoleOutput(output))), err
+}
+
+// outputOf runs a command and returns only its stdout, like exec.Cmd.Output
+// outputOf 运行命令并只返回其标准输出，与 exec.Cmd.Output 相同
+func (a *App) outputOf(spec CommandSpec) ([]byte, error) {
+	var stdout bytes.Buffer
+	err := a.executor.Run(context.Background(), spec, &stdout, io.Discard)
+	return stdout.Bytes(), err
+}
+
+// defaultDependencies returns the production implementations
+// defaultDependencies 返回生产环境使用的实现
+func defaultDependencies() Dependencies {
+	return Dependencies{
+		Executor:   osExecutor{},
+		Clock:      systemClock{},
+		FS:         osFS{},
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
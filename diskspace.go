@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMinFreeDiskMB is the free space below which installs are blocked by default
+// defaultMinFreeDiskMB 是默认情况下阻止安装的剩余空间阈值
+const defaultMinFreeDiskMB = 1024
+
+// VersionDiskUsage is the size of one installed version
+// VersionDiskUsage 表示一个已安装版本占用的空间
+type VersionDiskUsage struct {
+	Version  string
+	Size     int64
+	SizeText string
+}
+
+// DiskUsage is shown in the disk usage view
+// DiskUsage 用于磁盘占用视图
+type DiskUsage struct {
+	Root      string
+	Drive     string
+	FreeBytes uint64
+	DiskBytes uint64
+	Total     int64
+	Versions  []VersionDiskUsage
+	LowSpace  bool
+	FreeText  string
+	TotalText string
+}
+
+// DiskSpaceCheck is the result of checking free space before an install
+// DiskSpaceCheck 表示安装前检查剩余空间的结果
+type DiskSpaceCheck struct {
+	Drive       string
+	FreeBytes   uint64
+	MinFreeMB   int
+	Sufficient  bool
+	Message     string
+	CheckFailed bool
+}
+
+// checkInstallSpace compares the free space on the nvm root drive with the configured threshold
+// checkInstallSpace 将 nvm 根目录所在磁盘的剩余空间与配置的阈值进行比较
+func (a *App) checkInstallSpace() DiskSpaceCheck {
+	check := DiskSpaceCheck{MinFreeMB: a.currentSettings().MinFreeDiskMB, Sufficient: true}
+	if check.MinFreeMB <= 0 {
+		return check
+	}
+
+	root := a.nvmRoot()
+	if root == "" {
+		check.CheckFailed = true
+		return check
+	}
+	check.Drive = filepath.VolumeName(root)
+	free, _, err := diskFreeSpace(root)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error reading free disk space of %s: %v", root, err))
+		check.CheckFailed = true
+		return check
+	}
+
+	check.FreeBytes = free
+	if free < uint64(check.MinFreeMB)<<20 {
+		f := a.formatter()
+		freeText := f.size(int64(free))
+		minText := f.size(int64(check.MinFreeMB) << 20)
+		check.Sufficient = false
+		check.Message = fmt.Sprintf("%s 剩余空间仅 %s，低于 %s / Only %s free on %s, below the %s threshold",
+			check.Drive, freeText, minText, freeText, check.Drive, minText)
+	}
+	return check
+}
+
+// CheckInstallSpace reports whether the nvm root drive has enough free space for an install
+// CheckInstallSpace 报告 nvm 根目录所在磁盘是否有足够空间进行安装
+func (a *App) CheckInstallSpace() DiskSpaceCheck {
+	return a.checkInstallSpace()
+}
+
+// InstallNodeVersionIgnoringDiskSpace installs a version even when free space is below the threshold
+// InstallNodeVersionIgnoringDiskSpace 即使剩余空间低于阈值也安装指定版本
+func (a *App) InstallNodeVersionIgnoringDiskSpace(version string) string {
+	run := func() string {
+		a.audit("install-low-disk-override", version, "confirmed")
+		return a.installNodeVersion(version)
+	}
+	if msg, ok := a.requireConfirmationThen(OperationInstall, version, run); !ok {
+		return msg
+	}
+	return run()
+}
+
+// GetDiskUsage returns the size of every installed version and the free space of the nvm root drive
+// GetDiskUsage 返回每个已安装版本的大小以及 nvm 根目录所在磁盘的剩余空间
+func (a *App) GetDiskUsage() (DiskUsage, error) {
+	root := a.nvmRoot()
+	if root == "" {
+		return DiskUsage{}, fmt.Errorf("nvm root not found")
+	}
+	usage := DiskUsage{Root: root, Drive: filepath.VolumeName(root)}
+	f := a.formatter()
+
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return usage, err
+	}
+	for _, v := range installed {
+		// 扫描较慢，交互操作优先
+		// Scanning is slow, interactive actions go first
+		a.yieldToInteractive()
+		size := dirSize(versionDir(root, v.Version))
+		usage.Total += size
+		usage.Versions = append(usage.Versions, VersionDiskUsage{Version: strings.TrimPrefix(v.Version, "v"), Size: size, SizeText: f.size(size)})
+	}
+	usage.TotalText = f.size(usage.Total)
+
+	if free, total, err := diskFreeSpace(root); err == nil {
+		usage.FreeBytes = free
+		usage.FreeText = f.size(int64(free))
+		usage.DiskBytes = total
+		if min := a.currentSettings().MinFreeDiskMB; min > 0 {
+			usage.LowSpace = free < uint64(min)<<20
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// diskFreeSpace returns the bytes available to this user and the total size of the drive holding path
+// diskFreeSpace 返回 path 所在磁盘对当前用户可用的字节数及磁盘总大小
+func diskFreeSpace(path string) (uint64, uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return 0, 0, err
+	}
+	return free, total, nil
+}
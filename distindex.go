@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// officialDistURL is the primary source of the Node.js release index
+// officialDistURL 是 Node.js 发布索引的主要来源
+const officialDistURL = "https://nodejs.org/dist"
+
+// indexFetchTimeout bounds each attempt so a hanging source fails over quickly
+// indexFetchTimeout 限制每次尝试的时长，使无响应的来源能快速切换
+const indexFetchTimeout = 15 * time.Second
+
+// IndexFetchAttempt records one attempt to fetch the index from a source
+// IndexFetchAttempt 记录从某个来源获取索引的一次尝试
+type IndexFetchAttempt struct {
+	Source    string
+	LatencyMs int64
+	Error     string
+}
+
+// IndexFetchInfo describes where the last index came from and how long it took
+// IndexFetchInfo 描述上一次索引的来源及耗时
+type IndexFetchInfo struct {
+	Source    string
+	LatencyMs int64
+	FetchedAt time.Time
+	Attempts  []IndexFetchAttempt
+}
+
+// distSources returns the official dist URL followed by the configured mirrors, without duplicates
+// distSources 返回官方地址及其后配置的镜像地址（已去重）
+func (a *App) distSources() []string {
+	sources := []string{officialDistURL}
+	seen := map[string]bool{officialDistURL: true}
+	for _, mirror := range a.currentSettings().Mirrors {
+		mirror = strings.TrimRight(strings.TrimSpace(mirror), "/")
+		if mirror == "" || seen[mirror] {
+			continue
+		}
+		seen[mirror] = true
+		sources = append(sources, mirror)
+	}
+	return sources
+}
+
+// indexCacheTTL is how long a fetched index is reused before the sources are asked again
+// indexCacheTTL 是获取到的索引在重新请求来源前被复用的时长
+const indexCacheTTL = 10 * time.Minute
+
+// indexEntry is the compact form of one index.json release, keeping only the fields the app uses
+// indexEntry 是 index.json 中一个发布版本的精简形式，只保留应用使用的字段
+type indexEntry struct {
+	Version string // 不带 v 前缀 / without the v prefix
+	Date    string
+	Npm     string
+	V8      string
+	OpenSSL string
+	LTS     string // LTS 代号，非 LTS 为空 / LTS codename, empty when not LTS
+	ARM64   bool   // 是否提供 win-arm64 构建 / Whether a win-arm64 build exists
+}
+
+// indexCache keeps the last fetched index so repeated refreshes do not download and decode it again
+// indexCache 保存最近获取的索引，避免重复刷新时再次下载和解析
+type indexCache struct {
+	mu      sync.Mutex
+	entries []indexEntry
+	info    IndexFetchInfo
+}
+
+// decodeIndex stream-decodes index.json one release at a time into compact entries,
+// reducing the per-release file lists to the flags the app needs
+// decodeIndex 逐个发布版本流式解析 index.json 为精简条目，每个版本的文件列表只保留应用需要的标记
+func decodeIndex(r io.Reader) ([]indexEntry, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("expected a JSON array")
+	}
+	var entries []indexEntry
+	for dec.More() {
+		var release struct {
+			Version string      `json:"version"`
+			Date    string      `json:"date"`
+			Npm     string      `json:"npm"`
+			V8      string      `json:"v8"`
+			OpenSSL string      `json:"openssl"`
+			LTS     interface{} `json:"lts"` // false 或 LTS 代号 / false or the LTS codename
+			Files   []string    `json:"files"`
+		}
+		if err := dec.Decode(&release); err != nil {
+			return nil, err
+		}
+		lts, _ := release.LTS.(string)
+		arm64 := false
+		for _, file := range release.Files {
+			if file == winARM64File {
+				arm64 = true
+				break
+			}
+		}
+		entries = append(entries, indexEntry{
+			Version: strings.TrimPrefix(release.Version, "v"),
+			Date:    release.Date,
+			Npm:     release.Npm,
+			V8:      release.V8,
+			OpenSSL: release.OpenSSL,
+			LTS:     lts,
+			ARM64:   arm64,
+		})
+	}
+	return entries, nil
+}
+
+// fetchIndexFrom downloads and decodes index.json from one source
+// fetchIndexFrom 从单个来源下载并解析 index.json
+func (a *App) fetchIndexFrom(client *http.Client, source string) ([]indexEntry, error) {
+	resp, err := client.Get(source + "/index.json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	nodeVersions, err := decodeIndex(&countingReader{r: resp.Body, metrics: a.metrics, host: resp.Request.URL.Host})
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing JSON response: %v", err)
+	}
+	return nodeVersions, nil
+}
+
+// fetchDistIndex tries the official index and then each mirror in order until one succeeds
+// fetchDistIndex 依次尝试官方索引和各个镜像，直到有一个成功
+func (a *App) fetchDistIndex() ([]indexEntry, IndexFetchInfo, error) {
+	info := IndexFetchInfo{}
+	if a.safeMode {
+		return nil, info, fmt.Errorf("networking disabled in safe mode")
+	}
+
+	a.indexCache.mu.Lock()
+	defer a.indexCache.mu.Unlock()
+	if a.indexCache.entries != nil && a.clock.Now().Sub(a.indexCache.info.FetchedAt) < indexCacheTTL {
+		return a.indexCache.entries, a.indexCache.info, nil
+	}
+
+	client := a.client(indexFetchTimeout)
+	for _, source := range a.distSources() {
+		start := a.clock.Now()
+		nodeVersions, err := a.fetchIndexFrom(client, source)
+		latency := a.clock.Now().Sub(start).Milliseconds()
+
+		attempt := IndexFetchAttempt{Source: source, LatencyMs: latency}
+		if err != nil {
+			a.metrics.recordError()
+			attempt.Error = err.Error()
+			info.Attempts = append(info.Attempts, attempt)
+			a.logToFile(fmt.Sprintf("Failed to fetch index from %s after %dms: %v", source, latency, err))
+			continue
+		}
+
+		info.Attempts = append(info.Attempts, attempt)
+		info.Source = source
+		info.LatencyMs = latency
+		info.FetchedAt = a.clock.Now()
+		a.setLastIndexFetch(info)
+		a.logToFile(fmt.Sprintf("Fetched index from %s in %dms", source, latency))
+		a.indexCache.entries = nodeVersions
+		a.indexCache.info = info
+		a.submitTask("index-snapshot", TaskPriorityBackground, func() { a.saveIndexSnapshot(nodeVersions) })
+		return nodeVersions, info, nil
+	}
+
+	a.setLastIndexFetch(info)
+	return nil, info, fmt.Errorf("all %d index sources failed", len(info.Attempts))
+}
+
+// setLastIndexFetch stores the outcome of the latest index fetch
+// setLastIndexFetch 保存最近一次索引获取的结果
+func (a *App) setLastIndexFetch(info IndexFetchInfo) {
+	a.indexMu.Lock()
+	a.lastIndexFetch = info
+	a.indexMu.Unlock()
+}
+
+// GetLastIndexFetch returns the source, latency and attempts of the latest index fetch
+// GetLastIndexFetch 返回最近一次索引获取的来源、耗时和尝试记录
+func (a *App) GetLastIndexFetch() IndexFetchInfo {
+	a.indexMu.Lock()
+	defer a.indexMu.Unlock()
+	return a.lastIndexFetch
+}
+
+// invalidateDistIndex drops the cached index so the next call fetches it from the current sources
+// invalidateDistIndex 清除缓存的索引，使下次调用从当前来源重新获取
+func (a *App) invalidateDistIndex() {
+	a.indexCache.mu.Lock()
+	a.indexCache.entries = nil
+	a.indexCache.mu.Unlock()
+}
+
+// RefreshAvailableVersions drops the cached index and version list and fetches them again
+// RefreshAvailableVersions 清除缓存的索引和版本列表并重新获取
+func (a *App) RefreshAvailableVersions() ([]NodeVersionInfo, error) {
+	a.invalidateDistIndex()
+	a.invalidateAvailableVersions()
+	return a.cachedAvailableVersions()
+}
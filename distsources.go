@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DistSource is an additional version index merged into the available versions,
+// such as unofficial-builds.nodejs.org or the legacy io.js releases
+// DistSource 表示合并到可用版本列表中的额外版本索引，
+// 例如 unofficial-builds.nodejs.org 或旧版 io.js 发布
+type DistSource struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+}
+
+// defaultDistSources returns the well-known extra sources, disabled until the user opts in
+// defaultDistSources 返回常用的额外来源，默认禁用，需用户手动开启
+func defaultDistSources() []DistSource {
+	return []DistSource{
+		{Name: "unofficial-builds", URL: "https://unofficial-builds.nodejs.org/download/release", Enabled: false},
+		{Name: "iojs", URL: "https://iojs.org/dist", Enabled: false},
+	}
+}
+
+// extraSourceVersions fetches every enabled extra source and returns its versions labelled with the source name
+// extraSourceVersions 获取所有已启用的额外来源，并返回以来源名称标记的版本列表
+func (a *App) extraSourceVersions(installedMap map[string]bool) []NodeVersionInfo {
+	if a.safeMode {
+		return nil
+	}
+
+	client := a.client(indexFetchTimeout)
+	f := a.formatter()
+	var versions []NodeVersionInfo
+	for _, source := range a.currentSettings().DistSources {
+		url := strings.TrimRight(strings.TrimSpace(source.URL), "/")
+		if !source.Enabled || url == "" {
+			continue
+		}
+
+		nodeVersions, err := a.fetchIndexFrom(client, url)
+		if err != nil {
+			a.metrics.recordError()
+			a.logToFile(fmt.Sprintf("Failed to fetch index from extra source %s: %v", source.Name, err))
+			continue
+		}
+
+		for _, versionInfo := range nodeVersions {
+			cleanVersion := strings.TrimPrefix(versionInfo.Version, "v")
+			status := "Not Installed"
+			if installedMap[cleanVersion] {
+				status = "Installed"
+			}
+			versions = append(versions, NodeVersionInfo{
+				Version:    cleanVersion,
+				Status:     status,
+				NpmVersion: versionInfo.Npm,
+				Source:     source.Name,
+				Date:       versionInfo.Date,
+				DateText:   f.dateString(versionInfo.Date),
+				V8:         versionInfo.V8,
+				OpenSSL:    versionInfo.OpenSSL,
+				Corepack:   corepackBundled(cleanVersion),
+				Npx:        npxBundled(versionInfo.Npm),
+			})
+		}
+		a.logToFile(fmt.Sprintf("Found %d versions from extra source %s", len(nodeVersions), source.Name))
+	}
+	return versions
+}
+
+// mergeDistSources adds the versions only offered by extra sources to the list, newest first
+// mergeDistSources 将仅由额外来源提供的版本合并到列表中，最新的在前
+func (a *App) mergeDistSources(versions []NodeVersionInfo, installedMap map[string]bool) []NodeVersionInfo {
+	extra := a.extraSourceVersions(installedMap)
+	if len(extra) == 0 {
+		return versions
+	}
+
+	seen := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		seen[v.Version] = true
+	}
+	for _, v := range extra {
+		if seen[v.Version] {
+			continue
+		}
+		seen[v.Version] = true
+		versions = append(versions, v)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareSemver(versions[i].Version, versions[j].Version) > 0
+	})
+	return versions
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Downloader defaults and the ranges accepted in the settings
+// 下载器默认值及设置中允许的范围
+const (
+	defaultDownloadConnections = 4
+	defaultSegmentSizeMB       = 4
+	defaultMaxDownloadJobs     = 2
+
+	maxDownloadConnections = 16
+	maxSegmentSizeMB       = 64
+	maxDownloadJobs        = 8
+
+	segmentAttempts = 3
+)
+
+// DownloadConfig tunes the downloader for very fast or very flaky networks. Connections and segments apply to
+// the files the app downloads itself, such as updates; nvm downloads Node.js archives on its own
+// DownloadConfig 针对高速或不稳定的网络调整下载器。连接数和分段只作用于应用自身下载的文件（例如更新），
+// Node.js 安装包由 nvm 自行下载
+type DownloadConfig struct {
+	Connections       int `json:"connections"`       // 每个文件的并发连接数，不含 nvm 的下载 / parallel connections per file, not used by nvm
+	SegmentSizeMB     int `json:"segmentSizeMB"`     // 每个分段的大小，不含 nvm 的下载 / size of each segment, not used by nvm
+	MaxConcurrentJobs int `json:"maxConcurrentJobs"` // 同时进行的安装和下载任务数 / installs and downloads running at once
+}
+
+// defaultDownloadConfig returns the downloader defaults
+// defaultDownloadConfig 返回下载器的默认配置
+func defaultDownloadConfig() DownloadConfig {
+	return DownloadConfig{
+		Connections:       defaultDownloadConnections,
+		SegmentSizeMB:     defaultSegmentSizeMB,
+		MaxConcurrentJobs: defaultMaxDownloadJobs,
+	}
+}
+
+// validate rejects values outside the supported ranges; zero selects the default
+// validate 拒绝超出支持范围的值，为 0 时使用默认值
+func (c DownloadConfig) validate() error {
+	switch {
+	case c.Connections < 0 || c.Connections > maxDownloadConnections:
+		return fmt.Errorf("下载连接数必须在 1 到 %d 之间 / Connections must be between 1 and %d", maxDownloadConnections, maxDownloadConnections)
+	case c.SegmentSizeMB < 0 || c.SegmentSizeMB > maxSegmentSizeMB:
+		return fmt.Errorf("分段大小必须在 1 到 %d MB 之间 / Segment size must be between 1 and %d MB", maxSegmentSizeMB, maxSegmentSizeMB)
+	case c.MaxConcurrentJobs < 0 || c.MaxConcurrentJobs > maxDownloadJobs:
+		return fmt.Errorf("并发任务数必须在 1 到 %d 之间 / Concurrent jobs must be between 1 and %d", maxDownloadJobs, maxDownloadJobs)
+	}
+	return nil
+}
+
+// downloadConfig returns the effective downloader settings, with zero values replaced by the defaults
+// downloadConfig 返回生效的下载器设置，为 0 的值使用默认值
+func (a *App) downloadConfig() DownloadConfig {
+	cfg := a.currentSettings().Downloads
+	defaults := defaultDownloadConfig()
+	if cfg.Connections <= 0 {
+		cfg.Connections = defaults.Connections
+	}
+	if cfg.SegmentSizeMB <= 0 {
+		cfg.SegmentSizeMB = defaults.SegmentSizeMB
+	}
+	if cfg.MaxConcurrentJobs <= 0 {
+		cfg.MaxConcurrentJobs = defaults.MaxConcurrentJobs
+	}
+	return cfg
+}
+
+// downloadSlots limits the installs and large downloads running at once
+// downloadSlots 限制同时进行的安装和大文件下载数量
+type downloadSlots struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	running int
+}
+
+// acquireDownloadSlot waits until fewer than the configured number of jobs run and returns the release function
+// acquireDownloadSlot 等待正在运行的任务少于配置的数量，并返回释放函数
+func (a *App) acquireDownloadSlot() func() {
+	s := &a.downloadSlots
+	s.mu.Lock()
+	if s.cond == nil {
+		s.cond = sync.NewCond(&s.mu)
+	}
+	// 每次重新读取设置，使修改立即生效
+	// Read the setting every time so changes apply immediately
+	for s.running >= a.downloadConfig().MaxConcurrentJobs {
+		s.cond.Wait()
+	}
+	s.running++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.running--
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		})
+	}
+}
+
+// downloadSegmented fetches a file of known size in segments over several connections, retrying failed
+// segments. Servers without range support get a single plain download
+// downloadSegmented 通过多个连接分段下载已知大小的文件，并重试失败的分段。服务器不支持 Range 时使用普通下载
+func (a *App) downloadSegmented(url string, size int64) ([]byte, error) {
+	cfg := a.downloadConfig()
+	segment := int64(cfg.SegmentSizeMB) << 20
+	if a.safeMode || size <= segment || cfg.Connections <= 1 {
+		return a.download(url)
+	}
+
+	client := a.client(2 * time.Minute)
+	data := make([]byte, size)
+	starts := make(chan int64)
+	// 第一个失败的分段结束整个分段下载：停止分发剩余分段，已启动的连接随之退出
+	// The first failing segment ends the segmented download: the remaining segments are no longer handed
+	// out and the connections return
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := start + segment - 1
+				if end >= size {
+					end = size - 1
+				}
+				var chunk []byte
+				var err error
+				for attempt := 0; attempt < segmentAttempts; attempt++ {
+					if chunk, err = fetchRange(client, a.metrics, url, start, end); err == nil {
+						break
+					}
+				}
+				if err != nil {
+					failOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+					return
+				}
+				copy(data[start:], chunk)
+			}
+		}()
+	}
+feed:
+	for start := int64(0); start < size; start += segment {
+		select {
+		case starts <- start:
+		case <-failed:
+			break feed
+		}
+	}
+	close(starts)
+	wg.Wait()
+
+	if firstErr != nil {
+		a.logToFile(fmt.Sprintf("Segmented download of %s failed, downloading in one piece: %v", url, firstErr))
+		return a.download(url)
+	}
+	return data, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// rangeFailingTransport serves whole files but fails every range request
+// rangeFailingTransport 返回完整文件，但所有 Range 请求都失败
+type rangeFailingTransport struct {
+	body []byte
+}
+
+// RoundTrip implements http.RoundTripper
+// RoundTrip 实现 http.RoundTripper 接口
+func (t rangeFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, t.body
+	if req.Header.Get("Range") != "" {
+		status, body = http.StatusInternalServerError, nil
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestDownloadSegmentedFallsBackWhenEverySegmentFails(t *testing.T) {
+	a := newTestApp(t, &FakeExecutor{})
+	settings := a.currentSettings()
+	settings.Downloads = DownloadConfig{Connections: 2, SegmentSizeMB: 1}
+	a.SetSettings(settings)
+	body := bytes.Repeat([]byte("n"), 10<<20)
+	a.httpClient = &http.Client{Transport: rangeFailingTransport{body: body}}
+
+	// 失败的分段多于连接数时也不能卡住
+	// More failing segments than connections must not hang
+	done := make(chan error, 1)
+	go func() {
+		data, err := a.downloadSegmented("https://example.test/update.exe", int64(len(body)))
+		if err == nil && !bytes.Equal(data, body) {
+			t.Errorf("fallback download returned %d bytes, want %d", len(data), len(body))
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("downloadSegmented: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("downloadSegmented did not return after the segments failed")
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Operations that require administrator rights
+// 需要管理员权限的操作
+const (
+	ElevatedPathRepair        = "path-repair"
+	ElevatedDefenderExclusion = "defender-exclusion"
+	ElevatedNvmUpgrade        = "nvm-upgrade"
+)
+
+// elevationPlanTTL is how long a plan may wait for confirmation
+// elevationPlanTTL 是计划等待确认的有效期
+const elevationPlanTTL = 5 * time.Minute
+
+const machineEnvironmentKey = `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+
+// ElevationPlan lists exactly what an elevated operation will run and touch
+// ElevationPlan 列出提权操作将要执行的命令和修改的内容
+type ElevationPlan struct {
+	ID           string
+	Operation    string
+	Title        string
+	Description  string
+	Commands     []string
+	RegistryKeys []string
+	ExpiresAt    time.Time
+
+	// 生成计划时读取的系统 PATH，执行前用于检测其间的修改
+	// System PATH read when planning, used to detect changes made before the plan runs
+	originalPath string
+}
+
+// psQuote quotes a string as a PowerShell single-quoted literal
+// psQuote 将字符串转换为 PowerShell 单引号字面量
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// newPlanID returns a random identifier for an elevation plan
+// newPlanID 为提权计划生成随机标识
+func newPlanID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// buildElevationPlan computes the commands and registry keys for an operation
+// buildElevationPlan 计算操作所需的命令和注册表项
+func buildElevationPlan(operation string) (ElevationPlan, error) {
+	nvmHome := nvmEnvPath("NVM_HOME")
+	nvmSymlink := nvmEnvPath("NVM_SYMLINK")
+	plan := ElevationPlan{Operation: operation}
+
+	switch operation {
+	case ElevatedPathRepair:
+		current, err := readMachinePath()
+		if err != nil {
+			return plan, fmt.Errorf("Error reading system PATH: %v", err)
+		}
+		newPath, added := repairedPath(current, nvmHome, nvmSymlink)
+		if len(added) == 0 {
+			return plan, fmt.Errorf("The system PATH already contains the nvm directories")
+		}
+		plan.Title = "修复 PATH / Repair PATH"
+		plan.Description = fmt.Sprintf("Append %s to the system PATH", strings.Join(added, ", "))
+		plan.originalPath = current
+		// 提权脚本在写入前再次比较 PATH，覆盖 UAC 提示期间发生的修改
+		// The elevated script compares PATH again before writing, covering changes made during the UAC prompt
+		plan.Commands = []string{
+			fmt.Sprintf("if ((Get-Item -LiteralPath %s).GetValue('Path', '', 'DoNotExpandEnvironmentNames') -cne %s) { throw 'The system PATH changed since the plan was made' }",
+				psQuote(`HKLM:\`+machineEnvironmentKey), psQuote(current)),
+			fmt.Sprintf("Set-ItemProperty -LiteralPath %s -Name 'Path' -Type ExpandString -Value %s",
+				psQuote(`HKLM:\`+machineEnvironmentKey), psQuote(newPath)),
+		}
+		plan.RegistryKeys = []string{`HKEY_LOCAL_MACHINE\` + machineEnvironmentKey + `\Path`}
+
+	case ElevatedDefenderExclusion:
+		var paths []string
+		for _, p := range []string{nvmHome, nvmSymlink} {
+			if p != "" {
+				paths = append(paths, psQuote(p))
+			}
+		}
+		if len(paths) == 0 {
+			return plan, fmt.Errorf("NVM_HOME and NVM_SYMLINK are not set")
+		}
+		plan.Title = "添加 Defender 排除项 / Add Defender exclusion"
+		plan.Description = "Exclude the nvm directories from Microsoft Defender real-time scanning to speed up installs"
+		plan.Commands = []string{"Add-MpPreference -ExclusionPath " + strings.Join(paths, ",")}
+		plan.RegistryKeys = []string{`HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows Defender\Exclusions\Paths`}
+
+	case ElevatedNvmUpgrade:
+		if nvmHome == "" {
+			return plan, fmt.Errorf("NVM_HOME is not set")
+		}
+		plan.Title = "升级 nvm / Upgrade nvm"
+		plan.Description = "Upgrade nvm-windows in place using its built-in updater"
+		plan.Commands = []string{fmt.Sprintf("& %s upgrade", psQuote(filepath.Join(nvmHome, "nvm.exe")))}
+		plan.RegistryKeys = []string{`HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\NVM for Windows_is1`}
+
+	default:
+		return plan, fmt.Errorf("Unknown elevated operation: %s", operation)
+	}
+	return plan, nil
+}
+
+// repairedPath appends the nvm directories missing from path and returns the new value and the added entries
+// repairedPath 将缺失的 nvm 目录追加到 path 中，返回新值和追加的条目
+func repairedPath(path, nvmHome, nvmSymlink string) (string, []string) {
+	present := make(map[string]bool)
+	for _, entry := range strings.Split(path, ";") {
+		present[strings.ToLower(strings.TrimRight(strings.TrimSpace(entry), `\`))] = true
+	}
+
+	var added []string
+	for _, want := range []struct{ variable, value string }{
+		{"%NVM_HOME%", nvmHome},
+		{"%NVM_SYMLINK%", nvmSymlink},
+	} {
+		if present[strings.ToLower(want.variable)] || (want.value != "" && present[strings.ToLower(strings.TrimRight(want.value, `\`))]) {
+			continue
+		}
+		added = append(added, want.variable)
+	}
+
+	newPath := strings.TrimRight(path, ";")
+	for _, entry := range added {
+		newPath += ";" + entry
+	}
+	return newPath, added
+}
+
+// PlanElevatedOperation returns the exact commands and registry keys an elevated operation would touch
+// The plan must be confirmed with ConfirmElevatedOperation before anything runs
+// PlanElevatedOperation 返回提权操作将执行的命令和修改的注册表项
+// 必须调用 ConfirmElevatedOperation 确认后才会真正执行
+func (a *App) PlanElevatedOperation(operation string) (ElevationPlan, error) {
+	plan, err := buildElevationPlan(operation)
+	if err != nil {
+		a.audit("elevation-plan", operation, "rejected: "+err.Error())
+		return plan, err
+	}
+	plan.ID = newPlanID()
+	plan.ExpiresAt = a.clock.Now().Add(elevationPlanTTL)
+
+	a.plansMu.Lock()
+	a.pendingPlans[plan.ID] = plan
+	a.plansMu.Unlock()
+
+	a.audit("elevation-plan", fmt.Sprintf("%s [%s]: %s", operation, plan.ID, strings.Join(plan.Commands, "; ")), "pending")
+	return plan, nil
+}
+
+// CancelElevatedOperation discards a pending plan
+// CancelElevatedOperation 丢弃一个待确认的计划
+func (a *App) CancelElevatedOperation(planID string) {
+	a.plansMu.Lock()
+	plan, ok := a.pendingPlans[planID]
+	delete(a.pendingPlans, planID)
+	a.plansMu.Unlock()
+
+	if ok {
+		a.audit("elevation-cancel", fmt.Sprintf("%s [%s]", plan.Operation, planID), "cancelled")
+	}
+}
+
+// ConfirmElevatedOperation runs a previously planned operation with administrator rights
+// ConfirmElevatedOperation 以管理员权限执行之前生成的计划
+func (a *App) ConfirmElevatedOperation(planID string) (string, error) {
+	a.plansMu.Lock()
+	plan, ok := a.pendingPlans[planID]
+	delete(a.pendingPlans, planID)
+	a.plansMu.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("Unknown or already used elevation plan: %s", planID)
+	}
+	if a.clock.Now().After(plan.ExpiresAt) {
+		a.audit("elevation-confirm", fmt.Sprintf("%s [%s]", plan.Operation, planID), "expired")
+		return "", fmt.Errorf("The elevation plan has expired, please review it again")
+	}
+
+	if plan.Operation == ElevatedPathRepair {
+		// 计划生成后 PATH 被修改时拒绝执行，避免用旧值覆盖其他程序的修改
+		// Refuse when PATH changed after planning, so the stale value does not overwrite another program's edit
+		current, err := readMachinePath()
+		if err != nil {
+			return "", fmt.Errorf("Error reading system PATH: %v", err)
+		}
+		if current != plan.originalPath {
+			a.audit("elevation-confirm", fmt.Sprintf("%s [%s]", plan.Operation, planID), "PATH changed")
+			return "", fmt.Errorf("The system PATH changed since the plan was made, please review it again")
+		}
+	}
+
+	a.audit("elevation-confirm", fmt.Sprintf("%s [%s]", plan.Operation, planID), "confirmed")
+	if err := a.runElevatedPowerShell(plan.Commands); err != nil {
+		a.audit("elevation-run", fmt.Sprintf("%s [%s]", plan.Operation, planID), "failed: "+err.Error())
+		a.logToFile(fmt.Sprintf("Elevated operation %s failed: %v", plan.Operation, err))
+		return "", fmt.Errorf("Error running %s: %v", plan.Title, err)
+	}
+
+	a.audit("elevation-run", fmt.Sprintf("%s [%s]", plan.Operation, planID), "succeeded")
+	if plan.Operation == ElevatedPathRepair {
+		// 提权进程修改的是注册表，需要通知正在运行的程序重新读取环境变量
+		// The elevated process only changed the registry, so running programs are told to reload the environment
+		broadcastEnvironmentChange()
+	}
+	successMsg := fmt.Sprintf("Successfully completed: %s", plan.Title)
+	a.logToFile(successMsg)
+	return successMsg, nil
+}
+
+// runElevatedPowerShell runs the commands in an elevated PowerShell (UAC prompt) and waits for it
+// runElevatedPowerShell 在提权的 PowerShell 中执行命令（会弹出 UAC 提示）并等待完成
+func (a *App) runElevatedPowerShell(commands []string) error {
+	script := "$ErrorActionPreference = 'Stop'\n" + strings.Join(commands, "\n")
+
+	// -EncodedCommand takes base64 of UTF-16LE and avoids every quoting problem
+	// -EncodedCommand 接收 UTF-16LE 的 base64 编码，避免所有引号问题
+	units := utf16.Encode([]rune(script))
+	raw := make([]byte, len(units)*2)
+	for i, u := range units {
+		raw[i*2] = byte(u)
+		raw[i*2+1] = byte(u >> 8)
+	}
+	encoded := base64.StdEncoding.EncodeToString(raw)
+
+	launcher := fmt.Sprintf("$p = Start-Process -FilePath powershell.exe -Verb RunAs -Wait -PassThru -WindowStyle Hidden "+
+		"-ArgumentList '-NoProfile','-NonInteractive','-EncodedCommand','%s'; exit $p.ExitCode", encoded)
+	output, err := a.executor.CombinedOutput(CommandSpec{Name: "powershell.exe", Args: []string{"-NoProfile", "-NonInteractive", "-Command", launcher}, Hidden: true})
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(decodeConsoleOutput(output))))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// readMachinePath returns the unexpanded system PATH from the registry
+// readMachinePath 从注册表读取未展开的系统 PATH
+func readMachinePath() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, machineEnvironmentKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue("Path")
+	return value, err
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Where a tool checked against the target version was found
+// 用于检查目标版本的工具的来源
+const (
+	EngineSourceNpm      = "npm"      // 目标版本自带的 npm / npm bundled with the target version
+	EngineSourceGlobal   = "global"   // 全局安装的包 / globally installed package
+	EngineSourceCorepack = "corepack" // corepack 缓存的包管理器 / package manager cached by corepack
+)
+
+// EngineWarning is a tool on this machine whose engines field does not accept the target version
+// EngineWarning 表示本机上 engines 字段不接受目标版本的工具
+type EngineWarning struct {
+	Tool        string
+	ToolVersion string
+	Requires    string
+	Source      string
+}
+
+// packageEngines reads the name, version and engines.node of a package directory
+// packageEngines 读取包目录的名称、版本及 engines.node
+func packageEngines(dir string) (name, version, node string) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", "", ""
+	}
+	var pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return "", "", ""
+	}
+	return pkg.Name, pkg.Version, pkg.Engines.Node
+}
+
+// partialVersion parses a possibly partial version such as "18", "18.12" or "18.x" into its numbers and
+// how many of them were given
+// partialVersion 将 "18"、"18.12" 或 "18.x" 等可能不完整的版本解析为各数字及给出的位数
+func partialVersion(s string) ([3]int, int, bool) {
+	var parts [3]int
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	given := 0
+	for i, field := range strings.Split(s, ".") {
+		if i >= 3 {
+			break
+		}
+		if field == "x" || field == "X" || field == "*" || field == "" {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, 0, false
+		}
+		parts[i] = n
+		given++
+	}
+	return parts, given, true
+}
+
+// compareParts compares two parsed versions
+// compareParts 比较两个已解析的版本
+func compareParts(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// satisfiesComparator checks one comparator of a range, such as ">=18.12", "^16.13.0", "~14.17" or "20.x"
+// satisfiesComparator 检查范围中的单个比较条件，例如 ">=18.12"、"^16.13.0"、"~14.17" 或 "20.x"
+func satisfiesComparator(v [3]int, comparator string) bool {
+	op := strings.TrimRight(comparator, "0123456789.xX*vV")
+	if len(op) > 2 {
+		return true
+	}
+	bound, given, ok := partialVersion(comparator[len(op):])
+	if !ok {
+		return true
+	}
+	// upper 是部分版本或 ^/~ 所允许的上限（不含）
+	// upper is the exclusive limit allowed by a partial version or by ^/~
+	upper := func(level int) [3]int {
+		next := bound
+		if level < 0 {
+			return [3]int{1 << 30}
+		}
+		next[level]++
+		for i := level + 1; i < 3; i++ {
+			next[i] = 0
+		}
+		return next
+	}
+	switch op {
+	case ">=":
+		return compareParts(v, bound) >= 0
+	case ">":
+		if given < 3 {
+			return compareParts(v, upper(given-1)) >= 0
+		}
+		return compareParts(v, bound) > 0
+	case "<=":
+		if given < 3 {
+			return compareParts(v, upper(given-1)) < 0
+		}
+		return compareParts(v, bound) <= 0
+	case "<":
+		return compareParts(v, bound) < 0
+	case "^":
+		level := 0
+		if bound[0] == 0 && given > 1 {
+			level = 1
+		}
+		return compareParts(v, bound) >= 0 && compareParts(v, upper(level)) < 0
+	case "~":
+		level := 1
+		if given < 2 {
+			level = 0
+		}
+		return compareParts(v, bound) >= 0 && compareParts(v, upper(level)) < 0
+	case "", "=":
+		if given == 0 {
+			return true
+		}
+		return compareParts(v, bound) >= 0 && compareParts(v, upper(given-1)) < 0
+	}
+	return true
+}
+
+// satisfiesRange reports whether a version is accepted by an engines range. Ranges that cannot be parsed
+// are treated as accepted so they never produce a false warning
+// satisfiesRange 判断版本是否满足 engines 范围。无法解析的范围视为满足，以免产生误报
+func satisfiesRange(version, rng string) bool {
+	v, given, ok := partialVersion(version)
+	if !ok || given == 0 {
+		return true
+	}
+	for _, set := range strings.Split(rng, "||") {
+		set = strings.TrimSpace(set)
+		if set == "" || set == "*" {
+			return true
+		}
+		// "14 - 18" 这样的连字符范围
+		// Hyphen ranges such as "14 - 18"
+		if parts := strings.Split(set, " - "); len(parts) == 2 {
+			set = ">=" + strings.TrimSpace(parts[0]) + " <=" + strings.TrimSpace(parts[1])
+		}
+		// 去掉比较符与版本之间的空格，例如 ">= 18"
+		// Drop the spaces between an operator and its version, e.g. ">= 18"
+		for _, op := range []string{">=", "<=", ">", "<", "^", "~", "="} {
+			set = strings.ReplaceAll(set, op+" ", op)
+		}
+		matched := true
+		for _, comparator := range strings.Fields(set) {
+			if !satisfiesComparator(v, comparator) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// corepackHome returns the directory where corepack caches the package managers it downloaded
+// corepackHome 返回 corepack 缓存已下载包管理器的目录
+func corepackHome() string {
+	if home := os.Getenv("COREPACK_HOME"); home != "" {
+		return home
+	}
+	return filepath.Join(os.Getenv("LOCALAPPDATA"), "node", "corepack")
+}
+
+// corepackToolDirs returns the newest cached version directory of each package manager corepack downloaded
+// corepackToolDirs 返回 corepack 已下载的每个包管理器最新缓存版本的目录
+func corepackToolDirs() map[string]string {
+	newest := map[string]string{}
+	versions := map[string]string{}
+	home := corepackHome()
+	// 新版 corepack 将缓存放在 v1 子目录中
+	// Newer corepack releases keep the cache in a v1 subdirectory
+	for _, base := range []string{home, filepath.Join(home, "v1")} {
+		for _, name := range []string{"npm", "pnpm", "yarn"} {
+			entries, _ := os.ReadDir(filepath.Join(base, name))
+			for _, entry := range entries {
+				if !entry.IsDir() || compareSemver(entry.Name(), versions[name]) <= 0 {
+					continue
+				}
+				versions[name] = entry.Name()
+				newest[name] = filepath.Join(base, name, entry.Name())
+			}
+		}
+	}
+	return newest
+}
+
+// GetEngineWarnings lists the tools on this machine whose engines field rejects version: the npm bundled with
+// it, the global packages of it and of the active version, and the package managers cached by corepack
+// GetEngineWarnings 列出本机上 engines 字段不接受该版本的工具：其自带的 npm、它及当前版本的全局包，
+// 以及 corepack 缓存的包管理器
+func (a *App) GetEngineWarnings(version string) ([]EngineWarning, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	root := a.nvmRoot()
+	if root == "" {
+		return nil, fmt.Errorf("nvm root not found")
+	}
+
+	var warnings []EngineWarning
+	seen := map[string]bool{}
+	check := func(dir, source string) {
+		name, toolVersion, node := packageEngines(dir)
+		if name == "" || node == "" || seen[name+"@"+toolVersion] || satisfiesRange(version, node) {
+			return
+		}
+		seen[name+"@"+toolVersion] = true
+		warnings = append(warnings, EngineWarning{Tool: name, ToolVersion: toolVersion, Requires: node, Source: source})
+	}
+
+	targetDir := versionDir(root, version)
+	check(filepath.Join(targetDir, "node_modules", "npm"), EngineSourceNpm)
+	dirs := []string{targetDir}
+	if current, err := a.currentNodeVersion(); err == nil && current != "" {
+		dirs = append(dirs, versionDir(root, strings.TrimPrefix(current, "v")))
+	}
+	for _, dir := range dirs {
+		for _, pkgDir := range globalPackageDirs(dir) {
+			check(pkgDir, EngineSourceGlobal)
+		}
+	}
+	for _, dir := range corepackToolDirs() {
+		check(dir, EngineSourceCorepack)
+	}
+
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Tool < warnings[j].Tool })
+	return warnings, nil
+}
+
+// reportEngineWarnings tells the frontend after a switch which tools will refuse to run on the new version
+// reportEngineWarnings 在切换后告知前端哪些工具将拒绝在新版本上运行
+func (a *App) reportEngineWarnings(version string) {
+	warnings, err := a.GetEngineWarnings(version)
+	if err != nil || len(warnings) == 0 {
+		return
+	}
+	for _, w := range warnings {
+		a.logToFile(fmt.Sprintf("%s %s requires Node.js %s, which Node.js %s does not satisfy", w.Tool, w.ToolVersion, w.Requires, version))
+	}
+	a.notify("engine-warnings", map[string]interface{}{"version": version, "warnings": warnings})
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		version, rng string
+		want         bool
+	}{
+		{"20.11.0", ">=18", true},
+		{"16.20.2", ">=18", false},
+		{"18.0.0", ">= 18.0.0", true},
+		{"20.11.0", "^18.17.0 || ^20.3.0", true},
+		{"19.9.0", "^18.17.0 || ^20.3.0", false},
+		{"18.19.1", "~18.17", false},
+		{"18.17.9", "~18.17", true},
+		{"16.0.0", "14 - 18", true},
+		{"19.0.0", "14 - 18", false},
+		{"20.1.0", "20", true},
+		{"20.1.0", "20.2", false},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"18.0.0", "<18", false},
+		{"18.99.0", "<=18", true},
+		{"20.11.0", "*", true},
+		{"20.11.0", "", true},
+		{"20.11.0", "not a range", true},
+	}
+	for _, tt := range tests {
+		if got := satisfiesRange(tt.version, tt.rng); got != tt.want {
+			t.Errorf("satisfiesRange(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry names inside an environment backup archive
+// 环境备份压缩包中的条目名称
+const (
+	backupManifestName = "manifest.json"
+	backupSettingsName = "settings.json"
+	backupVersionsDir  = "versions/"
+)
+
+// EnvironmentManifest describes what an environment backup contains
+// EnvironmentManifest 描述环境备份包含的内容
+type EnvironmentManifest struct {
+	CreatedAt      time.Time
+	AppVersion     string
+	DefaultVersion string
+	Versions       []string
+	Globals        map[string][]string
+	Labels         map[string][]string // 备份时各版本的自定义标签 / Custom labels of each version at backup time
+	Binaries       bool
+}
+
+// RestoreSummary reports what RestoreEnvironment did
+// RestoreSummary 报告 RestoreEnvironment 执行的操作
+type RestoreSummary struct {
+	DefaultVersion string
+	Extracted      []string
+	Installed      []string
+	Globals        int
+	Errors         []string
+}
+
+// addFileToZip stores one file in the archive under name
+// addFileToZip 将一个文件以 name 为名存入压缩包
+func addFileToZip(zw *zip.Writer, name, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// BackupEnvironment writes settings, pinned projects, installed versions, the default version and the
+// global packages of every version to a zip archive at target, optionally with the version binaries
+// BackupEnvironment 将设置、固定版本的项目、已安装版本、默认版本及每个版本的全局包写入 target 处的 zip 压缩包，
+// 可选地包含各版本的二进制文件
+func (a *App) BackupEnvironment(target string, includeBinaries bool) (EnvironmentManifest, error) {
+	manifest := EnvironmentManifest{
+		CreatedAt:  a.clock.Now(),
+		AppVersion: appVersion,
+		Globals:    map[string][]string{},
+		Labels:     map[string][]string{},
+		Binaries:   includeBinaries,
+	}
+	root := a.nvmRoot()
+	if root == "" {
+		return manifest, fmt.Errorf("nvm root not found")
+	}
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return manifest, err
+	}
+	labels, err := a.loadLabels()
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Ignoring labels file: %v", err))
+	}
+	locale := a.formatter().locale
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		manifest.Versions = append(manifest.Versions, version)
+		if v.IsCurrent {
+			manifest.DefaultVersion = version
+		}
+		var specs []string
+		for spec := range globalPackageDirs(versionDir(root, version)) {
+			specs = append(specs, spec)
+		}
+		sort.Strings(specs)
+		manifest.Globals[version] = specs
+		for _, label := range labels.labelsFor(version, locale) {
+			manifest.Labels[version] = append(manifest.Labels[version], label.Text)
+		}
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return manifest, fmt.Errorf("Error creating backup archive: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	if _, err := os.Stat(a.settingsPath); err == nil {
+		if err := addFileToZip(zw, backupSettingsName, a.settingsPath); err != nil {
+			return manifest, fmt.Errorf("Error adding settings: %v", err)
+		}
+	}
+
+	if includeBinaries {
+		for _, version := range manifest.Versions {
+			dir := longPath(versionDir(root, version))
+			err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				return addFileToZip(zw, backupVersionsDir+"v"+version+"/"+filepath.ToSlash(rel), path)
+			})
+			if err != nil {
+				return manifest, fmt.Errorf("Error adding Node.js %s: %v", version, err)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	w, err := zw.Create(backupManifestName)
+	if err != nil {
+		return manifest, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return manifest, err
+	}
+	if err := zw.Close(); err != nil {
+		return manifest, fmt.Errorf("Error writing backup archive: %v", err)
+	}
+
+	a.audit("backup-environment", target, "success")
+	a.logToFile(fmt.Sprintf("Backed up %d versions to %s", len(manifest.Versions), target))
+	return manifest, nil
+}
+
+// extractZipEntry writes one archive entry below dir, refusing names that escape it
+// extractZipEntry 将压缩包中的一个条目写入 dir 下，拒绝逃逸出该目录的名称
+func extractZipEntry(file *zip.File, dir, name string) error {
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid entry %s", file.Name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// RestoreEnvironment re-establishes an environment from a BackupEnvironment archive: it restores the settings,
+// extracts or re-installs every version, reinstalls the global packages and switches to the default version
+// RestoreEnvironment 从 BackupEnvironment 生成的压缩包恢复环境：恢复设置，解压或重新安装各版本，
+// 重新安装全局包并切换到默认版本
+func (a *App) RestoreEnvironment(archive string) (RestoreSummary, error) {
+	var summary RestoreSummary
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return summary, fmt.Errorf("Error opening backup archive: %v", err)
+	}
+	defer zr.Close()
+
+	var manifest EnvironmentManifest
+	var settingsData []byte
+	for _, file := range zr.File {
+		if file.Name != backupManifestName && file.Name != backupSettingsName {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return summary, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return summary, err
+		}
+		if file.Name == backupManifestName {
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return summary, fmt.Errorf("Error parsing backup manifest: %v", err)
+			}
+		} else {
+			settingsData = data
+		}
+	}
+	if manifest.CreatedAt.IsZero() {
+		return summary, fmt.Errorf("%s is not an environment backup", archive)
+	}
+
+	if settingsData != nil {
+		settings, err := parseSettings(settingsData)
+		if err == nil {
+			err = a.SetSettings(settings)
+		}
+		if err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("settings: %v", err))
+		}
+	}
+
+	root := a.nvmRoot()
+	if root == "" {
+		return summary, fmt.Errorf("nvm root not found")
+	}
+
+	// 先解压备份中的二进制文件，避免重新下载
+	// Extract the backed up binaries first so they need not be downloaded again
+	existing := map[string]bool{}
+	for _, version := range manifest.Versions {
+		if _, err := os.Stat(filepath.Join(versionDir(root, version), "node.exe")); err == nil {
+			existing[version] = true
+		}
+	}
+	extracted := map[string]bool{}
+	for _, file := range zr.File {
+		if !strings.HasPrefix(file.Name, backupVersionsDir) || file.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(file.Name, backupVersionsDir)
+		version := strings.TrimPrefix(strings.SplitN(name, "/", 2)[0], "v")
+		if existing[version] {
+			continue
+		}
+		if err := extractZipEntry(file, root, name); err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", version, err))
+			continue
+		}
+		extracted[version] = true
+	}
+
+	for _, version := range manifest.Versions {
+		switch {
+		case existing[version]:
+		case extracted[version]:
+			summary.Extracted = append(summary.Extracted, version)
+		default:
+			if diagnoseVersionDir(versionDir(root, version)) != "" {
+				a.installNodeVersionWithinCap(version)
+			}
+			if reason := diagnoseVersionDir(versionDir(root, version)); reason != "" {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %s", version, reason))
+				continue
+			}
+			summary.Installed = append(summary.Installed, version)
+		}
+
+		// 已带二进制文件的版本已包含全局包
+		// Versions restored from binaries already contain their globals
+		if extracted[version] || len(manifest.Globals[version]) == 0 {
+			continue
+		}
+		missing := []string{}
+		present := globalPackageDirs(versionDir(root, version))
+		for _, spec := range manifest.Globals[version] {
+			if _, ok := present[spec]; !ok {
+				missing = append(missing, spec)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		args := append([]string{"install", "-g"}, missing...)
+		if _, err := a.runWithNodeVersion(version, versionDir(root, version), "npm.cmd", args...); err != nil {
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s globals: %v", version, err))
+			continue
+		}
+		summary.Globals += len(missing)
+	}
+
+	if manifest.DefaultVersion != "" {
+		summary.DefaultVersion = manifest.DefaultVersion
+		a.switchNodeVersion(manifest.DefaultVersion)
+	}
+
+	outcome := "success"
+	if len(summary.Errors) > 0 {
+		outcome = "partial"
+	}
+	a.audit("restore-environment", archive, outcome)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("project-shims", TaskPriorityBackground, a.refreshProjectShims)
+	return summary, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// eventStreamKeepAlive is how often an idle event stream sends a comment to keep the connection open
+// eventStreamKeepAlive 是空闲事件流发送注释以保持连接的间隔
+const eventStreamKeepAlive = 30 * time.Second
+
+// eventSubscriberBuffer is the number of events queued per subscriber before new ones are dropped
+// eventSubscriberBuffer 是每个订阅者在丢弃新事件前可排队的事件数量
+const eventSubscriberBuffer = 16
+
+// eventHub fans backend events out to the local API event stream subscribers
+// eventHub 将后端事件分发给本地接口事件流的订阅者
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan WebhookEvent][]string
+}
+
+// subscribe registers a subscriber for the given events, or all events when none are given
+// subscribe 为指定事件注册订阅者，未指定时订阅所有事件
+func (h *eventHub) subscribe(events []string) chan WebhookEvent {
+	ch := make(chan WebhookEvent, eventSubscriberBuffer)
+	h.mu.Lock()
+	if h.subscribers == nil {
+		h.subscribers = map[chan WebhookEvent][]string{}
+	}
+	h.subscribers[ch] = events
+	h.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes a subscriber
+// unsubscribe 移除订阅者
+func (h *eventHub) unsubscribe(ch chan WebhookEvent) {
+	h.mu.Lock()
+	delete(h.subscribers, ch)
+	h.mu.Unlock()
+}
+
+// publish delivers an event to every interested subscriber without blocking on slow ones
+// publish 将事件发送给所有感兴趣的订阅者，不会因较慢的订阅者而阻塞
+func (h *eventHub) publish(event WebhookEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch, events := range h.subscribers {
+		if !(WebhookConfig{Events: events}).subscribes(event.Event) {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// serveEventStream streams backend events as server-sent events; ?events=switch,install narrows the stream
+// serveEventStream 以 SSE 形式推送后端事件，?events=switch,install 可筛选事件
+func (a *App) serveEventStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var events []string
+	for _, e := range strings.Split(r.URL.Query().Get("events"), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	ch := a.eventHub.subscribe(events)
+	defer a.eventHub.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(eventStreamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event := <-ch:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeResponse is the canned result of one command for FakeExecutor
+// FakeResponse 是 FakeExecutor 中某条命令的预设结果
+type FakeResponse struct {
+	Output string
+	Err    error
+}
+
+// FakeExecutor replays canned output keyed by "name arg1 arg2" and records every call
+// FakeExecutor 按 "name arg1 arg2" 回放预设输出，并记录每次调用
+type FakeExecutor struct {
+	mu        sync.Mutex
+	Responses map[string]FakeResponse
+	Calls     []CommandSpec
+}
+
+// CombinedOutput implements Executor
+// CombinedOutput 实现 Executor 接口
+func (f *FakeExecutor) CombinedOutput(spec CommandSpec) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, spec)
+	key := strings.TrimSpace(spec.Name + " " + strings.Join(spec.Args, " "))
+	response, ok := f.Responses[key]
+	if !ok {
+		return nil, fmt.Errorf("fake executor: no response for %q", key)
+	}
+	return []byte(response.Output), response.Err
+}
+
+// Run implements Executor, writing the canned output to stdout
+// Run 实现 Executor 接口，将预设输出写入标准输出
+func (f *FakeExecutor) Run(_ context.Context, spec CommandSpec, stdout, _ io.Writer) error {
+	output, err := f.CombinedOutput(spec)
+	stdout.Write(output)
+	return err
+}
+
+// Start implements Executor, only recording the call
+// Start 实现 Executor 接口，只记录调用
+func (f *FakeExecutor) Start(spec CommandSpec) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, spec)
+	return nil
+}
+
+// FakeClock returns a fixed time that can be advanced
+// FakeClock 返回一个可以向前推进的固定时间
+type FakeClock struct {
+	mu sync.Mutex
+	T  time.Time
+}
+
+// Now implements Clock
+// Now 实现 Clock 接口
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.T
+}
+
+// Advance moves the clock forward
+// Advance 将时钟向前推进
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.T = c.T.Add(d)
+	c.mu.Unlock()
+}
+
+// newTestApp returns an App running commands with executor, keeping its settings in memory and never reaching
+// the network
+// newTestApp 返回一个使用 executor 运行命令的 App，其设置只保存在内存中，且不会访问网络
+func newTestApp(t *testing.T, executor Executor) *App {
+	t.Helper()
+	return newAppWithDependencies(Dependencies{
+		Executor:   executor,
+		Clock:      &FakeClock{T: time.Date(2024, 11, 20, 9, 0, 0, 0, time.UTC)},
+		FS:         &mockFS{},
+		HTTPClient: &http.Client{Timeout: time.Second, Transport: mockTransport{}},
+	})
+}
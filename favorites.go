@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ToggleFavorite stars or unstars a version, installed or not, and reports whether it is now a favorite
+// ToggleFavorite 收藏或取消收藏一个版本（无论是否已安装），并返回该版本当前是否为收藏
+func (a *App) ToggleFavorite(version string) (bool, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	starred := true
+	err := a.updateSettings(func(settings *Settings) {
+		var favorites []string
+		for _, v := range settings.Favorites {
+			if v == version {
+				starred = false
+				continue
+			}
+			favorites = append(favorites, v)
+		}
+		if starred {
+			favorites = append(favorites, version)
+		}
+		settings.Favorites = favorites
+	})
+	if err != nil {
+		return !starred, err
+	}
+	// 缓存的版本列表中带有收藏标记
+	// The cached version list carries the favorite marks
+	a.invalidateAvailableVersions()
+	go a.refreshTrayVersions()
+	return starred, nil
+}
+
+// GetFavorites returns the starred versions
+// GetFavorites 返回已收藏的版本
+func (a *App) GetFavorites() []string {
+	return a.currentSettings().Favorites
+}
+
+// favoriteSet returns the starred versions as a set
+// favoriteSet 以集合形式返回已收藏的版本
+func (a *App) favoriteSet() map[string]bool {
+	set := map[string]bool{}
+	for _, v := range a.currentSettings().Favorites {
+		set[v] = true
+	}
+	return set
+}
+
+// withFavorites marks the starred versions of a listing and moves them to the top, keeping the order otherwise
+// withFavorites 标记列表中已收藏的版本并将其移到最前，其余顺序保持不变
+func (a *App) withFavorites(versions []NodeVersionInfo) []NodeVersionInfo {
+	favorites := a.favoriteSet()
+	for i := range versions {
+		versions[i].Favorite = favorites[strings.TrimPrefix(versions[i].Version, "v")]
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Favorite && !versions[j].Favorite })
+	return versions
+}
+
+// installedWithFavorites marks the starred installed versions and moves them to the top
+// installedWithFavorites 标记已收藏的已安装版本并将其移到最前
+func (a *App) installedWithFavorites(versions []NodeVersion) []NodeVersion {
+	favorites := a.favoriteSet()
+	for i := range versions {
+		versions[i].Favorite = favorites[strings.TrimPrefix(versions[i].Version, "v")]
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Favorite && !versions[j].Favorite })
+	return versions
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Feature support statuses
+// 特性支持状态
+const (
+	FeatureSupported    = "supported"
+	FeatureExperimental = "experimental"
+	FeatureFlagged      = "flagged"
+	FeatureUnsupported  = "unsupported"
+)
+
+// nodeFeature describes when a runtime feature appeared behind a flag, as experimental and as stable
+// nodeFeature 描述一个运行时特性何时以参数形式出现、何时为实验性、何时稳定
+type nodeFeature struct {
+	id           string
+	name         string
+	category     string
+	flaggedSince string // 需要 flag 参数才能使用 / usable behind flag
+	flag         string
+	experimental string // 无需参数但仍为实验性 / unflagged but experimental
+	stable       string
+}
+
+// nodeFeatures is the bundled feature table, ordered for display
+// nodeFeatures 是内置的特性表，按显示顺序排列
+var nodeFeatures = []nodeFeature{
+	{id: "esm", name: "ES modules", category: "modules", flaggedSince: "8.5.0", flag: "--experimental-modules", experimental: "12.17.0", stable: "15.3.0"},
+	{id: "top-level-await", name: "Top-level await (ESM)", category: "modules", flaggedSince: "14.3.0", flag: "--experimental-top-level-await", stable: "14.8.0"},
+	{id: "node-protocol", name: "node: import specifiers", category: "modules", stable: "14.18.0"},
+	{id: "require-esm", name: "require() of ES modules", category: "modules", flaggedSince: "22.0.0", flag: "--experimental-require-module", experimental: "22.12.0", stable: "25.4.0"},
+	{id: "detect-module", name: "Module syntax detection", category: "modules", flaggedSince: "21.1.0", flag: "--experimental-detect-module", stable: "22.7.0"},
+	{id: "import-meta-dirname", name: "import.meta.dirname / filename", category: "modules", experimental: "20.11.0", stable: "24.0.0"},
+	{id: "fetch", name: "Global fetch", category: "web", flaggedSince: "17.5.0", flag: "--experimental-fetch", experimental: "18.0.0", stable: "21.0.0"},
+	{id: "websocket", name: "Global WebSocket", category: "web", flaggedSince: "21.0.0", flag: "--experimental-websocket", stable: "22.4.0"},
+	{id: "web-streams", name: "Global Web Streams", category: "web", experimental: "18.0.0", stable: "21.0.0"},
+	{id: "abort-controller", name: "Global AbortController", category: "web", stable: "15.4.0"},
+	{id: "structured-clone", name: "structuredClone()", category: "web", stable: "17.0.0"},
+	{id: "webcrypto", name: "Global crypto (Web Crypto)", category: "web", flaggedSince: "17.6.0", flag: "--experimental-global-webcrypto", stable: "19.0.0"},
+	{id: "test-runner", name: "node:test runner", category: "tooling", experimental: "18.0.0", stable: "20.0.0"},
+	{id: "watch-mode", name: "--watch mode", category: "tooling", experimental: "18.11.0", stable: "22.0.0"},
+	{id: "env-file", name: "--env-file", category: "tooling", experimental: "20.6.0", stable: "24.10.0"},
+	{id: "sea", name: "Single executable applications", category: "tooling", experimental: "19.7.0"},
+	{id: "strip-types", name: "TypeScript type stripping", category: "tooling", flaggedSince: "22.6.0", flag: "--experimental-strip-types", experimental: "23.6.0", stable: "25.2.0"},
+	{id: "permission-model", name: "Permission model", category: "security", flaggedSince: "20.0.0", flag: "--experimental-permission", stable: "23.5.0"},
+	{id: "openssl3", name: "OpenSSL 3", category: "security", stable: "17.0.0"},
+}
+
+// FeatureSupport is the support status of one feature in one version
+// FeatureSupport 表示某个特性在某个版本中的支持状态
+type FeatureSupport struct {
+	Version string
+	Status  string
+	Note    string
+}
+
+// FeatureRow is one feature across all requested versions
+// FeatureRow 表示一个特性在所有请求版本中的支持情况
+type FeatureRow struct {
+	ID       string
+	Name     string
+	Category string
+	Support  []FeatureSupport
+}
+
+// FeatureMatrix is the feature comparison table for a set of versions
+// FeatureMatrix 表示一组版本的特性对比表
+type FeatureMatrix struct {
+	Versions []string
+	Features []FeatureRow
+}
+
+// featureStatus returns the support status of a feature in a version
+// featureStatus 返回特性在指定版本中的支持状态
+func featureStatus(f nodeFeature, version string) FeatureSupport {
+	support := FeatureSupport{Version: version, Status: FeatureUnsupported}
+	switch {
+	case f.stable != "" && compareSemver(version, f.stable) >= 0:
+		support.Status = FeatureSupported
+	case f.experimental != "" && compareSemver(version, f.experimental) >= 0:
+		support.Status = FeatureExperimental
+		if f.stable != "" {
+			support.Note = fmt.Sprintf("stable since %s", f.stable)
+		}
+	case f.flaggedSince != "" && compareSemver(version, f.flaggedSince) >= 0:
+		support.Status = FeatureFlagged
+		support.Note = f.flag
+	default:
+		first := f.flaggedSince
+		if first == "" {
+			first = f.experimental
+		}
+		if first == "" {
+			first = f.stable
+		}
+		support.Note = fmt.Sprintf("requires %s or later", first)
+	}
+	return support
+}
+
+// GetFeatureMatrix compares ESM/CJS and runtime feature support across the given versions
+// When versions is empty the installed versions are used
+// GetFeatureMatrix 比较给定版本之间的 ESM/CJS 及运行时特性支持情况
+// versions 为空时使用已安装的版本
+func (a *App) GetFeatureMatrix(versions []string) (FeatureMatrix, error) {
+	if len(versions) == 0 {
+		installed, err := a.GetInstalledNodeVersions()
+		if err != nil {
+			return FeatureMatrix{}, err
+		}
+		for _, v := range installed {
+			versions = append(versions, v.Version)
+		}
+	}
+
+	matrix := FeatureMatrix{}
+	for _, v := range versions {
+		matrix.Versions = append(matrix.Versions, strings.TrimPrefix(strings.TrimSpace(v), "v"))
+	}
+
+	for _, f := range nodeFeatures {
+		row := FeatureRow{ID: f.id, Name: f.name, Category: f.category}
+		for _, v := range matrix.Versions {
+			row.Support = append(row.Support, featureStatus(f, v))
+		}
+		matrix.Features = append(matrix.Features, row)
+	}
+	return matrix, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// NvmrcFile is a .nvmrc opened from Explorer with the version it resolves to
+// NvmrcFile 是从资源管理器打开的 .nvmrc 文件及其解析得到的版本
+type NvmrcFile struct {
+	Path      string
+	Requested string // 文件中写的版本 / Version written in the file
+	Resolved  string // 匹配的具体版本，无法解析时为空 / Matching concrete version, empty when it cannot be resolved
+	Installed bool
+	IsCurrent bool
+}
+
+// nvmrcOpenCommand is the command Explorer runs to open a .nvmrc with this executable
+// nvmrcOpenCommand 是资源管理器使用本程序打开 .nvmrc 时运行的命令
+func nvmrcOpenCommand() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`"%s" %s "%%1"`, exePath, argOpenNvmrc), nil
+}
+
+// RegisterNvmrcAssociation makes double-clicking a .nvmrc open it in this app, for the current user only
+// RegisterNvmrcAssociation 使双击 .nvmrc 时用本应用打开，仅对当前用户生效
+func (a *App) RegisterNvmrcAssociation() error {
+	command, err := nvmrcOpenCommand()
+	if err != nil {
+		return err
+	}
+	if err := writeNvmrcAssociation(command); err != nil {
+		a.audit("nvmrc-association", "register", "failed")
+		return fmt.Errorf("Error registering .nvmrc association: %v", err)
+	}
+	a.audit("nvmrc-association", "register", "success")
+	return nil
+}
+
+// UnregisterNvmrcAssociation removes the .nvmrc association
+// UnregisterNvmrcAssociation 删除 .nvmrc 文件关联
+func (a *App) UnregisterNvmrcAssociation() error {
+	if err := deleteNvmrcAssociation(); err != nil {
+		a.audit("nvmrc-association", "unregister", "failed")
+		return fmt.Errorf("Error removing .nvmrc association: %v", err)
+	}
+	a.audit("nvmrc-association", "unregister", "success")
+	return nil
+}
+
+// IsNvmrcAssociated reports whether .nvmrc files open with this executable
+// IsNvmrcAssociated 判断 .nvmrc 文件是否使用本程序打开
+func (a *App) IsNvmrcAssociated() bool {
+	command, err := nvmrcOpenCommand()
+	return err == nil && strings.EqualFold(readNvmrcAssociation(), command)
+}
+
+// ResolveNvmrc reads a .nvmrc and resolves it to an installed version, or else the newest available match
+// ResolveNvmrc 读取 .nvmrc 并解析为已安装版本，没有时解析为最新的可用匹配版本
+func (a *App) ResolveNvmrc(path string) (NvmrcFile, error) {
+	file := NvmrcFile{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, fmt.Errorf("Error reading %s: %v", path, err)
+	}
+	file.Requested = strings.TrimPrefix(strings.TrimSpace(string(data)), "v")
+	if file.Requested == "" {
+		return file, fmt.Errorf("%s does not name a version", path)
+	}
+
+	project := Project{Name: filepath.Base(filepath.Dir(path)), Path: filepath.Dir(path), PinnedVersion: file.Requested}
+	if version, err := a.resolveProjectVersion(project); err == nil {
+		file.Resolved = version
+		file.Installed = true
+		current, _ := a.currentNodeVersion()
+		file.IsCurrent = current == version
+		return file, nil
+	}
+
+	available, err := a.cachedAvailableVersions()
+	if err != nil {
+		return file, nil
+	}
+	for _, v := range available {
+		if (v.Version == file.Requested || strings.HasPrefix(v.Version, file.Requested+".")) &&
+			(file.Resolved == "" || compareSemver(v.Version, file.Resolved) > 0) {
+			file.Resolved = v.Version
+		}
+	}
+	return file, nil
+}
+
+// openNvmrc shows the window with the version a .nvmrc asks for, so the user can install or switch to it
+// openNvmrc 显示窗口并展示 .nvmrc 要求的版本，供用户安装或切换
+func (a *App) openNvmrc(path string) {
+	a.showWindow()
+	file, err := a.ResolveNvmrc(path)
+	if err != nil {
+		a.logToFile(err.Error())
+		return
+	}
+	a.logToFile(fmt.Sprintf("Opened %s: %s resolves to %q", path, file.Requested, file.Resolved))
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "nvmrc-opened", file)
+	}
+}
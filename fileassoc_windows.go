@@ -0,0 +1,66 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+var procSHChangeNotify = modshell32.NewProc("SHChangeNotify")
+
+// shcneAssocChanged tells Explorer that a file association changed
+// shcneAssocChanged 通知资源管理器文件关联已更改
+const shcneAssocChanged = 0x08000000
+
+// Per-user registry keys of the .nvmrc association
+// .nvmrc 关联在当前用户注册表中的键
+const (
+	nvmrcExtKey     = `Software\Classes\.nvmrc`
+	nvmrcProgID     = "NodeVersionSwitcher.nvmrc"
+	nvmrcProgIDKey  = `Software\Classes\` + nvmrcProgID
+	nvmrcCommandKey = nvmrcProgIDKey + `\shell\open\command`
+)
+
+// writeNvmrcAssociation registers command as the per-user handler of .nvmrc files
+// writeNvmrcAssociation 将 command 注册为当前用户 .nvmrc 文件的打开方式
+func writeNvmrcAssociation(command string) error {
+	for _, entry := range []struct{ path, value string }{
+		{nvmrcExtKey, nvmrcProgID},
+		{nvmrcProgIDKey, "Node.js version file"},
+		{nvmrcCommandKey, command},
+	} {
+		key, _, err := registry.CreateKey(registry.CURRENT_USER, entry.path, registry.SET_VALUE)
+		if err != nil {
+			return err
+		}
+		err = key.SetStringValue("", entry.value)
+		key.Close()
+		if err != nil {
+			return err
+		}
+	}
+	procSHChangeNotify.Call(shcneAssocChanged, 0, 0, 0)
+	return nil
+}
+
+// readNvmrcAssociation returns the registered .nvmrc open command, or an empty string
+// readNvmrcAssociation 返回已注册的 .nvmrc 打开命令，未注册时返回空字符串
+func readNvmrcAssociation() string {
+	key, err := registry.OpenKey(registry.CURRENT_USER, nvmrcCommandKey, registry.QUERY_VALUE)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+	value, _, _ := key.GetStringValue("")
+	return value
+}
+
+// deleteNvmrcAssociation removes the per-user .nvmrc association created by writeNvmrcAssociation
+// deleteNvmrcAssociation 删除 writeNvmrcAssociation 创建的当前用户 .nvmrc 关联
+func deleteNvmrcAssociation() error {
+	for _, path := range []string{nvmrcCommandKey, nvmrcProgIDKey + `\shell\open`, nvmrcProgIDKey + `\shell`, nvmrcProgIDKey, nvmrcExtKey} {
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && err != registry.ErrNotExist {
+			return err
+		}
+	}
+	procSHChangeNotify.Call(shcneAssocChanged, 0, 0, 0)
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// focusModePollInterval is how often the Windows notification state is checked
+// focusModePollInterval 是检查 Windows 通知状态的间隔
+const focusModePollInterval = 15 * time.Second
+
+// maxDeferredNotifications caps the notifications held back during focus mode
+// maxDeferredNotifications 限制专注模式期间暂缓的通知数量
+const maxDeferredNotifications = 50
+
+// FocusModeState describes whether notifications are currently held back
+// FocusModeState 描述当前是否正在暂缓通知
+type FocusModeState struct {
+	Active   bool
+	Ignored  bool
+	Deferred int
+}
+
+// deferredNotification is a frontend event held back until focus mode ends
+// deferredNotification 是在专注模式结束前暂缓发送的前端事件
+type deferredNotification struct {
+	event string
+	data  interface{}
+}
+
+// focusMode tracks focus mode and the notifications deferred during it
+// focusMode 跟踪专注模式以及期间暂缓的通知
+type focusMode struct {
+	mu       sync.Mutex
+	active   bool
+	deferred []deferredNotification
+}
+
+// inFocusMode reports whether non-critical notifications should wait, honoring the settings override. Safe mode
+// does not watch focus mode, so nothing waits there for a delivery that would never come
+// inFocusMode 判断非关键通知是否需要等待，并遵循设置中的覆盖选项。安全模式不监视专注模式，因此不会等待永远不会到来的发送
+func (a *App) inFocusMode() bool {
+	return !a.safeMode && !a.currentSettings().IgnoreFocusMode && userDoNotDisturb()
+}
+
+// notify emits a non-critical event to the frontend, or holds it back while the user presents or uses
+// Focus Assist; a newer event of the same name replaces the deferred one
+// notify 向前端发送非关键事件，用户演示或开启专注助手时暂缓发送；同名的新事件会替换已暂缓的事件
+func (a *App) notify(event string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	if !a.inFocusMode() {
+		runtime.EventsEmit(a.ctx, event, data)
+		return
+	}
+
+	f := &a.focus
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.deferred {
+		if f.deferred[i].event == event {
+			f.deferred[i].data = data
+			return
+		}
+	}
+	if len(f.deferred) < maxDeferredNotifications {
+		f.deferred = append(f.deferred, deferredNotification{event: event, data: data})
+	}
+}
+
+// flushDeferredNotifications emits the notifications held back during focus mode
+// flushDeferredNotifications 发送专注模式期间暂缓的通知
+func (a *App) flushDeferredNotifications() {
+	f := &a.focus
+	f.mu.Lock()
+	deferred := f.deferred
+	f.deferred = nil
+	f.mu.Unlock()
+
+	if len(deferred) > 0 {
+		a.logToFile(fmt.Sprintf("Focus mode ended, delivering %d deferred notifications", len(deferred)))
+	}
+	for _, n := range deferred {
+		runtime.EventsEmit(a.ctx, n.event, n.data)
+	}
+}
+
+// watchFocusMode emits "focus-mode-changed" on transitions and delivers deferred notifications once focus mode ends
+// watchFocusMode 在专注模式切换时发送 "focus-mode-changed" 事件，并在专注模式结束后发送暂缓的通知
+func (a *App) watchFocusMode() {
+	ticker := time.NewTicker(focusModePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			active := a.inFocusMode()
+			f := &a.focus
+			f.mu.Lock()
+			changed := active != f.active
+			f.active = active
+			f.mu.Unlock()
+			if !changed {
+				continue
+			}
+			runtime.EventsEmit(a.ctx, "focus-mode-changed", a.GetFocusMode())
+			if !active {
+				a.flushDeferredNotifications()
+			}
+		}
+	}
+}
+
+// GetFocusMode returns whether notifications are being held back and how many are waiting
+// GetFocusMode 返回当前是否正在暂缓通知以及等待发送的数量
+func (a *App) GetFocusMode() FocusModeState {
+	f := &a.focus
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return FocusModeState{
+		Active:   f.active,
+		Ignored:  a.currentSettings().IgnoreFocusMode,
+		Deferred: len(f.deferred),
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modshell32                       = syscall.NewLazyDLL("shell32.dll")
+	procSHQueryUserNotificationState = modshell32.NewProc("SHQueryUserNotificationState")
+)
+
+// QUERY_USER_NOTIFICATION_STATE values meaning the user should not be disturbed
+// 表示不应打扰用户的 QUERY_USER_NOTIFICATION_STATE 取值
+const (
+	qunsBusy                 = 2 // 全屏应用 / full-screen application
+	qunsRunningD3DFullScreen = 3
+	qunsPresentationMode     = 4
+	qunsQuietTime            = 6 // 专注助手或首次登录后的安静时间 / Focus Assist or the quiet time after first sign-in
+	qunsApp                  = 7 // 全屏的应用商店应用 / full-screen Store app
+)
+
+// userDoNotDisturb reports whether Windows says the user is presenting, in a full-screen app or in quiet time
+// userDoNotDisturb 判断 Windows 是否报告用户正在演示、使用全屏应用或处于安静时间
+func userDoNotDisturb() bool {
+	var state int32
+	if ret, _, _ := procSHQueryUserNotificationState.Call(uintptr(unsafe.Pointer(&state))); ret != 0 {
+		return false
+	}
+	switch state {
+	case qunsBusy, qunsRunningD3DFullScreen, qunsPresentationMode, qunsQuietTime, qunsApp:
+		return true
+	}
+	return false
+}
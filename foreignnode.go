@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sources of Node.js installations not managed by nvm
+// 非 nvm 管理的 Node.js 安装来源
+const (
+	ForeignScoop     = "scoop"
+	ForeignChoco     = "chocolatey"
+	ForeignInstaller = "installer"
+	ForeignPath      = "path"
+)
+
+// ForeignNodeInstall is a Node.js installation found outside the nvm root
+// ForeignNodeInstall 表示在 nvm 根目录之外发现的 Node.js 安装
+type ForeignNodeInstall struct {
+	Source      string
+	Path        string
+	Version     string
+	OnPath      bool
+	ShadowsNvm  bool
+	Imported    bool
+	RemovalHint string
+}
+
+// EnvironmentReport summarizes the nvm setup and anything conflicting with it
+// EnvironmentReport 汇总 nvm 的配置情况及与其冲突的内容
+type EnvironmentReport struct {
+	NvmHome    string
+	NvmSymlink string
+	NvmRoot    string
+	NvmExe     string // 实际运行的 nvm / The nvm that is actually run
+	Foreign    []ForeignNodeInstall
+	Warnings   []string
+}
+
+// removalHints tells the user how to remove an installation from each source
+// removalHints 告诉用户如何移除各来源的安装
+var removalHints = map[string]string{
+	ForeignScoop:     "scoop uninstall nodejs",
+	ForeignChoco:     "choco uninstall nodejs",
+	ForeignInstaller: "winget uninstall OpenJS.NodeJS (或在“应用和功能”中卸载 / or uninstall it from Apps & features)",
+	ForeignPath:      "从 PATH 中移除该目录 / Remove the directory from PATH",
+}
+
+// samePath compares two Windows paths case-insensitively, ignoring trailing separators
+// samePath 忽略大小写和末尾分隔符比较两个 Windows 路径
+func samePath(a, b string) bool {
+	clean := func(p string) string {
+		return strings.ToLower(strings.TrimRight(filepath.Clean(os.ExpandEnv(strings.TrimSpace(p))), `\/`))
+	}
+	return a != "" && b != "" && clean(a) == clean(b)
+}
+
+// nodeExeVersion returns the version printed by node.exe in dir
+// nodeExeVersion 返回 dir 中 node.exe 输出的版本号
+func (a *App) nodeExeVersion(dir string) string {
+	output, err := a.runHidden(filepath.Join(dir, "node.exe"), "--version")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(output), "v")
+}
+
+// scoopNodeApps are the scoop apps that install Node.js
+// scoopNodeApps 是安装 Node.js 的 scoop 应用
+var scoopNodeApps = []string{"nodejs", "nodejs-lts"}
+
+// scoopRoot returns the scoop installation directory
+// scoopRoot 返回 scoop 的安装目录
+func scoopRoot() string {
+	if scoop := os.Getenv("SCOOP"); scoop != "" {
+		return scoop
+	}
+	return filepath.Join(os.Getenv("USERPROFILE"), "scoop")
+}
+
+// scoopCurrentDirs maps the "current" junction scoop puts on PATH to the version directory it points to
+// scoopCurrentDirs 将 scoop 放入 PATH 的 "current" 目录联接映射到其指向的版本目录
+func scoopCurrentDirs() map[string]string {
+	dirs := map[string]string{}
+	for _, app := range scoopNodeApps {
+		current := filepath.Join(scoopRoot(), "apps", app, "current")
+		if target, err := filepath.EvalSymlinks(current); err == nil {
+			dirs[current] = target
+		}
+	}
+	return dirs
+}
+
+// foreignNodeDirs lists the directories where scoop, Chocolatey and the MSI installer (also used by winget) put node.exe
+// foreignNodeDirs 列出 scoop、Chocolatey 和 MSI 安装程序（winget 也使用它）存放 node.exe 的目录
+func foreignNodeDirs() map[string]string {
+	dirs := map[string]string{}
+
+	scoop := scoopRoot()
+	for _, app := range scoopNodeApps {
+		versions, _ := os.ReadDir(filepath.Join(scoop, "apps", app))
+		for _, v := range versions {
+			if v.IsDir() && v.Name() != "current" {
+				dirs[filepath.Join(scoop, "apps", app, v.Name())] = ForeignScoop
+			}
+		}
+	}
+
+	// Chocolatey 和 winget 都通过官方 MSI 安装到 Program Files\nodejs
+	// Chocolatey and winget both run the official MSI, which installs to Program Files\nodejs
+	source := ForeignInstaller
+	if choco := os.Getenv("ChocolateyInstall"); choco != "" {
+		for _, pkg := range []string{"nodejs", "nodejs.install", "nodejs-lts"} {
+			if _, err := os.Stat(filepath.Join(choco, "lib", pkg)); err == nil {
+				source = ForeignChoco
+				break
+			}
+		}
+	}
+	for _, programFiles := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
+		if programFiles != "" {
+			dirs[filepath.Join(programFiles, "nodejs")] = source
+		}
+	}
+	return dirs
+}
+
+// CheckEnvironment reports the nvm directories and any Node.js installed by scoop, Chocolatey, winget
+// or otherwise found on PATH, warning when one of them shadows the nvm-managed node
+// CheckEnvironment 报告 nvm 目录以及通过 scoop、Chocolatey、winget 安装或在 PATH 中发现的 Node.js，
+// 当其遮蔽 nvm 管理的 node 时给出警告
+func (a *App) CheckEnvironment() EnvironmentReport {
+	report := EnvironmentReport{
+		NvmHome:    nvmEnvPath("NVM_HOME"),
+		NvmSymlink: nvmEnvPath("NVM_SYMLINK"),
+		NvmRoot:    a.nvmRoot(),
+		NvmExe:     nvmExecutable(),
+	}
+	if report.NvmHome == "" {
+		report.Warnings = append(report.Warnings, "未设置 NVM_HOME / NVM_HOME is not set")
+	}
+	if report.NvmSymlink == "" {
+		report.Warnings = append(report.Warnings, "未设置 NVM_SYMLINK / NVM_SYMLINK is not set")
+	}
+	if ext := strings.ToLower(filepath.Ext(report.NvmExe)); ext == ".cmd" || ext == ".bat" {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("nvm 是脚本包装 %s，将通过 cmd 运行 / nvm is the script shim %s and runs through cmd", report.NvmExe, report.NvmExe))
+	}
+
+	// 记录每个 PATH 目录的位置，以判断是否排在 nvm 目录之前
+	// Record the position of each PATH entry to tell whether it comes before the nvm directory
+	pathIndex := map[string]int{}
+	symlinkIndex := -1
+	candidates := foreignNodeDirs()
+	scoopCurrent := scoopCurrentDirs()
+	for i, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// scoop 将 apps\nodejs\current 放入 PATH，它指向已列出的版本目录，不能再作为单独的 PATH 安装报告
+		// scoop puts apps\nodejs\current on PATH; it points at a version directory already listed and must not be
+		// reported again as a separate PATH install
+		for current, target := range scoopCurrent {
+			if samePath(entry, current) {
+				entry = target
+			}
+		}
+		if samePath(entry, report.NvmSymlink) {
+			symlinkIndex = i
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(entry, "node.exe")); err != nil {
+			continue
+		}
+		pathIndex[strings.ToLower(filepath.Clean(entry))] = i
+		known := false
+		for dir := range candidates {
+			if samePath(dir, entry) {
+				known = true
+			}
+		}
+		if !known && !strings.HasPrefix(strings.ToLower(filepath.Clean(entry)), strings.ToLower(filepath.Clean(report.NvmRoot))) {
+			candidates[entry] = ForeignPath
+		}
+	}
+
+	managed := map[string]bool{}
+	if installed, err := a.GetInstalledNodeVersions(); err == nil {
+		for _, v := range installed {
+			managed[strings.TrimPrefix(v.Version, "v")] = true
+		}
+	}
+
+	for dir, source := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, "node.exe")); err != nil {
+			continue
+		}
+		install := ForeignNodeInstall{
+			Source:      source,
+			Path:        dir,
+			Version:     a.nodeExeVersion(dir),
+			RemovalHint: removalHints[source],
+		}
+		install.Imported = managed[install.Version]
+		if index, ok := pathIndex[strings.ToLower(filepath.Clean(dir))]; ok {
+			install.OnPath = true
+			install.ShadowsNvm = symlinkIndex == -1 || index < symlinkIndex
+		}
+		if install.ShadowsNvm {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s 中的 Node.js %s 位于 PATH 中 nvm 之前，将覆盖 nvm 切换的版本 / Node.js %s in %s comes before nvm on PATH and overrides the version nvm switches to",
+				dir, install.Version, install.Version, dir))
+		}
+		report.Foreign = append(report.Foreign, install)
+	}
+
+	sort.Slice(report.Foreign, func(i, j int) bool {
+		return report.Foreign[i].Path < report.Foreign[j].Path
+	})
+	return report
+}
+
+// ImportForeignNode copies a Node.js installation found by CheckEnvironment into the nvm root
+// so nvm can switch to it
+// ImportForeignNode 将 CheckEnvironment 发现的 Node.js 安装复制到 nvm 根目录，以便 nvm 切换到该版本
+func (a *App) ImportForeignNode(path string) (string, error) {
+	version := a.nodeExeVersion(path)
+	if version == "" {
+		return "", fmt.Errorf("No working node.exe found in %s", path)
+	}
+	root := a.nvmRoot()
+	if root == "" {
+		return "", fmt.Errorf("nvm root not found")
+	}
+	target := versionDir(root, version)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("Node.js %s is already installed", version)
+	}
+
+	if err := copyTree(a.fs, path, target); err != nil {
+		os.RemoveAll(longPath(target))
+		a.audit("import-node", path, "failed")
+		return "", fmt.Errorf("Error copying %s: %v", path, err)
+	}
+	a.audit("import-node", fmt.Sprintf("%s -> %s", path, target), "success")
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	return fmt.Sprintf("Imported Node.js %s from %s", version, path), nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+// Supported locales
+// 支持的区域设置
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEnUS = "en-US"
+)
+
+// formatter formats dates, sizes and numbers for one locale
+// formatter 按某个区域设置格式化日期、大小和数字
+type formatter struct {
+	locale string
+}
+
+// resolveLocale maps a configured or system locale to a supported one
+// resolveLocale 将配置的或系统的区域设置映射为支持的区域设置
+func resolveLocale(locale string) string {
+	if locale == "" {
+		locale = systemLocale()
+	}
+	if strings.HasPrefix(strings.ToLower(locale), "zh") {
+		return LocaleZhCN
+	}
+	return LocaleEnUS
+}
+
+// formatter returns the formatter for the selected locale
+// formatter 返回所选区域设置的格式化器
+func (a *App) formatter() formatter {
+	return formatter{locale: resolveLocale(a.currentSettings().Locale)}
+}
+
+// date formats a time as a calendar date
+// date 将时间格式化为日历日期
+func (f formatter) date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if f.locale == LocaleZhCN {
+		return t.Format("2006年1月2日")
+	}
+	return t.Format("Jan 2, 2006")
+}
+
+// dateString formats a YYYY-MM-DD date as used by the Node.js index and schedule; other input is returned unchanged
+// dateString 格式化 Node.js 索引和发布计划使用的 YYYY-MM-DD 日期，其他格式原样返回
+func (f formatter) dateString(s string) string {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return s
+	}
+	return f.date(t)
+}
+
+// size formats a byte count with binary units
+// size 使用二进制单位格式化字节数
+func (f formatter) size(bytes int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	if f.locale == LocaleZhCN {
+		units = []string{"B", "KB", "MB", "GB", "TB"}
+	}
+	if bytes < 1024 {
+		return fmt.Sprintf("%d %s", bytes, units[0])
+	}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// GetLocale returns the locale used for formatted values
+// GetLocale 返回格式化数值使用的区域设置
+func (a *App) GetLocale() string {
+	return a.formatter().locale
+}
+
+// SetLocale selects zh-CN or en-US for formatted values; an empty locale follows Windows
+// SetLocale 为格式化数值选择 zh-CN 或 en-US，为空时跟随 Windows 设置
+func (a *App) SetLocale(locale string) error {
+	if locale != "" && locale != LocaleZhCN && locale != LocaleEnUS {
+		return fmt.Errorf("unsupported locale %q", locale)
+	}
+	settings := a.currentSettings()
+	settings.Locale = locale
+	if err := a.SetSettings(settings); err != nil {
+		return err
+	}
+	a.submitTask("tray-tooltip", TaskPriorityNormal, a.refreshTrayTooltip)
+	return nil
+}
+
+// FormatSize formats a byte count for the selected locale
+// FormatSize 按所选区域设置格式化字节数
+func (a *App) FormatSize(bytes int64) string {
+	return a.formatter().size(bytes)
+}
+
+// FormatDate formats a YYYY-MM-DD or RFC 3339 date for the selected locale
+// FormatDate 按所选区域设置格式化 YYYY-MM-DD 或 RFC 3339 日期
+func (a *App) FormatDate(date string) string {
+	f := a.formatter()
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return f.date(t.Local())
+	}
+	return f.dateString(date)
+}
+
+// trayTooltip describes the active version, the free space of the nvm root drive and the running installs
+// trayTooltip 描述当前版本、nvm 根目录所在磁盘的剩余空间以及正在进行的安装
+func (a *App) trayTooltip() string {
+	f := a.formatter()
+	tooltip := "nvm可视化"
+	if installed, err := a.GetInstalledNodeVersions(); err == nil {
+		for _, v := range installed {
+			if v.IsCurrent {
+				tooltip = "Node.js v" + strings.TrimPrefix(v.Version, "v")
+			}
+		}
+	}
+	if root := a.nvmRoot(); root != "" {
+		if free, _, err := diskFreeSpace(root); err == nil {
+			if f.locale == LocaleZhCN {
+				tooltip += fmt.Sprintf("\n%s 剩余 %s", filepath.VolumeName(root), f.size(int64(free)))
+			} else {
+				tooltip += fmt.Sprintf("\n%s free on %s", f.size(int64(free)), filepath.VolumeName(root))
+			}
+		}
+	}
+	for _, p := range a.activeInstallProgress() {
+		tooltip += "\n" + f.installProgressLine(p)
+	}
+	return tooltip
+}
+
+// refreshTrayTooltip updates the tray tooltip, e.g. after switching versions or locales
+// refreshTrayTooltip 更新托盘提示，例如在切换版本或区域设置之后
+func (a *App) refreshTrayTooltip() {
+	systray.SetTooltip(a.trayTooltip())
+}
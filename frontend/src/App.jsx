@@ -5,19 +5,11 @@ import {
     GetAvailableNodeVersions,
     GetInstalledNodeVersions,
     InstallNodeVersion,
-    UninstallNodeVersion // 引入卸载函数
+    UninstallNodeVersion, // 引入卸载函数
+    GetVersionDetails,
+    RunDefaultAction
 } from '../wailsjs/go/main/App';
-
-// 改进版 compareVersions，确保按主、次、小版本的降序排列
-const compareVersions = (a, b) => {
-    const parseVersion = (version) => version.split('.').map((num) => parseInt(num, 10));
-    const [aMajor, aMinor, aPatch] = parseVersion(a.Version);
-    const [bMajor, bMinor, bPatch] = parseVersion(b.Version);
-
-    if (aMajor !== bMajor) return bMajor - aMajor;
-    if (aMinor !== bMinor) return bMinor - aMinor;
-    return bPatch - aPatch;
-};
+import { EventsOn } from '../wailsjs/runtime/runtime';
 
 function App() {
     const [activeTab, setActiveTab] = useState('versions');
@@ -27,6 +19,7 @@ function App() {
     const [maxHeight, setMaxHeight] = useState(window.innerHeight - 220);
     const [loadingVersion, setLoadingVersion] = useState(null);
     const [loadingAction, setLoadingAction] = useState(''); // 可以是 'install', 'uninstall', 或 'switch'
+    const [details, setDetails] = useState(null); // 详情面板显示的版本详情
 
     // 动态计算 maxHeight，当窗口大小变化时更新
     useEffect(() => {
@@ -38,8 +31,8 @@ function App() {
     // 获取最新已安装版本列表
     const fetchInstalledVersions = async () => {
         try {
-            const installed = await GetInstalledNodeVersions();
-            setInstalledVersions(installed.sort(compareVersions));
+            // 列表已按用户的排序偏好由后端排好
+            setInstalledVersions(await GetInstalledNodeVersions());
         } catch (error) {
             setResult('Error fetching installed versions');
         }
@@ -48,8 +41,7 @@ function App() {
     // 获取所有版本信息
     const fetchAvailableVersions = async () => {
         try {
-            const available = await GetAvailableNodeVersions();
-            setAvailableVersions(available.sort(compareVersions));
+            setAvailableVersions(await GetAvailableNodeVersions());
         } catch (error) {
             setResult('Error fetching available versions');
         }
@@ -63,6 +55,37 @@ function App() {
         fetchVersions();
     }, []);
 
+    // 通过任务栏跳转列表切换版本后刷新列表
+    useEffect(() => {
+        return EventsOn('version-switched', async (message) => {
+            setResult(message);
+            await fetchInstalledVersions();
+            await fetchAvailableVersions();
+        });
+    }, []);
+
+    // 默认操作为"显示详情"时，由后端通知打开详情面板（双击版本行或托盘快捷列表）
+    useEffect(() => {
+        return EventsOn('show-version-details', async (version) => {
+            try {
+                setDetails(await GetVersionDetails(version));
+            } catch (error) {
+                setResult('Error fetching version details');
+            }
+        });
+    }, []);
+
+    // 双击已安装版本时执行配置的默认操作，与托盘快捷列表保持一致
+    const handleDefaultAction = async (version) => {
+        if (loadingVersion !== null) return;
+        try {
+            const response = await RunDefaultAction(version);
+            setResult(response);
+        } catch (error) {
+            setResult('Error running the default action');
+        }
+    };
+
     const handleInstallVersion = async (version) => {
         setLoadingVersion(version);
         setLoadingAction('install');
@@ -216,7 +239,11 @@ function App() {
                         </thead>
                         <tbody>
                             {installedVersions.map((versionData, index) => (
-                                <tr key={index} className="border-t border-gray-700">
+                                <tr
+                                    key={index}
+                                    className="border-t border-gray-700 cursor-pointer"
+                                    onDoubleClick={() => handleDefaultAction(versionData.Version)}
+                                >
                                     <td className="px-4 py-2">
                                         {versionData.Version}{" "}
                                         {versionData.IsCurrent && (
@@ -268,6 +295,77 @@ function App() {
             )}
 
             <p className="text-sm text-gray-400 mt-4">{result}</p>
+
+            {details && (
+                <div
+                    className="fixed inset-0 bg-black bg-opacity-50 flex justify-end"
+                    onClick={() => setDetails(null)}
+                >
+                    <div
+                        className="details-drawer bg-gray-800 w-96 h-full p-6 overflow-y-auto custom-scrollbar text-left"
+                        onClick={(e) => e.stopPropagation()}
+                    >
+                        <div className="flex justify-between items-center mb-4">
+                            <h2 className="text-xl font-semibold">Node.js {details.Version}</h2>
+                            <button className="operation-button bg-gray-600 text-gray-300" onClick={() => setDetails(null)}>
+                                Close
+                            </button>
+                        </div>
+                        <table className="table-auto w-full text-sm mb-4">
+                            <tbody>
+                                <tr>
+                                    <td className="py-1 text-gray-400">Status</td>
+                                    <td className="py-1">
+                                        {details.Current ? 'Current' : details.Installed ? 'Installed' : 'Not Installed'}
+                                        {details.EndOfLife && <span className="text-red-500"> (End of life)</span>}
+                                    </td>
+                                </tr>
+                                {details.Dist && details.Dist.NpmVersion && (
+                                    <tr>
+                                        <td className="py-1 text-gray-400">NPM Version</td>
+                                        <td className="py-1">{details.Dist.NpmVersion}</td>
+                                    </tr>
+                                )}
+                                {details.ReleaseLine && (
+                                    <tr>
+                                        <td className="py-1 text-gray-400">Release Line</td>
+                                        <td className="py-1">
+                                            {details.ReleaseLine.Major}.x {details.ReleaseLine.Codename} ({details.ReleaseLine.Phase})
+                                        </td>
+                                    </tr>
+                                )}
+                                {details.Path && (
+                                    <tr>
+                                        <td className="py-1 text-gray-400">Path</td>
+                                        <td className="py-1 break-all">{details.Path}</td>
+                                    </tr>
+                                )}
+                            </tbody>
+                        </table>
+                        {details.GlobalPackages && details.GlobalPackages.length > 0 && (
+                            <>
+                                <h3 className="font-semibold mb-2">Global Packages</h3>
+                                <ul className="text-sm mb-4">
+                                    {details.GlobalPackages.map((pkg) => (
+                                        <li key={pkg.Name}>
+                                            {pkg.Name} <span className="text-gray-400">{pkg.Version}</span>
+                                        </li>
+                                    ))}
+                                </ul>
+                            </>
+                        )}
+                        <h3 className="font-semibold mb-2">Links</h3>
+                        <ul className="text-sm text-blue-400 mb-4">
+                            <li><a href={details.Links.Changelog} target="_blank" rel="noreferrer">Changelog</a></li>
+                            <li><a href={details.Links.Docs} target="_blank" rel="noreferrer">Docs</a></li>
+                            <li><a href={details.Links.Download} target="_blank" rel="noreferrer">Download</a></li>
+                        </ul>
+                        {details.Warnings && details.Warnings.map((warning, index) => (
+                            <p key={index} className="text-sm text-yellow-500">{warning}</p>
+                        ))}
+                    </div>
+                </div>
+            )}
         </div>
     );
 }
@@ -6,8 +6,12 @@ export function GetAvailableNodeVersions():Promise<Array<main.NodeVersionInfo>>;
 
 export function GetInstalledNodeVersions():Promise<Array<main.NodeVersion>>;
 
+export function GetVersionDetails(arg1:string):Promise<main.VersionDetails>;
+
 export function InstallNodeVersion(arg1:string):Promise<string>;
 
+export function RunDefaultAction(arg1:string):Promise<string>;
+
 export function SwitchNodeVersion(arg1:string):Promise<string>;
 
 export function UninstallNodeVersion(arg1:string):Promise<string>;
@@ -10,10 +10,18 @@ export function GetInstalledNodeVersions() {
   return window['go']['main']['App']['GetInstalledNodeVersions']();
 }
 
+export function GetVersionDetails(arg1) {
+  return window['go']['main']['App']['GetVersionDetails'](arg1);
+}
+
 export function InstallNodeVersion(arg1) {
   return window['go']['main']['App']['InstallNodeVersion'](arg1);
 }
 
+export function RunDefaultAction(arg1) {
+  return window['go']['main']['App']['RunDefaultAction'](arg1);
+}
+
 export function SwitchNodeVersion(arg1) {
   return window['go']['main']['App']['SwitchNodeVersion'](arg1);
 }
@@ -1,5 +1,19 @@
 export namespace main {
 	
+	export class GlobalPackage {
+	    Name: string;
+	    Version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GlobalPackage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Name = source["Name"];
+	        this.Version = source["Version"];
+	    }
+	}
 	export class NodeVersion {
 	    Version: string;
 	    IsCurrent: boolean;
@@ -30,6 +44,77 @@ export namespace main {
 	        this.NpmVersion = source["NpmVersion"];
 	    }
 	}
+	export class VersionLinks {
+	    Changelog: string;
+	    Docs: string;
+	    Download: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VersionLinks(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Changelog = source["Changelog"];
+	        this.Docs = source["Docs"];
+	        this.Download = source["Download"];
+	    }
+	}
+	export class VersionDetails {
+	    Version: string;
+	    Dist?: NodeVersionInfo;
+	    Installed: boolean;
+	    Current: boolean;
+	    Path: string;
+	    Local?: any;
+	    GlobalPackages: GlobalPackage[];
+	    ReleaseLine?: any;
+	    EndOfLife: boolean;
+	    Summary?: any;
+	    Links: VersionLinks;
+	    Warnings: string[];
+	    // Go type: time
+	    GatheredAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new VersionDetails(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Version = source["Version"];
+	        this.Dist = this.convertValues(source["Dist"], NodeVersionInfo);
+	        this.Installed = source["Installed"];
+	        this.Current = source["Current"];
+	        this.Path = source["Path"];
+	        this.Local = source["Local"];
+	        this.GlobalPackages = this.convertValues(source["GlobalPackages"], GlobalPackage);
+	        this.ReleaseLine = source["ReleaseLine"];
+	        this.EndOfLife = source["EndOfLife"];
+	        this.Summary = source["Summary"];
+	        this.Links = this.convertValues(source["Links"], VersionLinks);
+	        this.Warnings = source["Warnings"];
+	        this.GatheredAt = this.convertValues(source["GatheredAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
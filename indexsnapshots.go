@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxIndexSnapshots is the number of daily dist index snapshots kept on disk
+// maxIndexSnapshots 是磁盘上保留的每日发布索引快照数量
+const maxIndexSnapshots = 90
+
+// snapshotDateLayout names the snapshot files by day
+// snapshotDateLayout 按日期命名快照文件
+const snapshotDateLayout = "2006-01-02"
+
+// indexSnapshotEntry is one release recorded in a snapshot
+// indexSnapshotEntry 表示快照中记录的一个发布版本
+type indexSnapshotEntry struct {
+	Version string `json:"version"`
+	Date    string `json:"date"`
+	LTS     bool   `json:"lts"`
+}
+
+// NewRelease is a release that appeared since a given date
+// NewRelease 表示自指定日期以来出现的发布版本
+type NewRelease struct {
+	Version string
+	Date    string
+	LTS     bool
+}
+
+// NewReleasesReport lists the releases published since a date and the snapshots they were derived from
+// NewReleasesReport 列出自某日期以来发布的版本及其所依据的快照
+type NewReleasesReport struct {
+	Since    string
+	Baseline string // 作为比较基准的快照日期，为空表示按发布日期判断 / snapshot compared against, empty when release dates were used
+	Latest   string
+	Releases []NewRelease
+}
+
+// indexSnapshotDir returns the directory holding the dated dist index snapshots
+// indexSnapshotDir 返回存放按日期命名的发布索引快照的目录
+func indexSnapshotDir() string {
+	return filepath.Join(cacheDir(), "index-snapshots")
+}
+
+// saveIndexSnapshot records today's dist index, replacing an earlier snapshot of the same day
+// and pruning the oldest ones
+// saveIndexSnapshot 记录当天的发布索引，替换同一天较早的快照并清理最旧的快照
+func (a *App) saveIndexSnapshot(versions []indexEntry) {
+	// 模拟后端的索引是虚构的，不能混入真实的快照
+	// The mock backend's index is fabricated and must not mix with the real snapshots
+	if a.mockBackend {
+		return
+	}
+	entries := make([]indexSnapshotEntry, 0, len(versions))
+	for _, v := range versions {
+		entries = append(entries, indexSnapshotEntry{Version: v.Version, Date: v.Date, LTS: v.LTS != ""})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+
+	dir := indexSnapshotDir()
+	if err := a.fs.MkdirAll(dir, 0755); err != nil {
+		a.logToFile(fmt.Sprintf("Error creating index snapshot directory: %v", err))
+		return
+	}
+	if err := a.fs.WriteFile(filepath.Join(dir, a.clock.Now().Format(snapshotDateLayout)+".json"), data, 0644); err != nil {
+		a.logToFile(fmt.Sprintf("Error writing index snapshot: %v", err))
+		return
+	}
+
+	dates := a.indexSnapshotDates()
+	for len(dates) > maxIndexSnapshots {
+		a.fs.Remove(filepath.Join(dir, dates[0]+".json"))
+		dates = dates[1:]
+	}
+}
+
+// indexSnapshotDates returns the dates of the stored snapshots, oldest first; the mock backend has none
+// indexSnapshotDates 返回已保存快照的日期，最早的在前；模拟后端没有快照
+func (a *App) indexSnapshotDates() []string {
+	if a.mockBackend {
+		return nil
+	}
+	entries, err := a.fs.ReadDir(indexSnapshotDir())
+	if err != nil {
+		return nil
+	}
+	var dates []string
+	for _, entry := range entries {
+		date := strings.TrimSuffix(entry.Name(), ".json")
+		if _, err := time.Parse(snapshotDateLayout, date); err == nil && !entry.IsDir() {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// readIndexSnapshot loads the snapshot of a date
+// readIndexSnapshot 读取某一天的快照
+func (a *App) readIndexSnapshot(date string) ([]indexSnapshotEntry, error) {
+	data, err := a.fs.ReadFile(filepath.Join(indexSnapshotDir(), date+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []indexSnapshotEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("Error parsing index snapshot %s: %v", date, err)
+	}
+	return entries, nil
+}
+
+// GetIndexSnapshots returns the dates of the stored dist index snapshots, oldest first
+// GetIndexSnapshots 返回已保存的发布索引快照日期，最早的在前
+func (a *App) GetIndexSnapshots() []string {
+	return a.indexSnapshotDates()
+}
+
+// GetNewVersionsSince lists the releases that appeared after since (YYYY-MM-DD), comparing the latest snapshot
+// with the newest snapshot taken on or before that day. Without such a baseline the release dates are used
+// GetNewVersionsSince 列出 since（YYYY-MM-DD）之后出现的发布版本，将最新快照与当天或之前最新的快照进行比较。
+// 没有这样的基准快照时按发布日期判断
+func (a *App) GetNewVersionsSince(since string) (NewReleasesReport, error) {
+	report := NewReleasesReport{Since: since}
+	if _, err := time.Parse(snapshotDateLayout, since); err != nil {
+		return report, fmt.Errorf("Invalid date %q, expected YYYY-MM-DD", since)
+	}
+	dates := a.indexSnapshotDates()
+	if len(dates) == 0 {
+		return report, fmt.Errorf("No index snapshots recorded yet")
+	}
+	report.Latest = dates[len(dates)-1]
+	latest, err := a.readIndexSnapshot(report.Latest)
+	if err != nil {
+		return report, err
+	}
+
+	for _, date := range dates {
+		if date <= since && date != report.Latest {
+			report.Baseline = date
+		}
+	}
+	known := map[string]bool{}
+	if report.Baseline != "" {
+		baseline, err := a.readIndexSnapshot(report.Baseline)
+		if err != nil {
+			return report, err
+		}
+		for _, entry := range baseline {
+			known[entry.Version] = true
+		}
+	}
+
+	for _, entry := range latest {
+		isNew := !known[entry.Version]
+		if report.Baseline == "" {
+			isNew = entry.Date > since
+		}
+		if isNew {
+			report.Releases = append(report.Releases, NewRelease{Version: entry.Version, Date: entry.Date, LTS: entry.LTS})
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Signature verification states of the SHASUMS file
+// SHASUMS 文件的签名验证状态
+const (
+	SignatureNotChecked  = "not-checked"
+	SignatureUnavailable = "unavailable"
+)
+
+// InstallPreview describes exactly what an install will download
+// InstallPreview 描述一次安装将要下载的具体内容
+type InstallPreview struct {
+	Version         string
+	Arch            string
+	FileName        string
+	URL             string
+	Source          string
+	Size            int64
+	SHA256          string
+	ShasumsURL      string
+	SignatureURL    string
+	SignatureStatus string
+	Warnings        []string
+}
+
+// distFileName returns the name of the Windows zip archive of a version
+// distFileName 返回指定版本 Windows zip 压缩包的文件名
+func distFileName(version, arch string) string {
+	return fmt.Sprintf("node-v%s-win-%s.zip", version, arch)
+}
+
+// parseShasums finds the SHA256 of a file in a SHASUMS256.txt listing
+// parseShasums 从 SHASUMS256.txt 列表中查找指定文件的 SHA256
+func parseShasums(data []byte, fileName string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+// defaultInstallArch returns the architecture an install of version uses when none is given, with a warning
+// when an ARM64 host falls back to x64
+// defaultInstallArch 返回未指定架构时安装该版本所用的架构，ARM64 主机回退到 x64 时同时返回警告
+func (a *App) defaultInstallArch(version string) (string, string) {
+	// ARM64 主机在没有 arm64 构建时回退到 x64
+	// ARM64 hosts fall back to x64 when there is no arm64 build
+	if fallback, warning := a.installArch(version); fallback != "" {
+		return fallback, warning
+	}
+	return hostArch(), ""
+}
+
+// locateArchive asks the dist sources in order for an archive of version with HEAD requests and returns the
+// first source serving it with the archive size
+// locateArchive 使用 HEAD 请求依次询问各下载来源，返回第一个提供该版本压缩包的来源及压缩包大小
+func (a *App) locateArchive(version, fileName string) (string, int64) {
+	client := a.client(indexFetchTimeout)
+	for _, source := range a.distSources() {
+		resp, err := client.Head(fmt.Sprintf("%s/v%s/%s", source, version, fileName))
+		if err != nil {
+			a.logToFile(fmt.Sprintf("Install preview: %s unreachable: %v", source, err))
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return source, resp.ContentLength
+		}
+	}
+	return "", 0
+}
+
+// downloadSize returns the size of the archive an install of version downloads, looked up with HEAD requests
+// only, without fetching or verifying SHASUMS256.txt
+// downloadSize 返回安装该版本将下载的压缩包大小，只使用 HEAD 请求查询，不下载或校验 SHASUMS256.txt
+func (a *App) downloadSize(version string) (int64, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if a.safeMode {
+		return 0, fmt.Errorf("networking disabled in safe mode")
+	}
+	arch, _ := a.defaultInstallArch(version)
+	source, size := a.locateArchive(version, distFileName(version, normalizeArch(arch)))
+	if source == "" {
+		return 0, fmt.Errorf("Node.js %s is not available for %s", version, arch)
+	}
+	return size, nil
+}
+
+// GetInstallPreview returns the URL, size, SHA256 and signature status of the archive
+// that installing version for arch would fetch
+// GetInstallPreview 返回为指定架构安装该版本时将下载的压缩包地址、大小、SHA256 及签名状态
+func (a *App) GetInstallPreview(version, arch string) (InstallPreview, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var archWarning string
+	if arch == "" {
+		arch, archWarning = a.defaultInstallArch(version)
+	}
+	arch = normalizeArch(arch)
+	if arch == ArchUnknown {
+		return InstallPreview{}, fmt.Errorf("unsupported architecture")
+	}
+
+	preview := InstallPreview{
+		Version:         version,
+		Arch:            arch,
+		FileName:        distFileName(version, arch),
+		SignatureStatus: SignatureNotChecked,
+		Warnings:        a.CheckInstallArch(version, arch),
+	}
+	if archWarning != "" {
+		preview.Warnings = append(preview.Warnings, archWarning)
+	}
+	if a.safeMode {
+		return preview, fmt.Errorf("networking disabled in safe mode")
+	}
+
+	source, size := a.locateArchive(version, preview.FileName)
+	if source == "" {
+		return preview, fmt.Errorf("Node.js %s is not available for %s", version, arch)
+	}
+	base := fmt.Sprintf("%s/v%s/", source, version)
+	preview.Source = source
+	preview.URL = base + preview.FileName
+	preview.Size = size
+	preview.ShasumsURL = base + "SHASUMS256.txt"
+	preview.SignatureURL = base + "SHASUMS256.txt.sig"
+	client := a.client(indexFetchTimeout)
+
+	shasums, _, err := a.fetchCached(fmt.Sprintf("SHASUMS256-v%s.txt", version), preview.ShasumsURL, 7*24*time.Hour)
+	if err != nil {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("无法获取 SHASUMS256.txt / Could not fetch SHASUMS256.txt: %v", err))
+		preview.SignatureStatus = SignatureUnavailable
+		return preview, nil
+	}
+	preview.SHA256 = parseShasums(shasums, preview.FileName)
+	if preview.SHA256 == "" {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("%s 未在 SHASUMS256.txt 中列出 / %s is not listed in SHASUMS256.txt", preview.FileName, preview.FileName))
+	}
+
+	resp, err := client.Head(preview.SignatureURL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if err != nil || resp.StatusCode != http.StatusOK {
+		preview.SignatureURL = ""
+		preview.SignatureStatus = SignatureUnavailable
+	} else {
+		preview.SignatureStatus = a.verifyShasums(version, shasums, preview.SignatureURL)
+	}
+	if preview.SignatureStatus != SignatureVerified {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("SHASUMS256.txt 签名未验证（%s） / The SHASUMS256.txt signature was not verified (%s)", preview.SignatureStatus, preview.SignatureStatus))
+	}
+
+	a.logToFile(fmt.Sprintf("Install preview for %s (%s): %s", version, arch, preview.URL))
+	return preview, nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestParseShasums(t *testing.T) {
+	data := []byte("0f1e2d3c  node-v20.18.0-win-x64.zip\n" +
+		"A1B2C3D4 *node-v20.18.0-win-arm64.zip\n" +
+		"deadbeef  node-v20.18.0-win-x64.7z\n")
+	tests := map[string]string{
+		"node-v20.18.0-win-x64.zip":   "0f1e2d3c",
+		"node-v20.18.0-win-arm64.zip": "a1b2c3d4",
+		"node-v20.18.0-win-x86.zip":   "",
+		"win-x64.zip":                 "",
+	}
+	for name, want := range tests {
+		if got := parseShasums(data, name); got != want {
+			t.Errorf("parseShasums(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultInventoryIntervalHours is how often the inventory is sent when no interval is configured
+// defaultInventoryIntervalHours 是未配置间隔时发送资产清单的频率
+const defaultInventoryIntervalHours = 24
+
+// inventoryPollInterval is how often the watcher checks whether an inventory is due
+// inventoryPollInterval 是检查是否需要发送资产清单的频率
+const inventoryPollInterval = time.Hour
+
+// InventoryConfig configures the periodic inventory sent to an internal fleet management URL.
+// A machine policy, when present, overrides these settings
+// InventoryConfig 配置定期发送到内部资产管理地址的资产清单，存在计算机策略时以策略为准
+type InventoryConfig struct {
+	Enabled       bool   `json:"enabled"`
+	URL           string `json:"url"`
+	IntervalHours int    `json:"intervalHours"`
+	ClientCert    string `json:"clientCert"` // 双向 TLS 客户端证书 PEM 文件 / PEM client certificate for mutual TLS
+	ClientKey     string `json:"clientKey"`
+	CACert        string `json:"caCert"` // 用于校验内部服务器的 CA 证书 / CA certificate verifying the internal server
+}
+
+// Inventory is the report posted to the fleet management URL
+// Inventory 是发送到资产管理地址的报告
+type Inventory struct {
+	Hostname          string    `json:"hostname"`
+	AppVersion        string    `json:"appVersion"`
+	InstalledVersions []string  `json:"installedVersions"`
+	DefaultVersion    string    `json:"defaultVersion"`
+	Time              time.Time `json:"time"`
+}
+
+// InventoryStatus is shown in the settings, including whether the configuration comes from a policy
+// InventoryStatus 显示在设置中，包括配置是否来自策略
+type InventoryStatus struct {
+	Config     InventoryConfig
+	FromPolicy bool
+	LastSent   time.Time
+	LastError  string
+}
+
+// inventoryState remembers the error of the last delivery; the time of the last success is kept in the settings
+// inventoryState 记录最近一次发送的错误；最近一次成功发送的时间保存在设置中
+type inventoryState struct {
+	mu        sync.Mutex
+	lastError string
+}
+
+// inventoryConfig returns the effective inventory configuration, preferring the machine policy
+// inventoryConfig 返回生效的资产上报配置，优先使用计算机策略
+func (a *App) inventoryConfig() (InventoryConfig, bool) {
+	if cfg, ok := readInventoryPolicy(); ok {
+		return cfg, true
+	}
+	return a.currentSettings().Inventory, false
+}
+
+// inventoryClient returns an HTTP client presenting the configured client certificate
+// inventoryClient 返回使用已配置客户端证书的 HTTP 客户端
+func (a *App) inventoryClient(cfg InventoryConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CACert != "" {
+		data, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("No certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := a.client(30 * time.Second)
+	// 模拟后端的传输层不访问网络，保持不变
+	// The mock transport never reaches the network and is kept as is
+	if _, mocked := client.Transport.(mockTransport); !mocked {
+		client.Transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}
+	}
+	return client, nil
+}
+
+// collectInventory gathers the hostname, installed versions and the default version
+// collectInventory 收集主机名、已安装版本和默认版本
+func (a *App) collectInventory() (Inventory, error) {
+	hostname, _ := os.Hostname()
+	inventory := Inventory{Hostname: hostname, AppVersion: appVersion, InstalledVersions: []string{}, Time: a.clock.Now()}
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return inventory, err
+	}
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		inventory.InstalledVersions = append(inventory.InstalledVersions, version)
+		if v.IsCurrent {
+			inventory.DefaultVersion = version
+		}
+	}
+	return inventory, nil
+}
+
+// sendInventory posts the inventory to the configured URL and records the outcome
+// sendInventory 将资产清单发送到配置的地址并记录结果
+func (a *App) sendInventory(cfg InventoryConfig) error {
+	err := func() error {
+		inventory, err := a.collectInventory()
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(inventory)
+		if err != nil {
+			return err
+		}
+		client, err := a.inventoryClient(cfg)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Post(cfg.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	}()
+
+	s := &a.inventory
+	s.mu.Lock()
+	if err == nil {
+		s.lastError = ""
+	} else {
+		s.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err == nil {
+		settings := a.currentSettings()
+		settings.InventoryLastSent = a.clock.Now()
+		if err := a.SetSettings(settings); err != nil {
+			a.logToFile(fmt.Sprintf("Error saving the inventory time: %v", err))
+		}
+	}
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error sending inventory to %s: %v", cfg.URL, err))
+		return fmt.Errorf("Error sending inventory: %v", err)
+	}
+	a.logToFile(fmt.Sprintf("Inventory sent to %s", cfg.URL))
+	return nil
+}
+
+// watchInventory sends the inventory whenever the configured interval has passed
+// watchInventory 在达到配置的间隔时发送资产清单
+func (a *App) watchInventory() {
+	ticker := time.NewTicker(inventoryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		cfg, _ := a.inventoryConfig()
+		if cfg.Enabled && cfg.URL != "" {
+			interval := cfg.IntervalHours
+			if interval <= 0 {
+				interval = defaultInventoryIntervalHours
+			}
+			if a.clock.Now().Sub(a.currentSettings().InventoryLastSent) >= time.Duration(interval)*time.Hour {
+				a.submitTask("inventory", TaskPriorityBackground, func() { a.sendInventory(cfg) })
+			}
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetInventoryStatus returns the effective inventory configuration and the last delivery
+// GetInventoryStatus 返回生效的资产上报配置和最近一次发送结果
+func (a *App) GetInventoryStatus() InventoryStatus {
+	cfg, fromPolicy := a.inventoryConfig()
+	a.inventory.mu.Lock()
+	defer a.inventory.mu.Unlock()
+	return InventoryStatus{Config: cfg, FromPolicy: fromPolicy, LastSent: a.currentSettings().InventoryLastSent, LastError: a.inventory.lastError}
+}
+
+// SendInventoryNow sends the inventory immediately, if it is enabled
+// SendInventoryNow 在已启用时立即发送资产清单
+func (a *App) SendInventoryNow() error {
+	cfg, _ := a.inventoryConfig()
+	if !cfg.Enabled || cfg.URL == "" {
+		return fmt.Errorf("Inventory reporting is not enabled")
+	}
+	return a.sendInventory(cfg)
+}
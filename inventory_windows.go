@@ -0,0 +1,31 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// inventoryPolicyKey is where administrators configure the inventory through Group Policy
+// inventoryPolicyKey 是管理员通过组策略配置资产上报的位置
+const inventoryPolicyKey = `SOFTWARE\Policies\NodeVersionSwitcher\Inventory`
+
+// readInventoryPolicy returns the machine policy for the inventory, ok is false when no policy is set
+// readInventoryPolicy 返回资产上报的计算机策略，未设置策略时 ok 为 false
+func readInventoryPolicy() (InventoryConfig, bool) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, inventoryPolicyKey, registry.QUERY_VALUE)
+	if err != nil {
+		return InventoryConfig{}, false
+	}
+	defer key.Close()
+
+	var cfg InventoryConfig
+	cfg.URL, _, _ = key.GetStringValue("URL")
+	cfg.ClientCert, _, _ = key.GetStringValue("ClientCert")
+	cfg.ClientKey, _, _ = key.GetStringValue("ClientKey")
+	cfg.CACert, _, _ = key.GetStringValue("CACert")
+	if hours, _, err := key.GetIntegerValue("IntervalHours"); err == nil {
+		cfg.IntervalHours = int(hours)
+	}
+	enabled, _, err := key.GetIntegerValue("Enabled")
+	cfg.Enabled = err != nil || enabled != 0
+	return cfg, cfg.URL != ""
+}
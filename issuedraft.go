@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/skratchdot/open-golang/open"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// appIssuesURL is where issue drafts are opened
+// appIssuesURL 是打开问题草稿的地址
+const appIssuesURL = "https://github.com/Shadownc/node-version-switcher/issues/new"
+
+// issueLogLines is how many recent log lines are attached to a draft
+// issueLogLines 是问题草稿中附带的最近日志行数
+const issueLogLines = 40
+
+// maxIssueURLLength keeps the prefilled URL below the length GitHub and browsers accept
+// maxIssueURLLength 使预填充的地址长度保持在 GitHub 和浏览器可接受的范围内
+const maxIssueURLLength = 7000
+
+// knownErrorPatterns are failures with a known cause that the UI already explains, so no report is offered
+// knownErrorPatterns 是原因已知、界面已有说明的错误，因此不提供报告
+var knownErrorPatterns = []string{
+	"is not installed",
+	"is already installed",
+	"networking disabled in safe mode",
+	"No newer version available",
+	"is not writable",
+	"low disk space",
+	"not available for",
+	"No Node.js version is currently in use",
+	"nvm root not found",
+	"unsupported architecture",
+	"wrong sync passphrase",
+}
+
+// secretPatterns match credentials that must never leave the machine in a report
+// secretPatterns 匹配绝不能随报告离开本机的凭据
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(_authToken|_auth|_password|token|password)(\s*[=:]\s*)\S+`),
+	regexp.MustCompile(`(?i)bearer\s+\S+`),
+	regexp.MustCompile(`\b(ghp|gho|ghs|github_pat|npm)_[A-Za-z0-9_]+`),
+	regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`),
+	regexp.MustCompile(`https://hooks\.slack\.com/\S+`),
+	regexp.MustCompile(`https://[^\s/]+\.webhook\.office\.com/\S+`),
+}
+
+// IssueDraft is a prefilled GitHub issue shown to the user before anything is sent
+// IssueDraft 是发送前展示给用户的预填充 GitHub 问题
+type IssueDraft struct {
+	Title string
+	Body  string
+	URL   string
+}
+
+// isKnownError reports whether an error message has a known cause
+// isKnownError 判断错误信息是否属于已知原因
+func isKnownError(message string) bool {
+	lower := strings.ToLower(message)
+	for _, pattern := range knownErrorPatterns {
+		if strings.Contains(lower, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact removes credentials, the user name and the home directory from text
+// redact 从文本中移除凭据、用户名和用户主目录
+func redact(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if sub := pattern.FindStringSubmatch(match); len(sub) == 3 {
+				return sub[1] + sub[2] + "<redacted>"
+			}
+			if strings.HasPrefix(match, "://") {
+				return "://<redacted>@"
+			}
+			return "<redacted>"
+		})
+	}
+	if home := os.Getenv("USERPROFILE"); home != "" {
+		text = strings.ReplaceAll(text, home, "%USERPROFILE%")
+	}
+	if user := os.Getenv("USERNAME"); len(user) > 2 {
+		text = strings.ReplaceAll(text, user, "<user>")
+	}
+	return text
+}
+
+// recentLogLines returns the last n lines of the log file
+// recentLogLines 返回日志文件的最后 n 行
+func (a *App) recentLogLines(n int) []string {
+	data, err := os.ReadFile(a.logFilePath)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// environmentSummary describes the machine without personal data
+// environmentSummary 描述运行环境，不包含个人数据
+func (a *App) environmentSummary() string {
+	nvmVersion := "unknown"
+	if output, err := a.executeNvmCommand("version"); err == nil {
+		nvmVersion = strings.TrimSpace(string(output))
+	}
+	current, _ := a.currentNodeVersion()
+	return fmt.Sprintf("- App: %s\n- OS: %s/%s (host %s)\n- nvm: %s\n- Node.js in use: %s\n- Safe mode: %v",
+		appVersion, goruntime.GOOS, goruntime.GOARCH, hostArch(), nvmVersion, current, a.safeMode)
+}
+
+// GetIssueDraft builds a redacted GitHub issue for a failed operation so the user can review it
+// GetIssueDraft 为失败的操作生成已脱敏的 GitHub 问题，供用户检查
+func (a *App) GetIssueDraft(operation, errMsg string) IssueDraft {
+	title := redact(fmt.Sprintf("%s failed: %s", operation, strings.SplitN(errMsg, "\n", 2)[0]))
+	if runes := []rune(title); len(runes) > 120 {
+		title = string(runes[:120])
+	}
+
+	logs := a.recentLogLines(issueLogLines)
+	build := func(logs []string) IssueDraft {
+		body := fmt.Sprintf("### 操作 / Operation\n%s\n\n### 错误 / Error\n```\n%s\n```\n\n### 环境 / Environment\n%s\n\n### 日志 / Recent log\n```\n%s\n```\n",
+			operation, redact(errMsg), a.environmentSummary(), redact(strings.Join(logs, "\n")))
+		query := url.Values{"title": {title}, "body": {body}}
+		return IssueDraft{Title: title, Body: body, URL: appIssuesURL + "?" + query.Encode()}
+	}
+
+	// 地址过长时减少附带的日志
+	// Attach fewer log lines while the URL is too long
+	draft := build(logs)
+	for len(draft.URL) > maxIssueURLLength && len(logs) > 0 {
+		logs = logs[len(logs)/2+1:]
+		draft = build(logs)
+	}
+	return draft
+}
+
+// ReportError offers to open a prefilled GitHub issue for an unrecognized failure. Nothing is opened
+// unless the user agrees in the consent dialog. It returns whether the draft was opened
+// ReportError 为无法识别的失败提供打开预填充 GitHub 问题的选项。只有用户在确认对话框中同意后才会打开。
+// 返回是否已打开草稿
+func (a *App) ReportError(operation, errMsg string) (bool, error) {
+	if isKnownError(errMsg) || a.ctx == nil {
+		return false, nil
+	}
+
+	result, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:          runtime.QuestionDialog,
+		Title:         "报告问题 / Report a problem",
+		Message:       fmt.Sprintf("%s 失败，原因未知。是否在浏览器中打开预填充的 GitHub 问题？发送前可以检查内容，日志已脱敏。\n\n%s failed for an unknown reason. Open a prefilled GitHub issue in your browser? You can review it before submitting, logs are redacted.", operation, operation),
+		Buttons:       []string{"Yes", "No"},
+		DefaultButton: "No",
+		CancelButton:  "No",
+	})
+	if err != nil || result != "Yes" {
+		return false, err
+	}
+
+	draft := a.GetIssueDraft(operation, errMsg)
+	if err := open.Run(draft.URL); err != nil {
+		return false, fmt.Errorf("Error opening browser: %v", err)
+	}
+	a.audit("report-error", operation, "opened")
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJumpListVersions caps the number of versions listed in the jump list
+// maxJumpListVersions 限制跳转列表中显示的版本数量
+const maxJumpListVersions = 8
+
+// JumpListItem is one entry of the taskbar jump list
+// JumpListItem 表示任务栏跳转列表中的一个条目
+type JumpListItem struct {
+	Title       string
+	Description string
+	Arguments   string
+}
+
+// JumpListCategory is a titled group of jump list entries
+// JumpListCategory 表示跳转列表中带标题的一组条目
+type JumpListCategory struct {
+	Title string
+	Items []JumpListItem
+}
+
+// JumpList is the full content of the taskbar jump list
+// JumpList 表示任务栏跳转列表的完整内容
+type JumpList struct {
+	Categories []JumpListCategory
+	Tasks      []JumpListItem
+}
+
+var jumpListMu sync.Mutex
+
+// buildJumpList assembles the jump list from the usage history of the installed versions, most recent first
+// buildJumpList 根据已安装版本的使用记录生成跳转列表内容，最近使用的排在最前
+func buildJumpList(installed []NodeVersion, lastUsed map[string]time.Time) JumpList {
+	var recent []NodeVersion
+	current := ""
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		if v.IsCurrent {
+			current = version
+		}
+		if _, ok := lastUsed[version]; ok {
+			recent = append(recent, NodeVersion{Version: version, IsCurrent: v.IsCurrent})
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return lastUsed[recent[i].Version].After(lastUsed[recent[j].Version])
+	})
+	// 当前版本没有使用记录时排在最前面，确保始终可见
+	// A current version without usage history is listed first so it is always visible
+	if _, ok := lastUsed[current]; current != "" && !ok {
+		recent = append([]NodeVersion{{Version: current, IsCurrent: true}}, recent...)
+	}
+	if len(recent) > maxJumpListVersions {
+		recent = recent[:maxJumpListVersions]
+	}
+
+	var items []JumpListItem
+	for _, v := range recent {
+		item := JumpListItem{
+			Title:       fmt.Sprintf("切换到 Node.js %s", v.Version),
+			Description: fmt.Sprintf("Switch to Node.js %s", v.Version),
+			Arguments:   fmt.Sprintf("%s %s", argSwitch, v.Version),
+		}
+		if v.IsCurrent {
+			item.Title = fmt.Sprintf("Node.js %s (当前)", v.Version)
+			item.Description = fmt.Sprintf("Node.js %s is the active version", v.Version)
+		}
+		items = append(items, item)
+	}
+
+	list := JumpList{
+		Tasks: []JumpListItem{
+			{Title: "显示应用", Description: "Show the Node Version Switcher window", Arguments: argShow},
+		},
+	}
+	if len(items) > 0 {
+		list.Categories = append(list.Categories, JumpListCategory{Title: "最近使用", Items: items})
+	}
+	return list
+}
+
+// refreshJumpList rebuilds the taskbar jump list from the recently used versions, skipped in safe mode
+// refreshJumpList 根据最近使用的版本重新生成任务栏跳转列表，安全模式下跳过
+func (a *App) refreshJumpList() {
+	jumpListMu.Lock()
+	defer jumpListMu.Unlock()
+
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Skipping jump list refresh: %v", err))
+		return
+	}
+
+	// 托盘快捷列表与跳转列表使用相同的版本
+	// The tray quick list shows the same versions as the jump list
+	go a.refreshTrayVersions()
+
+	// 安全模式下不修改 Windows 外壳集成
+	// Safe mode leaves the Windows shell integration untouched
+	if a.safeMode {
+		return
+	}
+	if err := commitJumpList(buildJumpList(installed, a.currentSettings().LastUsed)); err != nil {
+		a.logToFile(fmt.Sprintf("Error updating jump list: %v", err))
+		return
+	}
+	a.logToFile("Jump list updated")
+}
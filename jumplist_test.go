@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildJumpListOrdersByUsageHistory(t *testing.T) {
+	installed := []NodeVersion{
+		{Version: "22.11.0"},
+		{Version: "20.18.0", IsCurrent: true},
+		{Version: "18.20.4"},
+		{Version: "16.20.2"},
+	}
+	day := time.Date(2024, 11, 20, 9, 0, 0, 0, time.UTC)
+	lastUsed := map[string]time.Time{
+		"18.20.4": day.Add(-2 * time.Hour),
+		"20.18.0": day,
+		"22.11.0": day.Add(-time.Hour),
+	}
+
+	list := buildJumpList(installed, lastUsed)
+	if len(list.Categories) != 1 {
+		t.Fatalf("categories = %+v, want one recent category", list.Categories)
+	}
+	var got []string
+	for _, item := range list.Categories[0].Items {
+		got = append(got, item.Arguments)
+	}
+	want := []string{argSwitch + " 20.18.0", argSwitch + " 22.11.0", argSwitch + " 18.20.4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jump list order = %v, want %v", got, want)
+	}
+}
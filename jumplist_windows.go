@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	clsidDestinationList            = syscall.GUID{Data1: 0x77f10cf0, Data2: 0x3db5, Data3: 0x4966, Data4: [8]byte{0xb5, 0x20, 0xb7, 0xc5, 0x4f, 0xd3, 0x5e, 0xd6}}
+	iidICustomDestinationList       = syscall.GUID{Data1: 0x6332debf, Data2: 0x87b5, Data3: 0x4670, Data4: [8]byte{0x90, 0xc0, 0x5e, 0x57, 0xb4, 0x08, 0xa4, 0x9e}}
+	clsidEnumerableObjectCollection = syscall.GUID{Data1: 0x2d3468c1, Data2: 0x36a7, Data3: 0x43b6, Data4: [8]byte{0xac, 0x24, 0xd3, 0xf0, 0x2f, 0xd9, 0x60, 0x7a}}
+	iidIObjectCollection            = syscall.GUID{Data1: 0x5632b1a4, Data2: 0xe38a, Data3: 0x400a, Data4: [8]byte{0x92, 0x8a, 0xd4, 0xcd, 0x63, 0x23, 0x02, 0x95}}
+	iidIObjectArray                 = syscall.GUID{Data1: 0x92ca9dcd, Data2: 0x5622, Data3: 0x4bba, Data4: [8]byte{0xa8, 0x05, 0x5e, 0x9f, 0x54, 0x1b, 0xd8, 0xc9}}
+	clsidShellLink                  = syscall.GUID{Data1: 0x00021401, Data2: 0x0000, Data3: 0x0000, Data4: [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	iidIShellLinkW                  = syscall.GUID{Data1: 0x000214f9, Data2: 0x0000, Data3: 0x0000, Data4: [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	iidIPropertyStore               = syscall.GUID{Data1: 0x886d8eeb, Data2: 0x8cf2, Data3: 0x4446, Data4: [8]byte{0x8d, 0x02, 0xcd, 0xba, 0x1d, 0xbc, 0xf9, 0x9f}}
+	pkeyTitle                       = propertyKey{fmtid: syscall.GUID{Data1: 0xf29f85e0, Data2: 0x4ff9, Data3: 0x1068, Data4: [8]byte{0xab, 0x91, 0x08, 0x00, 0x2b, 0x27, 0xb3, 0xd9}}, pid: 2}
+)
+
+// Vtable indices of the interfaces used to build the jump list
+// 构建跳转列表所用接口的虚函数表索引
+const (
+	cdlBeginList      = 4
+	cdlAppendCategory = 5
+	cdlAddUserTasks   = 7
+	cdlCommitList     = 8
+
+	ocAddObject = 5
+
+	slSetDescription  = 7
+	slSetArguments    = 11
+	slSetIconLocation = 17
+	slSetPath         = 20
+
+	psSetValue = 6
+	psCommit   = 7
+
+	vtLPWSTR = 31
+)
+
+// propertyKey mirrors the Win32 PROPERTYKEY structure
+// propertyKey 对应 Win32 的 PROPERTYKEY 结构
+type propertyKey struct {
+	fmtid syscall.GUID
+	pid   uint32
+}
+
+// propVariant mirrors the layout of a PROPVARIANT holding a string pointer
+// propVariant 对应保存字符串指针的 PROPVARIANT 结构布局
+type propVariant struct {
+	vt       uint16
+	reserved [3]uint16
+	val      uintptr
+	extra    uintptr
+}
+
+// commitJumpList replaces the taskbar jump list with the given categories and tasks
+// commitJumpList 使用指定的分类和任务替换任务栏跳转列表
+func commitJumpList(list JumpList) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// COM objects must be created and used on a single OS thread
+	// COM 对象必须在同一个系统线程上创建和使用
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	return withCOM(func() error {
+		destList, err := coCreateInstance(&clsidDestinationList, &iidICustomDestinationList)
+		if err != nil {
+			return err
+		}
+		defer destList.release()
+
+		var maxSlots uint32
+		var removed *comObject
+		if err := destList.call(cdlBeginList, uintptr(unsafe.Pointer(&maxSlots)), uintptr(unsafe.Pointer(&iidIObjectArray)), uintptr(unsafe.Pointer(&removed))); err != nil {
+			return err
+		}
+		removed.release()
+
+		for _, category := range list.Categories {
+			items := category.Items
+			if len(items) > int(maxSlots) {
+				items = items[:maxSlots]
+			}
+			array, err := buildLinkArray(exePath, items)
+			if err != nil {
+				return err
+			}
+			title, _ := syscall.UTF16PtrFromString(category.Title)
+			err = destList.call(cdlAppendCategory, uintptr(unsafe.Pointer(title)), uintptr(unsafe.Pointer(array)))
+			array.release()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(list.Tasks) > 0 {
+			array, err := buildLinkArray(exePath, list.Tasks)
+			if err != nil {
+				return err
+			}
+			err = destList.call(cdlAddUserTasks, uintptr(unsafe.Pointer(array)))
+			array.release()
+			if err != nil {
+				return err
+			}
+		}
+
+		return destList.call(cdlCommitList)
+	})
+}
+
+// buildLinkArray creates an IObjectArray of shell links that relaunch the app with arguments
+// buildLinkArray 创建一个以指定参数重新启动应用的快捷方式 IObjectArray
+func buildLinkArray(exePath string, items []JumpListItem) (*comObject, error) {
+	collection, err := coCreateInstance(&clsidEnumerableObjectCollection, &iidIObjectCollection)
+	if err != nil {
+		return nil, err
+	}
+	defer collection.release()
+
+	for _, item := range items {
+		link, err := newShellLink(exePath, item)
+		if err != nil {
+			return nil, err
+		}
+		err = collection.call(ocAddObject, uintptr(unsafe.Pointer(link)))
+		link.release()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collection.queryInterface(&iidIObjectArray)
+}
+
+// newShellLink creates a shell link for one jump list entry
+// newShellLink 为一个跳转列表条目创建快捷方式
+func newShellLink(exePath string, item JumpListItem) (*comObject, error) {
+	link, err := coCreateInstance(&clsidShellLink, &iidIShellLinkW)
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := syscall.UTF16PtrFromString(exePath)
+	args, _ := syscall.UTF16PtrFromString(item.Arguments)
+	desc, _ := syscall.UTF16PtrFromString(item.Description)
+	title, _ := syscall.UTF16PtrFromString(item.Title)
+
+	steps := []func() error{
+		func() error { return link.call(slSetPath, uintptr(unsafe.Pointer(path))) },
+		func() error { return link.call(slSetArguments, uintptr(unsafe.Pointer(args))) },
+		func() error { return link.call(slSetDescription, uintptr(unsafe.Pointer(desc))) },
+		func() error { return link.call(slSetIconLocation, uintptr(unsafe.Pointer(path)), 0) },
+		func() error {
+			// The visible title of a jump list link is stored in its property store
+			// 跳转列表快捷方式的显示标题保存在其属性存储中
+			store, err := link.queryInterface(&iidIPropertyStore)
+			if err != nil {
+				return err
+			}
+			defer store.release()
+			value := propVariant{vt: vtLPWSTR, val: uintptr(unsafe.Pointer(title))}
+			err = store.call(psSetValue, uintptr(unsafe.Pointer(&pkeyTitle)), uintptr(unsafe.Pointer(&value)))
+			runtime.KeepAlive(title)
+			if err != nil {
+				return err
+			}
+			return store.call(psCommit)
+		},
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			link.release()
+			return nil, fmt.Errorf("Error creating jump list entry %q: %v", item.Title, err)
+		}
+	}
+	return link, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"sort"
+	"sync"
+)
+
+// keepAwake counts the long jobs that must not be interrupted by sleep. A single goroutine locked to
+// its OS thread owns the execution state, because Windows ties it to the thread that set it
+// keepAwake 统计不能被睡眠打断的耗时任务。由一个固定在系统线程上的协程负责执行状态，
+// 因为 Windows 将该状态绑定到设置它的线程
+type keepAwake struct {
+	mu      sync.Mutex
+	reasons map[string]int
+	changes chan bool
+}
+
+// run applies the requested execution state on its own locked thread
+// run 在专用的固定线程上应用请求的执行状态
+func (k *keepAwake) run() {
+	goruntime.LockOSThread()
+	for on := range k.changes {
+		setSystemRequired(on)
+	}
+}
+
+// stayAwake keeps the machine from sleeping until the returned function is called
+// stayAwake 阻止计算机睡眠，直到调用返回的函数
+func (a *App) stayAwake(reason string) func() {
+	k := &a.awake
+	k.mu.Lock()
+	if k.changes == nil {
+		k.reasons = map[string]int{}
+		k.changes = make(chan bool, 16)
+		go k.run()
+	}
+	k.reasons[reason]++
+	if len(k.reasons) == 1 && k.reasons[reason] == 1 {
+		k.changes <- true
+		a.logToFile(fmt.Sprintf("Keeping the system awake: %s", reason))
+	}
+	k.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			if k.reasons[reason]--; k.reasons[reason] <= 0 {
+				delete(k.reasons, reason)
+			}
+			if len(k.reasons) == 0 {
+				k.changes <- false
+				a.logToFile("System may sleep again")
+			}
+		})
+	}
+}
+
+// GetKeepAwakeReasons returns the jobs currently keeping the machine awake
+// GetKeepAwakeReasons 返回当前阻止计算机睡眠的任务
+func (a *App) GetKeepAwakeReasons() []string {
+	k := &a.awake
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	reasons := make([]string, 0, len(k.reasons))
+	for reason := range k.reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
@@ -0,0 +1,21 @@
+package main
+
+var procSetThreadExecutionState = modkernel32.NewProc("SetThreadExecutionState")
+
+// EXECUTION_STATE flags
+// EXECUTION_STATE 标志
+const (
+	esSystemRequired = 0x00000001
+	esContinuous     = 0x80000000
+)
+
+// setSystemRequired keeps the machine from sleeping while on is true. The state belongs to the calling thread,
+// so the caller must stay on one locked OS thread
+// setSystemRequired 在 on 为真时阻止计算机睡眠。该状态属于调用线程，因此调用方必须固定在同一个系统线程上
+func setSystemRequired(on bool) {
+	flags := uintptr(esContinuous)
+	if on {
+		flags |= esSystemRequired
+	}
+	procSetThreadExecutionState.Call(flags)
+}
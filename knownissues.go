@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// knownIssue is a known nvm-windows problem recognized by the nvm version and the error output
+// knownIssue 是根据 nvm 版本和错误输出识别的已知 nvm-windows 问题
+type knownIssue struct {
+	ID         string
+	Operations []string       // 为空表示适用于所有操作 / empty applies to every operation
+	NvmVersion string         // 为空表示适用于所有 nvm 版本 / empty applies to every nvm version
+	Signature  *regexp.Regexp // 错误输出的特征 / signature of the error output
+	Applies    func() bool    // 可选的环境条件 / optional environment condition
+	Guidance   string
+	// Fix 是可自动执行的安全修复，没有时为 nil
+	// Fix is an automated fix that is safe to run, nil when there is none
+	Fix func(a *App, version string) error
+}
+
+// KnownIssueMatch is a known issue matching a failed operation, shown with the error
+// KnownIssueMatch 是与失败操作匹配的已知问题，与错误一起显示
+type KnownIssueMatch struct {
+	ID       string
+	Guidance string
+	CanFix   bool
+}
+
+// pathHasSpaces reports whether the nvm directories contain spaces, which older nvm releases cannot handle
+// pathHasSpaces 判断 nvm 目录是否包含空格，旧版 nvm 无法处理这种路径
+func pathHasSpaces() bool {
+	return strings.Contains(nvmEnvPath("NVM_HOME"), " ") || strings.Contains(nvmEnvPath("NVM_SYMLINK"), " ")
+}
+
+// removePartialInstall deletes a version directory left incomplete by a failed extraction
+// removePartialInstall 删除解压失败后遗留的不完整版本目录
+func removePartialInstall(a *App, version string) error {
+	root := a.nvmRoot()
+	if root == "" {
+		return fmt.Errorf("nvm root not found")
+	}
+	dir := versionDir(root, version)
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+	if diagnoseVersionDir(dir) == "" {
+		return fmt.Errorf("%s looks complete, it was not removed", dir)
+	}
+	return os.RemoveAll(longPath(dir))
+}
+
+// removeStaleTempArchives deletes the downloads nvm leaves in its temp directory after a failed unzip
+// removeStaleTempArchives 删除 nvm 解压失败后遗留在临时目录中的下载文件
+func removeStaleTempArchives(a *App, version string) error {
+	root := a.nvmRoot()
+	if root == "" {
+		return fmt.Errorf("nvm root not found")
+	}
+	matches, _ := filepath.Glob(filepath.Join(root, "temp", "*"))
+	for _, match := range matches {
+		if err := os.RemoveAll(longPath(match)); err != nil {
+			return err
+		}
+	}
+	return removePartialInstall(a, version)
+}
+
+// knownIssues are the recognized nvm-windows problems, most specific first
+// knownIssues 是可识别的 nvm-windows 问题，越具体的排在越前
+var knownIssues = []knownIssue{
+	{
+		ID:         "nvm-1.1.7-unzip",
+		Operations: []string{OperationInstall},
+		NvmVersion: "1.1.7",
+		Signature:  regexp.MustCompile(`(?i)exit status 1|unzip|could not extract`),
+		Guidance:   "nvm 1.1.7 存在解压缺陷，请升级到 nvm 1.1.9 或更高版本后重试；可自动清理不完整的下载和目录 / nvm 1.1.7 has an unzip bug, upgrade to nvm 1.1.9 or later and retry; the partial download and directory can be cleaned up automatically",
+		Fix:        removeStaleTempArchives,
+	},
+	{
+		ID:        "spaces-in-path",
+		Signature: regexp.MustCompile(`(?i)exit status|is not recognized|cannot find the path|系统找不到指定的路径`),
+		Applies:   pathHasSpaces,
+		Guidance:  "NVM_HOME 或 NVM_SYMLINK 包含空格，旧版 nvm 无法处理，请将 nvm 重新安装到不含空格的路径（例如 C:\\nvm） / NVM_HOME or NVM_SYMLINK contains spaces, which older nvm releases cannot handle; reinstall nvm to a path without spaces such as C:\\nvm",
+	},
+	{
+		ID:         "symlink-access-denied",
+		Operations: []string{OperationSwitch},
+		Signature:  regexp.MustCompile(`(?i)access is denied|拒绝访问|elevat|privilege`),
+		Guidance:   "创建符号链接需要管理员权限，请以管理员身份运行或在 Windows 设置中开启开发者模式 / Creating the symlink needs elevation, run as administrator or turn on Developer Mode in Windows settings",
+	},
+	{
+		ID:         "partial-install",
+		Operations: []string{OperationInstall},
+		Signature:  regexp.MustCompile(`(?i)already installed|node\.exe.*(missing|not found)`),
+		Guidance:   "上一次安装未完成，版本目录不完整；可自动删除后重新安装 / A previous install did not finish and left an incomplete directory; it can be removed automatically before installing again",
+		Fix:        removePartialInstall,
+	},
+}
+
+// installedNvmVersion returns the version of nvm-windows, or an empty string when it cannot be determined
+// installedNvmVersion 返回 nvm-windows 的版本，无法确定时返回空字符串
+func (a *App) installedNvmVersion() string {
+	output, err := a.executeNvmCommand("version")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "v")
+}
+
+// matches reports whether the issue applies to a failed operation
+// matches 判断该问题是否适用于失败的操作
+func (k knownIssue) matches(operation, nvmVersion, message string) bool {
+	if len(k.Operations) > 0 {
+		found := false
+		for _, op := range k.Operations {
+			found = found || op == operation
+		}
+		if !found {
+			return false
+		}
+	}
+	if k.NvmVersion != "" && k.NvmVersion != nvmVersion {
+		return false
+	}
+	if k.Applies != nil && !k.Applies() {
+		return false
+	}
+	return k.Signature.MatchString(message)
+}
+
+// MatchKnownIssues returns the known issues explaining a failed operation
+// MatchKnownIssues 返回能够解释失败操作的已知问题
+func (a *App) MatchKnownIssues(operation, message string) []KnownIssueMatch {
+	nvmVersion := a.installedNvmVersion()
+	var matches []KnownIssueMatch
+	for _, issue := range knownIssues {
+		if issue.matches(operation, nvmVersion, message) {
+			matches = append(matches, KnownIssueMatch{ID: issue.ID, Guidance: issue.Guidance, CanFix: issue.Fix != nil})
+		}
+	}
+	return matches
+}
+
+// withKnownIssueGuidance appends the remediation of the matching known issues to an error message
+// withKnownIssueGuidance 在错误信息后附加匹配的已知问题的处理建议
+func (a *App) withKnownIssueGuidance(operation, message string) string {
+	for _, match := range a.MatchKnownIssues(operation, message) {
+		message += fmt.Sprintf("\n[%s] %s", match.ID, match.Guidance)
+	}
+	return message
+}
+
+// ApplyKnownIssueFix runs the automated fix of a known issue for a version
+// ApplyKnownIssueFix 为某个版本执行已知问题的自动修复
+func (a *App) ApplyKnownIssueFix(id, version string) error {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	for _, issue := range knownIssues {
+		if issue.ID != id {
+			continue
+		}
+		if issue.Fix == nil {
+			return fmt.Errorf("Known issue %s has no automated fix", id)
+		}
+		if err := issue.Fix(a, version); err != nil {
+			a.audit("known-issue-fix", fmt.Sprintf("%s %s", id, version), "failed")
+			return fmt.Errorf("Error applying fix for %s: %v", id, err)
+		}
+		a.audit("known-issue-fix", fmt.Sprintf("%s %s", id, version), "success")
+		return nil
+	}
+	return fmt.Errorf("Unknown known issue: %s", id)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultStatusText is the built-in status vocabulary by locale
+// defaultStatusText 是按区域设置区分的内置状态词汇
+var defaultStatusText = map[string]map[string]string{
+	"Installed": {
+		LocaleZhCN: "已安装",
+		LocaleEnUS: "Installed",
+	},
+	"Not Installed": {
+		LocaleZhCN: "未安装",
+		LocaleEnUS: "Not installed",
+	},
+}
+
+// LabelDefinition is a custom label declared in the labels file, with its text per locale
+// LabelDefinition 是标签文件中声明的自定义标签，包含各区域设置下的文本
+type LabelDefinition struct {
+	ID    string            `json:"id"`
+	Text  map[string]string `json:"text"`
+	Color string            `json:"color"`
+}
+
+// LabelsFile is a team-maintained file adding labels such as "company standard" to versions.
+// Versions are matched exactly or by prefix ("16" matches every 16.x), like the team config
+// LabelsFile 是团队维护的标签文件，用于为版本添加“公司标准”等标签。
+// 版本按完整版本号或前缀匹配（"16" 匹配所有 16.x），与团队配置相同
+type LabelsFile struct {
+	Labels   []LabelDefinition            `json:"labels"`
+	Versions map[string][]string          `json:"versions"`
+	Status   map[string]map[string]string `json:"status"` // 覆盖内置状态文本 / overrides of the built-in status text
+}
+
+// VersionLabel is a label resolved for the current locale
+// VersionLabel 是按当前区域设置解析后的标签
+type VersionLabel struct {
+	ID    string
+	Text  string
+	Color string
+}
+
+// labelsFilePath returns the configured labels file, defaulting to one next to the executable
+// labelsFilePath 返回配置的标签文件路径，默认为可执行文件同目录下的文件
+func (a *App) labelsFilePath() string {
+	if path := strings.TrimSpace(a.currentSettings().LabelsFile); path != "" {
+		return path
+	}
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-labels.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-labels.json")
+}
+
+// loadLabels reads the labels file; a missing file means no custom labels
+// loadLabels 读取标签文件，文件不存在表示没有自定义标签
+func (a *App) loadLabels() (LabelsFile, error) {
+	var labels LabelsFile
+	data, err := a.fs.ReadFile(a.labelsFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return labels, nil
+		}
+		return labels, err
+	}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return labels, fmt.Errorf("Error parsing labels file: %v", err)
+	}
+	return labels, nil
+}
+
+// localizedText picks the text for locale, falling back to English and then to any text
+// localizedText 选择对应区域设置的文本，依次回退到英文和任意文本
+func localizedText(texts map[string]string, locale, fallback string) string {
+	if text := texts[locale]; text != "" {
+		return text
+	}
+	if text := texts[LocaleEnUS]; text != "" {
+		return text
+	}
+	keys := make([]string, 0, len(texts))
+	for key := range texts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if texts[key] != "" {
+			return texts[key]
+		}
+	}
+	return fallback
+}
+
+// statusText returns the localized text of a status, preferring the labels file
+// statusText 返回状态的本地化文本，优先使用标签文件中的文本
+func (l LabelsFile) statusText(status, locale string) string {
+	if text := localizedText(l.Status[status], locale, ""); text != "" {
+		return text
+	}
+	return localizedText(defaultStatusText[status], locale, status)
+}
+
+// labelsFor returns the labels of a version, in the order they are declared
+// labelsFor 返回版本的标签，按声明顺序排列
+func (l LabelsFile) labelsFor(version, locale string) []VersionLabel {
+	ids := map[string]bool{}
+	for pattern, labelIDs := range l.Versions {
+		if versionAllowed(version, []string{pattern}) {
+			for _, id := range labelIDs {
+				ids[id] = true
+			}
+		}
+	}
+	var labels []VersionLabel
+	for _, def := range l.Labels {
+		if ids[def.ID] {
+			labels = append(labels, VersionLabel{ID: def.ID, Text: localizedText(def.Text, locale, def.ID), Color: def.Color})
+		}
+	}
+	return labels
+}
+
+// withLabels fills in the localized status text and the custom labels of the versions
+// withLabels 为版本填写本地化状态文本和自定义标签
+func (a *App) withLabels(versions []NodeVersionInfo) []NodeVersionInfo {
+	labels, err := a.loadLabels()
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Ignoring labels file: %v", err))
+	}
+	locale := a.formatter().locale
+	for i := range versions {
+		versions[i].StatusText = labels.statusText(versions[i].Status, locale)
+		versions[i].Labels = labels.labelsFor(versions[i].Version, locale)
+	}
+	return versions
+}
+
+// GetVersionLabels returns the custom labels of one version
+// GetVersionLabels 返回某个版本的自定义标签
+func (a *App) GetVersionLabels(version string) ([]VersionLabel, error) {
+	labels, err := a.loadLabels()
+	if err != nil {
+		return nil, err
+	}
+	return labels.labelsFor(strings.TrimPrefix(strings.TrimSpace(version), "v"), a.formatter().locale), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Command line arguments understood by the application, used by jump list entries
+// 应用程序支持的命令行参数，供跳转列表条目使用
+const (
+	argShow        = "--show"
+	argSwitch      = "--switch"
+	argSafeMode    = "--safe-mode"
+	argWaitForExit = "--wait-for-exit"
+	// 开启 nvm 输出录制，用于收集解析器语料
+	// Record nvm outputs to collect a corpus for the parsers
+	argRecordCorpus = "--record-corpus"
+	// 使用虚构数据代替 nvm 和网络，用于演示和界面开发
+	// Serve fabricated data instead of nvm and the network, for demos and UI development
+	argMockBackend = "--mock-backend"
+	// 双击 .nvmrc 时由文件关联传入
+	// Passed by the file association when a .nvmrc is double-clicked
+	argOpenNvmrc = "--open-nvmrc"
+)
+
+// startupFlags holds the arguments that must be known before the app is created
+// startupFlags 保存创建应用之前就必须知道的参数
+type startupFlags struct {
+	safeMode     bool
+	recordCorpus bool
+	mockBackend  bool
+	waitForPid   int
+}
+
+// parseStartupFlags extracts the startup-only flags from the command line
+// parseStartupFlags 从命令行中提取仅在启动时使用的参数
+func parseStartupFlags(args []string) startupFlags {
+	var flags startupFlags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case argSafeMode:
+			flags.safeMode = true
+		case argRecordCorpus:
+			flags.recordCorpus = true
+		case argMockBackend:
+			flags.mockBackend = true
+		case argWaitForExit:
+			if i+1 < len(args) {
+				i++
+				flags.waitForPid, _ = strconv.Atoi(args[i])
+			}
+		}
+	}
+	return flags
+}
+
+// applyStartupFlags acts on the startup-only flags before the window is created
+// applyStartupFlags 在创建窗口之前处理仅在启动时使用的参数
+func (a *App) applyStartupFlags(flags startupFlags) {
+	if flags.waitForPid > 0 {
+		waitForProcessExit(flags.waitForPid, 10*time.Second)
+	}
+	if flags.mockBackend {
+		a.enterMockBackend()
+	}
+	if flags.safeMode {
+		a.enterSafeMode()
+	}
+	if flags.recordCorpus {
+		a.SetCorpusRecording(true)
+	}
+}
+
+// handleLaunchArgs performs the actions requested on the command line of this or a second instance
+// handleLaunchArgs 执行本实例或第二个实例命令行中请求的操作
+func (a *App) handleLaunchArgs(args []string) {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case argShow:
+			a.showWindow()
+		case argSwitch:
+			if i+1 >= len(args) {
+				a.logToFile("Ignoring --switch without a version")
+				continue
+			}
+			i++
+			version := args[i]
+			a.showWindow()
+			result := a.SwitchNodeVersion(version)
+			// 由外部启动参数触发的切换同样记录审计
+			// Switches triggered by launch arguments are audited as well
+			outcome := "failed"
+			if strings.HasPrefix(result, "Successfully") {
+				outcome = "success"
+			}
+			a.audit("launch-switch", version, outcome)
+			runtime.EventsEmit(a.ctx, "version-switched", result)
+		case argOpenNvmrc:
+			if i+1 >= len(args) {
+				a.logToFile("Ignoring --open-nvmrc without a file")
+				continue
+			}
+			i++
+			a.openNvmrc(args[i])
+		case argSafeMode, argRecordCorpus, argMockBackend:
+			// 已在启动时处理
+			// Already handled at startup
+		case argWaitForExit:
+			i++
+		default:
+			// 通过“打开方式”选择本程序时只传入文件路径
+			// Choosing this app through "Open with" passes just the file path
+			if strings.EqualFold(filepath.Base(args[i]), ".nvmrc") {
+				a.openNvmrc(args[i])
+				continue
+			}
+			fmt.Printf("Debug: Ignoring unknown argument %q\n", args[i])
+		}
+	}
+}
+
+// showWindow brings the main window to the front
+// showWindow 将主窗口显示到最前面
+func (a *App) showWindow() {
+	if a.ctx == nil {
+		return
+	}
+	runtime.WindowUnminimise(a.ctx)
+	runtime.WindowShow(a.ctx)
+	a.MoveToActiveMonitor()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultLocalAPIPort is the loopback port used when none is configured
+// defaultLocalAPIPort 是未配置端口时使用的本地回环端口
+const defaultLocalAPIPort = 18989
+
+// LocalAPIConfig controls the optional loopback HTTP API
+// LocalAPIConfig 控制可选的本地回环 HTTP 接口
+type LocalAPIConfig struct {
+	Enabled bool `json:"enabled"`
+	Port    int  `json:"port"`
+
+	// AllowedOrigins are browser origins, besides the API's own loopback address, allowed to call the API
+	// AllowedOrigins 是除接口自身回环地址外允许调用接口的浏览器来源
+	AllowedOrigins []string `json:"allowedOrigins"`
+}
+
+// localAPIAddr returns the listen address, always bound to loopback
+// localAPIAddr 返回监听地址，始终只绑定本地回环
+func (c LocalAPIConfig) localAPIAddr() string {
+	port := c.Port
+	if port <= 0 {
+		port = defaultLocalAPIPort
+	}
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
+// localAPIHandler builds the routes served by the local API
+// localAPIHandler 构建本地接口提供的路由
+func (a *App) localAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/installed", a.guardLocalAPI(APIScopeRead, func(w http.ResponseWriter, r *http.Request) {
+		versions, err := a.GetInstalledNodeVersions()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(versions)
+	}))
+
+	// 外部工具使用应用中显示的一次性代码配对
+	// External tools pair using the one-time code shown in the app
+	mux.HandleFunc("/api/pair", a.guardLocalAPI(apiScopePair, a.servePairing))
+
+	// 切换和安装会被审计，并遵循与界面相同的确认策略
+	// Switch and install are audited and follow the same confirmation policy as the UI
+	mux.HandleFunc("/api/switch", a.guardLocalAPI(APIScopeManage, a.serveVersionOperation(OperationSwitch)))
+	mux.HandleFunc("/api/install", a.guardLocalAPI(APIScopeManage, a.serveVersionOperation(OperationInstall)))
+
+	// 本地自动化工具可订阅切换、安装等事件，例如在版本变化时重启语言服务器
+	// Local automation tools can subscribe to switch/install events, e.g. to restart language servers on version changes
+	mux.HandleFunc("/api/events", a.guardLocalAPI(APIScopeRead, a.serveEventStream))
+
+	mux.HandleFunc("/metrics", a.guardLocalAPI(APIScopeRead, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		a.writeMetrics(w)
+	}))
+
+	return mux
+}
+
+// restartLocalAPI stops any running local API server and starts a new one if enabled
+// restartLocalAPI 停止正在运行的本地接口服务，并在启用时重新启动
+func (a *App) restartLocalAPI() {
+	a.apiMu.Lock()
+	defer a.apiMu.Unlock()
+
+	if a.apiServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		if err := a.apiServer.Shutdown(shutdownCtx); err != nil {
+			// 事件流连接不会自行空闲，超时后强制关闭
+			// Event stream connections never go idle, so close them once the timeout passes
+			a.apiServer.Close()
+		}
+		cancel()
+		a.apiServer = nil
+	}
+
+	cfg := a.currentSettings().LocalAPI
+	if !cfg.Enabled || a.safeMode || a.ctx == nil || a.ctx.Err() != nil {
+		return
+	}
+
+	listener, err := net.Listen("tcp", cfg.localAPIAddr())
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error starting local API: %v", err))
+		return
+	}
+
+	server := &http.Server{
+		Handler:           a.localAPIHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	a.apiServer = server
+	a.logToFile(fmt.Sprintf("Local API listening on %s", listener.Addr()))
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			a.logToFile(fmt.Sprintf("Local API stopped: %v", err))
+		}
+	}()
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretLocalAPIToken is the secret holding the local API bearer token
+// SecretLocalAPIToken 是保存本地接口访问令牌的敏感信息名称
+const SecretLocalAPIToken = "localapi.token"
+
+// localAPITokenMaxAge is how long a local API token is used before it is rotated automatically
+// localAPITokenMaxAge 是本地接口令牌自动轮换前的使用期限
+const localAPITokenMaxAge = 30 * 24 * time.Hour
+
+// Local API request classes with their per-minute limits
+// 本地接口请求类别及其每分钟请求上限
+const (
+	APIScopeRead   = "read"
+	APIScopeManage = "manage"
+	apiScopePair   = "pair"
+
+	localAPIReadLimit   = 120
+	localAPIManageLimit = 10
+)
+
+// localAPIToken is the stored bearer token with the time it was issued
+// localAPIToken 是保存的访问令牌及其签发时间
+type localAPIToken struct {
+	Token     string    `json:"token"`
+	RotatedAt time.Time `json:"rotatedAt"`
+}
+
+// rateWindow counts the requests of one class in the current minute
+// rateWindow 统计某类请求在当前一分钟内的次数
+type rateWindow struct {
+	start    time.Time
+	count    int
+	reported bool
+}
+
+// rateLimiter is a fixed one-minute window limiter keyed by request class and remote address
+// rateLimiter 是按请求类别和远程地址计数的固定一分钟窗口限流器
+type rateLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+// allow counts a request against limit; firstReject is true for the first rejected request of a window
+// allow 按 limit 计数一次请求；firstReject 表示该请求是窗口内第一次被拒绝
+func (l *rateLimiter) allow(key string, limit int) (ok bool, firstReject bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.windows == nil {
+		l.windows = map[string]*rateWindow{}
+	}
+	now := time.Now()
+	w, found := l.windows[key]
+	if !found || now.Sub(w.start) >= time.Minute {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= limit {
+		first := !w.reported
+		w.reported = true
+		return false, first, w.start.Add(time.Minute).Sub(now)
+	}
+	w.count++
+	return true, false, 0
+}
+
+// blocked reports whether key has used up limit in the current window, without counting a request;
+// firstReject is as in allow
+// blocked 判断 key 在当前窗口内是否已用完 limit，不计入请求次数；firstReject 与 allow 中的含义相同
+func (l *rateLimiter) blocked(key string, limit int) (blocked bool, firstReject bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	w, found := l.windows[key]
+	if !found || now.Sub(w.start) >= time.Minute || w.count < limit {
+		return false, false, 0
+	}
+	first := !w.reported
+	w.reported = true
+	return true, first, w.start.Add(time.Minute).Sub(now)
+}
+
+// limiterKey keys the rate limit of a request class by the remote address, so one client cannot use up
+// the budget of the others
+// limiterKey 以远程地址区分请求类别的限流，使单个客户端不会耗尽其他客户端的额度
+func limiterKey(class string, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return class + " " + host
+}
+
+// tooManyRequests answers a request rejected by the rate limit
+// tooManyRequests 响应被限流拒绝的请求
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+	http.Error(w, "too many requests", http.StatusTooManyRequests)
+}
+
+// newAPIToken returns a random bearer token
+// newAPIToken 生成随机访问令牌
+func newAPIToken() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// rotateLocalAPIToken issues a new local API token, invalidating the previous one
+// rotateLocalAPIToken 签发新的本地接口令牌，旧令牌随即失效
+func (a *App) rotateLocalAPIToken() (localAPIToken, error) {
+	token := localAPIToken{Token: newAPIToken(), RotatedAt: a.clock.Now()}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return localAPIToken{}, err
+	}
+	if err := a.setSecret(SecretLocalAPIToken, string(data)); err != nil {
+		a.audit("localapi-token-rotate", "", "failed")
+		return localAPIToken{}, fmt.Errorf("Error storing local API token: %v", err)
+	}
+	a.apiTokenMu.Lock()
+	a.apiToken = token
+	a.apiTokenMu.Unlock()
+	a.audit("localapi-token-rotate", "", "success")
+	return token, nil
+}
+
+// currentLocalAPIToken returns the local API token, issuing a new one when none exists or it is too old
+// currentLocalAPIToken 返回本地接口令牌，不存在或过期时签发新令牌
+func (a *App) currentLocalAPIToken() (localAPIToken, error) {
+	a.apiTokenMu.Lock()
+	token := a.apiToken
+	a.apiTokenMu.Unlock()
+
+	if token.Token == "" {
+		stored, err := a.getSecret(SecretLocalAPIToken)
+		if err != nil {
+			return localAPIToken{}, err
+		}
+		if stored != "" {
+			json.Unmarshal([]byte(stored), &token)
+		}
+	}
+	if token.Token == "" || a.clock.Now().Sub(token.RotatedAt) > localAPITokenMaxAge {
+		return a.rotateLocalAPIToken()
+	}
+
+	a.apiTokenMu.Lock()
+	a.apiToken = token
+	a.apiTokenMu.Unlock()
+	return token, nil
+}
+
+// GetLocalAPIToken returns the bearer token scripts must send to the local API
+// GetLocalAPIToken 返回脚本访问本地接口时必须携带的令牌
+func (a *App) GetLocalAPIToken() (string, error) {
+	token, err := a.currentLocalAPIToken()
+	return token.Token, err
+}
+
+// RotateLocalAPIToken replaces the local API token, locking out every script using the old one
+// RotateLocalAPIToken 更换本地接口令牌，使用旧令牌的脚本将无法再访问
+func (a *App) RotateLocalAPIToken() (string, error) {
+	token, err := a.rotateLocalAPIToken()
+	return token.Token, err
+}
+
+// loopbackHost reports whether host (without port) names the loopback interface
+// loopbackHost 判断 host（不含端口）是否为本地回环地址
+func loopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkLocalAPIOrigin rejects requests addressed to another host name, which defeats DNS rebinding,
+// and browser requests from origins that are not explicitly allowed
+// checkLocalAPIOrigin 拒绝发往其他主机名的请求以防御 DNS 重绑定，并拒绝未明确允许的来源发起的浏览器请求
+func checkLocalAPIOrigin(r *http.Request, cfg LocalAPIConfig) error {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil || !loopbackHost(host) {
+		return fmt.Errorf("host %q is not allowed", r.Host)
+	}
+	if p, _ := strconv.Atoi(port); cfg.Port > 0 && p != cfg.Port {
+		return fmt.Errorf("host %q is not allowed", r.Host)
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// 命令行工具不发送 Origin
+		// Command line tools send no Origin
+		return nil
+	}
+	for _, allowed := range cfg.AllowedOrigins {
+		if strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return nil
+		}
+	}
+	if u, err := url.Parse(origin); err == nil && loopbackHost(u.Hostname()) && u.Port() == port {
+		return nil
+	}
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+// requestToken reads the bearer token from the Authorization header, or from ?token= for EventSource clients
+// requestToken 从 Authorization 请求头读取令牌，EventSource 客户端可使用 ?token= 传递
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return r.URL.Query().Get("token")
+}
+
+// guardLocalAPI wraps a handler with the origin check, token check and the rate limit of its class.
+// The owner token from GetLocalAPIToken may do everything, paired tools only what their scope allows
+// guardLocalAPI 为处理函数加上来源检查、令牌检查及其类别的限流。
+// GetLocalAPIToken 返回的所有者令牌拥有全部权限，已配对的工具只能执行其权限范围内的操作
+func (a *App) guardLocalAPI(scope string, next http.HandlerFunc) http.HandlerFunc {
+	limit := localAPIReadLimit
+	if scope == APIScopeManage || scope == apiScopePair {
+		limit = localAPIManageLimit
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := a.currentSettings().LocalAPI
+		if err := checkLocalAPIOrigin(r, cfg); err != nil {
+			a.logToFile(fmt.Sprintf("Local API rejected %s %s: %v", r.Method, r.URL.Path, err))
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		caller := "owner"
+		if scope != apiScopePair {
+			token, err := a.currentLocalAPIToken()
+			if err != nil {
+				http.Error(w, "token unavailable", http.StatusInternalServerError)
+				return
+			}
+			// 用完无效令牌额度的客户端在窗口结束前不再比较令牌，猜中也无效
+			// A client out of invalid token attempts is not compared until the window ends, so a lucky guess fails too
+			unauthorized := limiterKey("unauthorized", r)
+			if blocked, first, retryAfter := a.apiLimiter.blocked(unauthorized, localAPIManageLimit); blocked {
+				if first {
+					a.audit("localapi-reject", fmt.Sprintf("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr), "invalid token")
+				}
+				tooManyRequests(w, retryAfter)
+				return
+			}
+			presented := requestToken(r)
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(token.Token)) != 1 {
+				app, paired := a.connectedAppForToken(presented)
+				if !paired {
+					// 无效令牌同样计入限流，防止暴力猜测
+					// Invalid tokens count against the limit too, to slow down guessing
+					ok, first, retryAfter := a.apiLimiter.allow(unauthorized, localAPIManageLimit)
+					if first {
+						a.audit("localapi-reject", fmt.Sprintf("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr), "invalid token")
+					}
+					if !ok {
+						tooManyRequests(w, retryAfter)
+						return
+					}
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				if scope == APIScopeManage && app.Scope != APIScopeManage {
+					a.audit("localapi-reject", fmt.Sprintf("%s %s by %s", r.Method, r.URL.Path, app.Name), "read-only token")
+					http.Error(w, "token is read-only", http.StatusForbidden)
+					return
+				}
+				caller = app.Name
+			}
+		}
+
+		ok, first, retryAfter := a.apiLimiter.allow(limiterKey(scope, r), limit)
+		if !ok {
+			if first {
+				a.audit("localapi-rate-limit", fmt.Sprintf("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr), "rejected")
+			}
+			tooManyRequests(w, retryAfter)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), apiCallerKey{}, caller)))
+	}
+}
+
+// apiCallerKey stores the name of the authenticated caller in the request context
+// apiCallerKey 在请求上下文中保存已认证调用方的名称
+type apiCallerKey struct{}
+
+// apiCaller returns the authenticated caller of a local API request
+// apiCaller 返回本地接口请求的已认证调用方
+func apiCaller(r *http.Request) string {
+	caller, _ := r.Context().Value(apiCallerKey{}).(string)
+	return caller
+}
+
+// serveVersionOperation handles POST requests running switch or install for {"version": "..."},
+// auditing every call; the confirmation policy applies as in the UI
+// serveVersionOperation 处理以 {"version": "..."} 请求切换或安装的 POST 请求，并审计每次调用；
+// 确认策略与界面中一样生效
+func (a *App) serveVersionOperation(operation string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var body struct {
+			Version string `json:"version"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil || strings.TrimSpace(body.Version) == "" {
+			http.Error(w, "a JSON body with a version is required", http.StatusBadRequest)
+			return
+		}
+		version := strings.TrimPrefix(strings.TrimSpace(body.Version), "v")
+
+		var message string
+		switch operation {
+		case OperationSwitch:
+			message = a.SwitchNodeVersion(version)
+		case OperationInstall:
+			message = a.InstallNodeVersion(version)
+		}
+		outcome := "failed"
+		switch {
+		case strings.HasPrefix(message, "Successfully"):
+			outcome = "success"
+		case strings.HasPrefix(message, "Confirmation required"):
+			outcome = "pending confirmation"
+		}
+		a.audit("api-"+operation, fmt.Sprintf("%s by %s", version, apiCaller(r)), outcome)
+
+		w.Header().Set("Content-Type", "application/json")
+		if outcome == "failed" {
+			w.WriteHeader(http.StatusConflict)
+		} else if outcome == "pending confirmation" {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"message": message})
+	}
+}
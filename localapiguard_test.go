@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimiterPerRemoteAddress(t *testing.T) {
+	var l rateLimiter
+	first := httptest.NewRequest("GET", "/versions", nil)
+	first.RemoteAddr = "127.0.0.1:50001"
+	other := httptest.NewRequest("GET", "/versions", nil)
+	other.RemoteAddr = "[::1]:50002"
+	sameHost := httptest.NewRequest("GET", "/versions", nil)
+	sameHost.RemoteAddr = "127.0.0.1:50003"
+
+	key := limiterKey("unauthorized", first)
+	if key != limiterKey("unauthorized", sameHost) {
+		t.Errorf("connections from the same host got different keys")
+	}
+	for i := 0; i < 3; i++ {
+		if blocked, _, _ := l.blocked(key, 3); blocked {
+			t.Fatalf("blocked after %d of 3 requests", i)
+		}
+		if ok, _, _ := l.allow(key, 3); !ok {
+			t.Fatalf("request %d of 3 rejected", i+1)
+		}
+	}
+	blocked, firstReject, retryAfter := l.blocked(key, 3)
+	if !blocked || !firstReject || retryAfter <= 0 {
+		t.Errorf("blocked = (%v, %v, %v), want the first rejection with a retry delay", blocked, firstReject, retryAfter)
+	}
+	if _, firstReject, _ := l.blocked(key, 3); firstReject {
+		t.Errorf("the second rejection was reported as the first")
+	}
+	if blocked, _, _ := l.blocked(limiterKey("unauthorized", other), 3); blocked {
+		t.Errorf("another address was blocked")
+	}
+	if ok, _, _ := l.allow(limiterKey(APIScopeRead, first), 3); !ok {
+		t.Errorf("another request class was blocked")
+	}
+}
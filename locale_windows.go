@@ -0,0 +1,19 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetUserDefaultLocaleName = modkernel32.NewProc("GetUserDefaultLocaleName")
+
+// systemLocale returns the Windows user locale, e.g. "zh-CN"
+// systemLocale 返回 Windows 用户区域设置，例如 "zh-CN"
+func systemLocale() string {
+	buf := make([]uint16, 85)
+	n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
@@ -21,6 +21,10 @@ var assets embed.FS
 //go:embed build/trayicon.ico
 var trayIcon []byte
 
+// appTitle is the main window title, also used to locate the window handle
+// appTitle 是主窗口标题，同时用于查找窗口句柄
+const appTitle = "Node Version Switcher"
+
 // AppState struct is used to manage global state
 // AppState 结构体用于管理全局状态
 type AppState struct {
@@ -44,13 +48,16 @@ func NewAppState() *AppState {
 func main() {
 	// Initialize the App
 	// 初始化 App
-	state.app = NewApp()
+	flags := parseStartupFlags(os.Args[1:])
+	if flags.mockBackend {
+		state.app = newAppWithDependencies(mockDependencies())
+	} else {
+		state.app = NewApp()
+	}
 
-	// Start the system tray in a separate goroutine
-	// 启动托盘图标，运行在一个独立的 goroutine 中
-	go func() {
-		runSystray()
-	}()
+	// Handle startup-only flags such as --safe-mode
+	// 处理 --safe-mode 等仅在启动时使用的参数
+	state.app.applyStartupFlags(flags)
 
 	// Set debug mode if environment variable is set
 	// 如果环境变量设置了DEBUG，则进入调试模式
@@ -70,7 +77,7 @@ func main() {
 	// Run the Wails application
 	// 运行 Wails 应用程序
 	err := wails.Run(&options.App{
-		Title:            "Node Version Switcher",
+		Title:            appTitle,
 		Width:            1024,
 		Height:           768,
 		MinWidth:         1024,
@@ -79,6 +86,7 @@ func main() {
 		MaxHeight:        2160,
 		DisableResize:    false,
 		Fullscreen:       false,
+		Frameless:        state.app.currentSettings().Frameless,
 		WindowStartState: options.Normal,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
@@ -89,57 +97,23 @@ func main() {
 			state.ctx = ctx // 存储 Wails 提供的上下文以便托盘操作使用
 			fmt.Println("Debug: Application starting up")
 
-			// Display dialog for logging setup if not in debug mode and no existing log file
-			// 如果不在调试模式且没有现存日志文件，显示日志设置对话框
-			if !existingLogFile && !state.app.debugMode {
-				fmt.Println("Debug: Not in debug mode and no existing log file, showing dialog")
-
-				dialogComplete := make(chan bool)
-
-				go func() {
-					result, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
-						Type:          runtime.QuestionDialog,
-						Title:         "日志设置",
-						Message:       "是否启用应用程序日志记录？",
-						Buttons:       []string{"Yes", "No"},
-						DefaultButton: "No",
-						CancelButton:  "No",
-					})
-
-					if err != nil {
-						fmt.Printf("Debug: Error showing dialog: %v\n", err)
-						dialogComplete <- true
-						return
-					}
-
-					fmt.Printf("Debug: Dialog result: %s\n", result)
-
-					if result == "Yes" {
-						fmt.Println("Debug: User selected 'Yes', enabling logs")
-						state.app.enableLogs = true
-						fmt.Println("Debug: Logging has been enabled")
-					} else {
-						fmt.Println("Debug: User selected 'No', logs will be disabled")
-					}
-
-					dialogComplete <- true
-				}()
-
-				<-dialogComplete
-			} else if existingLogFile || state.app.debugMode {
-				state.app.enableLogs = true
-				fmt.Println("Debug: Logging automatically enabled due to existing log file or debug mode")
-			}
-
-			fmt.Printf("Debug: Final logging state - enableLogs: %v, debugMode: %v\n",
-				state.app.enableLogs, state.app.debugMode)
-
+			// 日志设置对话框异步显示，不阻塞启动
+			// The logging setup dialog is shown asynchronously so it never blocks startup
+			state.app.promptLoggingSetup(ctx, existingLogFile)
 			if state.app.enableLogs {
-				fmt.Println("Debug: Attempting to create initial log entry")
 				state.app.logToFile("Application startup initiated")
 			}
 
 			state.app.startup(ctx)
+
+			// 处理通过跳转列表等方式传入的启动参数
+			// Handle launch arguments passed e.g. by jump list entries
+			go state.app.handleLaunchArgs(os.Args[1:])
+		},
+		// 前端加载完成、窗口已显示后再初始化较重的子系统
+		// Initialize the heavier subsystems once the frontend has loaded and the window is visible
+		OnDomReady: func(ctx context.Context) {
+			go state.app.runDeferredStartup(ctx)
 		},
 		OnShutdown: state.app.shutdown,
 		OnBeforeClose: func(ctx context.Context) bool {
@@ -148,6 +122,14 @@ func main() {
 		Bind: []interface{}{
 			state.app,
 		},
+		// 跳转列表条目会再次启动程序，将其参数转交给正在运行的实例
+		// Jump list entries relaunch the executable; forward their arguments to the running instance
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId: "node-version-switcher-3f0c8e52",
+			OnSecondInstanceLaunch: func(data options.SecondInstanceData) {
+				go state.app.handleLaunchArgs(data.Args)
+			},
+		},
 		Windows: &windows.Options{
 			WebviewIsTransparent: false,
 			WindowIsTranslucent:  false,
@@ -180,9 +162,19 @@ func onReady() {
 		systray.SetTemplateIcon(trayIcon, trayIcon)
 		systray.SetTitle("Node Version Switcher")
 		systray.SetTooltip("nvm可视化")
+		go state.app.refreshTrayTooltip()
 		blog := systray.AddMenuItem("博客", "Blog")
 		github := systray.AddMenuItem("Github", "Github")
 		mShow := systray.AddMenuItem("显示应用", "mShow")
+		// 最近使用的版本，点击即切换，便于在常用的几个版本之间来回切换
+		// Recently used versions, clicking switches to quickly alternate between them
+		state.app.addRecentTrayItems()
+		// 已安装版本的快捷列表，点击时执行默认操作
+		// Quick list of installed versions, clicking runs the default action
+		state.app.addVersionTrayItems()
+		// 插件注册的托盘操作
+		// Tray actions registered by plugins
+		state.app.addPluginTrayItems()
 		mQuit := systray.AddMenuItem("退出", "Quit")
 		for {
 			select {
@@ -196,6 +188,9 @@ func onReady() {
 				fmt.Println("Debug: User clicked 'Show Application'")
 				if state.ctx != nil {
 					runtime.WindowShow(state.ctx)
+					// 避免窗口出现在已断开的显示器上
+					// Keep the window from appearing on a disconnected monitor
+					state.app.MoveToActiveMonitor()
 					fmt.Println("Debug: Application window shown successfully")
 				} else {
 					fmt.Println("Debug: Context is nil, cannot show window")
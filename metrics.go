@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// gaugeRefreshInterval limits how often expensive gauges (nvm ls, disk walk) are recomputed
+// gaugeRefreshInterval 限制开销较大的指标（nvm ls、磁盘遍历）的重新计算频率
+const gaugeRefreshInterval = time.Minute
+
+// Metrics holds the counters and cached gauges exposed on /metrics
+// Metrics 保存 /metrics 暴露的计数器和缓存的测量值
+type Metrics struct {
+	installsSucceeded uint64
+	installsFailed    uint64
+	switchesSucceeded uint64
+	switchesFailed    uint64
+	errors            uint64
+	downloadBytes     uint64
+	bandwidth         bandwidthLedger
+
+	gaugeMu         sync.Mutex
+	gaugesUpdated   time.Time
+	installedCount  int
+	diskUsageBytes  int64
+	currentVersion  string
+	gaugeRefreshing bool
+}
+
+// countingReader counts bytes read through it into the download metric and the bandwidth of the host
+// countingReader 统计读取的字节数，计入下载指标及对应主机的流量
+type countingReader struct {
+	r       io.Reader
+	metrics *Metrics
+	host    string
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddUint64(&c.metrics.downloadBytes, uint64(n))
+	c.metrics.bandwidth.add(c.host, int64(n))
+	if err == io.EOF {
+		c.metrics.bandwidth.flush()
+	}
+	return n, err
+}
+
+// recordInstall records the outcome of an install
+// recordInstall 记录一次安装的结果
+func (m *Metrics) recordInstall(success bool) {
+	if success {
+		atomic.AddUint64(&m.installsSucceeded, 1)
+	} else {
+		atomic.AddUint64(&m.installsFailed, 1)
+	}
+}
+
+// recordSwitch records the outcome of a version switch
+// recordSwitch 记录一次版本切换的结果
+func (m *Metrics) recordSwitch(success bool) {
+	if success {
+		atomic.AddUint64(&m.switchesSucceeded, 1)
+	} else {
+		atomic.AddUint64(&m.switchesFailed, 1)
+	}
+}
+
+// recordError increments the error counter
+// recordError 递增错误计数器
+func (m *Metrics) recordError() {
+	atomic.AddUint64(&m.errors, 1)
+}
+
+// refreshGauges recomputes the installed version count and disk usage when they are stale
+// refreshGauges 在测量值过期时重新计算已安装版本数量和磁盘占用
+func (a *App) refreshGauges() {
+	m := a.metrics
+	m.gaugeMu.Lock()
+	if m.gaugeRefreshing || a.clock.Now().Sub(m.gaugesUpdated) < gaugeRefreshInterval {
+		m.gaugeMu.Unlock()
+		return
+	}
+	m.gaugeRefreshing = true
+	m.gaugeMu.Unlock()
+
+	versions, err := a.GetInstalledNodeVersions()
+	root := a.nvmRoot()
+
+	var diskUsage int64
+	current := ""
+	for _, v := range versions {
+		a.yieldToInteractive()
+		if root != "" {
+			diskUsage += dirSize(versionDir(root, v.Version))
+		}
+		if v.IsCurrent {
+			current = v.Version
+		}
+	}
+
+	m.gaugeMu.Lock()
+	if err == nil {
+		m.installedCount = len(versions)
+		m.diskUsageBytes = diskUsage
+		m.currentVersion = current
+	}
+	m.gaugesUpdated = a.clock.Now()
+	m.gaugeRefreshing = false
+	m.gaugeMu.Unlock()
+}
+
+// writeMetrics renders all metrics in the Prometheus text exposition format
+// writeMetrics 以 Prometheus 文本格式输出所有指标
+func (a *App) writeMetrics(w io.Writer) {
+	a.refreshGauges()
+	m := a.metrics
+
+	var b strings.Builder
+	writeMetric := func(name, help, kind string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, s)
+		}
+	}
+
+	writeMetric("nvs_installs_total", "Node.js install operations by result.", "counter",
+		fmt.Sprintf(`{result="success"} %d`, atomic.LoadUint64(&m.installsSucceeded)),
+		fmt.Sprintf(`{result="failure"} %d`, atomic.LoadUint64(&m.installsFailed)))
+	writeMetric("nvs_switches_total", "Node.js version switches by result.", "counter",
+		fmt.Sprintf(`{result="success"} %d`, atomic.LoadUint64(&m.switchesSucceeded)),
+		fmt.Sprintf(`{result="failure"} %d`, atomic.LoadUint64(&m.switchesFailed)))
+	writeMetric("nvs_errors_total", "Failed nvm commands and network requests.", "counter",
+		fmt.Sprintf(" %d", atomic.LoadUint64(&m.errors)))
+	writeMetric("nvs_download_bytes_total", "Bytes downloaded by the switcher.", "counter",
+		fmt.Sprintf(" %d", atomic.LoadUint64(&m.downloadBytes)))
+
+	m.gaugeMu.Lock()
+	installed, disk, current := m.installedCount, m.diskUsageBytes, m.currentVersion
+	m.gaugeMu.Unlock()
+
+	writeMetric("nvs_installed_versions", "Number of installed Node.js versions.", "gauge",
+		fmt.Sprintf(" %d", installed))
+	writeMetric("nvs_disk_usage_bytes", "Disk space used by installed Node.js versions.", "gauge",
+		fmt.Sprintf(" %d", disk))
+	writeMetric("nvs_current_version_info", "Currently active Node.js version.", "gauge",
+		fmt.Sprintf(`{version="%s"} 1`, current))
+
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockRoot is the nvm root reported by the mock backend
+// mockRoot 是模拟后端报告的 nvm 根目录
+const mockRoot = `C:\nvm-mock`
+
+// mockReleases are the fabricated releases served by the mock backend: version, date, npm version, LTS codename
+// mockReleases 是模拟后端提供的虚构发布版本：版本号、日期、npm 版本、LTS 代号
+var mockReleases = [][4]string{
+	{"23.3.0", "2024-11-20", "10.9.0", ""},
+	{"22.11.0", "2024-10-29", "10.9.0", "Jod"},
+	{"22.10.0", "2024-10-16", "10.9.0", ""},
+	{"21.7.3", "2024-04-10", "10.5.0", ""},
+	{"20.18.1", "2024-11-20", "10.8.2", "Iron"},
+	{"20.18.0", "2024-10-03", "10.8.2", "Iron"},
+	{"18.20.5", "2024-11-12", "10.8.2", "Hydrogen"},
+	{"18.20.4", "2024-07-08", "10.7.0", "Hydrogen"},
+	{"16.20.2", "2023-08-08", "8.19.4", "Gallium"},
+	{"14.21.3", "2023-02-16", "6.14.18", "Fermium"},
+}
+
+// mockExecutor fabricates nvm output and scripts the outcome of install, use and uninstall
+// mockExecutor 虚构 nvm 的输出，并模拟安装、切换和卸载的结果
+type mockExecutor struct {
+	mu        sync.Mutex
+	installed map[string]bool
+	current   string
+}
+
+// newMockExecutor starts with a few versions installed and one in use
+// newMockExecutor 初始时已安装几个版本并使用其中一个
+func newMockExecutor() *mockExecutor {
+	return &mockExecutor{
+		installed: map[string]bool{"22.11.0": true, "20.18.0": true, "18.20.4": true},
+		current:   "20.18.0",
+	}
+}
+
+// mockKnown reports whether a version exists in the fabricated releases
+// mockKnown 判断版本是否存在于虚构的发布版本中
+func mockKnown(version string) bool {
+	for _, r := range mockReleases {
+		if r[0] == version {
+			return true
+		}
+	}
+	return false
+}
+
+// CombinedOutput implements Executor
+// CombinedOutput 实现 Executor 接口
+func (m *mockExecutor) CombinedOutput(spec CommandSpec) ([]byte, error) {
+	if spec.Name != "nvm" {
+		return nil, fmt.Errorf("mock backend: %s is not available", spec.Name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	args := spec.Args
+	if len(args) == 0 {
+		return []byte("Running version 1.1.12.\n"), nil
+	}
+	version := ""
+	if len(args) > 1 {
+		version = strings.TrimPrefix(args[1], "v")
+	}
+	// 模拟真实操作的耗时，便于观察加载状态
+	// Take a moment like the real operations so loading states are visible
+	switch args[0] {
+	case "install", "use", "uninstall":
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	switch args[0] {
+	case "version", "v":
+		return []byte("1.1.12\n"), nil
+	case "root":
+		return []byte("\nCurrent Root: " + mockRoot + "\n"), nil
+	case "ls", "list":
+		if version == "available" {
+			var b strings.Builder
+			b.WriteString("|   CURRENT    |     LTS      |  OLD STABLE  | OLD UNSTABLE |\n")
+			b.WriteString("|--------------|--------------|--------------|--------------|\n")
+			for _, r := range mockReleases {
+				fmt.Fprintf(&b, "|    %-9s |              |              |              |\n", r[0])
+			}
+			return []byte(b.String()), nil
+		}
+		var versions []string
+		for v := range m.installed {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return compareSemver(versions[i], versions[j]) > 0 })
+		var b strings.Builder
+		for _, v := range versions {
+			if v == m.current {
+				fmt.Fprintf(&b, "  * %s (Currently using 64-bit executable)\n", v)
+			} else {
+				fmt.Fprintf(&b, "    %s\n", v)
+			}
+		}
+		return []byte(b.String()), nil
+	case "install":
+		if !mockKnown(version) {
+			return []byte("Node.js v" + version + " is not available.\n"), fmt.Errorf("exit status 1")
+		}
+		m.installed[version] = true
+		return []byte(fmt.Sprintf("Downloading node.js version %s (64-bit)...\nExtracting node and npm...\nComplete\nInstallation complete. If you want to use this version, type\n\nnvm use %s\n", version, version)), nil
+	case "use":
+		if !m.installed[version] {
+			return []byte("node v" + version + " (64-bit) is not installed.\n"), fmt.Errorf("exit status 1")
+		}
+		m.current = version
+		return []byte(fmt.Sprintf("Now using node v%s (64-bit)\n", version)), nil
+	case "uninstall":
+		if !m.installed[version] {
+			return []byte("node v" + version + " is not installed.\n"), fmt.Errorf("exit status 1")
+		}
+		delete(m.installed, version)
+		if m.current == version {
+			m.current = ""
+		}
+		return []byte("Uninstalling node v" + version + "... done\n"), nil
+	}
+	return []byte(fmt.Sprintf("mock backend: nvm %s is not scripted\n", strings.Join(args, " "))), nil
+}
+
+// Run implements Executor, writing the fabricated output to stdout
+// Run 实现 Executor 接口，将虚构的输出写入标准输出
+func (m *mockExecutor) Run(_ context.Context, spec CommandSpec, stdout, _ io.Writer) error {
+	output, err := m.CombinedOutput(spec)
+	stdout.Write(output)
+	return err
+}
+
+// Start implements Executor; the mock backend never launches programs
+// Start 实现 Executor 接口；模拟后端不会启动任何程序
+func (m *mockExecutor) Start(spec CommandSpec) error {
+	return fmt.Errorf("mock backend: %s is not available", spec.Name)
+}
+
+// mockTransport answers index.json requests with the fabricated releases and refuses every other request
+// mockTransport 使用虚构的发布版本响应 index.json 请求，并拒绝其他所有请求
+type mockTransport struct{}
+
+// RoundTrip implements http.RoundTripper
+// RoundTrip 实现 http.RoundTripper 接口
+func (mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusNotFound, []byte("mock backend: offline")
+	if strings.HasSuffix(req.URL.Path, "/index.json") {
+		var index []NodeAPIResponse
+		for _, r := range mockReleases {
+			var lts interface{} = false
+			if r[3] != "" {
+				lts = r[3]
+			}
+			index = append(index, NodeAPIResponse{Version: "v" + r[0], Date: r[1], Npm: r[2], LTS: lts})
+		}
+		status = http.StatusOK
+		body, _ = json.Marshal(index)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// mockFS is an in-memory FileSystem, keeping the mock backend's settings off the disk
+// mockFS 是内存中的 FileSystem 实现，使模拟后端的设置不会写入磁盘
+type mockFS struct {
+	mu    sync.Mutex
+	Files map[string][]byte
+}
+
+// mockFileInfo describes a mockFS file
+// mockFileInfo 描述 mockFS 中的文件
+type mockFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i mockFileInfo) Name() string       { return i.name }
+func (i mockFileInfo) Size() int64        { return i.size }
+func (i mockFileInfo) ModTime() time.Time { return time.Time{} }
+func (i mockFileInfo) IsDir() bool        { return i.dir }
+func (i mockFileInfo) Sys() interface{}   { return nil }
+func (i mockFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// ReadFile implements FileSystem
+// ReadFile 实现 FileSystem 接口
+func (m *mockFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.Files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte{}, data...), nil
+}
+
+// WriteFile implements FileSystem
+// WriteFile 实现 FileSystem 接口
+func (m *mockFS) WriteFile(name string, data []byte, _ fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Files == nil {
+		m.Files = map[string][]byte{}
+	}
+	m.Files[name] = append([]byte{}, data...)
+	return nil
+}
+
+// MkdirAll implements FileSystem; directories exist implicitly
+// MkdirAll 实现 FileSystem 接口；目录隐式存在
+func (m *mockFS) MkdirAll(string, fs.FileMode) error {
+	return nil
+}
+
+// Rename implements FileSystem
+// Rename 实现 FileSystem 接口
+func (m *mockFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.Files[oldpath]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	m.Files[newpath] = data
+	delete(m.Files, oldpath)
+	return nil
+}
+
+// Stat implements FileSystem
+// Stat 实现 FileSystem 接口
+func (m *mockFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if data, ok := m.Files[name]; ok {
+		return mockFileInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	prefix := mockDirPrefix(name)
+	for key := range m.Files {
+		if strings.HasPrefix(key, prefix) {
+			return mockFileInfo{name: filepath.Base(name), dir: true}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// Remove implements FileSystem
+// Remove 实现 FileSystem 接口
+func (m *mockFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.Files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.Files, name)
+	return nil
+}
+
+// RemoveAll implements FileSystem
+// RemoveAll 实现 FileSystem 接口
+func (m *mockFS) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := mockDirPrefix(path)
+	for key := range m.Files {
+		if key == path || strings.HasPrefix(key, prefix) {
+			delete(m.Files, key)
+		}
+	}
+	return nil
+}
+
+// ReadDir implements FileSystem; directories are derived from the stored file paths
+// ReadDir 实现 FileSystem 接口；目录由已存储的文件路径推导得出
+func (m *mockFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := mockDirPrefix(name)
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for key, data := range m.Files {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		child, _, nested := strings.Cut(rest, string(filepath.Separator))
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := mockFileInfo{name: child, size: int64(len(data)), dir: nested}
+		if nested {
+			info.size = 0
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// AppendFile implements FileSystem
+// AppendFile 实现 FileSystem 接口
+func (m *mockFS) AppendFile(name string, data []byte, _ fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Files == nil {
+		m.Files = map[string][]byte{}
+	}
+	m.Files[name] = append(m.Files[name], data...)
+	return nil
+}
+
+// mockDirPrefix returns the key prefix shared by the files inside a mockFS directory
+// mockDirPrefix 返回 mockFS 目录内文件共有的键前缀
+func mockDirPrefix(dir string) string {
+	return strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+}
+
+// enterMockBackend marks an app created with mockDependencies as running the mock backend, so the UI can be
+// developed and demonstrated on a machine without nvm
+// enterMockBackend 将使用 mockDependencies 创建的应用标记为模拟后端，使界面可以在没有 nvm 的机器上开发和演示
+func (a *App) enterMockBackend() {
+	a.mockBackend = true
+	a.logToFile("Mock backend enabled, nvm and the network are simulated")
+}
+
+// mockDependencies returns the fabricated nvm, network and in-memory file system of the mock backend. The app
+// is created with them so not even the startup reads the real settings and data files
+// mockDependencies 返回模拟后端虚构的 nvm、网络和内存文件系统。应用使用它们创建，因此启动时也不会读取真实的设置和数据文件
+func mockDependencies() Dependencies {
+	return Dependencies{
+		Executor: newMockExecutor(),
+		Clock:    systemClock{},
+		// 所有 HTTP 客户端都由 a.client 复制而来，不会访问真实网络
+		// Every HTTP client is copied by a.client, so none reaches the real network
+		HTTPClient: &http.Client{Timeout: 30 * time.Second, Transport: mockTransport{}},
+		// 设置和数据文件只保存在内存中，不会读取或覆盖真实的文件
+		// Settings and data files are only kept in memory, the real files are neither read nor overwritten
+		FS: &mockFS{},
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMockBackendKeepsDataFilesInMemory(t *testing.T) {
+	a := newAppWithDependencies(mockDependencies())
+	a.enterMockBackend()
+	files := a.fs.(*mockFS)
+
+	if _, err := a.ScheduleOperation(JobInstall, "22.11.0", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ScheduleOperation: %v", err)
+	}
+	if _, err := files.ReadFile(scheduledJobsFilePath()); err != nil {
+		t.Errorf("scheduled jobs were not written to the mock file system: %v", err)
+	}
+	if dates := a.GetIndexSnapshots(); len(dates) != 0 {
+		t.Errorf("mock backend reported the real index snapshots %v", dates)
+	}
+	if root := a.nvmRoot(); root != mockRoot {
+		t.Errorf("nvmRoot = %q, want %q", root, mockRoot)
+	}
+}
+
+func TestMockBackendKeepsTheInjectedDependencies(t *testing.T) {
+	deps := mockDependencies()
+	a := newAppWithDependencies(deps)
+	a.enableLogs = true
+	a.enterMockBackend()
+
+	if a.fs != deps.FS || a.executor != deps.Executor {
+		t.Fatal("enterMockBackend replaced the dependencies the app was created with")
+	}
+	a.audit("switch", "20.18.0", "success")
+	files := a.fs.(*mockFS)
+	if _, err := files.ReadFile(a.auditPath); err != nil {
+		t.Errorf("audit entry was not written to the mock file system: %v", err)
+	}
+	if _, err := files.ReadFile(a.logFilePath); err != nil {
+		t.Errorf("log line was not written to the mock file system: %v", err)
+	}
+}
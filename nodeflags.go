@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Flag check statuses
+// 参数检查状态
+const (
+	FlagOK          = "ok"
+	FlagUnavailable = "unavailable"
+	FlagRemoved     = "removed"
+	FlagUnnecessary = "unnecessary"
+	FlagUnknown     = "unknown"
+)
+
+// nodeFlagRule describes when a Node.js CLI flag became available, became the default or was removed
+// nodeFlagRule 描述一个 Node.js 命令行参数何时可用、何时成为默认行为或何时被移除
+type nodeFlagRule struct {
+	addedIn     string
+	backports   []string // 向旧版本线回移的版本 / versions it was backported to on older lines
+	defaultIn   string   // 从该版本起无需此参数 / no longer needed from this version
+	removedIn   string
+	replacement string
+	note        string
+}
+
+// nodeFlagRules is the bundled compatibility table of commonly used flags
+// nodeFlagRules 是内置的常用参数兼容性表
+var nodeFlagRules = map[string]nodeFlagRule{
+	"--experimental-fetch":                {addedIn: "17.5.0", removedIn: "18.0.0", note: "fetch is enabled by default"},
+	"--no-experimental-fetch":             {addedIn: "18.0.0"},
+	"--openssl-legacy-provider":           {addedIn: "17.0.0", note: "only meaningful with OpenSSL 3 builds"},
+	"--experimental-modules":              {addedIn: "8.5.0", defaultIn: "12.17.0", note: "ES modules are enabled by default"},
+	"--experimental-specifier-resolution": {addedIn: "12.0.0", removedIn: "19.0.0", replacement: "a custom loader"},
+	"--experimental-worker":               {addedIn: "10.5.0", defaultIn: "11.7.0"},
+	"--experimental-report":               {addedIn: "11.8.0", defaultIn: "13.12.0", backports: []string{"12.17.0"}},
+	"--experimental-global-webcrypto":     {addedIn: "17.6.0", defaultIn: "19.0.0"},
+	"--experimental-websocket":            {addedIn: "21.0.0", defaultIn: "22.4.0"},
+	"--experimental-detect-module":        {addedIn: "21.1.0", defaultIn: "22.7.0", backports: []string{"20.10.0"}},
+	"--experimental-require-module":       {addedIn: "22.0.0", defaultIn: "23.0.0", backports: []string{"20.17.0"}},
+	"--experimental-strip-types":          {addedIn: "22.6.0", defaultIn: "23.6.0"},
+	"--experimental-permission":           {addedIn: "20.0.0", removedIn: "23.5.0", replacement: "--permission"},
+	"--permission":                        {addedIn: "23.5.0", backports: []string{"22.13.0"}},
+	"--experimental-loader":               {addedIn: "9.0.0"},
+	"--loader":                            {addedIn: "9.0.0"},
+	"--import":                            {addedIn: "19.0.0", backports: []string{"18.18.0"}},
+	"--experimental-vm-modules":           {addedIn: "9.6.0"},
+	"--experimental-wasm-modules":         {addedIn: "12.3.0"},
+	"--experimental-import-meta-resolve":  {addedIn: "13.9.0", backports: []string{"12.16.2"}},
+	"--experimental-test-coverage":        {addedIn: "19.7.0", backports: []string{"18.15.0"}},
+	"--experimental-sea-config":           {addedIn: "19.7.0", backports: []string{"18.16.0"}},
+	"--test":                              {addedIn: "18.1.0", backports: []string{"16.17.0"}},
+	"--watch":                             {addedIn: "18.11.0", backports: []string{"16.19.0"}},
+	"--env-file":                          {addedIn: "20.6.0"},
+	"--enable-source-maps":                {addedIn: "12.12.0"},
+	"--unhandled-rejections":              {addedIn: "12.0.0", backports: []string{"10.17.0"}},
+	"--inspect":                           {addedIn: "6.3.0"},
+	"--use-openssl-ca":                    {addedIn: "7.5.0", backports: []string{"6.11.0"}},
+	"--tls-min-v1.0":                      {addedIn: "11.4.0", backports: []string{"10.16.0"}},
+	"--max-old-space-size":                {addedIn: "0.10.0"},
+	"--no-deprecation":                    {addedIn: "0.8.0"},
+}
+
+// FlagCheckResult is the compatibility verdict for one flag
+// FlagCheckResult 表示单个参数的兼容性结论
+type FlagCheckResult struct {
+	Flag    string
+	Status  string
+	Message string
+}
+
+// flagAvailable reports whether version has the flag, considering backports to older release lines
+// flagAvailable 判断该版本是否支持此参数，同时考虑回移到旧版本线的情况
+func flagAvailable(rule nodeFlagRule, version string) bool {
+	if compareSemver(version, rule.addedIn) >= 0 {
+		return true
+	}
+	major := strings.Split(version, ".")[0]
+	for _, backport := range rule.backports {
+		if strings.Split(backport, ".")[0] == major && compareSemver(version, backport) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNodeFlag evaluates a single flag (with or without a value) against a version
+// checkNodeFlag 针对指定版本检查单个参数（可带值）
+func checkNodeFlag(version, flag string) FlagCheckResult {
+	name := flag
+	if idx := strings.Index(name, "="); idx != -1 {
+		name = name[:idx]
+	}
+	result := FlagCheckResult{Flag: flag}
+
+	rule, ok := nodeFlagRules[name]
+	if !ok {
+		result.Status = FlagUnknown
+		result.Message = fmt.Sprintf("%s is not in the bundled compatibility table", name)
+		return result
+	}
+
+	switch {
+	case rule.removedIn != "" && compareSemver(version, rule.removedIn) >= 0:
+		result.Status = FlagRemoved
+		result.Message = fmt.Sprintf("%s was removed in Node.js %s", name, rule.removedIn)
+		if rule.replacement != "" {
+			result.Message += fmt.Sprintf(", use %s instead", rule.replacement)
+		}
+	case !flagAvailable(rule, version):
+		result.Status = FlagUnavailable
+		result.Message = fmt.Sprintf("%s requires Node.js %s or later", name, rule.addedIn)
+	case rule.defaultIn != "" && compareSemver(version, rule.defaultIn) >= 0:
+		result.Status = FlagUnnecessary
+		result.Message = fmt.Sprintf("%s is not needed since Node.js %s", name, rule.defaultIn)
+	default:
+		result.Status = FlagOK
+		result.Message = fmt.Sprintf("%s is supported", name)
+	}
+	if rule.note != "" {
+		result.Message += " (" + rule.note + ")"
+	}
+	return result
+}
+
+// splitNodeOptions splits a NODE_OPTIONS style string into individual flags
+// splitNodeOptions 将 NODE_OPTIONS 风格的字符串拆分为单个参数
+func splitNodeOptions(options string) []string {
+	var flags []string
+	for _, field := range strings.Fields(options) {
+		if strings.HasPrefix(field, "-") {
+			flags = append(flags, field)
+		}
+	}
+	return flags
+}
+
+// CheckNodeFlags validates CLI flags against a target version; when flags is empty the current NODE_OPTIONS are checked
+// CheckNodeFlags 针对目标版本检查命令行参数；flags 为空时检查当前的 NODE_OPTIONS
+func (a *App) CheckNodeFlags(version string, flags []string) []FlagCheckResult {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if len(flags) == 0 {
+		flags = splitNodeOptions(os.Getenv("NODE_OPTIONS"))
+	}
+
+	results := make([]FlagCheckResult, 0, len(flags))
+	for _, flag := range flags {
+		results = append(results, checkNodeFlag(version, strings.TrimSpace(flag)))
+	}
+	return results
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cleanupUnusedAfter is how long a version must go unused before it is suggested for removal
+// cleanupUnusedAfter 是版本未被使用多久后才建议删除
+const cleanupUnusedAfter = 90 * 24 * time.Hour
+
+// CleanupSuggestion is an installed version that looks safe to uninstall. Versions with a note are
+// listed with Keep set instead of being suggested, so the user sees why they were left alone
+// CleanupSuggestion 是看起来可以安全卸载的已安装版本。带有备注的版本会设置 Keep 而不被建议删除，
+// 以便用户了解其被保留的原因
+type CleanupSuggestion struct {
+	Version  string
+	LastUsed time.Time
+	Reason   string
+	Note     string
+	Keep     bool
+}
+
+// SetVersionNote records why a version is installed, an empty note removes it
+// SetVersionNote 记录安装某个版本的原因，备注为空时删除该备注
+func (a *App) SetVersionNote(version, note string) error {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return fmt.Errorf("version is required")
+	}
+	note = strings.TrimSpace(note)
+	// 缓存的版本列表中带有备注
+	// The cached version list carries the notes
+	defer a.invalidateAvailableVersions()
+	return a.updateSettings(func(settings *Settings) {
+		notes := map[string]string{}
+		for v, n := range settings.VersionNotes {
+			notes[v] = n
+		}
+		if note == "" {
+			delete(notes, version)
+		} else {
+			notes[version] = note
+		}
+		settings.VersionNotes = notes
+	})
+}
+
+// GetVersionNotes returns the notes of all versions
+// GetVersionNotes 返回所有版本的备注
+func (a *App) GetVersionNotes() map[string]string {
+	return a.currentSettings().VersionNotes
+}
+
+// withNotes fills in the note of each listed version
+// withNotes 为列表中的每个版本填写备注
+func (a *App) withNotes(versions []NodeVersionInfo) []NodeVersionInfo {
+	notes := a.currentSettings().VersionNotes
+	for i := range versions {
+		versions[i].Note = notes[strings.TrimPrefix(versions[i].Version, "v")]
+	}
+	return versions
+}
+
+// GetCleanupSuggestions lists installed versions that are not current, not a favorite, not pinned by a registered
+// project, and neither switched to nor installed for a while; versions with a note are kept
+// GetCleanupSuggestions 列出非当前使用、未收藏、未被已登记项目固定，且长时间未切换使用也不是最近安装的已安装版本；
+// 带有备注的版本会被保留
+func (a *App) GetCleanupSuggestions() ([]CleanupSuggestion, error) {
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return nil, err
+	}
+	settings := a.currentSettings()
+
+	pinned := map[string]bool{}
+	for _, p := range a.GetProjects() {
+		if version, err := a.resolveProjectVersion(p); err == nil {
+			pinned[version] = true
+		}
+	}
+
+	root := a.nvmRoot()
+	var suggestions []CleanupSuggestion
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		if v.IsCurrent || v.Favorite || pinned[version] {
+			continue
+		}
+		lastUsed := settings.LastUsed[version]
+		if !lastUsed.IsZero() && a.clock.Now().Sub(lastUsed) < cleanupUnusedAfter {
+			continue
+		}
+		// 刚安装还没来得及使用的版本不建议删除
+		// A version installed recently and not used yet is not suggested
+		if root != "" {
+			if installedAt := fileCreationTime(versionDir(root, version)); !installedAt.IsZero() && a.clock.Now().Sub(installedAt) < cleanupUnusedAfter {
+				continue
+			}
+		}
+		s := CleanupSuggestion{Version: version, LastUsed: lastUsed, Reason: "未使用超过 90 天 / Not used for over 90 days"}
+		if lastUsed.IsZero() {
+			s.Reason = "从未通过本工具切换使用 / Never switched to with this app"
+		}
+		if note := settings.VersionNotes[version]; note != "" {
+			s.Note = note
+			s.Keep = true
+		}
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return compareSemver(suggestions[i].Version, suggestions[j].Version) < 0 })
+	return suggestions, nil
+}
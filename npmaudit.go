@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// npmAuditCacheTTL is how long an audit result is reused before running npm again
+// npmAuditCacheTTL 是审计结果在重新运行 npm 之前的复用时长
+const npmAuditCacheTTL = time.Hour
+
+// maxTopAdvisories limits the advisories returned in a summary
+// maxTopAdvisories 限制摘要中返回的安全公告数量
+const maxTopAdvisories = 10
+
+var severityRank = map[string]int{"critical": 5, "high": 4, "moderate": 3, "low": 2, "info": 1}
+
+// NpmAdvisory is a single vulnerability advisory
+// NpmAdvisory 表示一条漏洞安全公告
+type NpmAdvisory struct {
+	Package  string
+	Title    string
+	Severity string
+	URL      string
+	Range    string
+}
+
+// NpmAuditSummary is the severity summary of a project's npm audit
+// NpmAuditSummary 表示项目 npm audit 结果的严重程度摘要
+type NpmAuditSummary struct {
+	Project       string
+	NodeVersion   string
+	Counts        map[string]int
+	Total         int
+	TopAdvisories []NpmAdvisory
+	CheckedAt     time.Time
+	Cached        bool
+}
+
+// npmAuditReport covers both the npm 7+ and the legacy npm 6 JSON report formats
+// npmAuditReport 同时兼容 npm 7+ 和旧版 npm 6 的 JSON 报告格式
+type npmAuditReport struct {
+	Vulnerabilities map[string]struct {
+		Name     string            `json:"name"`
+		Severity string            `json:"severity"`
+		Range    string            `json:"range"`
+		Via      []json.RawMessage `json:"via"`
+	} `json:"vulnerabilities"`
+	Advisories map[string]struct {
+		ModuleName         string `json:"module_name"`
+		Title              string `json:"title"`
+		Severity           string `json:"severity"`
+		URL                string `json:"url"`
+		VulnerableVersions string `json:"vulnerable_versions"`
+	} `json:"advisories"`
+	Metadata struct {
+		Vulnerabilities map[string]int `json:"vulnerabilities"`
+	} `json:"metadata"`
+	Error *struct {
+		Summary string `json:"summary"`
+	} `json:"error"`
+}
+
+// parseNpmAudit converts an `npm audit --json` report into a summary
+// parseNpmAudit 将 `npm audit --json` 报告转换为摘要
+func parseNpmAudit(data []byte) (NpmAuditSummary, error) {
+	var report npmAuditReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return NpmAuditSummary{}, fmt.Errorf("Error parsing npm audit report: %v", err)
+	}
+	if report.Error != nil {
+		return NpmAuditSummary{}, fmt.Errorf("npm audit failed: %s", report.Error.Summary)
+	}
+
+	summary := NpmAuditSummary{Counts: map[string]int{}}
+	for severity, count := range report.Metadata.Vulnerabilities {
+		if severity == "total" {
+			summary.Total = count
+			continue
+		}
+		summary.Counts[severity] = count
+	}
+
+	var advisories []NpmAdvisory
+	seen := map[string]bool{}
+	for _, vuln := range report.Vulnerabilities {
+		for _, raw := range vuln.Via {
+			// "via" entries are either advisory objects or names of other vulnerable packages
+			// "via" 中的条目要么是公告对象，要么是其他有漏洞的包名
+			var via struct {
+				Title    string `json:"title"`
+				URL      string `json:"url"`
+				Severity string `json:"severity"`
+				Range    string `json:"range"`
+				Name     string `json:"name"`
+			}
+			if json.Unmarshal(raw, &via) != nil || via.Title == "" || seen[via.URL+via.Title] {
+				continue
+			}
+			seen[via.URL+via.Title] = true
+			advisories = append(advisories, NpmAdvisory{
+				Package:  via.Name,
+				Title:    via.Title,
+				Severity: via.Severity,
+				URL:      via.URL,
+				Range:    via.Range,
+			})
+		}
+	}
+	for _, adv := range report.Advisories {
+		advisories = append(advisories, NpmAdvisory{
+			Package:  adv.ModuleName,
+			Title:    adv.Title,
+			Severity: adv.Severity,
+			URL:      adv.URL,
+			Range:    adv.VulnerableVersions,
+		})
+	}
+
+	sort.SliceStable(advisories, func(i, j int) bool {
+		if severityRank[advisories[i].Severity] != severityRank[advisories[j].Severity] {
+			return severityRank[advisories[i].Severity] > severityRank[advisories[j].Severity]
+		}
+		return advisories[i].Package < advisories[j].Package
+	})
+	if len(advisories) > maxTopAdvisories {
+		advisories = advisories[:maxTopAdvisories]
+	}
+	summary.TopAdvisories = advisories
+	return summary, nil
+}
+
+// RunNpmAudit returns the npm audit summary of a registered project, reusing a recent result when available
+// RunNpmAudit 返回已登记项目的 npm audit 摘要，如有近期结果则直接复用
+func (a *App) RunNpmAudit(projectPath string) (NpmAuditSummary, error) {
+	a.auditCacheMu.Lock()
+	cached, ok := a.npmAuditCache[projectPath]
+	a.auditCacheMu.Unlock()
+	if ok && a.clock.Now().Sub(cached.CheckedAt) < npmAuditCacheTTL {
+		cached.Cached = true
+		return cached, nil
+	}
+	return a.RefreshNpmAudit(projectPath)
+}
+
+// RefreshNpmAudit runs npm audit for a registered project under its pinned Node.js version
+// RefreshNpmAudit 使用项目固定的 Node.js 版本为已登记项目运行 npm audit
+func (a *App) RefreshNpmAudit(projectPath string) (NpmAuditSummary, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return NpmAuditSummary{}, err
+	}
+	version, err := a.resolveProjectVersion(project)
+	if err != nil {
+		return NpmAuditSummary{}, err
+	}
+
+	a.logToFile(fmt.Sprintf("Running npm audit for %s with Node.js %s", project.Path, version))
+	// npm audit exits non-zero when vulnerabilities are found, so rely on the JSON output instead
+	// npm audit 在发现漏洞时会返回非零退出码，因此以 JSON 输出为准
+	output, runErr := a.runWithNodeVersion(version, project.Path, "npm.cmd", "audit", "--json")
+	summary, err := parseNpmAudit(output)
+	if err != nil {
+		if runErr != nil {
+			err = fmt.Errorf("%v (%v)", err, runErr)
+		}
+		a.logToFile(fmt.Sprintf("npm audit for %s failed: %v", project.Path, err))
+		return NpmAuditSummary{}, err
+	}
+
+	summary.Project = project.Path
+	summary.NodeVersion = version
+	summary.CheckedAt = a.clock.Now()
+
+	a.auditCacheMu.Lock()
+	a.npmAuditCache[projectPath] = summary
+	a.auditCacheMu.Unlock()
+	return summary, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OutdatedDependency is one row of the outdated dependency table
+// OutdatedDependency 表示过期依赖表中的一行
+type OutdatedDependency struct {
+	Package    string
+	Current    string
+	Wanted     string
+	Latest     string
+	Type       string // dependencies 或 devDependencies
+	Location   string
+	UpdateKind string // patch、minor、major 或 missing
+}
+
+// OutdatedReport is the outdated dependency overview of a project
+// OutdatedReport 表示项目的过期依赖概览
+type OutdatedReport struct {
+	Project      string
+	NodeVersion  string
+	Dependencies []OutdatedDependency
+}
+
+// classifyUpdate tells whether moving from current to latest is a patch, minor or major update
+// classifyUpdate 判断从 current 更新到 latest 属于补丁、次版本还是主版本更新
+func classifyUpdate(current, latest string) string {
+	if current == "" {
+		return "missing"
+	}
+	c := strings.Split(current, ".")
+	l := strings.Split(latest, ".")
+	if len(c) < 3 || len(l) < 3 {
+		return "major"
+	}
+	switch {
+	case c[0] != l[0]:
+		return "major"
+	case c[1] != l[1]:
+		return "minor"
+	default:
+		return "patch"
+	}
+}
+
+// parseNpmOutdated converts `npm outdated --json` output into table rows
+// parseNpmOutdated 将 `npm outdated --json` 的输出转换为表格行
+func parseNpmOutdated(data []byte) ([]OutdatedDependency, error) {
+	deps := []OutdatedDependency{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return deps, nil
+	}
+
+	// In workspaces npm reports an array per package name instead of a single object
+	// 在工作区中，npm 会为每个包名返回一个数组而不是单个对象
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Error parsing npm outdated output: %v", err)
+	}
+
+	type entry struct {
+		Current  string `json:"current"`
+		Wanted   string `json:"wanted"`
+		Latest   string `json:"latest"`
+		Type     string `json:"type"`
+		Location string `json:"location"`
+	}
+	for name, value := range raw {
+		var entries []entry
+		var single entry
+		if json.Unmarshal(value, &single) == nil {
+			entries = []entry{single}
+		} else if err := json.Unmarshal(value, &entries); err != nil {
+			continue
+		}
+		for _, e := range entries {
+			deps = append(deps, OutdatedDependency{
+				Package:    name,
+				Current:    e.Current,
+				Wanted:     e.Wanted,
+				Latest:     e.Latest,
+				Type:       e.Type,
+				Location:   e.Location,
+				UpdateKind: classifyUpdate(e.Current, e.Latest),
+			})
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Package < deps[j].Package
+	})
+	return deps, nil
+}
+
+// GetOutdatedDependencies lists the outdated dependencies of a registered project using its pinned Node.js version
+// GetOutdatedDependencies 使用项目固定的 Node.js 版本列出已登记项目的过期依赖
+func (a *App) GetOutdatedDependencies(projectPath string) (OutdatedReport, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return OutdatedReport{}, err
+	}
+	version, err := a.resolveProjectVersion(project)
+	if err != nil {
+		return OutdatedReport{}, err
+	}
+
+	a.logToFile(fmt.Sprintf("Running npm outdated for %s with Node.js %s", project.Path, version))
+	// npm outdated exits with code 1 whenever something is outdated
+	// 只要存在过期依赖，npm outdated 就会以退出码 1 结束
+	output, runErr := a.runWithNodeVersion(version, project.Path, "npm.cmd", "outdated", "--json")
+	deps, err := parseNpmOutdated(output)
+	if err != nil {
+		if runErr != nil {
+			err = fmt.Errorf("%v (%v)", err, runErr)
+		}
+		a.logToFile(fmt.Sprintf("npm outdated for %s failed: %v", project.Path, err))
+		return OutdatedReport{}, err
+	}
+
+	return OutdatedReport{
+		Project:      project.Path,
+		NodeVersion:  version,
+		Dependencies: deps,
+	}, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// npm config keys managed per version
+// 按版本管理的 npm 配置项
+const (
+	NpmPathPrefix = "prefix"
+	NpmPathCache  = "cache"
+)
+
+// NpmPaths are the effective npm prefix and cache locations of one version
+// NpmPaths 表示某个版本实际使用的 npm prefix 和缓存目录
+type NpmPaths struct {
+	Version        string
+	Prefix         string
+	Cache          string
+	PrefixWritable bool
+	CacheWritable  bool
+}
+
+// builtinNpmrcPath returns npm's builtin config file, which only applies to the npm bundled with this version.
+// `npm config set` would write the user .npmrc shared by all versions instead
+// builtinNpmrcPath 返回 npm 内置配置文件路径，仅对该版本自带的 npm 生效。
+// `npm config set` 会写入所有版本共享的用户 .npmrc
+func builtinNpmrcPath(dir string) string {
+	return filepath.Join(dir, "node_modules", "npm", "npmrc")
+}
+
+// checkWritable verifies that dir exists (creating it if needed) and accepts new files
+// checkWritable 检查目录是否存在（必要时创建）且可以写入新文件
+func checkWritable(fsys FileSystem, dir string) error {
+	if err := fsys.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, ".nvs-write-test-"+newPlanID())
+	if err := fsys.WriteFile(name, nil, 0644); err != nil {
+		return err
+	}
+	return fsys.Remove(name)
+}
+
+// npmConfigGet returns the effective value of an npm config key for a version
+// npmConfigGet 返回某个版本中 npm 配置项的实际值
+func (a *App) npmConfigGet(version, key string) (string, error) {
+	_, dir, err := a.versionEnv(version)
+	if err != nil {
+		return "", err
+	}
+	output, err := a.runWithNodeVersion(version, dir, "npm.cmd", "config", "get", key)
+	if err != nil {
+		return "", fmt.Errorf("Error reading npm %s: %v", key, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// setBuiltinNpmrc sets key=value in the builtin npmrc of a version, keeping the other lines.
+// An empty value removes the key so npm falls back to its default
+// setBuiltinNpmrc 在某个版本的内置 npmrc 中设置 key=value，并保留其他行。
+// 值为空时删除该配置项，使 npm 恢复默认值
+func setBuiltinNpmrc(fsys FileSystem, dir, key, value string) error {
+	return setNpmrcKeys(fsys, builtinNpmrcPath(dir), map[string]string{key: value})
+}
+
+// copyTree copies the files below src into dst, creating directories as needed
+// copyTree 将 src 下的文件复制到 dst，并按需创建目录
+func copyTree(fsys FileSystem, src, dst string) error {
+	info, err := fsys.Stat(longPath(src))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		data, err := fsys.ReadFile(longPath(src))
+		if err != nil {
+			return err
+		}
+		return fsys.WriteFile(longPath(dst), data, info.Mode())
+	}
+
+	if err := fsys.MkdirAll(longPath(dst), 0755); err != nil {
+		return err
+	}
+	entries, err := fsys.ReadDir(longPath(src))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := copyTree(fsys, filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveTree moves src to dst, copying across drives when a rename is not possible
+// moveTree 将 src 移动到 dst，无法重命名（如跨磁盘）时改为复制
+func moveTree(fsys FileSystem, src, dst string) error {
+	if _, err := fsys.Stat(dst); os.IsNotExist(err) {
+		if fsys.MkdirAll(filepath.Dir(dst), 0755) == nil && fsys.Rename(src, dst) == nil {
+			return nil
+		}
+	}
+	if err := copyTree(fsys, src, dst); err != nil {
+		return err
+	}
+	return fsys.RemoveAll(longPath(src))
+}
+
+// GetNpmPaths returns the npm prefix and cache of a version and whether they are writable
+// GetNpmPaths 返回某个版本的 npm prefix 和缓存目录，以及它们是否可写
+func (a *App) GetNpmPaths(version string) (NpmPaths, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	paths := NpmPaths{Version: version}
+
+	prefix, err := a.npmConfigGet(version, NpmPathPrefix)
+	if err != nil {
+		return paths, err
+	}
+	cache, err := a.npmConfigGet(version, NpmPathCache)
+	if err != nil {
+		return paths, err
+	}
+	paths.Prefix = prefix
+	paths.Cache = cache
+	paths.PrefixWritable = checkWritable(a.fs, prefix) == nil
+	paths.CacheWritable = checkWritable(a.fs, cache) == nil
+	return paths, nil
+}
+
+// SetNpmPath points the npm prefix or cache of a version at newPath after checking write access.
+// When migrate is set the existing content is moved to the new location.
+// An empty newPath restores npm's default location
+// SetNpmPath 在检查写入权限后，将某个版本的 npm prefix 或缓存目录指向 newPath。
+// migrate 为真时会把已有内容移动到新位置。newPath 为空时恢复 npm 的默认位置
+func (a *App) SetNpmPath(version, kind, newPath string, migrate bool) (NpmPaths, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if kind != NpmPathPrefix && kind != NpmPathCache {
+		return NpmPaths{}, fmt.Errorf("unknown npm path %q", kind)
+	}
+	_, dir, err := a.versionEnv(version)
+	if err != nil {
+		return NpmPaths{}, err
+	}
+
+	newPath = strings.TrimSpace(newPath)
+	if newPath != "" {
+		newPath = filepath.Clean(newPath)
+		if err := checkWritable(a.fs, newPath); err != nil {
+			return NpmPaths{}, fmt.Errorf("%s is not writable: %v", newPath, err)
+		}
+	}
+
+	oldPath, err := a.npmConfigGet(version, kind)
+	if err != nil {
+		return NpmPaths{}, err
+	}
+	// 保存原有的 npmrc，迁移失败时恢复
+	// Keep the previous npmrc to restore it when the migration fails
+	npmrc := builtinNpmrcPath(dir)
+	previous, readErr := a.fs.ReadFile(npmrc)
+	if err := setBuiltinNpmrc(a.fs, dir, kind, newPath); err != nil {
+		a.audit("set-npm-"+kind, version, "failed")
+		return NpmPaths{}, fmt.Errorf("Error writing npmrc: %v", err)
+	}
+
+	if migrate && newPath != "" && !strings.EqualFold(filepath.Clean(oldPath), newPath) {
+		if err := a.migrateNpmPath(kind, dir, oldPath, newPath); err != nil {
+			if readErr == nil {
+				a.fs.WriteFile(npmrc, previous, 0644)
+			} else if os.IsNotExist(readErr) {
+				a.fs.Remove(npmrc)
+			}
+			a.audit("set-npm-"+kind, fmt.Sprintf("%s: %s -> %s", version, oldPath, newPath), "rolled back")
+			return NpmPaths{}, fmt.Errorf("Error migrating npm %s of %s, the previous location is kept: %v", kind, version, err)
+		}
+	}
+	a.audit("set-npm-"+kind, fmt.Sprintf("%s: %s -> %s", version, oldPath, newPath), "success")
+	return a.GetNpmPaths(version)
+}
+
+// migrateNpmPath moves existing content from the old npm location to the new one.
+// The default prefix is the version directory itself, so only its global packages are moved; when one of
+// them fails the packages already moved are moved back
+// migrateNpmPath 将旧 npm 目录中的已有内容移动到新位置。
+// 默认 prefix 就是版本目录本身，因此只移动其中的全局包；其中一个失败时，已移动的包会被移回
+func (a *App) migrateNpmPath(kind, dir, oldPath, newPath string) error {
+	if kind == NpmPathCache {
+		if _, err := a.fs.Stat(oldPath); err != nil {
+			return nil
+		}
+		return moveTree(a.fs, oldPath, newPath)
+	}
+
+	if !strings.EqualFold(filepath.Clean(oldPath), filepath.Clean(dir)) {
+		return moveTree(a.fs, oldPath, newPath)
+	}
+	moved := map[string]string{}
+	for spec, pkgDir := range globalPackageDirs(dir) {
+		rel, _ := filepath.Rel(dir, pkgDir)
+		target := filepath.Join(newPath, rel)
+		if err := moveTree(a.fs, pkgDir, target); err != nil {
+			for from, to := range moved {
+				if err := moveTree(a.fs, to, from); err != nil {
+					a.logToFile(fmt.Sprintf("Error moving %s back to %s: %v", to, from, err))
+				}
+			}
+			return fmt.Errorf("Error moving %s: %v", spec, err)
+		}
+		moved[pkgDir] = target
+	}
+	return nil
+}
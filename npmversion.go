@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resolvedNpm is the npm version reported by a version's npm.cmd, with the state of npm's package.json it was read at
+// resolvedNpm 是某版本 npm.cmd 报告的 npm 版本，以及读取时 npm 的 package.json 的状态
+type resolvedNpm struct {
+	version string
+	stamp   time.Time
+}
+
+// npmVersionCache remembers the resolved npm version of each installed version
+// npmVersionCache 记录每个已安装版本实际解析到的 npm 版本
+type npmVersionCache struct {
+	mu      sync.Mutex
+	entries map[string]resolvedNpm
+}
+
+// npmPackageStamp returns the modification time of the npm package shipped in a version directory,
+// which changes when npm upgrades itself
+// npmPackageStamp 返回版本目录中 npm 包的修改时间，npm 自我升级时该时间会改变
+func npmPackageStamp(dir string) time.Time {
+	info, err := os.Stat(filepath.Join(dir, "node_modules", "npm", "package.json"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// GetNpmVersionFor runs the version's own npm.cmd --version, so the result reflects npm upgrades rather than
+// the npm bundled with the release. Results are cached until npm's package.json changes
+// GetNpmVersionFor 运行该版本自身的 npm.cmd --version，结果反映 npm 升级后的实际版本而非发布时附带的版本。
+// 结果会被缓存，直到 npm 的 package.json 发生变化
+func (a *App) GetNpmVersionFor(version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	root := a.nvmRoot()
+	if root == "" {
+		return "", fmt.Errorf("nvm root not found")
+	}
+	stamp := npmPackageStamp(versionDir(root, version))
+
+	c := &a.npmVersions
+	c.mu.Lock()
+	cached, ok := c.entries[version]
+	c.mu.Unlock()
+	if ok && cached.stamp.Equal(stamp) {
+		return cached.version, nil
+	}
+
+	home, _ := os.UserHomeDir()
+	output, err := a.runWithNodeVersion(version, home, "npm.cmd", "--version")
+	if err != nil {
+		return "", fmt.Errorf("Error reading npm version of Node.js %s: %v", version, err)
+	}
+	npmVersion := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			npmVersion = line
+		}
+	}
+	if npmVersion == "" {
+		return "", fmt.Errorf("npm of Node.js %s reported no version", version)
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]resolvedNpm{}
+	}
+	c.entries[version] = resolvedNpm{version: npmVersion, stamp: stamp}
+	c.mu.Unlock()
+	return npmVersion, nil
+}
+
+// forgetNpmVersion drops the cached npm version of a version
+// forgetNpmVersion 删除某个版本缓存的 npm 版本
+func (a *App) forgetNpmVersion(version string) {
+	c := &a.npmVersions
+	c.mu.Lock()
+	delete(c.entries, strings.TrimPrefix(version, "v"))
+	c.mu.Unlock()
+}
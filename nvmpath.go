@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// cmdMetaChars are the characters cmd.exe interprets even inside quotes, arguments containing them
+// are never passed through the cmd fallback
+// cmdMetaChars 是 cmd.exe 即使在引号内也会解释的字符，包含这些字符的参数不会经由 cmd 回退方式传递
+const cmdMetaChars = "\"%!^&|<>\r\n"
+
+// cmdPathMetaChars are the characters that still change a quoted script path: % expands variables and
+// " ends the quoting
+// cmdPathMetaChars 是在加引号的脚本路径中仍会起作用的字符：% 会展开变量，" 会结束引号
+const cmdPathMetaChars = "\"%"
+
+var (
+	nvmExeMu   sync.Mutex
+	nvmExePath string
+)
+
+// nvmEnvPath reads a path from an nvm environment variable. Installers and users sometimes store
+// paths with spaces in quotes, such as "C:\Program Files (x86)\nvm", which is not a usable path
+// nvmEnvPath 从 nvm 环境变量读取路径。安装程序和用户有时会将含空格的路径加上引号保存，
+// 例如 "C:\Program Files (x86)\nvm"，这样的值不能直接作为路径使用
+func nvmEnvPath(name string) string {
+	return strings.Trim(strings.TrimSpace(os.Getenv(name)), `"`)
+}
+
+// nvmExecutable returns the nvm to run, looked up in NVM_HOME and then on PATH like where does.
+// The result is cached until the file disappears
+// nvmExecutable 返回要运行的 nvm，依次在 NVM_HOME 和 PATH 中查找（与 where 相同），结果会被缓存直到文件消失
+func nvmExecutable() string {
+	nvmExeMu.Lock()
+	defer nvmExeMu.Unlock()
+	if nvmExePath != "" {
+		if _, err := os.Stat(nvmExePath); err == nil {
+			return nvmExePath
+		}
+	}
+
+	nvmExePath = ""
+	if home := nvmEnvPath("NVM_HOME"); home != "" {
+		if exe := filepath.Join(home, "nvm.exe"); fileExists(exe) {
+			nvmExePath = exe
+		}
+	}
+	if nvmExePath == "" {
+		if exe, err := exec.LookPath("nvm"); err == nil {
+			nvmExePath = exe
+		}
+	}
+	if nvmExePath == "" {
+		return "nvm"
+	}
+	return nvmExePath
+}
+
+// fileExists reports whether path names an existing file
+// fileExists 判断 path 是否为已存在的文件
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// nvmCommandSpec describes the command running the nvm at exe with args. nvm.exe is executed directly, so no shell
+// re-parses the arguments and no extra console process is started. Only when nvm is a script shim,
+// as some package managers install it, cmd.exe is used, and then only with arguments it cannot misread
+// nvmCommandSpec 描述以 args 运行位于 exe 的 nvm 的命令。nvm.exe 会被直接执行，参数不会被命令行重新解析，也不会多启动一个控制台进程。
+// 只有当 nvm 是某些包管理器安装的脚本包装时才使用 cmd.exe，并且只传递不会被其误解的参数
+func nvmCommandSpec(exe string, args []string) (CommandSpec, error) {
+	ext := strings.ToLower(filepath.Ext(exe))
+	if ext != ".cmd" && ext != ".bat" {
+		return CommandSpec{Name: exe, Args: args}, nil
+	}
+
+	// 路径总是加引号，使其中的 & ( ) 等字符保持原样
+	// The path is always quoted so characters such as & ( ) in it stay literal
+	if strings.ContainsAny(exe, cmdPathMetaChars) {
+		return CommandSpec{}, fmt.Errorf("nvm script %s cannot be run safely through cmd.exe, its path contains %% or \"", exe)
+	}
+	quoted := []string{`"` + exe + `"`}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, cmdMetaChars) {
+			return CommandSpec{}, fmt.Errorf("argument %q cannot be passed safely to %s", arg, exe)
+		}
+		quoted = append(quoted, syscall.EscapeArg(arg))
+	}
+	// /S 使 cmd 只去掉最外层引号，其余内容保持原样
+	// /S makes cmd strip only the outer quotes and keep the rest verbatim
+	return CommandSpec{Name: "cmd.exe", CmdLine: `cmd.exe /D /S /C "` + strings.Join(quoted, " ") + `"`}, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// trickyNvmDirs are nvm install directories whose names broke the cmd.exe based invocation before
+// trickyNvmDirs 是曾导致基于 cmd.exe 的调用出错的 nvm 安装目录名
+var trickyNvmDirs = []string{
+	`C:\Program Files (x86)\nvm`,
+	`C:\Tools & Utilities\nvm`,
+	`C:\Users\100%\nvm`,
+	`C:\Users\张三\AppData\Roaming\nvm`,
+	`\\fileserver\tools share\nvm`,
+}
+
+// resetNvmExecutable clears the cached nvm location for the duration of a test
+// resetNvmExecutable 在测试期间清除已缓存的 nvm 位置
+func resetNvmExecutable(t *testing.T) {
+	nvmExeMu.Lock()
+	nvmExePath = ""
+	nvmExeMu.Unlock()
+	t.Cleanup(func() {
+		nvmExeMu.Lock()
+		nvmExePath = ""
+		nvmExeMu.Unlock()
+	})
+}
+
+func TestNvmEnvPathStripsQuotes(t *testing.T) {
+	for _, dir := range trickyNvmDirs {
+		for _, value := range []string{dir, `"` + dir + `"`, "  \"" + dir + "\"\t"} {
+			t.Setenv("NVM_HOME", value)
+			if got := nvmEnvPath("NVM_HOME"); got != dir {
+				t.Errorf("nvmEnvPath with NVM_HOME=%q = %q, want %q", value, got, dir)
+			}
+		}
+	}
+}
+
+func TestNvmExecutableFromQuotedHome(t *testing.T) {
+	for _, name := range []string{"nvm & co", "100% nvm", "工具 nvm", "nvm (x86)"} {
+		resetNvmExecutable(t)
+		home := filepath.Join(t.TempDir(), name)
+		if err := os.MkdirAll(home, 0755); err != nil {
+			t.Fatal(err)
+		}
+		exe := filepath.Join(home, "nvm.exe")
+		if err := os.WriteFile(exe, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("NVM_HOME", `"`+home+`"`)
+		if got := nvmExecutable(); got != exe {
+			t.Errorf("nvmExecutable with NVM_HOME %q = %q, want %q", home, got, exe)
+		}
+	}
+}
+
+func TestNvmCommandSpecRunsExeDirectly(t *testing.T) {
+	args := []string{"use", "20.11.0", "a&b", "100%", "^!"}
+	for _, dir := range trickyNvmDirs {
+		exe := dir + `\nvm.exe`
+		spec, err := nvmCommandSpec(exe, args)
+		if err != nil {
+			t.Fatalf("nvmCommandSpec(%q): %v", exe, err)
+		}
+		want := CommandSpec{Name: exe, Args: args}
+		if !reflect.DeepEqual(spec, want) {
+			t.Errorf("nvmCommandSpec(%q) = %+v, want %+v", exe, spec, want)
+		}
+	}
+}
+
+func TestNvmCommandSpecQuotesScriptShims(t *testing.T) {
+	tests := []struct {
+		exe, cmdLine string
+	}{
+		{`C:\Program Files (x86)\nvm\nvm.cmd`, `cmd.exe /D /S /C ""C:\Program Files (x86)\nvm\nvm.cmd" use 20.11.0"`},
+		{`C:\Tools & Utilities\nvm\nvm.cmd`, `cmd.exe /D /S /C ""C:\Tools & Utilities\nvm\nvm.cmd" use 20.11.0"`},
+		{`C:\Users\张三\scoop\shims\nvm.bat`, `cmd.exe /D /S /C ""C:\Users\张三\scoop\shims\nvm.bat" use 20.11.0"`},
+		{`C:\Tools&Utilities\nvm\nvm.cmd`, `cmd.exe /D /S /C ""C:\Tools&Utilities\nvm\nvm.cmd" use 20.11.0"`},
+		{`\\fileserver\tools share\nvm\nvm.CMD`, `cmd.exe /D /S /C ""\\fileserver\tools share\nvm\nvm.CMD" use 20.11.0"`},
+	}
+	for _, tt := range tests {
+		spec, err := nvmCommandSpec(tt.exe, []string{"use", "20.11.0"})
+		if err != nil {
+			t.Fatalf("nvmCommandSpec(%q): %v", tt.exe, err)
+		}
+		if spec.Name != "cmd.exe" || spec.CmdLine != tt.cmdLine {
+			t.Errorf("nvmCommandSpec(%q) = %+v, want cmd.exe with %s", tt.exe, spec, tt.cmdLine)
+		}
+	}
+}
+
+func TestNvmCommandSpecRejectsShimMetaChars(t *testing.T) {
+	for _, arg := range []string{"a&b", "100%", "x|y", "<in", `"quoted"`, "a^b", "line\r\nbreak"} {
+		if _, err := nvmCommandSpec(`C:\nvm\nvm.cmd`, []string{"install", arg}); err == nil {
+			t.Errorf("argument %q was passed to the cmd shim", arg)
+		}
+	}
+}
+
+func TestNvmCommandSpecRejectsShimPathMetaChars(t *testing.T) {
+	for _, exe := range []string{`C:\Users\100%\nvm\nvm.cmd`, `C:\%NVM_HOME%\nvm.bat`, `C:\nvm"\nvm.cmd`} {
+		if spec, err := nvmCommandSpec(exe, []string{"ls"}); err == nil {
+			t.Errorf("nvmCommandSpec(%q) = %+v, want the cmd fallback refused", exe, spec)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parseNvmRoot returns the directory from the "Current Root:" line printed by "nvm root"
+// parseNvmRoot 从 "nvm root" 输出的 "Current Root:" 行中返回目录
+func parseNvmRoot(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if idx := strings.Index(line, ":"); idx != -1 && strings.HasPrefix(strings.ToLower(line), "current root") {
+			if root := strings.Trim(strings.TrimSpace(line[idx+1:]), `"`); root != "" {
+				return root
+			}
+		}
+	}
+	return ""
+}
+
+// nvmRoot returns the directory where nvm stores installed Node.js versions
+// nvmRoot 返回 nvm 存放已安装 Node.js 版本的目录
+func (a *App) nvmRoot() string {
+	// `nvm root` prints the configured root, e.g. "Current Root: C:\Users\me\AppData\Roaming\nvm"
+	// `nvm root` 会输出当前配置的根目录，例如 "Current Root: C:\Users\me\AppData\Roaming\nvm"
+	output, err := a.executeNvmCommand("root")
+	if err == nil {
+		if root := parseNvmRoot(string(output)); root != "" {
+			return root
+		}
+	}
+
+	// Fall back to the NVM_HOME environment variable set by the nvm-windows installer
+	// 回退到 nvm-windows 安装程序设置的 NVM_HOME 环境变量
+	return nvmEnvPath("NVM_HOME")
+}
+
+// currentNodeVersion returns the version nvm currently uses
+// currentNodeVersion 返回 nvm 当前使用的版本
+func (a *App) currentNodeVersion() (string, error) {
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return "", err
+	}
+	for _, v := range installed {
+		if v.IsCurrent {
+			return v.Version, nil
+		}
+	}
+	return "", fmt.Errorf("No Node.js version is currently in use")
+}
+
+// versionDir returns the install directory of the given version under the nvm root
+// versionDir 返回指定版本在 nvm 根目录下的安装目录
+func versionDir(root, version string) string {
+	return filepath.Join(root, "v"+strings.TrimPrefix(version, "v"))
+}
+
+// dirSize returns the total size in bytes of all files below dir
+// dirSize 返回目录下所有文件的总字节数
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(longPath(dir), func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Workspace tooling detected in a project
+// 项目中检测到的工作区工具
+const (
+	WorkspaceNone      = ""
+	WorkspacePnpm      = "pnpm"
+	WorkspaceYarnBerry = "yarn-berry"
+	WorkspaceNpm       = "npm"
+)
+
+// Corepack was added in 16.9.0 and backported to 14.19.0, so 15.x and 16.0-16.8 do not ship it
+// corepack 在 16.9.0 中加入并回移到 14.19.0，因此 15.x 和 16.0-16.8 不包含它
+const (
+	minCorepackVersion   = "16.9.0"
+	minCorepack14Version = "14.19.0"
+)
+
+// corepackBundled reports whether a release ships corepack: 14.19.0+ on the 14 line and 16.9.0+ afterwards
+// corepackBundled 判断发布版本是否附带 corepack：14 系列为 14.19.0 及以上，之后为 16.9.0 及以上
+func corepackBundled(version string) bool {
+	if versionMajor(version) == 14 {
+		return compareSemver(version, minCorepack14Version) >= 0
+	}
+	return compareSemver(version, minCorepackVersion) >= 0
+}
+
+// ProjectSwitchResult describes switching the global version for a project and preparing its package manager
+// ProjectSwitchResult 描述为项目切换全局版本并准备其包管理器的结果
+type ProjectSwitchResult struct {
+	Project        string
+	Version        string
+	Workspace      string
+	PackageManager string
+	Steps          []string
+	Warnings       []string
+}
+
+// readPackageManager returns the packageManager field of package.json and whether it declares workspaces
+// readPackageManager 返回 package.json 中的 packageManager 字段以及是否声明了 workspaces
+func readPackageManager(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", false
+	}
+	var pkg struct {
+		PackageManager string          `json:"packageManager"`
+		Workspaces     json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return "", false
+	}
+	return strings.TrimSpace(pkg.PackageManager), len(pkg.Workspaces) > 0 && string(pkg.Workspaces) != "null"
+}
+
+// detectWorkspace identifies the workspace tooling of a project and the package manager it requires
+// detectWorkspace 识别项目的工作区工具及其要求的包管理器
+func detectWorkspace(dir string) (string, string) {
+	packageManager, hasWorkspaces := readPackageManager(dir)
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	switch {
+	case exists("pnpm-workspace.yaml"):
+		return WorkspacePnpm, packageManager
+	case exists(".yarnrc.yml"):
+		return WorkspaceYarnBerry, packageManager
+	case hasWorkspaces:
+		return WorkspaceNpm, packageManager
+	}
+	return WorkspaceNone, packageManager
+}
+
+// packageManagerName returns the tool name of a packageManager spec such as "pnpm@9.1.0+sha512.abc"
+// packageManagerName 返回 "pnpm@9.1.0+sha512.abc" 这类 packageManager 声明中的工具名称
+func packageManagerName(spec string) string {
+	name, _, _ := strings.Cut(spec, "@")
+	return name
+}
+
+// SwitchForProject switches the global version to the one the project pins and, for pnpm and yarn projects,
+// enables corepack and prepares the package manager version from the packageManager field
+// SwitchForProject 将全局版本切换为项目固定的版本，并对 pnpm 和 yarn 项目启用 corepack，
+// 按 packageManager 字段准备对应版本的包管理器
+func (a *App) SwitchForProject(projectPath string) (ProjectSwitchResult, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return ProjectSwitchResult{}, err
+	}
+	version, err := a.resolveProjectVersion(project)
+	if err != nil {
+		return ProjectSwitchResult{}, err
+	}
+	result := ProjectSwitchResult{
+		Project:        project.Path,
+		Version:        version,
+		Workspace:      project.Workspace,
+		PackageManager: project.PackageManager,
+	}
+
+	message := a.SwitchNodeVersion(version)
+	if !strings.HasPrefix(message, "Successfully") {
+		return result, fmt.Errorf("%s", message)
+	}
+	result.Steps = append(result.Steps, message)
+
+	name := packageManagerName(project.PackageManager)
+	if name == "" {
+		switch project.Workspace {
+		case WorkspacePnpm:
+			name = "pnpm"
+		case WorkspaceYarnBerry:
+			name = "yarn"
+		}
+		if name != "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("package.json 未声明 packageManager，将使用 corepack 默认的 %s 版本 / package.json has no packageManager field, corepack's default %s is used", name, name))
+		}
+	}
+	if name != "pnpm" && name != "yarn" {
+		return result, nil
+	}
+
+	if !corepackBundled(version) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Node.js %s 未内置 corepack，请手动安装 %s / Node.js %s does not bundle corepack, install %s manually", version, name, version, name))
+		return result, nil
+	}
+	if _, err := a.RunWithVersion(version, project.Path, "corepack", []string{"enable", name}); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("corepack enable %s failed: %v", name, err))
+		return result, nil
+	}
+	result.Steps = append(result.Steps, "corepack enable "+name)
+
+	if project.PackageManager != "" {
+		// 去掉完整性哈希，corepack prepare 只接受 name@version
+		// Strip the integrity hash, corepack prepare only takes name@version
+		spec, _, _ := strings.Cut(project.PackageManager, "+")
+		if _, err := a.RunWithVersion(version, project.Path, "corepack", []string{"prepare", spec}); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("corepack prepare %s failed: %v", spec, err))
+		} else {
+			result.Steps = append(result.Steps, "corepack prepare "+spec)
+		}
+	}
+	a.setProjectCorepack(project.Path, name)
+	a.logToFile(fmt.Sprintf("Switched to Node.js %s for %s with %s", version, project.Path, name))
+	return result, nil
+}
+
+// setProjectCorepack remembers which package manager corepack was enabled for in a project
+// setProjectCorepack 记录项目通过 corepack 启用的包管理器
+func (a *App) setProjectCorepack(projectPath, name string) {
+	settings := a.currentSettings()
+	projects := append([]Project{}, settings.Projects...)
+	changed := false
+	for i := range projects {
+		if strings.EqualFold(filepath.Clean(projects[i].Path), filepath.Clean(projectPath)) && projects[i].Corepack != name {
+			projects[i].Corepack = name
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	settings.Projects = projects
+	a.SetSettings(settings)
+}
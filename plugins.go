@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+// pluginManifestFile is the manifest every plugin directory contains
+// pluginManifestFile 是每个插件目录中的清单文件
+const pluginManifestFile = "plugin.json"
+
+// Plugin action timeouts
+// 插件操作的超时时间
+const (
+	defaultPluginTimeout = 60 * time.Second
+	maxPluginTimeout     = 10 * time.Minute
+)
+
+// maxPluginOutput caps the output kept from a plugin action
+// maxPluginOutput 限制保留的插件操作输出大小
+const maxPluginOutput = 64 << 10
+
+// PluginAction is a menu action registered by a plugin
+// PluginAction 表示插件注册的菜单操作
+type PluginAction struct {
+	ID             string   `json:"id"`
+	Label          string   `json:"label"`
+	Script         string   `json:"script"` // 插件目录中的 .ps1、.cmd、.bat 或 .js 文件 / .ps1, .cmd, .bat or .js file in the plugin directory
+	Args           []string `json:"args"`
+	Tray           bool     `json:"tray"` // 同时显示在托盘菜单中 / also shown in the tray menu
+	TimeoutSeconds int      `json:"timeoutSeconds"`
+}
+
+// Plugin is a user-installed extension loaded from the plugins directory
+// Plugin 表示从插件目录加载的用户扩展
+type Plugin struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Version     string         `json:"version"`
+	Actions     []PluginAction `json:"actions"`
+	Dir         string         `json:"-"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// PluginRunResult is the outcome of running a plugin action
+// PluginRunResult 表示运行插件操作的结果
+type PluginRunResult struct {
+	Plugin   string
+	Action   string
+	Output   string
+	ExitCode int
+	TimedOut bool
+	Duration int64 // 毫秒 / milliseconds
+}
+
+// pluginsDir returns the plugins directory next to the executable
+// pluginsDir 返回可执行文件同目录下的插件目录
+func pluginsDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "plugins"
+	}
+	return filepath.Join(filepath.Dir(execPath), "plugins")
+}
+
+// loadPlugin reads and validates the manifest of one plugin directory
+// loadPlugin 读取并校验单个插件目录的清单
+func loadPlugin(dir string) Plugin {
+	plugin := Plugin{Name: filepath.Base(dir), Dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, pluginManifestFile))
+	if err != nil {
+		plugin.Error = err.Error()
+		return plugin
+	}
+	if err := json.Unmarshal(data, &plugin); err != nil {
+		plugin.Error = fmt.Sprintf("Error parsing %s: %v", pluginManifestFile, err)
+		return plugin
+	}
+	plugin.Dir = dir
+	for _, action := range plugin.Actions {
+		if _, err := pluginScriptPath(dir, action.Script); err != nil {
+			plugin.Error = fmt.Sprintf("action %s: %v", action.ID, err)
+		}
+	}
+	return plugin
+}
+
+// pluginScriptPath resolves an action script, refusing paths outside the plugin directory
+// pluginScriptPath 解析操作脚本路径，拒绝插件目录之外的路径
+func pluginScriptPath(dir, script string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(script))
+	if script == "" || !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("script %q must be inside the plugin directory", script)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ps1", ".cmd", ".bat", ".js":
+		return path, nil
+	default:
+		return "", fmt.Errorf("unsupported script type %q", filepath.Ext(path))
+	}
+}
+
+// GetPlugins returns the plugins found in the plugins directory, including those that failed to load
+// GetPlugins 返回插件目录中找到的插件，包括加载失败的插件
+func (a *App) GetPlugins() []Plugin {
+	entries, err := os.ReadDir(pluginsDir())
+	if err != nil {
+		return []Plugin{}
+	}
+	plugins := []Plugin{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			plugins = append(plugins, loadPlugin(filepath.Join(pluginsDir(), entry.Name())))
+		}
+	}
+	return plugins
+}
+
+// findPluginAction looks up a loaded plugin action
+// findPluginAction 查找已加载插件的操作
+func (a *App) findPluginAction(pluginName, actionID string) (Plugin, PluginAction, error) {
+	for _, plugin := range a.GetPlugins() {
+		if plugin.Name != pluginName {
+			continue
+		}
+		if plugin.Error != "" {
+			return plugin, PluginAction{}, fmt.Errorf("Plugin %s failed to load: %s", pluginName, plugin.Error)
+		}
+		for _, action := range plugin.Actions {
+			if action.ID == actionID {
+				return plugin, action, nil
+			}
+		}
+	}
+	return Plugin{}, PluginAction{}, fmt.Errorf("Unknown plugin action %s/%s", pluginName, actionID)
+}
+
+// RunPluginAction runs a plugin action hidden, with a timeout, the current Node.js version first on PATH
+// and elevation prompts suppressed
+// RunPluginAction 以隐藏窗口运行插件操作，带有超时限制，当前 Node.js 版本位于 PATH 最前，并禁止提权提示
+func (a *App) RunPluginAction(pluginName, actionID string) (PluginRunResult, error) {
+	result := PluginRunResult{Plugin: pluginName, Action: actionID}
+	if a.safeMode {
+		return result, fmt.Errorf("plugins disabled in safe mode")
+	}
+	plugin, action, err := a.findPluginAction(pluginName, actionID)
+	if err != nil {
+		return result, err
+	}
+	script, _ := pluginScriptPath(plugin.Dir, action.Script)
+
+	timeout := defaultPluginTimeout
+	if action.TimeoutSeconds > 0 {
+		timeout = time.Duration(action.TimeoutSeconds) * time.Second
+	}
+	if timeout > maxPluginTimeout {
+		timeout = maxPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	env, nodeExe := os.Environ(), "node.exe"
+	if version, err := a.currentNodeVersion(); err == nil {
+		if versionEnv, versionPath, err := a.versionEnv(strings.TrimPrefix(version, "v")); err == nil {
+			env = versionEnv
+			nodeExe = filepath.Join(versionPath, "node.exe")
+		}
+	}
+
+	spec := CommandSpec{Dir: plugin.Dir, Hidden: true}
+	switch strings.ToLower(filepath.Ext(script)) {
+	case ".ps1":
+		spec.Name, spec.Args = "powershell.exe", append([]string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", script}, action.Args...)
+	case ".js":
+		spec.Name, spec.Args = nodeExe, append([]string{script}, action.Args...)
+	default:
+		spec.Name, spec.Args = "cmd.exe", append([]string{"/C", script}, action.Args...)
+	}
+
+	// RunAsInvoker 使要求管理员权限的程序以当前权限运行，而不是弹出 UAC 提示
+	// RunAsInvoker makes programs requesting administrator rights run unelevated instead of prompting UAC
+	spec.Env = append(env, "__COMPAT_LAYER=RunAsInvoker", "NVS_PLUGIN_DIR="+plugin.Dir)
+
+	var output bytes.Buffer
+	out := &limitedWriter{w: &output, remaining: maxPluginOutput}
+
+	start := a.clock.Now()
+	// 超时时连同插件启动的子进程一起终止
+	// On timeout the processes the plugin started are terminated as well
+	err = a.executor.Run(ctx, spec, out, out)
+	result.Duration = a.clock.Now().Sub(start).Milliseconds()
+	result.Output = string(decodeConsoleOutput(output.Bytes()))
+	result.TimedOut = ctx.Err() == context.DeadlineExceeded
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failed"
+	}
+	a.audit("plugin-action", pluginName+"/"+actionID, outcome)
+	if result.TimedOut {
+		return result, fmt.Errorf("Plugin action %s/%s timed out after %s", pluginName, actionID, timeout)
+	}
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Plugin action %s/%s failed: %v\nOutput: %s", pluginName, actionID, err, result.Output))
+		return result, fmt.Errorf("Plugin action %s/%s failed: %v", pluginName, actionID, err)
+	}
+	return result, nil
+}
+
+// limitedWriter discards writes beyond a byte budget
+// limitedWriter 丢弃超出字节上限的写入
+type limitedWriter struct {
+	w         *bytes.Buffer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.remaining > 0 {
+		n := len(p)
+		if n > l.remaining {
+			n = l.remaining
+		}
+		l.w.Write(p[:n])
+		l.remaining -= n
+	}
+	return len(p), nil
+}
+
+// addPluginTrayItems adds the tray actions of the loaded plugins to the tray menu, none in safe mode
+// addPluginTrayItems 将已加载插件的托盘操作添加到托盘菜单，安全模式下不添加
+func (a *App) addPluginTrayItems() {
+	if a.safeMode {
+		return
+	}
+	for _, plugin := range a.GetPlugins() {
+		if plugin.Error != "" {
+			a.logToFile(fmt.Sprintf("Skipping plugin %s: %s", plugin.Name, plugin.Error))
+			continue
+		}
+		for _, action := range plugin.Actions {
+			if !action.Tray {
+				continue
+			}
+			item := systray.AddMenuItem(action.Label, plugin.Description)
+			go func(pluginName, actionID string) {
+				for range item.ClickedCh {
+					if _, err := a.RunPluginAction(pluginName, actionID); err != nil {
+						a.logToFile(err.Error())
+					}
+				}
+			}(plugin.Name, action.ID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// powerPollInterval is how often the power source is checked
+// powerPollInterval 是检查电源状态的间隔
+const powerPollInterval = 30 * time.Second
+
+// defaultLargeDownloadMB is the size above which a download counts as large when none is configured
+// defaultLargeDownloadMB 是未配置时下载被视为较大的阈值
+const defaultLargeDownloadMB = 100
+
+// PowerPolicy holds the per-rule overrides of the battery-aware scheduling
+// PowerPolicy 保存按电池状态调度的各项规则覆盖选项
+type PowerPolicy struct {
+	AllowMaintenanceOnBattery bool `json:"allowMaintenanceOnBattery"`
+	AllowDownloadsOnBattery   bool `json:"allowDownloadsOnBattery"`
+	LargeDownloadMB           int  `json:"largeDownloadMb"` // 0 表示使用默认值 / 0 uses the default
+}
+
+// PowerStatus describes the power source and what is being deferred because of it
+// PowerStatus 描述电源状态以及因此被推迟的内容
+type PowerStatus struct {
+	OnBattery            bool
+	BatterySaver         bool
+	BatteryPercent       int
+	DeferringMaintenance bool
+	DeferringDownloads   bool
+}
+
+// powerState is the last power status seen by watchPower
+// powerState 是 watchPower 最近一次读取的电源状态
+type powerState struct {
+	mu        sync.Mutex
+	onBattery bool
+	saver     bool
+}
+
+// onBatteryPower reports whether the machine runs on battery or has battery saver on
+// onBatteryPower 判断计算机是否使用电池供电或开启了省电模式
+func onBatteryPower() bool {
+	onBattery, saver, _ := readPowerStatus()
+	return onBattery || saver
+}
+
+// GetPowerStatus returns the power source and which rules currently defer work
+// GetPowerStatus 返回电源状态以及当前推迟工作的规则
+func (a *App) GetPowerStatus() PowerStatus {
+	onBattery, saver, percent := readPowerStatus()
+	policy := a.currentSettings().Power
+	restricted := onBattery || saver
+	return PowerStatus{
+		OnBattery:            onBattery,
+		BatterySaver:         saver,
+		BatteryPercent:       percent,
+		DeferringMaintenance: restricted && !policy.AllowMaintenanceOnBattery,
+		DeferringDownloads:   restricted && !policy.AllowDownloadsOnBattery,
+	}
+}
+
+// batteryDownloadDeferral returns why a background install is held back on battery, or an empty string. The
+// download size is only looked up when running on battery, and then with a HEAD request only
+// batteryDownloadDeferral 返回使用电池时推迟后台安装的原因，无需推迟时返回空字符串。
+// 仅在使用电池时才查询下载大小，并且只使用 HEAD 请求
+func (a *App) batteryDownloadDeferral(version string) string {
+	policy := a.currentSettings().Power
+	if policy.AllowDownloadsOnBattery || !onBatteryPower() {
+		return ""
+	}
+	limitMB := policy.LargeDownloadMB
+	if limitMB <= 0 {
+		limitMB = defaultLargeDownloadMB
+	}
+	bytes, err := a.downloadSize(version)
+	if err != nil || bytes <= int64(limitMB)<<20 {
+		return ""
+	}
+	f := a.formatter()
+	size := f.size(bytes)
+	return fmt.Sprintf("正在使用电池，已推迟 %s 的下载 / Running on battery, the %s download is deferred", size, size)
+}
+
+// watchPower pauses background maintenance while on battery or battery saver and resumes it on AC power
+// watchPower 在使用电池或省电模式时暂停后台维护任务，并在接通电源后恢复
+func (a *App) watchPower() {
+	ticker := time.NewTicker(powerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		onBattery, saver, _ := readPowerStatus()
+		p := &a.power
+		p.mu.Lock()
+		changed := onBattery != p.onBattery || saver != p.saver
+		p.onBattery, p.saver = onBattery, saver
+		p.mu.Unlock()
+
+		pause := (onBattery || saver) && !a.currentSettings().Power.AllowMaintenanceOnBattery
+		a.tasks.setPowerSaving(a, pause)
+		if changed {
+			a.logToFile(fmt.Sprintf("Power status changed: on battery %v, battery saver %v", onBattery, saver))
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
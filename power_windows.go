@@ -0,0 +1,33 @@
+package main
+
+import (
+	"unsafe"
+)
+
+var procGetSystemPowerStatus = modkernel32.NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus mirrors the Win32 SYSTEM_POWER_STATUS structure
+// systemPowerStatus 对应 Win32 的 SYSTEM_POWER_STATUS 结构体
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// readPowerStatus reports whether the machine runs on battery, whether battery saver is on and the charge percent
+// (-1 when unknown); desktops without a battery report AC power
+// readPowerStatus 报告是否使用电池供电、是否开启省电模式以及电量百分比（未知时为 -1）；没有电池的台式机报告为交流电源
+func readPowerStatus() (onBattery bool, saver bool, percent int) {
+	var status systemPowerStatus
+	if ret, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ret == 0 {
+		return false, false, -1
+	}
+	percent = int(status.BatteryLifePercent)
+	if percent == 255 {
+		percent = -1
+	}
+	return status.ACLineStatus == 0, status.SystemStatusFlag == 1, percent
+}
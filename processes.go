@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NodeProcess is a running node.exe and the installed version it belongs to
+// NodeProcess 表示一个正在运行的 node.exe 及其所属的已安装版本
+type NodeProcess struct {
+	PID     uint32
+	Path    string
+	Version string // 不属于 nvm 的进程为空 / empty for processes outside nvm
+}
+
+// pathUnder reports whether path lies below dir and returns the remaining relative path
+// pathUnder 判断 path 是否位于 dir 之下，并返回剩余的相对路径
+func pathUnder(path, dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return rel, true
+}
+
+// GetRunningNodeProcesses lists the running node.exe processes with the version each one runs.
+// Processes started through the nvm symlink belong to the current version
+// GetRunningNodeProcesses 列出正在运行的 node.exe 进程及其运行的版本，通过 nvm 符号链接启动的进程属于当前版本
+func (a *App) GetRunningNodeProcesses() ([]NodeProcess, error) {
+	processes, err := listProcesses("node.exe")
+	if err != nil {
+		return nil, fmt.Errorf("Error listing processes: %v", err)
+	}
+	root := a.nvmRoot()
+	symlink := nvmEnvPath("NVM_SYMLINK")
+	current, _ := a.currentNodeVersion()
+
+	result := make([]NodeProcess, 0, len(processes))
+	for _, p := range processes {
+		process := NodeProcess{PID: p.PID, Path: p.Path}
+		if _, ok := pathUnder(p.Path, symlink); ok {
+			process.Version = strings.TrimPrefix(current, "v")
+		} else if rel, ok := pathUnder(p.Path, root); ok {
+			if dir := strings.Split(rel, string(filepath.Separator))[0]; versionDirRegex.MatchString(dir) {
+				process.Version = strings.TrimPrefix(dir, "v")
+			}
+		}
+		result = append(result, process)
+	}
+	return result, nil
+}
+
+// processesUsingVersion returns the running node.exe processes of a version
+// processesUsingVersion 返回某个版本正在运行的 node.exe 进程
+func (a *App) processesUsingVersion(version string) []NodeProcess {
+	processes, err := a.GetRunningNodeProcesses()
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Skipping running process check: %v", err))
+		return nil
+	}
+	version = strings.TrimPrefix(version, "v")
+	var using []NodeProcess
+	for _, p := range processes {
+		if p.Version == version {
+			using = append(using, p)
+		}
+	}
+	return using
+}
+
+// recordVersionUsed remembers when a version was last switched to
+// recordVersionUsed 记录版本最近一次被切换使用的时间
+func (a *App) recordVersionUsed(version string) {
+	a.updateSettings(func(settings *Settings) {
+		// 复制映射，之前由 currentSettings 返回的副本不受影响
+		// Copy the map so copies returned by currentSettings before are unaffected
+		lastUsed := map[string]time.Time{}
+		for v, t := range settings.LastUsed {
+			lastUsed[v] = t
+		}
+		lastUsed[strings.TrimPrefix(version, "v")] = a.clock.Now()
+		settings.LastUsed = lastUsed
+	})
+}
+
+// GetVersionLastUsed returns when each version was last switched to
+// GetVersionLastUsed 返回每个版本最近一次被切换使用的时间
+func (a *App) GetVersionLastUsed() map[string]time.Time {
+	return a.currentSettings().LastUsed
+}
+
+// UninstallNodeVersionIgnoringProcesses uninstalls a version even though node.exe processes still run from it
+// UninstallNodeVersionIgnoringProcesses 即使该版本仍有 node.exe 进程在运行也执行卸载
+func (a *App) UninstallNodeVersionIgnoringProcesses(version string) string {
+	run := func() string {
+		a.audit("uninstall-running-override", version, "confirmed")
+		return a.uninstallNodeVersion(version)
+	}
+	if msg, ok := a.requireConfirmationThen(OperationUninstall, version, run); !ok {
+		return msg
+	}
+	return run()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processInfo is a running process and the full path of its executable
+// processInfo 表示一个正在运行的进程及其可执行文件的完整路径
+type processInfo struct {
+	PID  uint32
+	Path string
+}
+
+// listProcesses returns the running processes whose executable name matches exe, e.g. node.exe.
+// Processes the current user may not query are skipped
+// listProcesses 返回可执行文件名为 exe（如 node.exe）的正在运行的进程，当前用户无权查询的进程会被跳过
+func listProcesses(exe string) ([]processInfo, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	if err := windows.Process32First(snapshot, &entry); err != nil {
+		return nil, err
+	}
+
+	var result []processInfo
+	for {
+		if strings.EqualFold(syscall.UTF16ToString(entry.ExeFile[:]), exe) {
+			if path, err := processImagePath(entry.ProcessID); err == nil {
+				result = append(result, processInfo{PID: entry.ProcessID, Path: path})
+			}
+		}
+		if err := windows.Process32Next(snapshot, &entry); err != nil {
+			break
+		}
+	}
+	return result, nil
+}
+
+// processImagePath returns the full executable path of a process
+// processImagePath 返回进程可执行文件的完整路径
+func processImagePath(pid uint32) (string, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", err
+	}
+	return syscall.UTF16ToString(buf[:size]), nil
+}
+
+// terminateProcess ends a process with exit code 1
+// terminateProcess 以退出码 1 结束进程
+func terminateProcess(pid uint32) error {
+	handle, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(handle)
+	return windows.TerminateProcess(handle, 1)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// installTimeout bounds how long an nvm install may run before its whole process tree is terminated
+// installTimeout 限制 nvm 安装的最长运行时间，超时后终止其整个进程树
+const installTimeout = 30 * time.Minute
+
+// runningCommands tracks the cancellable commands by key, such as "install 20.11.0"
+// runningCommands 按键（例如 "install 20.11.0"）记录可取消的命令
+type runningCommands struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// runInJob runs cmd inside a job object and waits for it. When ctx ends, the command and every
+// process it started are terminated; children still running when it exits are terminated as well
+// runInJob 在作业对象中运行 cmd 并等待其结束。ctx 结束时终止该命令及其启动的所有进程；
+// 命令退出时仍在运行的子进程也会被终止
+func runInJob(ctx context.Context, cmd *exec.Cmd) error {
+	job, err := startInJob(cmd)
+	if err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	if job != nil {
+		// 命令退出后立即终止遗留的子进程：它们继承了输出管道，否则 Wait 会一直等到它们自行退出
+		// Children left behind are terminated as soon as the command exits: they inherited the output
+		// pipes, so Wait would otherwise block until they exit on their own
+		go func() {
+			defer close(exited)
+			if waitProcessExit(cmd.Process) == nil {
+				job.terminate()
+			}
+		}()
+		defer func() {
+			<-exited
+			job.close()
+		}()
+	} else {
+		close(exited)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// 无法创建作业时至少终止命令本身
+		// Without a job at least the command itself is terminated
+		if job != nil {
+			job.terminate()
+		} else {
+			cmd.Process.Kill()
+		}
+		<-done
+		return fmt.Errorf("%s: %v", cmd.Path, ctx.Err())
+	}
+}
+
+// baseContext returns the application context, which ends on shutdown
+// baseContext 返回应用程序上下文，应用退出时结束
+func (a *App) baseContext() context.Context {
+	if a.ctx != nil {
+		return a.ctx
+	}
+	return context.Background()
+}
+
+// beginCommand registers a cancellable command under key, limited to timeout when positive,
+// and returns its context with the function ending the registration
+// beginCommand 以 key 登记一个可取消的命令（timeout 为正时限制其运行时间），并返回其上下文及结束登记的函数
+func (a *App) beginCommand(key string, timeout time.Duration) (context.Context, func()) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(a.baseContext(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(a.baseContext())
+	}
+	c := &a.commands
+	c.mu.Lock()
+	if c.cancels == nil {
+		c.cancels = map[string]context.CancelFunc{}
+	}
+	c.cancels[key] = cancel
+	c.mu.Unlock()
+
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.cancels, key)
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelInstall stops a running install together with the downloads and extraction nvm started for it
+// CancelInstall 停止正在进行的安装，以及 nvm 为其启动的下载和解压进程
+func (a *App) CancelInstall(version string) error {
+	return a.cancelCommand("install " + version)
+}
+
+// cancelCommand cancels the command registered under key
+// cancelCommand 取消以 key 登记的命令
+func (a *App) cancelCommand(key string) error {
+	c := &a.commands
+	c.mu.Lock()
+	cancel, ok := c.cancels[key]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("No running command: %s", key)
+	}
+	cancel()
+	a.audit("cancel-command", key, "success")
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processJob is a Windows job object holding a command and every process it starts. Closing the job
+// terminates whatever is still running in it, so children such as curl or unzip cannot outlive their parent
+// processJob 是包含命令及其启动的所有进程的 Windows 作业对象。关闭作业会终止其中仍在运行的进程，
+// 因此 curl、unzip 等子进程不会比父进程存活得更久
+type processJob struct {
+	handle windows.Handle
+}
+
+// newProcessJob creates a job object that kills its processes when closed
+// newProcessJob 创建关闭时终止其中进程的作业对象
+func newProcessJob() (*processJob, error) {
+	handle, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(handle, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(handle)
+		return nil, err
+	}
+	return &processJob{handle: handle}, nil
+}
+
+// assign adds a started process to the job; processes it starts afterwards join the job automatically
+// assign 将已启动的进程加入作业，该进程之后启动的子进程会自动加入作业
+func (j *processJob) assign(p *os.Process) error {
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(p.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+	return windows.AssignProcessToJobObject(j.handle, process)
+}
+
+// startInJob starts cmd suspended, adds it to a new job and only then resumes it, so nothing it starts can
+// escape the job. The job is nil when it cannot be created, in which case the command runs without one
+// startInJob 以挂起状态启动 cmd，将其加入新作业后再恢复运行，使其启动的进程都无法逃出作业。
+// 无法创建作业时返回的作业为空，命令在作业之外运行
+func startInJob(cmd *exec.Cmd) (*processJob, error) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	job, err := newProcessJob()
+	if err == nil {
+		if err = job.assign(cmd.Process); err != nil {
+			job.close()
+			job = nil
+		}
+	}
+	if err := resumeProcess(uint32(cmd.Process.Pid)); err != nil {
+		if job != nil {
+			job.terminate()
+			job.close()
+		} else {
+			cmd.Process.Kill()
+		}
+		cmd.Wait()
+		return nil, fmt.Errorf("Error resuming %s: %v", cmd.Path, err)
+	}
+	return job, nil
+}
+
+// resumeProcess resumes the threads of a process started suspended
+// resumeProcess 恢复以挂起状态启动的进程的线程
+func resumeProcess(pid uint32) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snapshot)
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(thread)
+		windows.CloseHandle(thread)
+		if err != nil {
+			return err
+		}
+	}
+	if err == windows.ERROR_NO_MORE_FILES {
+		return nil
+	}
+	return err
+}
+
+// waitProcessExit blocks until a process has exited
+// waitProcessExit 阻塞直到进程退出
+func waitProcessExit(p *os.Process) error {
+	process, err := windows.OpenProcess(windows.SYNCHRONIZE, false, uint32(p.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+	_, err = windows.WaitForSingleObject(process, windows.INFINITE)
+	return err
+}
+
+// terminate kills every process in the job
+// terminate 终止作业中的所有进程
+func (j *processJob) terminate() error {
+	return windows.TerminateJobObject(j.handle, 1)
+}
+
+// close releases the job, killing the processes left in it
+// close 释放作业，并终止其中遗留的进程
+func (j *processJob) close() error {
+	return windows.CloseHandle(j.handle)
+}
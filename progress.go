@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Install progress phases
+// 安装进度阶段
+const (
+	PhaseStarted     = "started"
+	PhaseDownloading = "downloading"
+	PhaseExtracting  = "extracting"
+	PhaseCompleted   = "completed"
+	PhaseFailed      = "failed"
+)
+
+// InstallProgress is emitted to the frontend as the "install-progress" event
+// InstallProgress 作为 "install-progress" 事件发送给前端
+type InstallProgress struct {
+	Version string
+	Phase   string
+	Percent int // -1 表示进度未知 / -1 means the progress is unknown
+	Message string
+
+	Speed      int64  // 滚动平均下载速度（字节/秒），0 表示未知 / rolling average download speed in bytes/s, 0 when unknown
+	SpeedText  string // 本地化的速度 / localized speed
+	ETASeconds int    // 预计剩余秒数，-1 表示未知 / estimated seconds remaining, -1 when unknown
+	ETAText    string // 本地化的剩余时间 / localized time remaining
+	Retries    int    // nvm 报告的重试次数 / retries reported by nvm
+}
+
+var percentRegex = regexp.MustCompile(`(\d{1,3})(?:\.\d+)?\s*%`)
+
+// parseInstallLine maps a line of `nvm install` output to a progress update
+// parseInstallLine 将 `nvm install` 的一行输出转换为进度信息
+func parseInstallLine(version, line string) (InstallProgress, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return InstallProgress{}, false
+	}
+
+	progress := InstallProgress{Version: version, Percent: -1, Message: line}
+	lower := strings.ToLower(line)
+
+	switch {
+	case strings.Contains(lower, "extracting"):
+		progress.Phase = PhaseExtracting
+		progress.Percent = 80
+	case strings.Contains(lower, "downloading"):
+		progress.Phase = PhaseDownloading
+		// Newer nvm releases print a percentage while downloading
+		// 较新的 nvm 版本在下载时会输出百分比
+		if m := percentRegex.FindStringSubmatch(line); m != nil {
+			if p, err := strconv.Atoi(m[1]); err == nil && p <= 100 {
+				// 下载占整体进度的前 80%
+				// Downloading accounts for the first 80% of the overall progress
+				progress.Percent = p * 80 / 100
+			}
+		}
+	case strings.Contains(lower, "installation complete") || strings.Contains(lower, "installed successfully"):
+		progress.Phase = PhaseCompleted
+		progress.Percent = 100
+	default:
+		return InstallProgress{}, false
+	}
+	return progress, true
+}
+
+// emitInstallProgress publishes a progress update to the frontend, the taskbar and the tray tooltip.
+// Speed, time remaining and retries are filled in here so every consumer shows the same numbers
+// emitInstallProgress 将进度信息发布到前端、任务栏和托盘提示。速度、剩余时间和重试次数在此统一计算，
+// 使各处显示的数值一致
+func (a *App) emitInstallProgress(progress InstallProgress) {
+	if t := a.progressTrackerFor(progress.Version); t != nil {
+		progress = t.annotate(progress, a.formatter())
+		a.submitTask("tray-tooltip", TaskPriorityNormal, a.refreshTrayTooltip)
+	} else {
+		progress.ETASeconds = -1
+	}
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "install-progress", progress)
+	}
+	a.updateTaskbarProgress(progress)
+}
+
+// scanLinesOrCR splits output on both \n and \r so progress bars redrawn in place are seen
+// scanLinesOrCR 同时按 \n 和 \r 分割输出，以便捕获原地刷新的进度条
+func scanLinesOrCR(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// speedWindow is the span of samples averaged for the download speed
+// speedWindow 是计算平均下载速度所用样本的时间跨度
+const speedWindow = 10 * time.Second
+
+// progressSample is the download percent observed at a point in time
+// progressSample 是某一时刻观察到的下载百分比
+type progressSample struct {
+	at      time.Time
+	percent float64
+}
+
+// progressTracker derives speed, time remaining and retries of one install from its progress updates,
+// so the UI, the tray and the taskbar all show the same numbers
+// progressTracker 根据一次安装的进度更新计算速度、剩余时间和重试次数，使界面、托盘和任务栏显示一致的数值
+type progressTracker struct {
+	mu      sync.Mutex
+	total   int64 // 下载大小，未知时为 0 / download size, 0 when unknown
+	samples []progressSample
+	retries int
+	last    InstallProgress
+}
+
+// setTotal records the download size once it is known
+// setTotal 在得知下载大小后记录该值
+func (t *progressTracker) setTotal(size int64) {
+	t.mu.Lock()
+	t.total = size
+	t.mu.Unlock()
+}
+
+// observeLine counts the retries nvm reports in its output
+// observeLine 统计 nvm 输出中报告的重试次数
+func (t *progressTracker) observeLine(line string) {
+	lower := strings.ToLower(line)
+	if strings.Contains(lower, "retry") || strings.Contains(lower, "重试") {
+		t.mu.Lock()
+		t.retries++
+		t.mu.Unlock()
+	}
+}
+
+// annotate fills in the rolling average speed, the time remaining and the retry count of an update
+// annotate 为进度更新填写滚动平均速度、剩余时间和重试次数
+func (t *progressTracker) annotate(progress InstallProgress, f formatter) InstallProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	progress.ETASeconds = -1
+	progress.Retries = t.retries
+	if progress.Phase == PhaseDownloading && progress.Percent >= 0 {
+		// 下载占整体进度的前 80%，换算回下载本身的百分比
+		// Downloading is the first 80% of the overall progress, convert back to the download's own percent
+		now := time.Now()
+		t.samples = append(t.samples, progressSample{at: now, percent: float64(progress.Percent) * 100 / 80})
+		for len(t.samples) > 2 && now.Sub(t.samples[0].at) > speedWindow {
+			t.samples = t.samples[1:]
+		}
+		first, latest := t.samples[0], t.samples[len(t.samples)-1]
+		if elapsed := latest.at.Sub(first.at).Seconds(); elapsed > 0 && latest.percent > first.percent {
+			rate := (latest.percent - first.percent) / elapsed
+			progress.ETASeconds = int((100 - latest.percent) / rate)
+			if t.total > 0 {
+				progress.Speed = int64(rate / 100 * float64(t.total))
+			}
+		}
+	}
+
+	if progress.Speed > 0 {
+		progress.SpeedText = f.size(progress.Speed) + "/s"
+	}
+	if progress.ETASeconds >= 0 {
+		progress.ETAText = f.duration(time.Duration(progress.ETASeconds) * time.Second)
+	}
+	t.last = progress
+	return progress
+}
+
+// duration formats a remaining time such as "2 min 5 s"
+// duration 格式化剩余时间，例如 "2 分 5 秒"
+func (f formatter) duration(d time.Duration) string {
+	minutes, seconds := int(d.Minutes()), int(d.Seconds())%60
+	if f.locale == LocaleZhCN {
+		if minutes > 0 {
+			return fmt.Sprintf("%d 分 %d 秒", minutes, seconds)
+		}
+		return fmt.Sprintf("%d 秒", seconds)
+	}
+	if minutes > 0 {
+		return fmt.Sprintf("%d min %d s", minutes, seconds)
+	}
+	return fmt.Sprintf("%d s", seconds)
+}
+
+// installTrackers holds the progress tracker of each running install by version
+// installTrackers 按版本保存每个正在进行的安装的进度跟踪器
+type installTrackers struct {
+	mu        sync.Mutex
+	byVersion map[string]*progressTracker
+}
+
+// startProgressTracking registers a tracker for an install and looks up its download size in the background
+// startProgressTracking 为一次安装登记进度跟踪器，并在后台查询其下载大小
+func (a *App) startProgressTracking(version string) *progressTracker {
+	t := &progressTracker{}
+	p := &a.progress
+	p.mu.Lock()
+	if p.byVersion == nil {
+		p.byVersion = map[string]*progressTracker{}
+	}
+	p.byVersion[version] = t
+	p.mu.Unlock()
+
+	go func() {
+		if preview, err := a.GetInstallPreview(version, ""); err == nil && preview.Size > 0 {
+			t.setTotal(preview.Size)
+		}
+	}()
+	return t
+}
+
+// stopProgressTracking drops the tracker of a finished install
+// stopProgressTracking 删除已结束安装的进度跟踪器
+func (a *App) stopProgressTracking(version string) {
+	p := &a.progress
+	p.mu.Lock()
+	delete(p.byVersion, version)
+	p.mu.Unlock()
+	a.submitTask("tray-tooltip", TaskPriorityNormal, a.refreshTrayTooltip)
+}
+
+// progressTrackerFor returns the tracker of a running install, or nil
+// progressTrackerFor 返回正在进行的安装的进度跟踪器，没有时返回 nil
+func (a *App) progressTrackerFor(version string) *progressTracker {
+	p := &a.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.byVersion[version]
+}
+
+// activeInstallProgress returns the latest progress of the running installs
+// activeInstallProgress 返回正在进行的安装的最新进度
+func (a *App) activeInstallProgress() []InstallProgress {
+	p := &a.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var active []InstallProgress
+	for _, t := range p.byVersion {
+		t.mu.Lock()
+		if t.last.Version != "" {
+			active = append(active, t.last)
+		}
+		t.mu.Unlock()
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Version < active[j].Version })
+	return active
+}
+
+// installProgressLine summarizes a running install for the tray tooltip
+// installProgressLine 为托盘提示概述正在进行的安装
+func (f formatter) installProgressLine(p InstallProgress) string {
+	parts := []string{"Node.js " + p.Version}
+	if p.Percent >= 0 {
+		parts = append(parts, fmt.Sprintf("%d%%", p.Percent))
+	}
+	if p.SpeedText != "" {
+		parts = append(parts, p.SpeedText)
+	}
+	if p.ETAText != "" {
+		if f.locale == LocaleZhCN {
+			parts = append(parts, "剩余 "+p.ETAText)
+		} else {
+			parts = append(parts, p.ETAText+" left")
+		}
+	}
+	if p.Retries > 0 {
+		if f.locale == LocaleZhCN {
+			parts = append(parts, fmt.Sprintf("重试 %d 次", p.Retries))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d retries", p.Retries))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
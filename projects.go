@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project is a registered project directory with its pinned Node.js version
+// Project 表示已登记的项目目录及其固定的 Node.js 版本
+type Project struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	PinnedVersion string `json:"pinnedVersion"`
+	PinSource     string `json:"pinSource"` // .nvmrc、.node-version 或 package.json
+	Shims         bool   `json:"shims"`     // 是否生成了项目垫片 / whether project shims are generated
+
+	Workspace      string `json:"workspace"`      // pnpm、yarn-berry 或 npm 工作区 / pnpm, yarn-berry or npm workspace
+	PackageManager string `json:"packageManager"` // package.json 中的 packageManager 字段 / packageManager field of package.json
+	Corepack       string `json:"corepack"`       // 已通过 corepack 启用的包管理器 / package manager enabled through corepack
+}
+
+// readPinnedVersion determines the Node.js version a project directory asks for
+// readPinnedVersion 读取项目目录要求的 Node.js 版本
+func readPinnedVersion(dir string) (string, string) {
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			version := strings.TrimPrefix(strings.TrimSpace(string(data)), "v")
+			if version != "" {
+				return version, name
+			}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err == nil {
+		var pkg struct {
+			Engines struct {
+				Node string `json:"node"`
+			} `json:"engines"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Engines.Node != "" {
+			return pkg.Engines.Node, "package.json"
+		}
+	}
+	return "", ""
+}
+
+// GetProjects returns the registered projects with their pins re-read from disk
+// GetProjects 返回已登记的项目，并从磁盘重新读取固定版本
+func (a *App) GetProjects() []Project {
+	projects := a.currentSettings().Projects
+	result := make([]Project, 0, len(projects))
+	for _, p := range projects {
+		p.PinnedVersion, p.PinSource = readPinnedVersion(p.Path)
+		p.Workspace, p.PackageManager = detectWorkspace(p.Path)
+		result = append(result, p)
+	}
+	return result
+}
+
+// findProject looks up a registered project by its path
+// findProject 按路径查找已登记的项目
+func (a *App) findProject(path string) (Project, error) {
+	for _, p := range a.GetProjects() {
+		if strings.EqualFold(filepath.Clean(p.Path), filepath.Clean(path)) {
+			return p, nil
+		}
+	}
+	return Project{}, fmt.Errorf("Project is not registered: %s", path)
+}
+
+// AddProject registers a project directory
+// AddProject 登记一个项目目录
+func (a *App) AddProject(path string) (Project, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return Project{}, fmt.Errorf("Not a directory: %s", path)
+	}
+	if existing, err := a.findProject(path); err == nil {
+		return existing, nil
+	}
+
+	project := Project{Name: filepath.Base(path), Path: path}
+	project.PinnedVersion, project.PinSource = readPinnedVersion(path)
+	project.Workspace, project.PackageManager = detectWorkspace(path)
+
+	settings := a.currentSettings()
+	settings.Projects = append(append([]Project{}, settings.Projects...), project)
+	if err := a.SetSettings(settings); err != nil {
+		return Project{}, err
+	}
+	a.logToFile(fmt.Sprintf("Registered project %s (pinned: %s, workspace: %s, package manager: %s)",
+		path, project.PinnedVersion, project.Workspace, project.PackageManager))
+	go a.reportTeamDrift(path)
+	return project, nil
+}
+
+// RemoveProject unregisters a project directory
+// RemoveProject 取消登记一个项目目录
+func (a *App) RemoveProject(path string) error {
+	settings := a.currentSettings()
+	var kept []Project
+	for _, p := range settings.Projects {
+		if !strings.EqualFold(filepath.Clean(p.Path), filepath.Clean(path)) {
+			kept = append(kept, p)
+		}
+	}
+	settings.Projects = kept
+	return a.SetSettings(settings)
+}
+
+// resolveProjectVersion returns the installed version matching the project's pin
+// resolveProjectVersion 返回与项目固定版本匹配的已安装版本
+func (a *App) resolveProjectVersion(project Project) (string, error) {
+	if project.PinnedVersion == "" {
+		return "", fmt.Errorf("Project %s does not pin a Node.js version", project.Name)
+	}
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return "", err
+	}
+
+	// 精确匹配，或按 "18" / "18.17" 这样的前缀匹配最新的已安装版本；
+	// package.json 的 engines.node 可以是 ">=18 <21" 或 "^20" 这样的范围，此时取满足范围的最新版本
+	// Exact match, or the newest installed version matching a prefix such as "18" or "18.17"; engines.node in
+	// package.json may be a range such as ">=18 <21" or "^20", then the newest version satisfying it is used
+	want := strings.TrimPrefix(strings.TrimSpace(project.PinnedVersion), "v")
+	// "lts/*" 这样的 nvm 别名不是范围 / nvm aliases such as "lts/*" are not ranges
+	isRange := strings.ContainsAny(want, "<>=^~|*xX ") && !strings.Contains(want, "/")
+	best := ""
+	for _, v := range installed {
+		if v.Version == want {
+			return v.Version, nil
+		}
+		matches := strings.HasPrefix(v.Version, want+".")
+		if isRange {
+			matches = satisfiesRange(v.Version, want)
+		}
+		if matches && (best == "" || compareSemver(v.Version, best) > 0) {
+			best = v.Version
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("Node.js %s required by %s is not installed", project.PinnedVersion, project.Name)
+	}
+	return best, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// NvmOutputLine is emitted to the frontend as the "nvm-output" event while a raw command runs
+// NvmOutputLine 在原始命令运行期间作为 "nvm-output" 事件发送给前端
+type NvmOutputLine struct {
+	Command string
+	Line    string
+}
+
+// RunRawNvmCommand runs an arbitrary nvm subcommand for power users, streaming its output to the UI
+// It is only available when the advanced setting is enabled
+// RunRawNvmCommand 为高级用户运行任意 nvm 子命令，并将输出实时推送到界面
+// 仅在启用高级设置时可用
+func (a *App) RunRawNvmCommand(args []string) (string, error) {
+	command := strings.Join(args, " ")
+	if !a.currentSettings().AdvancedMode {
+		a.audit("nvm-raw", command, "rejected: advanced mode disabled")
+		return "", fmt.Errorf("Raw nvm commands require the advanced setting to be enabled")
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("No nvm command given")
+	}
+
+	a.logToFile(fmt.Sprintf("Running raw nvm command: nvm %s", command))
+	ctx, done := a.beginCommand("nvm-raw", 0)
+	defer done()
+	output, err := a.executeNvmCommandStreaming(ctx, func(line string) {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "nvm-output", NvmOutputLine{Command: command, Line: line})
+		}
+	}, args...)
+
+	if err != nil {
+		a.audit("nvm-raw", command, "failed: "+err.Error())
+		return string(output), fmt.Errorf("nvm %s failed: %v", command, err)
+	}
+	a.audit("nvm-raw", command, "succeeded")
+	return string(output), nil
+}
+
+// CancelRawNvmCommand stops the running raw nvm command and the processes it started
+// CancelRawNvmCommand 停止正在运行的原始 nvm 命令及其启动的进程
+func (a *App) CancelRawNvmCommand() error {
+	return a.cancelCommand("nvm-raw")
+}
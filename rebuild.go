@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// RebuildOutputLine is emitted to the frontend as the "rebuild-output" event while npm rebuild runs
+// RebuildOutputLine 在 npm rebuild 运行期间作为 "rebuild-output" 事件发送给前端
+type RebuildOutputLine struct {
+	Project string
+	Line    string
+}
+
+// RebuildNativeModules runs `npm rebuild` for a registered project under the current Node.js version
+// RebuildNativeModules 使用当前 Node.js 版本为已登记项目运行 `npm rebuild`
+func (a *App) RebuildNativeModules(projectPath string) (string, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return "", err
+	}
+	version, err := a.currentNodeVersion()
+	if err != nil {
+		return "", err
+	}
+
+	a.logToFile(fmt.Sprintf("Rebuilding native modules of %s for Node.js %s", project.Path, version))
+	output, err := a.runWithNodeVersionStreaming(func(line string) {
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "rebuild-output", RebuildOutputLine{Project: project.Path, Line: line})
+		}
+	}, version, project.Path, "npm.cmd", "rebuild")
+	if err != nil {
+		errMsg := fmt.Sprintf("Error rebuilding native modules of %s: %v", project.Name, err)
+		a.logToFile(errMsg)
+		return string(output), fmt.Errorf("%s", errMsg)
+	}
+
+	successMsg := fmt.Sprintf("Successfully rebuilt native modules of %s for Node.js %s", project.Name, version)
+	a.logToFile(successMsg)
+	return successMsg, nil
+}
+
+// runPostSwitchRebuilds rebuilds the projects selected in the settings after a successful switch
+// runPostSwitchRebuilds 在切换成功后为设置中选定的项目重新构建原生模块
+func (a *App) runPostSwitchRebuilds() {
+	for _, projectPath := range a.currentSettings().RebuildAfterSwitch {
+		if _, err := a.RebuildNativeModules(projectPath); err != nil && a.ctx != nil {
+			runtime.EventsEmit(a.ctx, "rebuild-output", RebuildOutputLine{Project: projectPath, Line: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+// maxRecentVersions caps the versions listed as recently used
+// maxRecentVersions 限制最近使用列表中的版本数量
+const maxRecentVersions = 3
+
+// RecentVersion is an installed version recently switched to
+// RecentVersion 是最近切换使用过的已安装版本
+type RecentVersion struct {
+	Version   string
+	LastUsed  time.Time
+	IsCurrent bool
+}
+
+// trayRecentItems are the tray entries for the recent versions, shown directly in the tray menu
+// trayRecentItems 是托盘菜单中直接显示的最近使用版本条目
+var (
+	trayRecentItems    []*systray.MenuItem
+	trayRecentLabels   []string
+	trayRecentMu       sync.Mutex
+	trayRecentsCreated bool
+)
+
+// GetRecentVersions returns the installed versions most recently switched to, newest first
+// GetRecentVersions 返回最近切换使用过的已安装版本，最近使用的排在最前
+func (a *App) GetRecentVersions() ([]RecentVersion, error) {
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return nil, err
+	}
+	lastUsed := a.currentSettings().LastUsed
+
+	var recent []RecentVersion
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		if used, ok := lastUsed[version]; ok {
+			recent = append(recent, RecentVersion{Version: version, LastUsed: used, IsCurrent: v.IsCurrent})
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].LastUsed.After(recent[j].LastUsed) })
+	if len(recent) > maxRecentVersions {
+		recent = recent[:maxRecentVersions]
+	}
+	return recent, nil
+}
+
+// addRecentTrayItems adds the recent versions to the tray menu, clicking one switches to it
+// addRecentTrayItems 在托盘菜单中添加最近使用的版本，点击即切换到该版本
+func (a *App) addRecentTrayItems() {
+	trayRecentMu.Lock()
+	defer trayRecentMu.Unlock()
+
+	for i := 0; i < maxRecentVersions; i++ {
+		item := systray.AddMenuItem("", "")
+		item.Hide()
+		trayRecentItems = append(trayRecentItems, item)
+		trayRecentLabels = append(trayRecentLabels, "")
+		go func(i int, item *systray.MenuItem) {
+			for range item.ClickedCh {
+				trayRecentMu.Lock()
+				version := trayRecentLabels[i]
+				trayRecentMu.Unlock()
+				if version == "" {
+					continue
+				}
+				a.logToFile(fmt.Sprintf("Tray recent list: %s", a.SwitchNodeVersion(version)))
+			}
+		}(i, item)
+	}
+	trayRecentsCreated = true
+	go a.refreshTrayRecent()
+}
+
+// refreshTrayRecent relabels the tray recent versions
+// refreshTrayRecent 更新托盘中最近使用版本的标题
+func (a *App) refreshTrayRecent() {
+	recent, err := a.GetRecentVersions()
+	if err != nil {
+		return
+	}
+
+	trayRecentMu.Lock()
+	defer trayRecentMu.Unlock()
+	if !trayRecentsCreated {
+		return
+	}
+	for i, item := range trayRecentItems {
+		if i >= len(recent) {
+			trayRecentLabels[i] = ""
+			item.Hide()
+			continue
+		}
+		trayRecentLabels[i] = recent[i].Version
+		title := "最近使用: Node.js " + recent[i].Version
+		if recent[i].IsCurrent {
+			title += " (当前)"
+		}
+		item.SetTitle(title)
+		item.Show()
+	}
+}
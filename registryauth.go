@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Where a registry configuration is written
+// 仓库配置写入的位置
+const (
+	RegistryTargetProject = "project"
+	RegistryTargetVersion = "version"
+)
+
+// RegistryConfig is a private registry the user authenticated against
+// RegistryConfig 表示用户已认证的私有仓库
+type RegistryConfig struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Scope       string `json:"scope"`  // 例如 @corp，为空表示作为默认仓库
+	Target      string `json:"target"` // project 或 version
+	ProjectPath string `json:"projectPath"`
+	Version     string `json:"version"`
+}
+
+// RegistryAuthResult reports the outcome of configuring a registry
+// RegistryAuthResult 报告配置仓库的结果
+type RegistryAuthResult struct {
+	Registry RegistryConfig
+	Npmrc    string
+	User     string
+	Valid    bool
+	Message  string
+}
+
+// secretName returns the secrets store key of the registry token
+// secretName 返回仓库令牌在敏感信息存储中的键
+func (r RegistryConfig) secretName() string {
+	return "registry." + r.Name
+}
+
+// npmrcLines returns the registry line for the project or version .npmrc and the key of the auth line
+// npmrcLines 返回写入项目或版本 .npmrc 的仓库配置行，以及认证配置项的键
+func (r RegistryConfig) npmrcLines() (map[string]string, string, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil || u.Host == "" {
+		return nil, "", fmt.Errorf("invalid registry URL %q", r.URL)
+	}
+	registry := strings.TrimRight(r.URL, "/") + "/"
+	key := "registry"
+	if r.Scope != "" {
+		key = r.Scope + ":registry"
+	}
+	// npm 按去掉协议后的地址匹配认证信息
+	// npm matches credentials by the URL without its scheme
+	authKey := "//" + u.Host + strings.TrimRight(u.Path, "/") + "/:_authToken"
+	return map[string]string{key: registry}, authKey, nil
+}
+
+// userNpmrcPath returns the per-user .npmrc that npm reads in every terminal, like `npm login` does
+// userNpmrcPath 返回 npm 在任何终端中都会读取的用户级 .npmrc，与 `npm login` 使用的相同
+func userNpmrcPath() string {
+	if path := os.Getenv("NPM_CONFIG_USERCONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(os.Getenv("USERPROFILE"), ".npmrc")
+}
+
+// npmrcPath returns the .npmrc the registry is written to
+// npmrcPath 返回写入仓库配置的 .npmrc 路径
+func (a *App) npmrcPath(r RegistryConfig) (string, error) {
+	switch r.Target {
+	case RegistryTargetProject:
+		project, err := a.findProject(r.ProjectPath)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(project.Path, ".npmrc"), nil
+	case RegistryTargetVersion:
+		_, dir, err := a.versionEnv(r.Version)
+		if err != nil {
+			return "", err
+		}
+		return builtinNpmrcPath(dir), nil
+	default:
+		return "", fmt.Errorf("unknown registry target %q", r.Target)
+	}
+}
+
+// setNpmrcKeys sets the given keys in an .npmrc file, keeping all other lines
+// setNpmrcKeys 在 .npmrc 文件中设置指定的配置项，并保留其他所有行
+func setNpmrcKeys(fsys FileSystem, path string, values map[string]string) error {
+	data, err := fsys.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok {
+			if _, replaced := values[strings.TrimSpace(name)]; replaced {
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	// 按键排序追加，使文件内容稳定
+	// Append in key order so the file content is stable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if values[key] != "" {
+			lines = append(lines, key+"="+values[key])
+		}
+	}
+	return fsys.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0644)
+}
+
+// ConfigureRegistryAuth stores the token in the secrets store, points the project or version .npmrc at the
+// registry, writes the token to the user .npmrc so npm finds it in any terminal but it is never committed with
+// the project, and validates the credentials with `npm whoami`
+// ConfigureRegistryAuth 将令牌保存到敏感信息存储，在项目或版本的 .npmrc 中配置该仓库，
+// 将令牌写入用户级 .npmrc，使 npm 在任何终端中都能找到它且不会随项目提交，并使用 `npm whoami` 验证凭据
+func (a *App) ConfigureRegistryAuth(registry RegistryConfig, token string) (RegistryAuthResult, error) {
+	registry.Name = strings.TrimSpace(registry.Name)
+	registry.Version = strings.TrimPrefix(strings.TrimSpace(registry.Version), "v")
+	if registry.Scope != "" && !strings.HasPrefix(registry.Scope, "@") {
+		registry.Scope = "@" + registry.Scope
+	}
+	result := RegistryAuthResult{Registry: registry}
+	if registry.Name == "" {
+		return result, fmt.Errorf("registry name is required")
+	}
+	lines, authKey, err := registry.npmrcLines()
+	if err != nil {
+		return result, err
+	}
+	npmrc, err := a.npmrcPath(registry)
+	if err != nil {
+		return result, err
+	}
+	result.Npmrc = npmrc
+
+	if token != "" {
+		if err := a.setSecret(registry.secretName(), token); err != nil {
+			return result, err
+		}
+	} else if token, err = a.getSecret(registry.secretName()); err != nil {
+		return result, err
+	}
+	// 删除旧版本写入的 ${NVS_TOKEN_*} 认证行，该变量只存在于本应用启动的进程中
+	// Drop the ${NVS_TOKEN_*} auth line older versions wrote, that variable only existed in processes started by the app
+	lines[authKey] = ""
+	if err := setNpmrcKeys(a.fs, npmrc, lines); err != nil {
+		return result, fmt.Errorf("Error writing %s: %v", npmrc, err)
+	}
+	if token != "" {
+		if err := setNpmrcKeys(a.fs, userNpmrcPath(), map[string]string{authKey: token}); err != nil {
+			return result, fmt.Errorf("Error writing %s: %v", userNpmrcPath(), err)
+		}
+	}
+
+	settings := a.currentSettings()
+	registries := []RegistryConfig{}
+	for _, r := range settings.Registries {
+		if r.Name != registry.Name {
+			registries = append(registries, r)
+		}
+	}
+	settings.Registries = append(registries, registry)
+	if err := a.SetSettings(settings); err != nil {
+		return result, err
+	}
+	a.audit("configure-registry", fmt.Sprintf("%s -> %s", registry.Name, npmrc), "success")
+
+	result.User, err = a.validateRegistry(registry)
+	if err != nil {
+		result.Message = fmt.Sprintf("npm whoami 失败 / npm whoami failed: %v", err)
+		return result, nil
+	}
+	result.Valid = true
+	result.Message = fmt.Sprintf("已以 %s 身份登录 / Logged in as %s", result.User, result.User)
+	return result, nil
+}
+
+// validateRegistry runs `npm whoami` against the registry and returns the user name
+// validateRegistry 针对该仓库运行 `npm whoami` 并返回用户名
+func (a *App) validateRegistry(registry RegistryConfig) (string, error) {
+	version := registry.Version
+	workDir := ""
+	if registry.Target == RegistryTargetProject {
+		project, err := a.findProject(registry.ProjectPath)
+		if err != nil {
+			return "", err
+		}
+		workDir = project.Path
+		if version, err = a.resolveProjectVersion(project); err != nil {
+			return "", err
+		}
+	}
+	if version == "" {
+		current, err := a.currentNodeVersion()
+		if err != nil {
+			return "", err
+		}
+		version = current
+	}
+
+	output, err := a.runWithNodeVersion(version, workDir, "npm.cmd", "whoami", "--registry", registry.URL)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ValidateRegistryAuth re-runs `npm whoami` for a configured registry
+// ValidateRegistryAuth 为已配置的仓库重新运行 `npm whoami`
+func (a *App) ValidateRegistryAuth(name string) (RegistryAuthResult, error) {
+	for _, r := range a.currentSettings().Registries {
+		if r.Name != name {
+			continue
+		}
+		result := RegistryAuthResult{Registry: r}
+		user, err := a.validateRegistry(r)
+		if err != nil {
+			result.Message = fmt.Sprintf("npm whoami 失败 / npm whoami failed: %v", err)
+			return result, nil
+		}
+		result.User = user
+		result.Valid = true
+		return result, nil
+	}
+	return RegistryAuthResult{}, fmt.Errorf("registry %s is not configured", name)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReinstallResult reports the outcome of reinstalling a version in place
+// ReinstallResult 报告原地重新安装某个版本的结果
+type ReinstallResult struct {
+	Version   string
+	Globals   []string
+	Restored  bool
+	BackupDir string
+	Message   string
+}
+
+// bundledGlobals are the packages shipped with Node.js itself and therefore never backed up
+// bundledGlobals 是 Node.js 自带的包，因此不需要备份
+var bundledGlobals = map[string]bool{"npm": true, "corepack": true}
+
+// globalPackageDirs returns the global package directories of an install keyed by "name@version"
+// globalPackageDirs 返回安装目录中的全局包目录，键为 "name@version"
+func globalPackageDirs(versionPath string) map[string]string {
+	packages := map[string]string{}
+	modules := filepath.Join(versionPath, "node_modules")
+
+	var dirs []string
+	entries, _ := os.ReadDir(modules)
+	for _, entry := range entries {
+		if !entry.IsDir() || bundledGlobals[entry.Name()] || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), "@") {
+			scoped, _ := os.ReadDir(filepath.Join(modules, entry.Name()))
+			for _, s := range scoped {
+				if s.IsDir() {
+					dirs = append(dirs, filepath.Join(modules, entry.Name(), s.Name()))
+				}
+			}
+			continue
+		}
+		dirs = append(dirs, filepath.Join(modules, entry.Name()))
+	}
+
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(data, &pkg) != nil || pkg.Name == "" {
+			continue
+		}
+		spec := pkg.Name
+		if pkg.Version != "" {
+			spec += "@" + pkg.Version
+		}
+		packages[spec] = dir
+	}
+	return packages
+}
+
+// ReinstallVersion backs up the global packages of a version, removes and re-downloads it,
+// then installs the same globals again
+// ReinstallVersion 备份某个版本的全局包，删除并重新下载该版本，然后重新安装相同的全局包
+func (a *App) ReinstallVersion(version string) (ReinstallResult, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	result := ReinstallResult{Version: version}
+
+	root := a.nvmRoot()
+	if root == "" {
+		return result, fmt.Errorf("nvm root not found")
+	}
+	dir := versionDir(root, version)
+	if _, err := os.Stat(dir); err != nil {
+		return result, fmt.Errorf("Node.js %s is not installed", version)
+	}
+
+	// 备份目录放在 nvm 根目录下，以便在同一磁盘上直接移动
+	// The backup lives under the nvm root so packages are moved on the same volume
+	packages := globalPackageDirs(dir)
+	result.BackupDir = filepath.Join(root, ".nvs-backup", "v"+version)
+	for spec, pkgDir := range packages {
+		rel, _ := filepath.Rel(dir, pkgDir)
+		target := filepath.Join(result.BackupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return result, fmt.Errorf("Error creating backup directory: %v", err)
+		}
+		if err := os.Rename(pkgDir, target); err != nil {
+			return result, fmt.Errorf("Error backing up %s: %v", spec, err)
+		}
+		result.Globals = append(result.Globals, spec)
+	}
+	sort.Strings(result.Globals)
+	a.logToFile(fmt.Sprintf("Backed up %d global packages of %s to %s", len(result.Globals), version, result.BackupDir))
+
+	a.uninstallNodeVersionWithinCap(version)
+	// nvm 无法卸载损坏的安装时直接删除目录
+	// Delete the directory directly when nvm cannot uninstall a broken install
+	os.RemoveAll(longPath(dir))
+
+	a.installNodeVersionWithinCap(version)
+	if reason := diagnoseVersionDir(dir); reason != "" {
+		a.audit("reinstall", version, "failed")
+		result.Message = fmt.Sprintf("Reinstall of %s failed (%s), global packages are kept in %s", version, reason, result.BackupDir)
+		return result, fmt.Errorf("%s", result.Message)
+	}
+
+	if len(result.Globals) > 0 {
+		args := append([]string{"install", "-g"}, result.Globals...)
+		output, err := a.runWithNodeVersion(version, dir, "npm.cmd", args...)
+		if err != nil {
+			a.logToFile(fmt.Sprintf("Error restoring global packages of %s: %v: %s", version, err, string(output)))
+			a.audit("reinstall", version, "globals-not-restored")
+			result.Message = fmt.Sprintf("Reinstalled %s but global packages could not be restored, they are kept in %s", version, result.BackupDir)
+			return result, nil
+		}
+	}
+
+	result.Restored = true
+	os.RemoveAll(longPath(result.BackupDir))
+	result.BackupDir = ""
+	result.Message = fmt.Sprintf("Reinstalled %s and restored %d global packages", version, len(result.Globals))
+	a.logToFile(result.Message)
+	a.audit("reinstall", version, "success")
+	return result, nil
+}
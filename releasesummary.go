@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// nodeChangelogURL is the changelog of one Node.js major line in the nodejs/node repository
+// nodeChangelogURL 是 nodejs/node 仓库中某个 Node.js 主版本线的更新日志
+const nodeChangelogURL = "https://raw.githubusercontent.com/nodejs/node/main/doc/changelogs/CHANGELOG_V%d.md"
+
+// nodeChangelogTTL is how long a downloaded changelog is used before refreshing
+// nodeChangelogTTL 是已下载的更新日志在刷新前的使用时长
+const nodeChangelogTTL = 24 * time.Hour
+
+// releaseSummariesFile is the cache file holding the parsed summaries by version
+// releaseSummariesFile 是按版本保存已解析摘要的缓存文件
+const releaseSummariesFile = "release-summaries.json"
+
+// minChangelogMajor is the first major line with a CHANGELOG_V<major>.md file
+// minChangelogMajor 是第一个拥有 CHANGELOG_V<major>.md 文件的主版本
+const minChangelogMajor = 4
+
+// Change categories of a release summary
+// 发布摘要中的变更分类
+const (
+	ChangeBreaking = "breaking"
+	ChangeSecurity = "security"
+	ChangeDeps     = "deps"
+	ChangeFeature  = "feature"
+)
+
+// changeCategoryText is the title of each change category by locale
+// changeCategoryText 是各变更分类按区域设置区分的标题
+var changeCategoryText = map[string]map[string]string{
+	ChangeBreaking: {LocaleZhCN: "破坏性变更", LocaleEnUS: "Breaking changes"},
+	ChangeSecurity: {LocaleZhCN: "安全", LocaleEnUS: "Security"},
+	ChangeDeps:     {LocaleZhCN: "依赖更新", LocaleEnUS: "Dependencies"},
+	ChangeFeature:  {LocaleZhCN: "新功能", LocaleEnUS: "Features"},
+}
+
+var (
+	// nodeReleaseHeadingRegex matches headings such as "## 2024-01-09, Version 20.11.0 'Iron' (LTS), @someone"
+	// nodeReleaseHeadingRegex 匹配 "## 2024-01-09, Version 20.11.0 'Iron' (LTS), @someone" 等标题
+	nodeReleaseHeadingRegex = regexp.MustCompile(`^##\s+(\d{4}-\d{2}-\d{2}),\s+Version\s+(\d+\.\d+\.\d+)`)
+	commitRefRegex          = regexp.MustCompile("^\\\\?\\[\\[`[0-9a-f]+`\\]\\([^)]*\\)\\\\?\\]\\s*-\\s*")
+	markdownLinkRegex       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	subsystemRegex          = regexp.MustCompile(`^\*\*([^*]+)\*\*:\s*`)
+	trailingRefsRegex       = regexp.MustCompile(`(\s*\([^()]*\))?(\s*,?\s*#\d+)+\s*$`)
+)
+
+// ChangeItem is one categorized entry of the notable changes
+// ChangeItem 是重要变更中已分类的一项
+type ChangeItem struct {
+	Category  string
+	Subsystem string
+	Text      string
+}
+
+// ReleaseSummary is the structured summary of one release's notable changes
+// ReleaseSummary 是一个版本重要变更的结构化摘要
+type ReleaseSummary struct {
+	Version        string
+	Date           string
+	Items          []ChangeItem
+	Counts         map[string]int
+	CategoryTitles map[string]string `json:",omitempty"` // 读取时按区域设置填写 / filled per locale when read
+}
+
+// releaseSummaryStore caches ReleaseSummary by version, persisted as JSON in the cache directory
+// releaseSummaryStore 按版本缓存 ReleaseSummary，并以 JSON 形式保存在缓存目录中
+type releaseSummaryStore struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]ReleaseSummary
+}
+
+// load reads the store from disk once, the caller holds s.mu
+// load 从磁盘读取一次缓存，调用方需持有 s.mu
+func (s *releaseSummaryStore) load(fsys FileSystem) {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.entries = map[string]ReleaseSummary{}
+	if data, err := fsys.ReadFile(cacheFilePath(releaseSummariesFile)); err == nil {
+		json.Unmarshal(data, &s.entries)
+	}
+}
+
+// save writes the store to disk, the caller holds s.mu
+// save 将缓存写入磁盘，调用方需持有 s.mu
+func (s *releaseSummaryStore) save(fsys FileSystem) error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(cacheDir(), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFile(cacheFilePath(releaseSummariesFile), data, 0644)
+}
+
+// classifyChange picks the category of a change from its text, its subsystem and the heading it appears under
+// classifyChange 根据变更文本、所属子系统及所在标题确定其分类
+func classifyChange(text, subsystem, heading string) string {
+	lower := strings.ToLower(text + " " + heading)
+	switch {
+	case strings.Contains(lower, "semver-major") || strings.Contains(lower, "breaking"):
+		return ChangeBreaking
+	case strings.Contains(lower, "security") || strings.Contains(lower, "cve-") || strings.Contains(lower, "vulnerab"):
+		return ChangeSecurity
+	case subsystem == "deps" || strings.HasPrefix(subsystem, "deps,") || strings.HasPrefix(lower, "deps:"):
+		return ChangeDeps
+	}
+	return ChangeFeature
+}
+
+// parseChangeItem turns a changelog bullet into a categorized item, dropping commit links and PR references
+// parseChangeItem 将更新日志中的一条列表项转换为已分类的变更，并去掉提交链接和 PR 引用
+func parseChangeItem(line, heading string) (ChangeItem, bool) {
+	text := commitRefRegex.ReplaceAllString(line, "")
+	breaking := strings.Contains(text, "(SEMVER-MAJOR)")
+	text = strings.ReplaceAll(text, "**(SEMVER-MAJOR)**", "")
+	text = strings.TrimSpace(strings.ReplaceAll(text, "(SEMVER-MAJOR)", ""))
+	text = markdownLinkRegex.ReplaceAllString(text, "$1")
+
+	var subsystem string
+	if m := subsystemRegex.FindStringSubmatch(text); m != nil {
+		subsystem = strings.TrimSpace(m[1])
+		text = text[len(m[0]):]
+	}
+	text = strings.TrimSpace(trailingRefsRegex.ReplaceAllString(text, ""))
+	if text == "" {
+		return ChangeItem{}, false
+	}
+
+	item := ChangeItem{Subsystem: subsystem, Text: text}
+	if breaking {
+		item.Category = ChangeBreaking
+	} else {
+		item.Category = classifyChange(text, subsystem, heading)
+	}
+	return item, true
+}
+
+// parseNodeChangelog extracts the notable changes and the semver-major commits of every release in a
+// CHANGELOG_V<major>.md file
+// parseNodeChangelog 从 CHANGELOG_V<major>.md 文件中提取每个版本的重要变更和 semver-major 提交
+func parseNodeChangelog(text string) []ReleaseSummary {
+	var summaries []ReleaseSummary
+	var section, heading string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if m := nodeReleaseHeadingRegex.FindStringSubmatch(line); m != nil {
+			summaries = append(summaries, ReleaseSummary{Version: m[2], Date: m[1], Counts: map[string]int{}})
+			section, heading = "", ""
+			continue
+		}
+		if len(summaries) == 0 {
+			continue
+		}
+		summary := &summaries[len(summaries)-1]
+		switch {
+		case strings.HasPrefix(line, "### "):
+			section = strings.ToLower(strings.TrimSpace(line[4:]))
+			heading = ""
+		case strings.HasPrefix(line, "#### "):
+			heading = strings.TrimSpace(line[5:])
+			// 重要变更中的小标题本身通常就是一项功能说明
+			// A sub-heading in the notable changes usually names a change on its own
+			lower := strings.ToLower(heading)
+			if section == "notable changes" && !strings.Contains(lower, "other notable") && !strings.Contains(lower, "semver-") {
+				item := ChangeItem{Text: heading, Category: classifyChange(heading, "", "")}
+				summary.Items = append(summary.Items, item)
+				summary.Counts[item.Category]++
+			}
+		case strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "- "):
+			var item ChangeItem
+			var ok bool
+			switch section {
+			case "notable changes":
+				item, ok = parseChangeItem(strings.TrimSpace(line[2:]), heading)
+			case "semver-major commits":
+				if item, ok = parseChangeItem(strings.TrimSpace(line[2:]), heading); ok {
+					item.Category = ChangeBreaking
+				}
+			}
+			if ok {
+				summary.Items = append(summary.Items, item)
+				summary.Counts[item.Category]++
+			}
+		}
+	}
+	return summaries
+}
+
+// loadReleaseSummaries makes sure the summaries of a major line are cached, downloading its changelog at most
+// once per nodeChangelogTTL. The caller holds s.mu
+// loadReleaseSummaries 确保某个主版本线的摘要已缓存，每个 nodeChangelogTTL 周期内最多下载一次其更新日志。调用方需持有 s.mu
+func (a *App) loadReleaseSummaries(major int) error {
+	if major < minChangelogMajor {
+		return fmt.Errorf("No changelog for Node.js %d.x", major)
+	}
+	data, _, err := a.fetchCached(fmt.Sprintf("CHANGELOG_V%d.md", major), fmt.Sprintf(nodeChangelogURL, major), nodeChangelogTTL)
+	if err != nil {
+		return fmt.Errorf("Error fetching the Node.js %d.x changelog: %v", major, err)
+	}
+	s := &a.summaries
+	changed := false
+	for _, summary := range parseNodeChangelog(string(data)) {
+		if _, ok := s.entries[summary.Version]; !ok {
+			s.entries[summary.Version] = summary
+			changed = true
+		}
+	}
+	if changed {
+		if err := s.save(a.fs); err != nil {
+			a.logToFile(fmt.Sprintf("Error saving release summaries: %v", err))
+		}
+	}
+	return nil
+}
+
+// versionMajor returns the major number of a version such as "v20.11.0"
+// versionMajor 返回 "v20.11.0" 等版本的主版本号
+func versionMajor(version string) int {
+	major, _ := strconv.Atoi(strings.SplitN(strings.TrimPrefix(version, "v"), ".", 2)[0])
+	return major
+}
+
+// localizeSummary fills in the category titles for the current locale
+// localizeSummary 按当前区域设置填写分类标题
+func localizeSummary(summary ReleaseSummary, f formatter) ReleaseSummary {
+	summary.CategoryTitles = map[string]string{}
+	for category, texts := range changeCategoryText {
+		summary.CategoryTitles[category] = localizedText(texts, f.locale, category)
+	}
+	return summary
+}
+
+// GetReleaseSummary returns the categorized notable changes of one Node.js release
+// GetReleaseSummary 返回某个 Node.js 版本已分类的重要变更
+func (a *App) GetReleaseSummary(version string) (ReleaseSummary, error) {
+	version = strings.TrimPrefix(version, "v")
+	s := &a.summaries
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(a.fs)
+
+	summary, ok := s.entries[version]
+	if !ok {
+		if err := a.loadReleaseSummaries(versionMajor(version)); err != nil {
+			return ReleaseSummary{}, err
+		}
+		if summary, ok = s.entries[version]; !ok {
+			return ReleaseSummary{}, fmt.Errorf("No release notes for Node.js %s", version)
+		}
+	}
+	return localizeSummary(summary, a.formatter()), nil
+}
+
+// GetReleaseSummariesBetween returns the summaries of the releases after from up to and including to, newest first,
+// for comparing two versions or advising on an upgrade. Each major line's changelog is fetched once
+// GetReleaseSummariesBetween 返回 from 之后直到 to（含）的各版本摘要，最新的在前，用于比较两个版本或提供升级建议。
+// 每个主版本线的更新日志只下载一次
+func (a *App) GetReleaseSummariesBetween(from, to string) ([]ReleaseSummary, error) {
+	from, to = strings.TrimPrefix(from, "v"), strings.TrimPrefix(to, "v")
+	if compareSemver(from, to) > 0 {
+		from, to = to, from
+	}
+	first, last := versionMajor(from), versionMajor(to)
+	if first < minChangelogMajor {
+		first = minChangelogMajor
+	}
+
+	s := &a.summaries
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(a.fs)
+
+	for major := first; major <= last; major++ {
+		// 奇数主版本线可能已从仓库中移除，缺失时跳过
+		// Odd lines may be gone from the repository, a missing one is skipped
+		if err := a.loadReleaseSummaries(major); err != nil {
+			a.logToFile(err.Error())
+		}
+	}
+
+	f := a.formatter()
+	var result []ReleaseSummary
+	for version, summary := range s.entries {
+		if compareSemver(version, from) > 0 && compareSemver(version, to) <= 0 {
+			result = append(result, localizeSummary(summary, f))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return compareSemver(result[i].Version, result[j].Version) > 0 })
+	return result, nil
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runtimeReleasesTTL is how long a runtime's release list is cached
+// runtimeReleasesTTL 是运行时发布列表的缓存时长
+const runtimeReleasesTTL = time.Hour
+
+// runtimeDownloadTimeout bounds the download of a runtime archive
+// runtimeDownloadTimeout 限制下载运行时压缩包的时长
+const runtimeDownloadTimeout = 10 * time.Minute
+
+// RuntimeRelease is one release of a JavaScript runtime
+// RuntimeRelease 表示 JavaScript 运行时的一个发布版本
+type RuntimeRelease struct {
+	Runtime    string
+	Version    string
+	Prerelease bool
+	Installed  bool
+	Active     bool
+	AssetURL   string
+}
+
+// RuntimeProvider lists, downloads and locates the releases of one JavaScript runtime
+// RuntimeProvider 负责列出、下载和定位某个 JavaScript 运行时的发布版本
+type RuntimeProvider interface {
+	// Name is the runtime identifier, e.g. "bun"
+	// Name 是运行时标识，例如 "bun"
+	Name() string
+	// Executable is the file name of the runtime binary
+	// Executable 是运行时可执行文件的文件名
+	Executable() string
+	// Releases returns the releases available for Windows, newest first
+	// Releases 返回适用于 Windows 的发布版本，最新的在前
+	Releases(a *App) ([]RuntimeRelease, error)
+}
+
+// githubRuntimeProvider is a runtime published as zip assets on GitHub releases
+// githubRuntimeProvider 表示以 zip 附件形式发布在 GitHub 上的运行时
+type githubRuntimeProvider struct {
+	name      string
+	repo      string
+	tagPrefix string
+	asset     string
+	exe       string
+}
+
+// runtimeProviders are the runtimes managed next to Node.js
+// runtimeProviders 是与 Node.js 一同管理的运行时
+var runtimeProviders = []RuntimeProvider{
+	githubRuntimeProvider{name: "bun", repo: "oven-sh/bun", tagPrefix: "bun-v", asset: "bun-windows-x64.zip", exe: "bun.exe"},
+	githubRuntimeProvider{name: "deno", repo: "denoland/deno", tagPrefix: "v", asset: "deno-x86_64-pc-windows-msvc.zip", exe: "deno.exe"},
+}
+
+// Name implements RuntimeProvider
+// Name 实现 RuntimeProvider 接口
+func (p githubRuntimeProvider) Name() string { return p.name }
+
+// Executable implements RuntimeProvider
+// Executable 实现 RuntimeProvider 接口
+func (p githubRuntimeProvider) Executable() string { return p.exe }
+
+// Releases implements RuntimeProvider using the GitHub release API
+// Releases 通过 GitHub 发布接口实现 RuntimeProvider 接口
+func (p githubRuntimeProvider) Releases(a *App) ([]RuntimeRelease, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=100", p.repo)
+	data, _, err := a.fetchCached(p.name+"-releases.json", url, runtimeReleasesTTL)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching %s releases: %v", p.name, err)
+	}
+	var releases []githubRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("Error parsing %s releases: %v", p.name, err)
+	}
+
+	var result []RuntimeRelease
+	for _, release := range releases {
+		if release.Draft || !strings.HasPrefix(release.TagName, p.tagPrefix) {
+			continue
+		}
+		for _, asset := range release.Assets {
+			if asset.Name == p.asset {
+				result = append(result, RuntimeRelease{
+					Runtime:    p.name,
+					Version:    strings.TrimPrefix(release.TagName, p.tagPrefix),
+					Prerelease: release.Prerelease,
+					AssetURL:   asset.BrowserDownloadURL,
+				})
+				break
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return compareAppVersion(result[i].Version, result[j].Version) > 0
+	})
+	return result, nil
+}
+
+// runtimeProvider looks up a provider by name
+// runtimeProvider 按名称查找运行时提供者
+func runtimeProvider(name string) (RuntimeProvider, error) {
+	for _, p := range runtimeProviders {
+		if strings.EqualFold(p.Name(), name) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown runtime: %s", name)
+}
+
+// runtimesDir returns the managed runtime directory next to the executable
+// runtimesDir 返回可执行文件同目录下的运行时管理目录
+func runtimesDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "runtimes"
+	}
+	return filepath.Join(filepath.Dir(execPath), "runtimes")
+}
+
+// runtimeVersionDir returns the directory a runtime version is installed to
+// runtimeVersionDir 返回运行时某个版本的安装目录
+func runtimeVersionDir(runtime, version string) string {
+	return filepath.Join(runtimesDir(), runtime, version)
+}
+
+// runtimeShimDir returns the directory holding the shims of the active runtimes, which is added to the user PATH
+// runtimeShimDir 返回存放当前运行时垫片的目录，该目录会加入用户 PATH
+func runtimeShimDir() string {
+	return filepath.Join(runtimesDir(), "bin")
+}
+
+// installedRuntimeVersions lists the installed versions of a runtime
+// installedRuntimeVersions 列出某个运行时已安装的版本
+func installedRuntimeVersions(p RuntimeProvider) []string {
+	entries, err := os.ReadDir(filepath.Join(runtimesDir(), p.Name()))
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if _, err := os.Stat(filepath.Join(runtimesDir(), p.Name(), entry.Name(), p.Executable())); err == nil {
+				versions = append(versions, entry.Name())
+			}
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareAppVersion(versions[i], versions[j]) > 0 })
+	return versions
+}
+
+// activeRuntimeVersion returns the version a runtime's shim points to
+// activeRuntimeVersion 返回运行时垫片当前指向的版本
+func (a *App) activeRuntimeVersion(runtime string) string {
+	return a.currentSettings().Runtimes[runtime]
+}
+
+// GetRuntimes returns the names of the managed runtimes besides Node.js
+// GetRuntimes 返回除 Node.js 外受管理的运行时名称
+func (a *App) GetRuntimes() []string {
+	names := make([]string, 0, len(runtimeProviders))
+	for _, p := range runtimeProviders {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// GetRuntimeReleases returns the releases of a runtime marked with their install state
+// GetRuntimeReleases 返回运行时的发布版本并标记安装状态
+func (a *App) GetRuntimeReleases(runtime string) ([]RuntimeRelease, error) {
+	p, err := runtimeProvider(runtime)
+	if err != nil {
+		return nil, err
+	}
+	installed := map[string]bool{}
+	for _, v := range installedRuntimeVersions(p) {
+		installed[v] = true
+	}
+	active := a.activeRuntimeVersion(p.Name())
+
+	releases, err := p.Releases(a)
+	if err != nil {
+		return nil, err
+	}
+	for i := range releases {
+		releases[i].Installed = installed[releases[i].Version]
+		releases[i].Active = releases[i].Version == active
+	}
+	return releases, nil
+}
+
+// GetInstalledRuntimeVersions returns the installed versions of a runtime without going online
+// GetInstalledRuntimeVersions 无需联网返回运行时已安装的版本
+func (a *App) GetInstalledRuntimeVersions(runtime string) ([]RuntimeRelease, error) {
+	p, err := runtimeProvider(runtime)
+	if err != nil {
+		return nil, err
+	}
+	active := a.activeRuntimeVersion(p.Name())
+	var result []RuntimeRelease
+	for _, v := range installedRuntimeVersions(p) {
+		result = append(result, RuntimeRelease{Runtime: p.Name(), Version: v, Installed: true, Active: v == active})
+	}
+	return result, nil
+}
+
+// downloadToFile streams url into a temporary file, counting its bytes in the metrics
+// downloadToFile 将 url 流式下载到临时文件，并将字节数计入指标
+func (a *App) downloadToFile(url string) (string, error) {
+	if a.safeMode {
+		return "", fmt.Errorf("networking disabled in safe mode")
+	}
+	client := a.client(runtimeDownloadTimeout)
+	resp, err := client.Get(url)
+	if err != nil {
+		a.metrics.recordError()
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.recordError()
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "nvs-runtime-*.zip")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, &countingReader{r: resp.Body, metrics: a.metrics, host: resp.Request.URL.Host}); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), tmp.Close()
+}
+
+// InstallRuntime downloads a runtime version into the managed directory
+// InstallRuntime 将运行时的某个版本下载到管理目录
+func (a *App) InstallRuntime(runtime, version string) error {
+	p, err := runtimeProvider(runtime)
+	if err != nil {
+		return err
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	releases, err := p.Releases(a)
+	if err != nil {
+		return err
+	}
+	var release *RuntimeRelease
+	for i := range releases {
+		if releases[i].Version == version {
+			release = &releases[i]
+		}
+	}
+	if release == nil {
+		return fmt.Errorf("%s %s has no Windows build", p.Name(), version)
+	}
+
+	a.logToFile(fmt.Sprintf("Installing %s %s from %s", p.Name(), version, release.AssetURL))
+	archive, err := a.downloadToFile(release.AssetURL)
+	if err != nil {
+		a.audit("install-runtime", p.Name()+" "+version, "failed")
+		return fmt.Errorf("Error downloading %s %s: %v", p.Name(), version, err)
+	}
+	defer os.Remove(archive)
+
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("Error opening %s archive: %v", p.Name(), err)
+	}
+	defer zr.Close()
+
+	dir := runtimeVersionDir(p.Name(), version)
+	found := false
+	for _, file := range zr.File {
+		// 压缩包中的可执行文件可能位于子目录中
+		// The executable may sit in a sub directory of the archive
+		if strings.EqualFold(filepath.Base(filepath.FromSlash(file.Name)), p.Executable()) {
+			if err := extractZipEntry(file, dir, p.Executable()); err != nil {
+				os.RemoveAll(dir)
+				return fmt.Errorf("Error extracting %s: %v", p.Executable(), err)
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%s archive does not contain %s", p.Name(), p.Executable())
+	}
+
+	a.audit("install-runtime", p.Name()+" "+version, "success")
+	a.logToFile(fmt.Sprintf("Installed %s %s to %s", p.Name(), version, dir))
+	return nil
+}
+
+// UseRuntime points the runtime's shim at version and makes sure the shim directory is on the user PATH
+// UseRuntime 将运行时垫片指向指定版本，并确保垫片目录位于用户 PATH 中
+func (a *App) UseRuntime(runtime, version string) error {
+	p, err := runtimeProvider(runtime)
+	if err != nil {
+		return err
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	versionPath := runtimeVersionDir(p.Name(), version)
+	if _, err := os.Stat(filepath.Join(versionPath, p.Executable())); err != nil {
+		return fmt.Errorf("%s %s is not installed", p.Name(), version)
+	}
+
+	shimDir := runtimeShimDir()
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		return fmt.Errorf("Error creating shim directory: %v", err)
+	}
+	shim := filepath.Join(shimDir, strings.TrimSuffix(p.Executable(), filepath.Ext(p.Executable()))+".cmd")
+	if err := os.WriteFile(shim, encodeConsoleText(cmdShim(versionPath, p.Executable())), 0755); err != nil {
+		return fmt.Errorf("Error writing shim: %v", err)
+	}
+	if err := addToUserPath(shimDir); err != nil {
+		a.logToFile(fmt.Sprintf("Error adding %s to the user PATH: %v", shimDir, err))
+		return fmt.Errorf("Error adding %s to PATH: %v", shimDir, err)
+	}
+
+	settings := a.currentSettings()
+	runtimes := map[string]string{}
+	for name, v := range settings.Runtimes {
+		runtimes[name] = v
+	}
+	runtimes[p.Name()] = version
+	settings.Runtimes = runtimes
+	if err := a.SetSettings(settings); err != nil {
+		return err
+	}
+
+	a.audit("use-runtime", p.Name()+" "+version, "success")
+	a.logToFile(fmt.Sprintf("Switched %s to %s", p.Name(), version))
+	return nil
+}
+
+// UninstallRuntime removes a runtime version; the active version must be switched away from first
+// UninstallRuntime 删除运行时的某个版本，当前使用的版本需先切换
+func (a *App) UninstallRuntime(runtime, version string) error {
+	p, err := runtimeProvider(runtime)
+	if err != nil {
+		return err
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if a.activeRuntimeVersion(p.Name()) == version {
+		return fmt.Errorf("%s %s is in use, switch to another version first", p.Name(), version)
+	}
+	if err := os.RemoveAll(longPath(runtimeVersionDir(p.Name(), version))); err != nil {
+		return fmt.Errorf("Error removing %s %s: %v", p.Name(), version, err)
+	}
+	a.audit("uninstall-runtime", p.Name()+" "+version, "success")
+	a.logToFile(fmt.Sprintf("Uninstalled %s %s", p.Name(), version))
+	return nil
+}
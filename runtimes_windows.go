@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// userEnvironmentKey holds the per-user environment variables
+// userEnvironmentKey 保存当前用户的环境变量
+const userEnvironmentKey = `Environment`
+
+var procSendMessageTimeoutW = moduser32.NewProc("SendMessageTimeoutW")
+
+// Window messages used to announce environment changes
+// 用于通知环境变量变化的窗口消息
+const (
+	hwndBroadcast    = 0xFFFF
+	wmSettingChange  = 0x001A
+	smtoAbortIfHung  = 0x0002
+	settingTimeoutMs = 5000
+)
+
+// addToUserPath appends dir to the user PATH if missing and tells running programs about the change
+// addToUserPath 如果用户 PATH 中没有 dir 则追加，并通知正在运行的程序
+func addToUserPath(dir string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, userEnvironmentKey, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	current, _, err := key.GetStringValue("Path")
+	if err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	for _, entry := range strings.Split(current, ";") {
+		if samePath(entry, dir) {
+			return nil
+		}
+	}
+	value := dir
+	if strings.TrimSpace(current) != "" {
+		value = strings.TrimRight(current, ";") + ";" + dir
+	}
+	if err := key.SetExpandStringValue("Path", value); err != nil {
+		return err
+	}
+	broadcastEnvironmentChange()
+	return nil
+}
+
+// broadcastEnvironmentChange asks Explorer and others to reload the environment so new terminals pick it up
+// broadcastEnvironmentChange 通知资源管理器等程序重新读取环境变量，新打开的终端即可使用
+func broadcastEnvironmentChange() {
+	env, _ := syscall.UTF16PtrFromString("Environment")
+	procSendMessageTimeoutW.Call(hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(env)), smtoAbortIfHung, settingTimeoutMs, 0)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// enterSafeMode switches the app to safe mode: default settings and no background integrations
+// enterSafeMode 将应用切换到安全模式：使用默认设置并禁用后台集成
+func (a *App) enterSafeMode() {
+	a.safeMode = true
+
+	a.settingsMu.Lock()
+	a.settings = defaultSettings()
+	a.settingsMu.Unlock()
+
+	fmt.Println("Debug: Safe mode enabled, using default settings")
+}
+
+// IsSafeMode reports whether the app was started with --safe-mode
+// IsSafeMode 返回应用是否以 --safe-mode 启动
+func (a *App) IsSafeMode() bool {
+	return a.safeMode
+}
+
+// RestartInSafeMode relaunches the application with --safe-mode and quits the current instance
+// RestartInSafeMode 以 --safe-mode 重新启动应用并退出当前实例
+func (a *App) RestartInSafeMode() error {
+	a.logToFile("Restarting in safe mode")
+	return a.restartApp(argSafeMode)
+}
+
+// restartApp starts a new instance with the given arguments once this one has exited, then quits
+// restartApp 启动一个在本实例退出后运行的新实例（带指定参数），然后退出
+func (a *App) restartApp(args ...string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Error locating executable: %v", err)
+	}
+
+	// The new instance waits for us to exit, otherwise the single instance lock would hand its arguments back to us
+	// 新实例会等待本实例退出，否则单实例锁会把它的参数转交回本实例
+	args = append([]string{argWaitForExit, strconv.Itoa(os.Getpid())}, args...)
+	if err := a.executor.Start(CommandSpec{Name: exePath, Args: args}); err != nil {
+		a.logToFile(fmt.Sprintf("Error restarting application: %v", err))
+		return fmt.Errorf("Error restarting application: %v", err)
+	}
+
+	runtime.Quit(a.ctx)
+	return nil
+}
+
+// waitForProcessExit blocks until the process with the given pid exits or the timeout elapses
+// waitForProcessExit 阻塞直到指定 pid 的进程退出或超时
+func waitForProcessExit(pid int, timeout time.Duration) {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		process.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		fmt.Printf("Debug: Timed out waiting for process %d to exit\n", pid)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// releaseScheduleURL is the official release schedule maintained in the nodejs/Release repository
+// releaseScheduleURL 是 nodejs/Release 仓库维护的官方发布计划
+const releaseScheduleURL = "https://raw.githubusercontent.com/nodejs/Release/main/schedule.json"
+
+// releaseScheduleTTL is how long the cached schedule is used before refreshing
+// releaseScheduleTTL 是缓存的发布计划在刷新前的使用时长
+const releaseScheduleTTL = 24 * time.Hour
+
+// Release line phases
+// 发布版本线所处阶段
+const (
+	PhasePending     = "pending"
+	PhaseCurrent     = "current"
+	PhaseActiveLTS   = "active-lts"
+	PhaseMaintenance = "maintenance"
+	PhaseEOL         = "eol"
+)
+
+// ReleaseLine is the timeline of one Node.js major release line
+// ReleaseLine 表示一个 Node.js 主版本线的时间线
+type ReleaseLine struct {
+	Line        string
+	Major       int
+	Codename    string
+	Start       string
+	LTS         string
+	Maintenance string
+	End         string
+	Phase       string
+}
+
+// ReleaseSchedule is the release timeline used by the calendar view
+// ReleaseSchedule 表示日历视图使用的发布时间线
+type ReleaseSchedule struct {
+	Lines     []ReleaseLine
+	FromCache bool
+}
+
+// releasePhase returns the phase of a release line on the given day
+// releasePhase 返回发布版本线在指定日期所处的阶段
+func releasePhase(line ReleaseLine, now time.Time) string {
+	today := now.Format("2006-01-02")
+	switch {
+	case line.Start != "" && today < line.Start:
+		return PhasePending
+	case line.End != "" && today >= line.End:
+		return PhaseEOL
+	case line.Maintenance != "" && today >= line.Maintenance:
+		return PhaseMaintenance
+	case line.LTS != "" && today >= line.LTS:
+		return PhaseActiveLTS
+	default:
+		return PhaseCurrent
+	}
+}
+
+// parseReleaseSchedule decodes schedule.json into release lines, newest first
+// parseReleaseSchedule 将 schedule.json 解析为发布版本线列表，最新的在前
+func parseReleaseSchedule(data []byte, now time.Time) ([]ReleaseLine, error) {
+	var raw map[string]struct {
+		Start       string `json:"start"`
+		LTS         string `json:"lts"`
+		Maintenance string `json:"maintenance"`
+		End         string `json:"end"`
+		Codename    string `json:"codename"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Error parsing release schedule: %v", err)
+	}
+
+	lines := make([]ReleaseLine, 0, len(raw))
+	for name, entry := range raw {
+		major, _ := strconv.Atoi(strings.Split(strings.TrimPrefix(name, "v"), ".")[0])
+		line := ReleaseLine{
+			Line:        name,
+			Major:       major,
+			Codename:    entry.Codename,
+			Start:       entry.Start,
+			LTS:         entry.LTS,
+			Maintenance: entry.Maintenance,
+			End:         entry.End,
+		}
+		line.Phase = releasePhase(line, now)
+		lines = append(lines, line)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Major != lines[j].Major {
+			return lines[i].Major > lines[j].Major
+		}
+		return lines[i].Line > lines[j].Line
+	})
+	return lines, nil
+}
+
+// GetReleaseSchedule returns the official Node.js release timeline, cached locally for a day
+// GetReleaseSchedule 返回官方 Node.js 发布时间线，本地缓存一天
+func (a *App) GetReleaseSchedule() (ReleaseSchedule, error) {
+	data, fromCache, err := a.fetchCached("schedule.json", releaseScheduleURL, releaseScheduleTTL)
+	if err != nil {
+		return ReleaseSchedule{}, fmt.Errorf("Error fetching release schedule: %v", err)
+	}
+
+	lines, err := parseReleaseSchedule(data, a.clock.Now())
+	if err != nil {
+		a.logToFile(err.Error())
+		return ReleaseSchedule{}, err
+	}
+	return ReleaseSchedule{Lines: lines, FromCache: fromCache}, nil
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of operations that can be scheduled
+// 可计划执行的操作类型
+const (
+	JobInstall   = "install"
+	JobUninstall = "uninstall"
+	JobCleanup   = "cleanup" // 卸载加入队列时选定的清理建议版本 / uninstalls the cleanup suggestions chosen when queued
+)
+
+// Scheduled job states
+// 计划任务状态
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// scheduledJobPollInterval is how often the scheduler looks for due jobs
+// scheduledJobPollInterval 是调度器检查到期任务的间隔
+const scheduledJobPollInterval = time.Minute
+
+// maxFinishedJobs is how many finished jobs are kept for the history
+// maxFinishedJobs 是历史记录中保留的已结束任务数量
+const maxFinishedJobs = 20
+
+// ScheduledJob is an install or cleanup deferred to a later time, e.g. a large download run off-hours
+// ScheduledJob 表示推迟到稍后执行的安装或清理，例如在非工作时间进行的大文件下载
+type ScheduledJob struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Version    string    `json:"version,omitempty"`
+	Versions   []string  `json:"versions,omitempty"` // 清理任务要卸载的版本 / versions a cleanup uninstalls
+	RunAt      time.Time `json:"runAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	State      string    `json:"state"`
+	Result     string    `json:"result,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty"`
+}
+
+// scheduledJobs is the persistent job queue, saved next to the executable so jobs survive restarts
+// scheduledJobs 是持久化的任务队列，保存在可执行文件同目录下，重启后任务仍然保留
+type scheduledJobs struct {
+	mu     sync.Mutex
+	loaded bool
+	jobs   []ScheduledJob
+}
+
+// scheduledJobsFilePath returns the location of the job queue file next to the executable
+// scheduledJobsFilePath 返回可执行文件同目录下的任务队列文件路径
+func scheduledJobsFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-jobs.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-jobs.json")
+}
+
+// load reads the queue from disk once, the caller holds q.mu. Jobs interrupted by an exit are marked as failed
+// rather than run again, since they may have stopped halfway
+// load 从磁盘读取一次任务队列，调用方需持有 q.mu。因退出而中断的任务可能只执行了一半，因此标记为失败而不是重新执行
+func (q *scheduledJobs) load(fsys FileSystem, clock Clock) {
+	if q.loaded {
+		return
+	}
+	q.loaded = true
+	if data, err := fsys.ReadFile(scheduledJobsFilePath()); err == nil {
+		json.Unmarshal(data, &q.jobs)
+	}
+	for i := range q.jobs {
+		if q.jobs[i].State == JobRunning {
+			q.jobs[i].State = JobFailed
+			q.jobs[i].Result = "任务因程序退出而中断 / Interrupted because the app exited"
+			q.jobs[i].FinishedAt = clock.Now()
+		}
+	}
+}
+
+// save writes the queue to disk, keeping only the most recent finished jobs. The caller holds q.mu
+// save 将任务队列写入磁盘，只保留最近的已结束任务。调用方需持有 q.mu
+func (q *scheduledJobs) save(fsys FileSystem) error {
+	sort.SliceStable(q.jobs, func(i, j int) bool { return q.jobs[i].RunAt.Before(q.jobs[j].RunAt) })
+	finished := 0
+	for _, job := range q.jobs {
+		if job.State == JobDone || job.State == JobFailed {
+			finished++
+		}
+	}
+	// 从最早的已结束任务开始丢弃
+	// Drop the oldest finished jobs first
+	var kept []ScheduledJob
+	for _, job := range q.jobs {
+		if (job.State == JobDone || job.State == JobFailed) && finished > maxFinishedJobs {
+			finished--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	q.jobs = kept
+
+	data, err := json.MarshalIndent(q.jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(scheduledJobsFilePath(), data, 0644)
+}
+
+// ScheduleOperation queues an install, uninstall or cleanup to run at runAt, even after a restart. The
+// confirmation policy is applied now, while the user is present; a cleanup removes the versions suggested now
+// ScheduleOperation 将安装、卸载或清理加入队列，在 runAt 时执行，重启后仍然有效。确认策略在用户在场的此刻执行；
+// 清理只卸载此刻建议的版本
+func (a *App) ScheduleOperation(kind, version string, runAt time.Time) (ScheduledJob, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var versions []string
+	operation := OperationUninstall
+	switch kind {
+	case JobInstall, JobUninstall:
+		if version == "" {
+			return ScheduledJob{}, fmt.Errorf("A version is required to schedule %s", kind)
+		}
+		if kind == JobInstall {
+			operation = OperationInstall
+		}
+	case JobCleanup:
+		version = ""
+		suggestions, err := a.GetCleanupSuggestions()
+		if err != nil {
+			return ScheduledJob{}, err
+		}
+		for _, s := range suggestions {
+			if !s.Keep {
+				versions = append(versions, s.Version)
+			}
+		}
+		if len(versions) == 0 {
+			return ScheduledJob{}, fmt.Errorf("没有可清理的版本 / There are no versions to clean up")
+		}
+	default:
+		return ScheduledJob{}, fmt.Errorf("Unknown operation: %s", kind)
+	}
+	if time.Until(runAt) < -scheduledJobPollInterval {
+		return ScheduledJob{}, fmt.Errorf("The scheduled time %s has already passed", a.formatter().date(runAt))
+	}
+
+	job := ScheduledJob{ID: newPlanID(), Kind: kind, Version: version, Versions: versions, RunAt: runAt, CreatedAt: a.clock.Now(), State: JobPending}
+	target := version
+	if kind == JobCleanup {
+		target = strings.Join(versions, ", ")
+	}
+	// 需要确认时，确认后才加入队列
+	// When confirmation is required the job is queued once confirmed
+	msg, ok := a.requireConfirmationThen(operation, target, func() string {
+		if err := a.queueScheduledJob(job); err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("Scheduled %s %s at %s", kind, target, a.formatter().date(runAt))
+	})
+	if !ok {
+		return ScheduledJob{}, fmt.Errorf("%s", msg)
+	}
+	if err := a.queueScheduledJob(job); err != nil {
+		return ScheduledJob{}, err
+	}
+	return job, nil
+}
+
+// queueScheduledJob adds a job to the persistent queue
+// queueScheduledJob 将任务加入持久化队列
+func (a *App) queueScheduledJob(job ScheduledJob) error {
+	q := &a.jobs
+	q.mu.Lock()
+	q.load(a.fs, a.clock)
+	q.jobs = append(q.jobs, job)
+	err := q.save(a.fs)
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("Error saving scheduled jobs: %v", err)
+	}
+	target := job.Version
+	if job.Kind == JobCleanup {
+		target = strings.Join(job.Versions, ", ")
+	}
+	a.audit("schedule-"+job.Kind, fmt.Sprintf("%s at %s", target, job.RunAt.Format(time.RFC3339)), "success")
+	return nil
+}
+
+// GetScheduledJobs returns the queued jobs and the recently finished ones in the order they are scheduled
+// GetScheduledJobs 按计划时间顺序返回排队中的任务以及最近结束的任务
+func (a *App) GetScheduledJobs() []ScheduledJob {
+	q := &a.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.load(a.fs, a.clock)
+	return append([]ScheduledJob(nil), q.jobs...)
+}
+
+// CancelScheduledJob removes a job that has not started yet
+// CancelScheduledJob 移除尚未开始的任务
+func (a *App) CancelScheduledJob(id string) error {
+	q := &a.jobs
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.load(a.fs, a.clock)
+	for i, job := range q.jobs {
+		if job.ID != id {
+			continue
+		}
+		if job.State != JobPending {
+			return fmt.Errorf("The job is already %s", job.State)
+		}
+		q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+		a.audit("cancel-scheduled-job", fmt.Sprintf("%s %s", job.Kind, job.Version), "success")
+		return q.save(a.fs)
+	}
+	return fmt.Errorf("No scheduled job: %s", id)
+}
+
+// runScheduledJob performs a due job with the same checks as when it is started by hand
+// runScheduledJob 执行到期任务，检查项与手动执行时相同
+func (a *App) runScheduledJob(job ScheduledJob) (string, bool) {
+	switch job.Kind {
+	case JobInstall:
+		result := a.installNodeVersionWithinCap(job.Version)
+		return result, strings.HasPrefix(result, "Successfully")
+	case JobUninstall:
+		result := a.uninstallNodeVersionWithinCap(job.Version)
+		return result, strings.HasPrefix(result, "Successfully")
+	case JobCleanup:
+		// 只卸载加入队列时选定且仍被建议清理的版本，期间收藏、使用或安装的版本会被保留
+		// Only versions chosen when queued and still suggested are removed, so versions favorited, used or
+		// installed in the meantime are kept
+		suggestions, err := a.GetCleanupSuggestions()
+		if err != nil {
+			return err.Error(), false
+		}
+		suggested := map[string]bool{}
+		for _, s := range suggestions {
+			if !s.Keep {
+				suggested[s.Version] = true
+			}
+		}
+		var removed, failed []string
+		for _, version := range job.Versions {
+			if !suggested[version] {
+				continue
+			}
+			if result := a.uninstallNodeVersionWithinCap(version); strings.HasPrefix(result, "Successfully") {
+				removed = append(removed, version)
+			} else {
+				failed = append(failed, result)
+			}
+		}
+		result := fmt.Sprintf("Removed %d versions: %s", len(removed), strings.Join(removed, ", "))
+		if len(failed) > 0 {
+			result += "\n" + strings.Join(failed, "\n")
+		}
+		return result, len(failed) == 0
+	}
+	return fmt.Sprintf("Unknown operation: %s", job.Kind), false
+}
+
+// finishScheduledJob records the outcome of a job and tells the frontend
+// finishScheduledJob 记录任务的结果并通知前端
+func (a *App) finishScheduledJob(id, result string, ok bool) {
+	q := &a.jobs
+	q.mu.Lock()
+	var finished ScheduledJob
+	for i := range q.jobs {
+		if q.jobs[i].ID == id {
+			q.jobs[i].State = JobFailed
+			if ok {
+				q.jobs[i].State = JobDone
+			}
+			q.jobs[i].Result = result
+			q.jobs[i].FinishedAt = a.clock.Now()
+			finished = q.jobs[i]
+		}
+	}
+	if err := q.save(a.fs); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving scheduled jobs: %v", err))
+	}
+	q.mu.Unlock()
+
+	a.logToFile(fmt.Sprintf("Scheduled %s %s finished: %s", finished.Kind, finished.Version, result))
+	a.notify("scheduled-job-finished", finished)
+}
+
+// runDueJobs starts the jobs whose time has come
+// runDueJobs 启动已到执行时间的任务
+func (a *App) runDueJobs() {
+	// 专注模式下不在后台安装或卸载，任务保持待执行，之后的检查中再运行
+	// Nothing is installed or removed in the background during focus mode; the jobs stay pending for a later poll
+	if a.inFocusMode() {
+		return
+	}
+	q := &a.jobs
+	q.mu.Lock()
+	q.load(a.fs, a.clock)
+	// 使用电池时较大的计划安装保持待执行，接通电源后再运行；查询大小需要访问网络，因此在锁外进行
+	// Large scheduled installs stay pending on battery and run once on AC power; the size lookup needs the
+	// network, so it happens outside the lock
+	var installs []string
+	for _, job := range q.jobs {
+		if job.State == JobPending && job.Kind == JobInstall && !a.clock.Now().Before(job.RunAt) {
+			installs = append(installs, job.Version)
+		}
+	}
+	q.mu.Unlock()
+	deferred := map[string]bool{}
+	for _, version := range installs {
+		if reason := a.batteryDownloadDeferral(version); reason != "" {
+			deferred[version] = true
+			a.logToFile(fmt.Sprintf("Scheduled install of Node.js %s deferred: %s", version, reason))
+		}
+	}
+
+	q.mu.Lock()
+	var due []ScheduledJob
+	for i := range q.jobs {
+		if q.jobs[i].Kind == JobInstall && deferred[q.jobs[i].Version] {
+			continue
+		}
+		if q.jobs[i].State == JobPending && !a.clock.Now().Before(q.jobs[i].RunAt) {
+			q.jobs[i].State = JobRunning
+			due = append(due, q.jobs[i])
+		}
+	}
+	if len(due) > 0 {
+		if err := q.save(a.fs); err != nil {
+			a.logToFile(fmt.Sprintf("Error saving scheduled jobs: %v", err))
+		}
+	}
+	q.mu.Unlock()
+
+	for _, job := range due {
+		job := job
+		a.logToFile(fmt.Sprintf("Running scheduled %s %s", job.Kind, job.Version))
+		a.submitTask("scheduled-job "+job.ID, TaskPriorityBackground, func() {
+			result, ok := a.runScheduledJob(job)
+			a.finishScheduledJob(job.ID, result, ok)
+		})
+	}
+}
+
+// watchScheduledJobs runs the scheduled jobs when they become due
+// watchScheduledJobs 在计划任务到期时执行它们
+func (a *App) watchScheduledJobs() {
+	ticker := time.NewTicker(scheduledJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		a.runDueJobs()
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Names of the secrets used by the application
+// 应用程序使用的敏感信息名称
+const (
+	SecretSyncToken = "sync.token"
+)
+
+// secretsFilePath returns the file holding the encrypted secrets next to the executable
+// secretsFilePath 返回可执行文件同目录下保存加密敏感信息的文件路径
+func secretsFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-secrets.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-secrets.json")
+}
+
+// readSecrets loads the encrypted secrets keyed by name
+// readSecrets 读取按名称保存的加密敏感信息
+func (a *App) readSecrets() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := a.fs.ReadFile(secretsFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("Error parsing secrets file: %v", err)
+	}
+	return secrets, nil
+}
+
+// getSecret returns the decrypted secret, or "" when it is not set
+// getSecret 返回解密后的敏感信息，未设置时返回空字符串
+func (a *App) getSecret(name string) (string, error) {
+	a.secretsMu.Lock()
+	defer a.secretsMu.Unlock()
+
+	secrets, err := a.readSecrets()
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := secrets[name]
+	if !ok {
+		return "", nil
+	}
+	blob, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	plain, err := unprotectSecret(blob)
+	if err != nil {
+		return "", fmt.Errorf("Error decrypting secret %s: %v", name, err)
+	}
+	return string(plain), nil
+}
+
+// setSecret encrypts and stores a secret; an empty value deletes it
+// setSecret 加密并保存敏感信息，值为空时删除
+func (a *App) setSecret(name, value string) error {
+	a.secretsMu.Lock()
+	defer a.secretsMu.Unlock()
+
+	secrets, err := a.readSecrets()
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		delete(secrets, name)
+	} else {
+		blob, err := protectSecret([]byte(value))
+		if err != nil {
+			return fmt.Errorf("Error encrypting secret %s: %v", name, err)
+		}
+		secrets[name] = base64.StdEncoding.EncodeToString(blob)
+	}
+
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := secretsFilePath() + ".tmp"
+	if err := a.fs.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("Error writing secrets file: %v", err)
+	}
+	return a.fs.Rename(tmpPath, secretsFilePath())
+}
+
+// SetSecret stores a credential such as a registry token; the frontend can never read it back
+// SetSecret 保存仓库令牌等凭据，前端无法读回
+func (a *App) SetSecret(name, value string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("secret name is required")
+	}
+	if err := a.setSecret(name, value); err != nil {
+		a.audit("set-secret", name, "failed")
+		return err
+	}
+	a.audit("set-secret", name, "success")
+	return nil
+}
+
+// ListSecrets returns the names of the stored secrets without their values
+// ListSecrets 返回已保存的敏感信息名称，不包含其值
+func (a *App) ListSecrets() ([]string, error) {
+	a.secretsMu.Lock()
+	defer a.secretsMu.Unlock()
+
+	secrets, err := a.readSecrets()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// movePlainSecrets stores the credentials found in settings in the secrets store and clears them, so they are
+// never written to the plain settings file
+// movePlainSecrets 将设置中的凭据保存到敏感信息存储并清空，使其不会被写入明文设置文件
+func (a *App) movePlainSecrets(settings *Settings) error {
+	if settings.Sync.Token == "" {
+		return nil
+	}
+	if err := a.setSecret(SecretSyncToken, settings.Sync.Token); err != nil {
+		return fmt.Errorf("Error storing sync token: %v", err)
+	}
+	settings.Sync.Token = ""
+	return nil
+}
+
+// scrubSettingsBackups removes plain credentials from the rotated settings backups, which still hold the
+// tokens saved by older versions
+// scrubSettingsBackups 从轮换的设置备份中删除明文凭据，这些备份仍保存着旧版本写入的令牌
+func (a *App) scrubSettingsBackups() {
+	for n := 1; n <= settingsBackupCount; n++ {
+		path := settingsBackupPath(a.settingsPath, n)
+		data, err := a.fs.ReadFile(path)
+		if err != nil || !bytes.Contains(data, []byte(`"token"`)) {
+			continue
+		}
+		// 按原始 JSON 修改，保留备份中的其他字段
+		// Edit the raw JSON so the other fields of the backup are kept
+		var raw map[string]json.RawMessage
+		var syncConfig map[string]json.RawMessage
+		if json.Unmarshal(data, &raw) != nil || json.Unmarshal(raw["sync"], &syncConfig) != nil {
+			continue
+		}
+		if _, ok := syncConfig["token"]; !ok {
+			continue
+		}
+		delete(syncConfig, "token")
+		raw["sync"], _ = json.Marshal(syncConfig)
+		scrubbed, err := json.MarshalIndent(raw, "", "  ")
+		if err == nil {
+			err = a.fs.WriteFile(path, scrubbed, 0644)
+		}
+		if err != nil {
+			a.logToFile(fmt.Sprintf("Error removing the sync token from %s: %v", path, err))
+		}
+	}
+}
+
+// migratePlainSecrets moves credentials still stored in the plain settings file into the secrets store and
+// removes them from the settings backups
+// migratePlainSecrets 将仍以明文保存在设置文件中的凭据移入敏感信息存储，并从设置备份中删除
+func (a *App) migratePlainSecrets() {
+	if settings := a.currentSettings(); settings.Sync.Token != "" {
+		// SetSettings 会移走令牌并清理备份
+		// SetSettings moves the token away and scrubs the backups
+		if err := a.SetSettings(settings); err != nil {
+			a.logToFile(fmt.Sprintf("Error migrating sync token: %v", err))
+		}
+		return
+	}
+	a.scrubSettingsBackups()
+}
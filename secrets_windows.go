@@ -0,0 +1,41 @@
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// cryptProtectUIForbidden stops DPAPI from ever showing a prompt
+// cryptProtectUIForbidden 禁止 DPAPI 弹出任何提示
+const cryptProtectUIForbidden = 0x1
+
+// protectSecret encrypts data with DPAPI so only the current Windows user can decrypt it
+// protectSecret 使用 DPAPI 加密数据，只有当前 Windows 用户能够解密
+func protectSecret(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, cryptProtectUIForbidden, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+// unprotectSecret decrypts data encrypted by protectSecret
+// unprotectSecret 解密由 protectSecret 加密的数据
+func unprotectSecret(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, cryptProtectUIForbidden, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appVersion is the version of this build, compared against GitHub releases
+// appVersion 是当前构建的版本号，用于与 GitHub 发布比较
+const appVersion = "0.0.5"
+
+// appReleasesURL lists the GitHub releases of the application
+// appReleasesURL 是应用程序 GitHub 发布列表的地址
+const appReleasesURL = "https://api.github.com/repos/Shadownc/node-version-switcher/releases?per_page=30"
+
+// Update channels
+// 更新通道
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta"
+)
+
+// githubRelease is the part of the GitHub release API response used for updates
+// githubRelease 是 GitHub 发布接口响应中用于更新的部分
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	HTMLURL    string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+		Size               int64  `json:"size"`
+		Digest             string `json:"digest"` // 例如 "sha256:..." / e.g. "sha256:..."
+	} `json:"assets"`
+}
+
+// AppUpdate describes the newest release available on the selected channel
+// AppUpdate 描述所选通道上可用的最新发布
+type AppUpdate struct {
+	CurrentVersion string
+	LatestVersion  string
+	Channel        string
+	Available      bool
+	Prerelease     bool
+	Notes          string
+	ReleaseURL     string
+	AssetName      string
+	AssetURL       string
+	AssetSize      int64
+	ManifestURL    string
+	SHA256         string // 发布资源摘要中的 SHA256 / SHA256 from the release asset digest
+	ChecksumURL    string // 发布的校验和文件 / published checksum file
+	Message        string
+}
+
+// checksumAssetNames are the checksum files a release may publish next to the executable, the first being
+// "<executable>.sha256"
+// checksumAssetNames 是发布中可能与可执行文件一同提供的校验和文件，第一个为 "<可执行文件>.sha256"
+func checksumAssetNames(assetName string) []string {
+	return []string{assetName + ".sha256", "SHASUMS256.txt", "checksums.txt"}
+}
+
+// compareAppVersion compares versions such as 1.2.0 and 1.3.0-beta.2; a pre-release sorts before its release
+// compareAppVersion 比较 1.2.0、1.3.0-beta.2 等版本号，预发布版本排在对应正式版本之前
+func compareAppVersion(a, b string) int {
+	coreA, preA, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	coreB, preB, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	if c := compareSemver(coreA, coreB); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+
+	partsA := strings.Split(preA, ".")
+	partsB := strings.Split(preB, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		x, errX := strconv.Atoi(partsA[i])
+		y, errY := strconv.Atoi(partsB[i])
+		switch {
+		case errX == nil && errY == nil && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		case (errX != nil || errY != nil) && partsA[i] != partsB[i]:
+			if partsA[i] < partsB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(partsA) < len(partsB):
+		return -1
+	case len(partsA) > len(partsB):
+		return 1
+	}
+	return 0
+}
+
+// updateChannel returns the configured update channel, defaulting to stable
+// updateChannel 返回配置的更新通道，默认为稳定版
+func (a *App) updateChannel() string {
+	if a.currentSettings().UpdateChannel == UpdateChannelBeta {
+		return UpdateChannelBeta
+	}
+	return UpdateChannelStable
+}
+
+// fetchAppReleases downloads the release list from GitHub
+// fetchAppReleases 从 GitHub 下载发布列表
+func (a *App) fetchAppReleases() ([]githubRelease, error) {
+	if a.safeMode {
+		return nil, fmt.Errorf("networking disabled in safe mode")
+	}
+
+	client := a.client(15 * time.Second)
+	req, err := http.NewRequest(http.MethodGet, appReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		a.metrics.recordError()
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		a.metrics.recordError()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("Error parsing releases: %v", err)
+	}
+	return releases, nil
+}
+
+// latestRelease picks the newest release on the channel; the beta channel also sees pre-releases
+// latestRelease 选出通道上最新的发布，测试版通道也包含预发布版本
+func latestRelease(releases []githubRelease, channel string) (githubRelease, bool) {
+	var latest githubRelease
+	found := false
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && channel != UpdateChannelBeta) {
+			continue
+		}
+		if !found || compareAppVersion(release.TagName, latest.TagName) > 0 {
+			latest = release
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// checkForAppUpdate looks up the newest release on the channel. Releases older than or equal to the running
+// version are never offered, so leaving the beta channel does not downgrade to an older stable build
+// checkForAppUpdate 查找通道上的最新发布。不会提供低于或等于当前运行版本的发布，
+// 因此退出测试版通道不会降级到较旧的稳定版
+func (a *App) checkForAppUpdate(channel string) (AppUpdate, error) {
+	update := AppUpdate{CurrentVersion: appVersion, Channel: channel}
+	releases, err := a.fetchAppReleases()
+	if err != nil {
+		return update, fmt.Errorf("Error checking for updates: %v", err)
+	}
+	release, ok := latestRelease(releases, channel)
+	if !ok {
+		update.Message = "没有可用的发布 / No releases available"
+		return update, nil
+	}
+
+	update.LatestVersion = strings.TrimPrefix(release.TagName, "v")
+	update.Prerelease = release.Prerelease
+	update.Notes = release.Body
+	update.ReleaseURL = release.HTMLURL
+	for _, asset := range release.Assets {
+		if strings.HasSuffix(strings.ToLower(asset.Name), ".exe") {
+			update.AssetName = asset.Name
+			update.AssetURL = asset.BrowserDownloadURL
+			update.AssetSize = asset.Size
+			if digest, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok {
+				update.SHA256 = strings.ToLower(digest)
+			}
+			break
+		}
+	}
+	// 分块清单是可选的，存在时可以增量更新
+	// The block manifest is optional, when present the update can be applied as a delta
+	for _, asset := range release.Assets {
+		if update.AssetName != "" && asset.Name == update.AssetName+blockManifestSuffix {
+			update.ManifestURL = asset.BrowserDownloadURL
+		}
+	}
+	// 按优先顺序查找校验和文件
+	// Look for a checksum file in order of preference
+	if update.AssetName != "" {
+	checksums:
+		for _, name := range checksumAssetNames(update.AssetName) {
+			for _, asset := range release.Assets {
+				if strings.EqualFold(asset.Name, name) {
+					update.ChecksumURL = asset.BrowserDownloadURL
+					break checksums
+				}
+			}
+		}
+	}
+
+	switch c := compareAppVersion(update.LatestVersion, appVersion); {
+	case c > 0 && update.AssetURL != "" && update.SHA256 == "" && update.ChecksumURL == "":
+		update.Message = fmt.Sprintf("%s 未发布校验和，无法验证，不会自动安装 / %s publishes no checksum, it cannot be verified and is not installed automatically",
+			update.LatestVersion, update.LatestVersion)
+	case c > 0:
+		update.Available = update.AssetURL != ""
+	case c < 0:
+		update.Message = fmt.Sprintf("当前版本 %s 比 %s 通道的最新版本 %s 更新，将保持当前版本直到有更新的发布 / The running version %s is newer than %s on the %s channel and is kept until a newer release appears",
+			appVersion, channel, update.LatestVersion, appVersion, update.LatestVersion, channel)
+	default:
+		update.Message = "已是最新版本 / Already up to date"
+	}
+	return update, nil
+}
+
+// CheckForAppUpdate returns the newest release on the configured channel
+// CheckForAppUpdate 返回所配置通道上的最新发布
+func (a *App) CheckForAppUpdate() (AppUpdate, error) {
+	return a.checkForAppUpdate(a.updateChannel())
+}
+
+// SwitchUpdateChannel remembers the update channel and checks it for updates
+// SwitchUpdateChannel 保存更新通道并检查该通道的更新
+func (a *App) SwitchUpdateChannel(channel string) (AppUpdate, error) {
+	if channel != UpdateChannelStable && channel != UpdateChannelBeta {
+		return AppUpdate{}, fmt.Errorf("unknown update channel %q", channel)
+	}
+	settings := a.currentSettings()
+	if settings.UpdateChannel != channel {
+		settings.UpdateChannel = channel
+		if err := a.SetSettings(settings); err != nil {
+			return AppUpdate{}, err
+		}
+		a.audit("switch-update-channel", channel, "success")
+	}
+	return a.checkForAppUpdate(channel)
+}
+
+// ApplyAppUpdate downloads the newest release on the configured channel, replaces the executable and restarts
+// ApplyAppUpdate 下载所配置通道上的最新发布，替换可执行文件并重启
+func (a *App) ApplyAppUpdate() error {
+	update, err := a.CheckForAppUpdate()
+	if err != nil {
+		return err
+	}
+	if !update.Available {
+		return fmt.Errorf("No newer version available on the %s channel", update.Channel)
+	}
+	// 先确定期望的 SHA256，无法验证的更新不会下载
+	// Settle the expected SHA256 first, an update that cannot be verified is not downloaded
+	expected, err := a.expectedUpdateHash(update)
+	if err != nil {
+		a.audit("app-update", update.LatestVersion, "unverified")
+		return err
+	}
+	defer a.stayAwake("app update")()
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Error locating executable: %v", err)
+	}
+
+	var data []byte
+	if update.ManifestURL != "" {
+		var downloaded int64
+		data, downloaded, err = a.downloadDelta(update, exePath)
+		if err != nil {
+			// 增量更新失败时回退到完整下载
+			// Fall back to the full download when the delta cannot be applied
+			a.logToFile(fmt.Sprintf("Delta update failed, downloading the full executable: %v", err))
+			data = nil
+		} else {
+			a.logToFile(fmt.Sprintf("Delta update downloaded %d of %d bytes", downloaded, len(data)))
+		}
+	}
+	if data == nil {
+		release := a.acquireDownloadSlot()
+		data, err = a.downloadSegmented(update.AssetURL, update.AssetSize)
+		release()
+		if err != nil {
+			a.audit("app-update", update.LatestVersion, "failed")
+			return fmt.Errorf("Error downloading update: %v", err)
+		}
+		if update.AssetSize > 0 && int64(len(data)) != update.AssetSize {
+			return fmt.Errorf("Downloaded update is %d bytes, expected %d", len(data), update.AssetSize)
+		}
+	}
+	if actual := hashBlock(data); actual != expected {
+		a.audit("app-update", update.LatestVersion, "checksum mismatch")
+		return fmt.Errorf("Downloaded update has SHA256 %s, expected %s", actual, expected)
+	}
+	if err := replaceExecutable(a.fs, exePath, data); err != nil {
+		a.audit("app-update", update.LatestVersion, "failed")
+		return err
+	}
+
+	a.audit("app-update", fmt.Sprintf("%s -> %s", appVersion, update.LatestVersion), "success")
+	a.logToFile(fmt.Sprintf("Updated to %s, restarting", update.LatestVersion))
+	return a.restartApp()
+}
+
+// expectedUpdateHash returns the published SHA256 of the update executable, from the asset digest or the
+// checksum file of the release
+// expectedUpdateHash 返回更新可执行文件已发布的 SHA256，来自资源摘要或发布中的校验和文件
+func (a *App) expectedUpdateHash(update AppUpdate) (string, error) {
+	if update.SHA256 != "" {
+		return update.SHA256, nil
+	}
+	if update.ChecksumURL == "" {
+		return "", fmt.Errorf("Release %s publishes no checksum, refusing to install an unverified update", update.LatestVersion)
+	}
+	data, err := a.download(update.ChecksumURL)
+	if err != nil {
+		return "", fmt.Errorf("Error downloading update checksum: %v", err)
+	}
+	hash := parseShasums(data, update.AssetName)
+	// "<可执行文件>.sha256" 可能只包含哈希值
+	// "<executable>.sha256" may hold the bare hash
+	if fields := strings.Fields(string(data)); hash == "" && len(fields) == 1 {
+		hash = strings.ToLower(fields[0])
+	}
+	if len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("No SHA256 for %s in %s", update.AssetName, update.ChecksumURL)
+	}
+	return hash, nil
+}
+
+// replaceExecutable swaps in a new executable. Windows allows renaming a running executable,
+// so the old one is moved aside and removed on the next start
+// replaceExecutable 替换可执行文件。Windows 允许重命名正在运行的可执行文件，
+// 因此先将旧文件移开，并在下次启动时删除
+func replaceExecutable(fsys FileSystem, exePath string, data []byte) error {
+	newPath := exePath + ".new"
+	oldPath := exePath + ".old"
+	if err := fsys.WriteFile(newPath, data, 0755); err != nil {
+		return fmt.Errorf("Error writing update: %v", err)
+	}
+	fsys.Remove(oldPath)
+	if err := fsys.Rename(exePath, oldPath); err != nil {
+		fsys.Remove(newPath)
+		return fmt.Errorf("Error moving current executable: %v", err)
+	}
+	if err := fsys.Rename(newPath, exePath); err != nil {
+		// 恢复旧的可执行文件
+		// Put the old executable back
+		fsys.Rename(oldPath, exePath)
+		return fmt.Errorf("Error installing update: %v", err)
+	}
+	return nil
+}
+
+// removeOldExecutable deletes the executable left behind by the previous update
+// removeOldExecutable 删除上一次更新遗留的可执行文件
+func (a *App) removeOldExecutable() {
+	if exePath, err := os.Executable(); err == nil {
+		a.fs.Remove(exePath + ".old")
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestCompareAppVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.3.0", -1},
+		{"v1.3.0", "1.3.0", 0},
+		{"1.10.0", "1.9.3", 1},
+		{"1.3.0-beta.2", "1.3.0", -1},
+		{"1.3.0", "1.3.0-rc.1", 1},
+		{"1.3.0-beta.2", "1.3.0-beta.10", -1},
+		{"1.3.0-beta.2", "1.3.0-rc.1", -1},
+		{"1.3.0-beta", "1.3.0-beta.1", -1},
+		{"1.3.0-beta.1", "1.3.0-beta.1", 0},
+	}
+	for _, tt := range tests {
+		if got := compareAppVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareAppVersion(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Shells that can be opened with a session-scoped Node.js version
+// 可以使用会话级 Node.js 版本打开的终端
+const (
+	ShellCmd        = "cmd"
+	ShellPowerShell = "powershell"
+	ShellPwsh       = "pwsh"
+)
+
+// createNewConsole makes the child process open its own console window
+// createNewConsole 使子进程打开独立的控制台窗口
+const createNewConsole = 0x00000010
+
+// sessionShellCommand describes the command that opens an interactive shell announcing the session version
+// sessionShellCommand 描述打开交互式终端并提示会话版本的命令
+func sessionShellCommand(shell, version string) (CommandSpec, error) {
+	title := fmt.Sprintf("Node.js %s (session)", version)
+	switch shell {
+	case ShellCmd, "":
+		return CommandSpec{Name: "cmd.exe", Args: []string{"/K", fmt.Sprintf("title %s && node -v", title)}}, nil
+	case ShellPowerShell, ShellPwsh:
+		exe := "powershell.exe"
+		if shell == ShellPwsh {
+			exe = "pwsh.exe"
+		}
+		script := fmt.Sprintf("$Host.UI.RawUI.WindowTitle = %s; node -v", psQuote(title))
+		return CommandSpec{Name: exe, Args: []string{"-NoExit", "-NoLogo", "-Command", script}}, nil
+	default:
+		return CommandSpec{}, fmt.Errorf("Unsupported shell: %s", shell)
+	}
+}
+
+// OpenTerminal opens a new terminal window whose PATH uses the given version, leaving the global version untouched
+// OpenTerminal 打开一个新的终端窗口，其 PATH 使用指定版本，不影响全局版本
+func (a *App) OpenTerminal(version, shell, dir string) error {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	env, _, err := a.versionEnv(version)
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		dir, _ = os.UserHomeDir()
+	}
+
+	spec, err := sessionShellCommand(shell, version)
+	if err != nil {
+		return err
+	}
+	spec.Dir = dir
+	spec.Env = append(env, "NVS_SESSION_VERSION="+version)
+	spec.NewConsole = true
+
+	// 终端由用户关闭
+	// The user closes the terminal
+	if err := a.executor.Start(spec); err != nil {
+		a.logToFile(fmt.Sprintf("Error opening %s terminal for Node.js %s: %v", shell, version, err))
+		return fmt.Errorf("Error opening terminal: %v", err)
+	}
+	a.logToFile(fmt.Sprintf("Opened %s terminal with session Node.js %s in %s", shell, version, dir))
+	return nil
+}
+
+// RunWithVersion runs a command with the given Node.js version first on PATH and returns its combined output
+// Tools shipped with Node.js (node, npm, npx, corepack) are resolved from that version's directory
+// RunWithVersion 以指定 Node.js 版本优先的 PATH 运行命令并返回合并后的输出
+// Node.js 自带的工具（node、npm、npx、corepack）会从该版本目录中解析
+func (a *App) RunWithVersion(version, dir, command string, args []string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	env, versionPath, err := a.versionEnv(version)
+	if err != nil {
+		return "", err
+	}
+
+	name := command
+	for _, tool := range []string{"node.exe", "npm.cmd", "npx.cmd", "corepack.cmd"} {
+		if strings.EqualFold(command, strings.TrimSuffix(tool, filepath.Ext(tool))) || strings.EqualFold(command, tool) {
+			name = filepath.Join(versionPath, tool)
+		}
+	}
+
+	output, err := a.executor.CombinedOutput(CommandSpec{Name: name, Args: args, Dir: dir, Env: append(env, "NVS_SESSION_VERSION="+version), Hidden: true})
+	output = decodeConsoleOutput(output)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Command failed: %s %v (Node.js %s)\nError: %v\nOutput: %s\n", command, args, version, err, string(output)))
+		return string(output), fmt.Errorf("%s failed: %v", command, err)
+	}
+	return string(output), nil
+}
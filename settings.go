@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// settingsBackupCount is the number of rotated settings backups kept on disk
+// settingsBackupCount 是磁盘上保留的设置备份轮换数量
+const settingsBackupCount = 5
+
+// Settings represents the persisted user preferences of the application
+// Settings 表示应用程序持久化保存的用户设置
+type Settings struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+	LocalAPI LocalAPIConfig  `json:"localApi"`
+	Projects []Project       `json:"projects"`
+	Mirrors  []string        `json:"mirrors"`
+
+	// DistSources are extra version indexes merged into the available versions
+	// DistSources 是合并到可用版本列表中的额外版本索引
+	DistSources []DistSource `json:"distSources"`
+
+	// RebuildAfterSwitch lists project paths whose native modules are rebuilt after each switch
+	// RebuildAfterSwitch 列出每次切换后需要重新构建原生模块的项目路径
+	RebuildAfterSwitch []string `json:"rebuildAfterSwitch"`
+
+	// MinFreeDiskMB blocks installs when the nvm root drive has less free space, 0 disables the check
+	// MinFreeDiskMB 表示 nvm 根目录所在磁盘剩余空间低于该值时阻止安装，0 表示不检查
+	MinFreeDiskMB int `json:"minFreeDiskMb"`
+
+	// UpdateChannel selects stable or beta application updates
+	// UpdateChannel 选择应用程序更新的稳定版或测试版通道
+	UpdateChannel string `json:"updateChannel"`
+
+	// Registries are the private npm registries configured through the auth helper
+	// Registries 是通过认证向导配置的私有 npm 仓库
+	Registries []RegistryConfig `json:"registries"`
+
+	// Sync configures the optional settings sync across machines
+	// Sync 配置可选的跨机器设置同步
+	Sync SyncConfig `json:"sync"`
+
+	// Window is the main window position and size saved on close
+	// Window 是关闭时保存的主窗口位置和大小
+	Window *WindowGeometry `json:"window,omitempty"`
+
+	// Frameless hides the native title bar so the frontend can draw its own
+	// Frameless 隐藏系统标题栏，由前端绘制自定义标题栏
+	Frameless bool `json:"frameless"`
+
+	// LastSeenVersion is the app version whose "What's new" the user has seen
+	// LastSeenVersion 是用户已查看“更新内容”的应用版本
+	LastSeenVersion string `json:"lastSeenVersion"`
+
+	// Runtimes maps a managed runtime such as bun or deno to its active version
+	// Runtimes 记录 bun、deno 等受管理运行时当前使用的版本
+	Runtimes map[string]string `json:"runtimes"`
+
+	// LastUsed records when each version was last switched to
+	// LastUsed 记录每个版本最近一次被切换使用的时间
+	LastUsed map[string]time.Time `json:"lastUsed"`
+
+	// Favorites are the starred versions shown first in lists and the tray quick list
+	// Favorites 是在列表和托盘快捷列表中置顶显示的已收藏版本
+	Favorites []string `json:"favorites"`
+
+	// VersionNotes records why each version is installed, such as "required by legacy app X"
+	// VersionNotes 记录安装每个版本的原因，例如“旧项目 X 需要”
+	VersionNotes map[string]string `json:"versionNotes"`
+
+	// Locale selects zh-CN or en-US formatting of dates and sizes, empty follows Windows
+	// Locale 选择 zh-CN 或 en-US 的日期和大小格式，为空时跟随 Windows
+	Locale string `json:"locale"`
+
+	// DefaultAction is run on version row double-click and from the tray quick list: switch, terminal or details
+	// DefaultAction 是双击版本行和托盘快捷列表执行的操作：switch、terminal 或 details
+	DefaultAction string `json:"defaultAction"`
+
+	// Confirmations selects the operations that must be confirmed before they run
+	// Confirmations 选择执行前必须确认的操作
+	Confirmations ConfirmationPolicy `json:"confirmations"`
+
+	// Downloads tunes the connections, segment size and concurrent jobs of the downloader
+	// Downloads 调整下载器的连接数、分段大小和并发任务数
+	Downloads DownloadConfig `json:"downloads"`
+
+	// Inventory opts in to sending a periodic inventory to an internal fleet management URL
+	// Inventory 选择启用定期向内部资产管理地址发送资产清单
+	Inventory InventoryConfig `json:"inventory"`
+	// InventoryLastSent is when the inventory was last delivered, so a restart does not send it again early
+	// InventoryLastSent 是最近一次成功发送资产清单的时间，使重启后不会提前再次发送
+	InventoryLastSent time.Time `json:"inventoryLastSent"`
+
+	// IgnoreFocusMode delivers notifications even during presentations, full-screen apps and Focus Assist
+	// IgnoreFocusMode 表示在演示、全屏应用和专注助手期间也照常发送通知
+	IgnoreFocusMode bool `json:"ignoreFocusMode"`
+
+	// Power holds the overrides of the battery-aware scheduling
+	// Power 保存按电池状态调度的覆盖选项
+	Power PowerPolicy `json:"power"`
+
+	// LabelsFile is the team labels file merged into version listings, empty uses the one next to the executable
+	// LabelsFile 是合并到版本列表中的团队标签文件，为空时使用可执行文件同目录下的文件
+	LabelsFile string `json:"labelsFile"`
+
+	// VersionSort is the preferred order of the version lists
+	// VersionSort 是版本列表的排序偏好
+	VersionSort VersionSort `json:"versionSort"`
+
+	// BandwidthCapMB is the soft monthly download cap that holds back installs exceeding it, 0 disables it
+	// BandwidthCapMB 是每月下载量软上限，超出时暂缓安装，0 表示不限制
+	BandwidthCapMB int `json:"bandwidthCapMb"`
+
+	// PermanentUninstall deletes uninstalled versions instead of sending them to the Recycle Bin
+	// PermanentUninstall 表示直接删除已卸载的版本，而不是移入回收站
+	PermanentUninstall bool `json:"permanentUninstall"`
+
+	// UninstallRetentionDays is how long an uninstalled version can be restored, 0 uses 30 days
+	// UninstallRetentionDays 是已卸载版本的可恢复天数，0 表示 30 天
+	UninstallRetentionDays int `json:"uninstallRetentionDays"`
+
+	// TrashedVersions are the uninstalled versions sent to the Recycle Bin
+	// TrashedVersions 是已移入回收站的已卸载版本
+	TrashedVersions []TrashedVersion `json:"trashedVersions"`
+
+	// TrustedReleaseKeys are release signing key fingerprints trusted in addition to the bundled ones
+	// TrustedReleaseKeys 是内置指纹之外额外信任的发布签名密钥指纹
+	TrustedReleaseKeys []string `json:"trustedReleaseKeys"`
+
+	// AdvancedMode unlocks power user features such as raw nvm commands
+	// AdvancedMode 用于开启原始 nvm 命令等高级功能
+	AdvancedMode bool `json:"advancedMode"`
+}
+
+// defaultSettings returns the settings used when no settings file exists
+// defaultSettings 返回不存在设置文件时使用的默认设置
+func defaultSettings() Settings {
+	return Settings{
+		Webhooks: []WebhookConfig{},
+		LocalAPI: LocalAPIConfig{Enabled: false, Port: defaultLocalAPIPort},
+		Projects: []Project{},
+		Mirrors:  []string{"https://npmmirror.com/mirrors/node"},
+
+		DistSources:   defaultDistSources(),
+		MinFreeDiskMB: defaultMinFreeDiskMB,
+		UpdateChannel: UpdateChannelStable,
+		DefaultAction: DefaultActionSwitch,
+		Downloads:     defaultDownloadConfig(),
+	}
+}
+
+// settingsFilePath returns the location of the settings file next to the executable
+// settingsFilePath 返回可执行文件同目录下的设置文件路径
+func settingsFilePath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "nvm-switcher-settings.json"
+	}
+	return filepath.Join(filepath.Dir(execPath), "nvm-switcher-settings.json")
+}
+
+// settingsBackupPath returns the path of the n-th settings backup (1 is the newest)
+// settingsBackupPath 返回第 n 个设置备份的路径（1 为最新）
+func settingsBackupPath(path string, n int) string {
+	return fmt.Sprintf("%s.bak%d", path, n)
+}
+
+// parseSettings decodes settings on top of the defaults
+// parseSettings 在默认设置的基础上解析设置内容
+func parseSettings(data []byte) (Settings, error) {
+	settings := defaultSettings()
+	err := json.Unmarshal(data, &settings)
+	return settings, err
+}
+
+// rotateSettingsBackups shifts the existing backups and saves the current valid settings file as the newest backup
+// rotateSettingsBackups 轮换现有备份，并将当前有效的设置文件保存为最新备份
+func (a *App) rotateSettingsBackups() {
+	data, err := a.fs.ReadFile(a.settingsPath)
+	if err != nil {
+		return
+	}
+	// 不备份已损坏的文件，以免覆盖有效的备份
+	// Never back up a corrupted file, it would push out a valid backup
+	if _, err := parseSettings(data); err != nil {
+		return
+	}
+	if newest, err := a.fs.ReadFile(settingsBackupPath(a.settingsPath, 1)); err == nil && bytes.Equal(newest, data) {
+		return
+	}
+
+	for n := settingsBackupCount - 1; n >= 1; n-- {
+		a.fs.Rename(settingsBackupPath(a.settingsPath, n), settingsBackupPath(a.settingsPath, n+1))
+	}
+	if err := a.fs.WriteFile(settingsBackupPath(a.settingsPath, 1), data, 0644); err != nil {
+		a.logToFile(fmt.Sprintf("Error writing settings backup: %v", err))
+	}
+}
+
+// restoreSettingsBackup finds the newest valid backup and restores it as the settings file
+// restoreSettingsBackup 查找最新的有效备份并将其恢复为设置文件
+func (a *App) restoreSettingsBackup() (Settings, int, error) {
+	for n := 1; n <= settingsBackupCount; n++ {
+		data, err := a.fs.ReadFile(settingsBackupPath(a.settingsPath, n))
+		if err != nil {
+			continue
+		}
+		settings, err := parseSettings(data)
+		if err != nil {
+			continue
+		}
+
+		// 保留损坏的文件以便排查问题
+		// Keep the corrupted file around for troubleshooting
+		a.fs.Rename(a.settingsPath, a.settingsPath+".corrupt")
+		if err := a.fs.WriteFile(a.settingsPath, data, 0644); err != nil {
+			return settings, n, fmt.Errorf("Error restoring settings backup: %v", err)
+		}
+		return settings, n, nil
+	}
+	return defaultSettings(), 0, fmt.Errorf("no valid settings backup found")
+}
+
+// loadSettings reads the settings file, falling back to defaults if it is missing
+// and to the newest valid backup if it is corrupted
+// loadSettings 读取设置文件，文件不存在时使用默认设置，文件损坏时恢复最新的有效备份
+func (a *App) loadSettings() error {
+	data, err := a.fs.ReadFile(a.settingsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			a.settingsMu.Lock()
+			a.settings = defaultSettings()
+			a.settingsMu.Unlock()
+			return nil
+		}
+		return fmt.Errorf("Error reading settings file: %v", err)
+	}
+
+	settings, parseErr := parseSettings(data)
+	if parseErr != nil {
+		restored, n, err := a.restoreSettingsBackup()
+		if err != nil {
+			a.addStartupIssue("settings-corrupted", IssueSeverityError,
+				fmt.Sprintf("设置文件已损坏且无法恢复，已使用默认设置 / The settings file is corrupted and could not be restored, defaults are in use: %v", parseErr))
+			return fmt.Errorf("Error parsing settings file: %v", parseErr)
+		}
+		a.addStartupIssue("settings-restored", IssueSeverityWarning,
+			fmt.Sprintf("设置文件已损坏，已从备份 %d 恢复 / The settings file was corrupted and has been restored from backup %d: %v", n, n, parseErr))
+		settings = restored
+	}
+
+	a.settingsMu.Lock()
+	a.settings = settings
+	a.settingsMu.Unlock()
+	return nil
+}
+
+// saveSettings writes the current settings to disk, keeping the previous file as a backup
+// saveSettings 将当前设置写入磁盘，并将之前的文件保留为备份
+func (a *App) saveSettings() error {
+	return a.writeSettings(true)
+}
+
+// writeSettings writes the current settings to disk, rotating the backups first when backup is set. Nothing is
+// written in safe mode
+// writeSettings 将当前设置写入磁盘，backup 为真时先轮换备份。安全模式下不写入
+func (a *App) writeSettings(backup bool) error {
+	// 安全模式下的设置是内存中的默认设置，不能覆盖用户保存的设置
+	// In safe mode the settings are in-memory defaults that must not replace the user's saved settings
+	if a.safeMode {
+		return nil
+	}
+	a.saveMu.Lock()
+	defer a.saveMu.Unlock()
+	a.settingsMu.RLock()
+	data, err := json.MarshalIndent(a.settings, "", "  ")
+	a.settingsMu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("Error encoding settings: %v", err)
+	}
+
+	if backup {
+		a.rotateSettingsBackups()
+	}
+
+	// Write to a temporary file first so a crash never leaves a half-written settings file
+	// 先写入临时文件，避免程序崩溃时留下写了一半的设置文件
+	tmpPath := a.settingsPath + ".tmp"
+	if err := a.fs.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("Error writing settings file: %v", err)
+	}
+	if err := a.fs.Rename(tmpPath, a.settingsPath); err != nil {
+		return fmt.Errorf("Error replacing settings file: %v", err)
+	}
+	return nil
+}
+
+// currentSettings returns a copy of the current settings
+// currentSettings 返回当前设置的副本
+func (a *App) currentSettings() Settings {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	return a.settings
+}
+
+// updateSettings applies change to the current settings under the settings lock and persists the result, so
+// concurrent updates do not lose each other's changes. Unlike SetSettings it restarts and invalidates nothing,
+// and it keeps no backup, which suits small bookkeeping such as favorites or usage times
+// updateSettings 在设置锁内对当前设置应用 change 并持久化结果，使并发更新不会丢失彼此的修改。
+// 与 SetSettings 不同，它不重启或清除任何内容，也不保留备份，适用于收藏、使用时间等少量记录
+func (a *App) updateSettings(change func(*Settings)) error {
+	a.settingsMu.Lock()
+	change(&a.settings)
+	a.settingsMu.Unlock()
+	if err := a.writeSettings(false); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving settings: %v", err))
+		return err
+	}
+	return nil
+}
+
+// GetSettings returns the current settings to the frontend
+// GetSettings 向前端返回当前设置
+func (a *App) GetSettings() Settings {
+	return a.currentSettings()
+}
+
+// SetSettings replaces the current settings and persists them
+// SetSettings 替换当前设置并持久化保存
+func (a *App) SetSettings(settings Settings) error {
+	previous := a.currentSettings()
+	// 只校验发生变化的下载设置，手动编辑出的无效值不会阻止其他设置的保存
+	// Only changed download settings are validated, so a hand-edited invalid value does not block saving others
+	if settings.Downloads != previous.Downloads {
+		if err := settings.Downloads.validate(); err != nil {
+			return err
+		}
+	}
+	// 凭据只保存在加密存储中
+	// Credentials are only kept in the encrypted store
+	hadSecrets := settings.Sync.Token != ""
+	if err := a.movePlainSecrets(&settings); err != nil {
+		return err
+	}
+	a.settingsMu.Lock()
+	a.settings = settings
+	a.settingsMu.Unlock()
+
+	if err := a.saveSettings(); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving settings: %v", err))
+		return err
+	}
+	a.logToFile("Settings saved")
+	if hadSecrets {
+		// 保存前的设置文件已被轮换为备份，其中可能含有令牌
+		// The settings file before the save was rotated into a backup and may hold the token
+		a.scrubSettingsBackups()
+	}
+
+	// 只在本地接口的开关或端口改变时重启，避免断开正在进行的请求和事件流；允许的来源在每次请求时读取
+	// Restart the local API only when its switch or port changed, so running requests and event streams are
+	// not dropped; the allowed origins are read on every request
+	if settings.LocalAPI.Enabled != previous.LocalAPI.Enabled || settings.LocalAPI.Port != previous.LocalAPI.Port {
+		a.restartLocalAPI()
+	}
+
+	// 镜像、额外来源或区域设置可能已改变，缓存的版本列表需要重新获取
+	// Mirrors, extra sources or the locale may have changed, so the cached version list is fetched again
+	a.invalidateAvailableVersions()
+	// 缓存的索引可能来自已删除的镜像
+	// The cached index may come from a mirror that was removed
+	if !slices.Equal(settings.Mirrors, previous.Mirrors) {
+		a.invalidateDistIndex()
+	}
+	return nil
+}
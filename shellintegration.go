@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Marker lines delimiting the block managed by the app in a shell profile
+// 标记应用在终端配置文件中管理的代码块的起止行
+const (
+	shellBlockStart = "# >>> node-version-switcher >>>"
+	shellBlockEnd   = "# <<< node-version-switcher <<<"
+)
+
+// powerShellSnippet switches to the version in the nearest .nvmrc whenever the prompt is drawn after a cd
+// powerShellSnippet 在每次 cd 后显示提示符时切换到最近的 .nvmrc 中的版本
+const powerShellSnippet = `# Managed by Node Version Switcher, do not edit
+function global:__nvs_auto_use {
+    $dir = (Get-Location).ProviderPath
+    $want = $null
+    while ($dir) {
+        $rc = Join-Path $dir '.nvmrc'
+        if (Test-Path -LiteralPath $rc) {
+            $want = (Get-Content -LiteralPath $rc -TotalCount 1).Trim().TrimStart('v')
+            break
+        }
+        $dir = Split-Path $dir -Parent
+    }
+    if ($want -and $want -ne $global:__nvs_last) {
+        $global:__nvs_last = $want
+        nvm use $want | Out-Null
+    }
+}
+if (-not $global:__nvs_prompt) { $global:__nvs_prompt = $function:prompt }
+function global:prompt { __nvs_auto_use; & $global:__nvs_prompt }`
+
+// ShellIntegration describes whether the integration is installed in one shell
+// ShellIntegration 描述某个终端是否已安装集成
+type ShellIntegration struct {
+	Shell       string
+	ProfilePath string
+	Installed   bool
+}
+
+// shellScriptDir returns the directory holding the generated cmd.exe scripts
+// shellScriptDir 返回存放生成的 cmd.exe 脚本的目录
+func shellScriptDir() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "shell"
+	}
+	return filepath.Join(filepath.Dir(execPath), "shell")
+}
+
+// cmdInitScript defines doskey macros so cd, pushd and popd run the .nvmrc lookup afterwards
+// cmdInitScript 定义 doskey 宏，使 cd、pushd 和 popd 执行后查找 .nvmrc
+func cmdInitScript(useScript string) string {
+	return fmt.Sprintf("@ECHO OFF\r\n"+
+		"REM Generated by Node Version Switcher, do not edit\r\n"+
+		"DOSKEY cd=cd $* $T CALL \"%[1]s\"\r\n"+
+		"DOSKEY pushd=pushd $* $T CALL \"%[1]s\"\r\n"+
+		"DOSKEY popd=popd $T CALL \"%[1]s\"\r\n", useScript)
+}
+
+// cmdUseScript walks up from the current directory to the nearest .nvmrc and runs nvm use with it
+// cmdUseScript 从当前目录向上查找最近的 .nvmrc，并用其中的版本执行 nvm use
+const cmdUseScript = "@ECHO OFF\r\n" +
+	"REM Generated by Node Version Switcher, do not edit\r\n" +
+	"SETLOCAL\r\n" +
+	"SET \"NVS_DIR=%CD%\"\r\n" +
+	":search\r\n" +
+	"IF EXIST \"%NVS_DIR%\\.nvmrc\" GOTO found\r\n" +
+	"FOR %%I IN (\"%NVS_DIR%\\..\") DO SET \"NVS_PARENT=%%~fI\"\r\n" +
+	"IF /I \"%NVS_PARENT%\"==\"%NVS_DIR%\" GOTO :EOF\r\n" +
+	"SET \"NVS_DIR=%NVS_PARENT%\"\r\n" +
+	"GOTO search\r\n" +
+	":found\r\n" +
+	"SET /P NVS_WANT=<\"%NVS_DIR%\\.nvmrc\"\r\n" +
+	"IF \"%NVS_WANT:~0,1%\"==\"v\" SET \"NVS_WANT=%NVS_WANT:~1%\"\r\n" +
+	"nvm use %NVS_WANT% >NUL\r\n"
+
+// powerShellProfilePath asks the given PowerShell for its $PROFILE, which follows redirected Documents folders
+// powerShellProfilePath 向指定的 PowerShell 查询 $PROFILE，可正确处理被重定向的“文档”目录
+func (a *App) powerShellProfilePath(shell string) (string, error) {
+	exe := "powershell.exe"
+	if shell == ShellPwsh {
+		exe = "pwsh.exe"
+	}
+	if _, err := exec.LookPath(exe); err != nil {
+		return "", fmt.Errorf("%s not found", exe)
+	}
+	output, err := a.runHidden(exe, "-NoProfile", "-NonInteractive", "-Command", "$PROFILE.CurrentUserCurrentHost")
+	if err != nil || output == "" {
+		return "", fmt.Errorf("Error reading %s profile path: %v", shell, err)
+	}
+	return output, nil
+}
+
+// replaceShellBlock removes the managed block from content and, when block is not empty, appends the new one
+// replaceShellBlock 从内容中移除受管理的代码块，block 不为空时在末尾追加新的代码块
+func replaceShellBlock(content, block string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if start := strings.Index(content, shellBlockStart); start != -1 {
+		end := strings.Index(content[start:], shellBlockEnd)
+		if end != -1 {
+			end = start + end + len(shellBlockEnd)
+			content = content[:start] + strings.TrimLeft(content[end:], "\n")
+		}
+	}
+	content = strings.TrimRight(content, "\n")
+	if block != "" {
+		if content != "" {
+			content += "\n\n"
+		}
+		content += shellBlockStart + "\n" + block + "\n" + shellBlockEnd
+	}
+	if content == "" {
+		return ""
+	}
+	return strings.ReplaceAll(content, "\n", "\r\n") + "\r\n"
+}
+
+// writePowerShellIntegration installs or, with an empty block, removes the managed block in a PowerShell profile
+// writePowerShellIntegration 在 PowerShell 配置文件中安装受管理的代码块，block 为空时将其移除
+func (a *App) writePowerShellIntegration(shell, block string) (string, error) {
+	profile, err := a.powerShellProfilePath(shell)
+	if err != nil {
+		return "", err
+	}
+	data, err := a.fs.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return profile, fmt.Errorf("Error reading profile: %v", err)
+	}
+	if block == "" && os.IsNotExist(err) {
+		return profile, nil
+	}
+
+	if err := a.fs.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return profile, fmt.Errorf("Error creating profile directory: %v", err)
+	}
+	if err := a.fs.WriteFile(profile, []byte(replaceShellBlock(string(data), block)), 0644); err != nil {
+		return profile, fmt.Errorf("Error writing profile: %v", err)
+	}
+	return profile, nil
+}
+
+// cmdAutoRunEntry returns the AutoRun fragment that loads the init script
+// cmdAutoRunEntry 返回加载初始化脚本的 AutoRun 片段
+func cmdAutoRunEntry() string {
+	return fmt.Sprintf("\"%s\"", filepath.Join(shellScriptDir(), "nvs-init.cmd"))
+}
+
+// stripCmdAutoRun removes our entry from an AutoRun command, keeping any other commands
+// stripCmdAutoRun 从 AutoRun 命令中移除本应用的片段，保留其他命令
+func stripCmdAutoRun(autoRun string) string {
+	var parts []string
+	for _, part := range strings.Split(autoRun, "&") {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.EqualFold(part, cmdAutoRunEntry()) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// writeCmdIntegration writes the cmd.exe scripts and registers them in AutoRun, or removes both
+// writeCmdIntegration 写入 cmd.exe 脚本并注册到 AutoRun，或同时移除两者
+func (a *App) writeCmdIntegration(install bool) (string, error) {
+	dir := shellScriptDir()
+	autoRun, err := readCmdAutoRun()
+	if err != nil {
+		return "", fmt.Errorf("Error reading cmd AutoRun: %v", err)
+	}
+	autoRun = stripCmdAutoRun(autoRun)
+
+	if install {
+		if err := a.fs.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("Error creating script directory: %v", err)
+		}
+		useScript := filepath.Join(dir, "nvs-use.cmd")
+		if err := a.fs.WriteFile(useScript, []byte(cmdUseScript), 0644); err != nil {
+			return "", fmt.Errorf("Error writing script: %v", err)
+		}
+		if err := a.fs.WriteFile(filepath.Join(dir, "nvs-init.cmd"), encodeConsoleText(cmdInitScript(useScript)), 0644); err != nil {
+			return "", fmt.Errorf("Error writing script: %v", err)
+		}
+		if autoRun != "" {
+			autoRun += " & "
+		}
+		autoRun += cmdAutoRunEntry()
+	} else {
+		a.fs.Remove(filepath.Join(dir, "nvs-use.cmd"))
+		a.fs.Remove(filepath.Join(dir, "nvs-init.cmd"))
+	}
+
+	if err := writeCmdAutoRun(autoRun); err != nil {
+		return "", fmt.Errorf("Error writing cmd AutoRun: %v", err)
+	}
+	return cmdAutoRunKey, nil
+}
+
+// InstallShellIntegration installs or updates the automatic `nvm use` on cd for the given shell
+// InstallShellIntegration 为指定终端安装或更新 cd 时自动执行 `nvm use` 的集成
+func (a *App) InstallShellIntegration(shell string) (ShellIntegration, error) {
+	var path string
+	var err error
+	switch shell {
+	case ShellPowerShell, ShellPwsh:
+		path, err = a.writePowerShellIntegration(shell, powerShellSnippet)
+	case ShellCmd:
+		path, err = a.writeCmdIntegration(true)
+	default:
+		return ShellIntegration{}, fmt.Errorf("unsupported shell %q", shell)
+	}
+	if err != nil {
+		a.audit("install-shell-integration", shell, "failed")
+		return ShellIntegration{Shell: shell, ProfilePath: path}, err
+	}
+	a.audit("install-shell-integration", fmt.Sprintf("%s: %s", shell, path), "success")
+	return ShellIntegration{Shell: shell, ProfilePath: path, Installed: true}, nil
+}
+
+// RemoveShellIntegration removes the managed block or AutoRun entry of the given shell
+// RemoveShellIntegration 移除指定终端中受管理的代码块或 AutoRun 片段
+func (a *App) RemoveShellIntegration(shell string) error {
+	var path string
+	var err error
+	switch shell {
+	case ShellPowerShell, ShellPwsh:
+		path, err = a.writePowerShellIntegration(shell, "")
+	case ShellCmd:
+		path, err = a.writeCmdIntegration(false)
+	default:
+		return fmt.Errorf("unsupported shell %q", shell)
+	}
+	if err != nil {
+		a.audit("remove-shell-integration", shell, "failed")
+		return err
+	}
+	a.audit("remove-shell-integration", fmt.Sprintf("%s: %s", shell, path), "success")
+	return nil
+}
+
+// GetShellIntegrationStatus reports for every supported shell whether the integration is installed
+// GetShellIntegrationStatus 报告每个支持的终端是否已安装集成
+func (a *App) GetShellIntegrationStatus() []ShellIntegration {
+	var status []ShellIntegration
+	for _, shell := range []string{ShellPowerShell, ShellPwsh} {
+		profile, err := a.powerShellProfilePath(shell)
+		if err != nil {
+			continue
+		}
+		data, _ := a.fs.ReadFile(profile)
+		status = append(status, ShellIntegration{
+			Shell:       shell,
+			ProfilePath: profile,
+			Installed:   strings.Contains(string(data), shellBlockStart),
+		})
+	}
+
+	autoRun, _ := readCmdAutoRun()
+	status = append(status, ShellIntegration{
+		Shell:       ShellCmd,
+		ProfilePath: cmdAutoRunKey,
+		Installed:   strings.Contains(strings.ToLower(autoRun), strings.ToLower(cmdAutoRunEntry())),
+	})
+	return status
+}
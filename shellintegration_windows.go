@@ -0,0 +1,46 @@
+package main
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// cmdAutoRunKey holds the per-user AutoRun command cmd.exe runs on start
+// cmdAutoRunKey 保存 cmd.exe 启动时运行的当前用户 AutoRun 命令
+const cmdAutoRunKey = `Software\Microsoft\Command Processor`
+
+// readCmdAutoRun returns the current per-user cmd.exe AutoRun command
+// readCmdAutoRun 返回当前用户的 cmd.exe AutoRun 命令
+func readCmdAutoRun() (string, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, cmdAutoRunKey, registry.QUERY_VALUE)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return "", nil
+		}
+		return "", err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue("AutoRun")
+	if err == registry.ErrNotExist {
+		return "", nil
+	}
+	return value, err
+}
+
+// writeCmdAutoRun replaces the per-user cmd.exe AutoRun command, deleting it when empty
+// writeCmdAutoRun 替换当前用户的 cmd.exe AutoRun 命令，为空时删除该值
+func writeCmdAutoRun(value string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, cmdAutoRunKey, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	if value == "" {
+		if err := key.DeleteValue("AutoRun"); err != nil && err != registry.ErrNotExist {
+			return err
+		}
+		return nil
+	}
+	return key.SetStringValue("AutoRun", value)
+}
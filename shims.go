@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// shimDirName is the directory inside a project that holds its shims
+// shimDirName 是项目中存放垫片脚本的目录
+const shimDirName = ".nvs\\bin"
+
+// shimTools are the Node.js tools a shim is generated for, keyed by name with the file inside the version dir
+// shimTools 是需要生成垫片的 Node.js 工具，键为名称，值为版本目录中的文件
+var shimTools = map[string]string{
+	"node":     "node.exe",
+	"npm":      "npm.cmd",
+	"npx":      "npx.cmd",
+	"corepack": "corepack.cmd",
+}
+
+// ShimResult describes the shims generated for a project
+// ShimResult 描述为项目生成的垫片脚本
+type ShimResult struct {
+	Project string
+	Version string
+	Dir     string
+	Files   []string
+}
+
+// cmdShim returns a batch shim that runs tool from versionPath with versionPath first on PATH
+// cmdShim 返回一个批处理垫片，将 versionPath 置于 PATH 最前并运行其中的工具
+func cmdShim(versionPath, tool string) string {
+	return fmt.Sprintf("@ECHO OFF\r\n"+
+		"REM Generated by Node Version Switcher, do not edit\r\n"+
+		"SETLOCAL\r\n"+
+		"SET \"PATH=%s;%%PATH%%\"\r\n"+
+		"\"%s\" %%*\r\n", versionPath, filepath.Join(versionPath, tool))
+}
+
+// msysPath converts a Windows path such as C:\nvm\v18 to the /c/nvm/v18 form used by Git Bash / MSYS
+// msysPath 将 C:\nvm\v18 这样的 Windows 路径转换为 Git Bash / MSYS 使用的 /c/nvm/v18 形式
+func msysPath(path string) string {
+	unixPath := filepath.ToSlash(path)
+	if len(unixPath) > 1 && unixPath[1] == ':' {
+		unixPath = "/" + strings.ToLower(unixPath[:1]) + unixPath[2:]
+	}
+	return unixPath
+}
+
+// shShim returns a POSIX shell shim for Git Bash / MSYS users
+// shShim 返回一个供 Git Bash / MSYS 用户使用的 POSIX shell 垫片
+func shShim(versionPath, tool string) string {
+	unixPath := msysPath(versionPath)
+	return fmt.Sprintf("#!/bin/sh\n"+
+		"# Generated by Node Version Switcher, do not edit\n"+
+		"PATH=\"%s:$PATH\" exec \"%s/%s\" \"$@\"\n", unixPath, unixPath, tool)
+}
+
+// CreateProjectShims writes node/npm/npx/corepack shims into the project's .nvs\bin directory that always launch
+// the project's pinned version, so several projects can run different versions concurrently
+// CreateProjectShims 在项目的 .nvs\bin 目录中写入 node/npm/npx/corepack 垫片，始终启动项目固定的版本，
+// 使多个项目可以同时运行不同版本
+func (a *App) CreateProjectShims(projectPath string) (ShimResult, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return ShimResult{}, err
+	}
+	version, err := a.resolveProjectVersion(project)
+	if err != nil {
+		return ShimResult{}, err
+	}
+	_, versionPath, err := a.versionEnv(version)
+	if err != nil {
+		return ShimResult{}, err
+	}
+
+	dir := filepath.Join(project.Path, shimDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ShimResult{}, fmt.Errorf("Error creating shim directory: %v", err)
+	}
+
+	result := ShimResult{Project: project.Path, Version: version, Dir: dir}
+	for name, tool := range shimTools {
+		if _, err := os.Stat(filepath.Join(versionPath, tool)); err != nil {
+			// 旧版本没有 corepack 等工具
+			// Older versions lack tools such as corepack
+			continue
+		}
+		files := map[string][]byte{
+			// cmd.exe 按 OEM 代码页读取批处理文件，非 ASCII 路径需要转换编码
+			// cmd.exe reads batch files in the OEM code page, so non-ASCII paths must be converted
+			name + ".cmd": encodeConsoleText(cmdShim(versionPath, tool)),
+			name:          []byte(shShim(versionPath, tool)),
+		}
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, file), content, 0755); err != nil {
+				return result, fmt.Errorf("Error writing shim %s: %v", file, err)
+			}
+			result.Files = append(result.Files, file)
+		}
+	}
+
+	sort.Strings(result.Files)
+	a.setProjectShims(project.Path, true)
+	a.logToFile(fmt.Sprintf("Created shims for %s using Node.js %s", project.Path, version))
+	return result, nil
+}
+
+// RemoveProjectShims deletes the shims generated for a project
+// RemoveProjectShims 删除为项目生成的垫片脚本
+func (a *App) RemoveProjectShims(projectPath string) error {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(project.Path, shimDirName)); err != nil {
+		return fmt.Errorf("Error removing shims: %v", err)
+	}
+	// 如果 .nvs 目录已空则一并删除
+	// Remove the .nvs directory as well once it is empty
+	os.Remove(filepath.Dir(filepath.Join(project.Path, shimDirName)))
+
+	a.setProjectShims(project.Path, false)
+	a.logToFile(fmt.Sprintf("Removed shims for %s", project.Path))
+	return nil
+}
+
+// setProjectShims records whether a project uses shims so they can be regenerated when versions change
+// setProjectShims 记录项目是否使用垫片，以便版本变化时重新生成
+func (a *App) setProjectShims(projectPath string, enabled bool) {
+	settings := a.currentSettings()
+	projects := append([]Project{}, settings.Projects...)
+	changed := false
+	for i := range projects {
+		if strings.EqualFold(filepath.Clean(projects[i].Path), filepath.Clean(projectPath)) && projects[i].Shims != enabled {
+			projects[i].Shims = enabled
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	settings.Projects = projects
+	a.SetSettings(settings)
+}
+
+// refreshProjectShims regenerates the shims of all projects using them, e.g. after a new version is installed
+// refreshProjectShims 为所有使用垫片的项目重新生成垫片，例如在安装新版本之后
+func (a *App) refreshProjectShims() {
+	for _, project := range a.GetProjects() {
+		if !project.Shims {
+			continue
+		}
+		if _, err := a.CreateProjectShims(project.Path); err != nil {
+			a.logToFile(fmt.Sprintf("Error refreshing shims for %s: %v", project.Path, err))
+		}
+	}
+}
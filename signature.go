@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// releaseKeyringURL is the keyring of active Node.js releasers published by the nodejs/release-keys repository
+// releaseKeyringURL 是 nodejs/release-keys 仓库发布的当前 Node.js 发布者公钥环
+const releaseKeyringURL = "https://raw.githubusercontent.com/nodejs/release-keys/HEAD/gpg-only-active-keys/pubring.kbx"
+
+// Signature verification results, in addition to SignatureNotChecked and SignatureUnavailable
+// 签名验证结果，SignatureNotChecked 和 SignatureUnavailable 之外的状态
+const (
+	SignatureVerified   = "verified"
+	SignatureInvalid    = "invalid"
+	SignatureUnknownKey = "unknown-key"
+	SignatureNoGpg      = "gpg-not-found"
+)
+
+// releaseKeyFingerprints are the bundled primary key fingerprints of the Node.js releasers.
+// A signature is only trusted when it was made by one of them, whatever else is in the keyring
+// releaseKeyFingerprints 是内置的 Node.js 发布者主密钥指纹。
+// 只有由其中之一签名时才视为可信，与公钥环中的其他密钥无关
+var releaseKeyFingerprints = map[string]string{
+	"C0D6248439F1D5604AAFFB4021D900FFDB233756": "Antoine du Hamel",
+	"4ED778F539E3634C779C87C6D7062848A1AB005C": "Beth Griggs",
+	"141F07595B7B3FFE74309A937405533BE57C7D57": "Bryan English",
+	"74F12602B6F1C4E913FAA37AD3A89613643B6201": "Danielle Adams",
+	"DD792F5973C6DE52C432CBDAC77ABFA00DDBF2B7": "Juan José Arboleda",
+	"CC68F5A3106FF448322E48ED27F5E38D5B0A215F": "Marco Ippolito",
+	"8FCCA13FEF1D0C2E91008E09770F7A9A5AE15600": "Michaël Zasso",
+	"C4F0DFFF4E8C1A8236409D08E73BC641CC11F4C8": "Myles Borins",
+	"890C08DB8579162FEE0DF9DB8BEAB4DFCF555EF4": "Rafael Gonzaga",
+	"C82FA3AE1CBEDC6BE46B9360C43CEC45C17AB93C": "Richard Lau",
+	"108F52B48DB57BB0CC439B2997B01419BD92F80A": "Ruy Adorno",
+	"A363A499291CBBC940DD62E41F10027AF002F8B0": "Ulises Gascón",
+}
+
+// ReleaseKey is a primary key fingerprint trusted to sign Node.js releases
+// ReleaseKey 是受信任用于签署 Node.js 发布的主密钥指纹
+type ReleaseKey struct {
+	Fingerprint string
+	Name        string
+	Bundled     bool // 内置的密钥，不可删除 / bundled with the app, cannot be removed
+}
+
+// normalizeFingerprint strips the spaces gpg prints inside a fingerprint and checks it is 40 hex digits
+// normalizeFingerprint 去掉 gpg 显示指纹时的空格，并检查其是否为 40 位十六进制数
+func normalizeFingerprint(fingerprint string) (string, bool) {
+	fingerprint = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(fingerprint), " ", ""))
+	if len(fingerprint) != 40 || strings.Trim(fingerprint, "0123456789ABCDEF") != "" {
+		return fingerprint, false
+	}
+	return fingerprint, true
+}
+
+// trustedReleaseKeys returns the bundled fingerprints merged with the ones added in the settings, so a new
+// releaser can be trusted without waiting for an app update
+// trustedReleaseKeys 返回内置指纹与设置中添加的指纹的合集，使新的发布者无需等待应用更新即可被信任
+func (a *App) trustedReleaseKeys() map[string]string {
+	trusted := make(map[string]string, len(releaseKeyFingerprints))
+	for fingerprint, name := range releaseKeyFingerprints {
+		trusted[fingerprint] = name
+	}
+	for _, fingerprint := range a.currentSettings().TrustedReleaseKeys {
+		if fingerprint, ok := normalizeFingerprint(fingerprint); ok && trusted[fingerprint] == "" {
+			trusted[fingerprint] = fingerprint
+		}
+	}
+	return trusted
+}
+
+// GetTrustedReleaseKeys lists the fingerprints trusted to sign Node.js releases, bundled ones first
+// GetTrustedReleaseKeys 列出受信任用于签署 Node.js 发布的指纹，内置的排在前面
+func (a *App) GetTrustedReleaseKeys() []ReleaseKey {
+	var keys []ReleaseKey
+	for fingerprint, name := range a.trustedReleaseKeys() {
+		_, bundled := releaseKeyFingerprints[fingerprint]
+		keys = append(keys, ReleaseKey{Fingerprint: fingerprint, Name: name, Bundled: bundled})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Bundled != keys[j].Bundled {
+			return keys[i].Bundled
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// SetTrustedReleaseKeys saves the extra fingerprints trusted besides the bundled ones, e.g. a releaser added
+// to nodejs/release-keys after this version of the app was built
+// SetTrustedReleaseKeys 保存内置指纹之外额外信任的指纹，例如本应用版本发布后才加入 nodejs/release-keys 的发布者
+func (a *App) SetTrustedReleaseKeys(fingerprints []string) error {
+	var extra []string
+	seen := map[string]bool{}
+	for _, fingerprint := range fingerprints {
+		fingerprint, ok := normalizeFingerprint(fingerprint)
+		if !ok {
+			return fmt.Errorf("Invalid key fingerprint: %s", fingerprint)
+		}
+		if _, bundled := releaseKeyFingerprints[fingerprint]; bundled || seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+		extra = append(extra, fingerprint)
+	}
+	settings := a.currentSettings()
+	settings.TrustedReleaseKeys = extra
+	if err := a.SetSettings(settings); err != nil {
+		return err
+	}
+	a.audit("trusted-release-keys", strings.Join(extra, ","), "success")
+	return nil
+}
+
+// findGpg locates gpg on PATH or in the Git for Windows and Gpg4win install folders
+// findGpg 在 PATH 或 Git for Windows、Gpg4win 的安装目录中查找 gpg
+func findGpg() string {
+	if path, err := exec.LookPath("gpg"); err == nil {
+		return path
+	}
+	candidates := []string{
+		filepath.Join(os.Getenv("ProgramFiles"), "Git", "usr", "bin", "gpg.exe"),
+		filepath.Join(os.Getenv("ProgramFiles(x86)"), "GnuPG", "bin", "gpg.exe"),
+		filepath.Join(os.Getenv("ProgramFiles"), "GnuPG", "bin", "gpg.exe"),
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// releaseKeyringDir returns the private gpg home holding the Node.js release keys
+// releaseKeyringDir 返回存放 Node.js 发布公钥的独立 gpg 主目录
+func releaseKeyringDir() string {
+	return filepath.Join(cacheDir(), "release-keys")
+}
+
+// RefreshReleaseKeys downloads the current keyring of Node.js releasers
+// RefreshReleaseKeys 下载当前的 Node.js 发布者公钥环
+func (a *App) RefreshReleaseKeys() error {
+	data, err := a.download(releaseKeyringURL)
+	if err != nil {
+		return fmt.Errorf("Error downloading release keys: %v", err)
+	}
+	if err := os.MkdirAll(releaseKeyringDir(), 0700); err != nil {
+		return fmt.Errorf("Error creating keyring directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(releaseKeyringDir(), "pubring.kbx"), data, 0644); err != nil {
+		return fmt.Errorf("Error writing release keys: %v", err)
+	}
+	a.audit("refresh-release-keys", releaseKeyringURL, "success")
+	return nil
+}
+
+// parseGpgStatus maps gpg --status-fd output to a signature result and the signer name, trusting only the
+// given primary key fingerprints
+// parseGpgStatus 将 gpg --status-fd 输出转换为签名结果和签名者名称，只信任给定的主密钥指纹
+func parseGpgStatus(output string, trusted map[string]string) (string, string) {
+	result := SignatureInvalid
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "VALIDSIG":
+			// 最后一个字段是主密钥指纹
+			// The last field is the primary key fingerprint
+			fingerprint := strings.ToUpper(fields[len(fields)-1])
+			if name, ok := trusted[fingerprint]; ok {
+				return SignatureVerified, name
+			}
+			result = SignatureUnknownKey
+		case "NO_PUBKEY":
+			result = SignatureUnknownKey
+		}
+	}
+	return result, ""
+}
+
+// verifyShasums checks the detached signature of a SHASUMS256.txt file against the release keys
+// verifyShasums 使用发布公钥校验 SHASUMS256.txt 文件的分离签名
+func (a *App) verifyShasums(version string, shasums []byte, signatureURL string) string {
+	gpg := findGpg()
+	if gpg == "" {
+		return SignatureNoGpg
+	}
+
+	keyring := filepath.Join(releaseKeyringDir(), "pubring.kbx")
+	if info, err := os.Stat(keyring); err != nil || a.clock.Now().Sub(info.ModTime()) > 30*24*time.Hour {
+		if err := a.RefreshReleaseKeys(); err != nil {
+			a.logToFile(err.Error())
+		}
+	}
+
+	signature, err := a.download(signatureURL)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error downloading signature for %s: %v", version, err))
+		return SignatureUnavailable
+	}
+
+	tmpDir, err := os.MkdirTemp("", "nvs-verify-")
+	if err != nil {
+		return SignatureNotChecked
+	}
+	defer os.RemoveAll(tmpDir)
+	shasumsPath := filepath.Join(tmpDir, "SHASUMS256.txt")
+	signaturePath := shasumsPath + ".sig"
+	if os.WriteFile(shasumsPath, shasums, 0644) != nil || os.WriteFile(signaturePath, signature, 0644) != nil {
+		return SignatureNotChecked
+	}
+
+	// 验证失败时 gpg 返回非零退出码，结果以状态输出为准
+	// gpg exits non-zero when verification fails, the status output is what counts
+	output, _ := a.outputOf(CommandSpec{Name: gpg, Args: []string{"--homedir", releaseKeyringDir(), "--batch", "--no-tty",
+		"--status-fd", "1", "--verify", signaturePath, shasumsPath}, Hidden: true})
+	result, signer := parseGpgStatus(string(output), a.trustedReleaseKeys())
+
+	detail := version
+	if signer != "" {
+		detail = fmt.Sprintf("%s signed by %s", version, signer)
+	}
+	a.audit("verify-signature", detail, result)
+	return result
+}
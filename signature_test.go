@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestParseGpgStatus(t *testing.T) {
+	const fingerprint = "C0D6248439F1D5604AAFFB4021D900FFDB233756"
+	trusted := map[string]string{fingerprint: "Antoine du Hamel"}
+	validsig := "[GNUPG:] VALIDSIG 0123 2024-11-20 1732060800 0 4 0 1 10 00 "
+
+	tests := []struct {
+		name, output, result, signer string
+	}{
+		{"trusted", "[GNUPG:] NEWSIG\n" + validsig + fingerprint + "\n", SignatureVerified, "Antoine du Hamel"},
+		{"lower case fingerprint", validsig + "c0d6248439f1d5604aaffb4021d900ffdb233756\n", SignatureVerified, "Antoine du Hamel"},
+		{"untrusted", validsig + "0000000000000000000000000000000000000000\n", SignatureUnknownKey, ""},
+		{"missing key", "[GNUPG:] ERRSIG 0123 1 10 00 1732060800 9\n[GNUPG:] NO_PUBKEY 0123\n", SignatureUnknownKey, ""},
+		{"bad signature", "[GNUPG:] BADSIG 0123 someone\n", SignatureInvalid, ""},
+		{"no output", "", SignatureInvalid, ""},
+	}
+	for _, tt := range tests {
+		result, signer := parseGpgStatus(tt.output, trusted)
+		if result != tt.result || signer != tt.signer {
+			t.Errorf("%s: parseGpgStatus = (%q, %q), want (%q, %q)", tt.name, result, signer, tt.result, tt.signer)
+		}
+	}
+}
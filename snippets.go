@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Snippet formats besides the interactive shells defined in session.go
+// 除 session.go 中定义的交互式终端外的代码片段格式
+const (
+	SnippetBash          = "bash"
+	SnippetGitHubActions = "github-actions"
+	SnippetDockerfile    = "dockerfile"
+)
+
+// GetEnvSnippet returns a copy-ready snippet pinning the given version for a shell (powershell, pwsh, cmd, bash)
+// or a CI format (github-actions, dockerfile). Shell snippets put the locally installed version first on PATH
+// GetEnvSnippet 返回固定指定版本、可直接复制的代码片段，支持终端（powershell、pwsh、cmd、bash）
+// 和 CI 格式（github-actions、dockerfile）。终端片段会将本地已安装的版本置于 PATH 最前
+func (a *App) GetEnvSnippet(version, shell string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return "", fmt.Errorf("No version given")
+	}
+
+	switch shell {
+	case SnippetGitHubActions:
+		return fmt.Sprintf("- uses: actions/setup-node@v4\n  with:\n    node-version: '%s'\n", version), nil
+	case SnippetDockerfile:
+		return fmt.Sprintf("FROM node:%s\n", version), nil
+	}
+
+	root := a.nvmRoot()
+	if root == "" {
+		return "", fmt.Errorf("Unable to locate the nvm root directory")
+	}
+	dir := versionDir(root, version)
+	switch shell {
+	case ShellPowerShell, ShellPwsh:
+		return fmt.Sprintf("$env:Path = %s + ';' + $env:Path\n", psQuote(dir)), nil
+	case ShellCmd:
+		return fmt.Sprintf("set \"PATH=%s;%%PATH%%\"\n", dir), nil
+	case SnippetBash:
+		return fmt.Sprintf("export PATH=\"%s:$PATH\"\n", msysPath(dir)), nil
+	default:
+		return "", fmt.Errorf("Unsupported snippet format: %s", shell)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// StaleNodeProcess is a node.exe still running the previous version after a switch
+// StaleNodeProcess 表示切换后仍在运行旧版本的 node.exe 进程
+type StaleNodeProcess struct {
+	PID         uint32
+	Path        string
+	Version     string
+	CommandLine string
+	Project     string // 命令行所属的已登记项目 / registered project the command line belongs to
+	CanRestart  bool
+}
+
+// processCommandLine reads the command line of a process through WMI
+// processCommandLine 通过 WMI 读取进程的命令行
+func (a *App) processCommandLine(pid uint32) string {
+	output, err := a.runHidden("powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+		fmt.Sprintf("(Get-CimInstance Win32_Process -Filter 'ProcessId=%d').CommandLine", pid))
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+// projectForCommandLine returns the registered project whose directory appears in the command line
+// projectForCommandLine 返回命令行中出现其目录的已登记项目
+func (a *App) projectForCommandLine(commandLine string) string {
+	lower := strings.ToLower(commandLine)
+	best := ""
+	for _, project := range a.currentSettings().Projects {
+		dir := strings.ToLower(filepath.Clean(project.Path))
+		// 嵌套项目取最长的匹配路径
+		// Prefer the longest match for nested projects
+		if strings.Contains(lower, dir) && len(dir) > len(best) {
+			best = project.Path
+		}
+	}
+	return best
+}
+
+// describeStaleProcess fills in the command line and project of a process
+// describeStaleProcess 补充进程的命令行和所属项目
+func (a *App) describeStaleProcess(p NodeProcess) StaleNodeProcess {
+	stale := StaleNodeProcess{PID: p.PID, Path: p.Path, Version: p.Version, CommandLine: a.processCommandLine(p.PID)}
+	if stale.CommandLine != "" {
+		stale.Project = a.projectForCommandLine(stale.CommandLine)
+		stale.CanRestart = stale.Project != ""
+	}
+	return stale
+}
+
+// reportStaleProcesses emits the processes that kept running the old version through a switch.
+// before is the process list taken before the switch, when the nvm symlink still pointed at the old version
+// reportStaleProcesses 发送切换后仍在运行旧版本的进程。before 是切换前获取的进程列表，此时 nvm 符号链接仍指向旧版本
+func (a *App) reportStaleProcesses(before []NodeProcess, newVersion string) {
+	alive, err := listProcesses("node.exe")
+	if err != nil {
+		return
+	}
+	running := map[uint32]string{}
+	for _, p := range alive {
+		running[p.PID] = p.Path
+	}
+
+	var stale []StaleNodeProcess
+	for _, p := range before {
+		if p.Version == "" || p.Version == strings.TrimPrefix(newVersion, "v") || !samePath(running[p.PID], p.Path) {
+			continue
+		}
+		stale = append(stale, a.describeStaleProcess(p))
+	}
+	if len(stale) == 0 {
+		return
+	}
+	a.logToFile(fmt.Sprintf("%d node.exe processes still run the previous version after switching to %s", len(stale), newVersion))
+	a.notify("stale-node-processes", stale)
+}
+
+// nodeProcess looks up a running node.exe by PID so only node processes can be terminated
+// nodeProcess 按 PID 查找正在运行的 node.exe，确保只会结束 node 进程
+func (a *App) nodeProcess(pid uint32) (NodeProcess, error) {
+	processes, err := a.GetRunningNodeProcesses()
+	if err != nil {
+		return NodeProcess{}, err
+	}
+	for _, p := range processes {
+		if p.PID == pid {
+			return p, nil
+		}
+	}
+	return NodeProcess{}, fmt.Errorf("No node.exe process with PID %d", pid)
+}
+
+// TerminateNodeProcess ends a running node.exe
+// TerminateNodeProcess 结束一个正在运行的 node.exe
+func (a *App) TerminateNodeProcess(pid uint32) error {
+	p, err := a.nodeProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := terminateProcess(pid); err != nil {
+		a.audit("terminate-node-process", fmt.Sprintf("%d %s", pid, p.Path), "failed")
+		return fmt.Errorf("Error terminating process %d: %v", pid, err)
+	}
+	a.audit("terminate-node-process", fmt.Sprintf("%d %s", pid, p.Path), "success")
+	return nil
+}
+
+// RestartNodeProcess ends a node.exe launched from a registered project and runs the same command
+// again in a new console with the current version
+// RestartNodeProcess 结束从已登记项目启动的 node.exe，并在新的控制台中使用当前版本重新运行相同命令
+func (a *App) RestartNodeProcess(pid uint32) error {
+	p, err := a.nodeProcess(pid)
+	if err != nil {
+		return err
+	}
+	stale := a.describeStaleProcess(p)
+	if !stale.CanRestart {
+		return fmt.Errorf("Process %d was not started from a registered project", pid)
+	}
+	version, err := a.currentNodeVersion()
+	if err != nil {
+		return err
+	}
+	env, _, err := a.versionEnv(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return err
+	}
+
+	// 将命令行中旧的 node.exe 路径替换为 node，使其从当前版本解析
+	// Replace the old node.exe path in the command line with node so it resolves to the current version
+	command := stale.CommandLine
+	if strings.HasPrefix(command, `"`) {
+		if end := strings.Index(command[1:], `"`); end >= 0 {
+			command = "node" + command[end+2:]
+		}
+	} else if first, rest, ok := strings.Cut(command, " "); ok && strings.EqualFold(filepath.Base(first), "node.exe") {
+		command = "node " + rest
+	}
+
+	if err := a.TerminateNodeProcess(pid); err != nil {
+		return err
+	}
+	spec := CommandSpec{Name: "cmd.exe", CmdLine: "cmd.exe /K " + command, Dir: stale.Project, Env: env, NewConsole: true}
+	if err := a.executor.Start(spec); err != nil {
+		return fmt.Errorf("Error restarting %s: %v", command, err)
+	}
+	a.logToFile(fmt.Sprintf("Restarted %q in %s with Node.js %s", command, stale.Project, version))
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+)
+
+// Startup issue severities
+// 启动问题的严重程度
+const (
+	IssueSeverityWarning = "warning"
+	IssueSeverityError   = "error"
+)
+
+// StartupIssue describes a problem detected and possibly recovered from during startup
+// StartupIssue 描述启动过程中检测到（并可能已恢复）的问题
+type StartupIssue struct {
+	Code     string
+	Severity string
+	Message  string
+	Time     time.Time
+}
+
+// addStartupIssue records a startup issue and logs it
+// addStartupIssue 记录一个启动问题并写入日志
+func (a *App) addStartupIssue(code, severity, message string) {
+	a.issuesMu.Lock()
+	a.startupIssues = append(a.startupIssues, StartupIssue{
+		Code:     code,
+		Severity: severity,
+		Message:  message,
+		Time:     a.clock.Now(),
+	})
+	a.issuesMu.Unlock()
+
+	a.logToFile("Startup issue [" + code + "]: " + message)
+}
+
+// GetStartupIssues returns the issues detected while the application was starting
+// GetStartupIssues 返回应用启动时检测到的问题
+func (a *App) GetStartupIssues() []StartupIssue {
+	a.issuesMu.Lock()
+	defer a.issuesMu.Unlock()
+	return append([]StartupIssue{}, a.startupIssues...)
+}
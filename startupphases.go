@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// StartupProgress is emitted as the "startup-progress" event while the deferred subsystems initialize
+// StartupProgress 在延迟初始化各子系统期间作为 "startup-progress" 事件发送
+type StartupProgress struct {
+	Step      string
+	Completed int
+	Total     int
+	Done      bool
+	ElapsedMs int64 // 自进程启动以来的毫秒数 / milliseconds since the process started
+}
+
+// startupStep is one subsystem initialized after the window is shown
+// startupStep 表示窗口显示后初始化的一个子系统
+type startupStep struct {
+	name string
+	run  func()
+}
+
+// processStart is when the process started, used to measure perceived startup time
+// processStart 是进程启动的时间，用于衡量感知到的启动耗时
+var processStart = time.Now()
+
+var (
+	startupMu    sync.Mutex
+	startupState StartupProgress
+	startupOnce  sync.Once
+)
+
+// deferredStartupSteps lists the subsystems that are initialized once the window is visible
+// deferredStartupSteps 列出窗口可见后才初始化的子系统
+func (a *App) deferredStartupSteps() []startupStep {
+	steps := []startupStep{
+		// 删除上一次自动更新遗留的旧可执行文件
+		// Remove the executable left behind by the previous self-update
+		{"cleanup", a.removeOldExecutable},
+		{"tray", func() { go runSystray() }},
+	}
+	// 安全模式下不启动任何后台任务和外部集成
+	// In safe mode no background tasks or integrations are started
+	if !a.safeMode {
+		steps = append(steps,
+			startupStep{"health-check", func() { go a.healthCheck() }},
+			startupStep{"webhooks", func() { go a.runWebhookWorker(a.ctx) }},
+			startupStep{"local-api", a.restartLocalAPI},
+			// 已启用时定期发送资产清单
+			// Send the periodic inventory when it is enabled
+			startupStep{"inventory", func() { go a.watchInventory() }},
+			// 在到期时执行计划的安装和清理
+			// Run the scheduled installs and cleanups when they are due
+			startupStep{"scheduled-jobs", func() { go a.watchScheduledJobs() }},
+			// 高对比度和减少动画设置变化时通知前端
+			// Tell the frontend when high contrast or reduced motion change
+			startupStep{"accessibility", func() { go a.watchAccessibility() }},
+			// 演示或专注助手结束后发送暂缓的通知
+			// Deliver the notifications deferred while presenting or in Focus Assist
+			startupStep{"focus-mode", func() { go a.watchFocusMode() }},
+			// 使用电池时暂停后台维护
+			// Pause background maintenance on battery
+			startupStep{"power", func() { go a.watchPower() }},
+		)
+	}
+	steps = append(steps,
+		// 按当前显示器布局恢复窗口位置
+		// Restore the window position against the current monitor layout
+		startupStep{"window", a.restoreWindowGeometry},
+	)
+	// 生成任务栏跳转列表，需要调用 nvm，放在最后；安全模式下不生成
+	// Build the taskbar jump list last, it has to run nvm; not built in safe mode
+	if !a.safeMode {
+		steps = append(steps, startupStep{"jump-list", a.refreshJumpList})
+	}
+	return steps
+}
+
+// runDeferredStartup initializes the remaining subsystems after the frontend has loaded, reporting progress.
+// Later DOM ready callbacks, e.g. after the health check reloads the window, only re-emit the final state
+// runDeferredStartup 在前端加载完成后初始化其余子系统，并报告进度。
+// 之后的 DOM 就绪回调（例如健康检查重新加载窗口后）只会重新发送最终状态
+func (a *App) runDeferredStartup(ctx context.Context) {
+	first := false
+	startupOnce.Do(func() { first = true })
+	if !first {
+		runtime.EventsEmit(ctx, "startup-progress", a.GetStartupProgress())
+		return
+	}
+	a.logToFile(fmt.Sprintf("Window ready after %dms", time.Since(processStart).Milliseconds()))
+	steps := a.deferredStartupSteps()
+	for i, step := range steps {
+		start := a.clock.Now()
+		step.run()
+		a.logToFile(fmt.Sprintf("Startup step %s took %dms", step.name, a.clock.Now().Sub(start).Milliseconds()))
+		a.setStartupProgress(ctx, StartupProgress{Step: step.name, Completed: i + 1, Total: len(steps), Done: i == len(steps)-1})
+	}
+	a.logToFile(fmt.Sprintf("Startup completed after %dms", time.Since(processStart).Milliseconds()))
+}
+
+// setStartupProgress stores and emits the startup progress
+// setStartupProgress 保存并发送启动进度
+func (a *App) setStartupProgress(ctx context.Context, progress StartupProgress) {
+	progress.ElapsedMs = time.Since(processStart).Milliseconds()
+	startupMu.Lock()
+	startupState = progress
+	startupMu.Unlock()
+	runtime.EventsEmit(ctx, "startup-progress", progress)
+}
+
+// GetStartupProgress returns the latest startup progress for a frontend that missed the events
+// GetStartupProgress 为错过事件的前端返回最新的启动进度
+func (a *App) GetStartupProgress() StartupProgress {
+	startupMu.Lock()
+	defer startupMu.Unlock()
+	return startupState
+}
+
+// promptLoggingSetup asks whether to enable logging on the first run without blocking startup
+// promptLoggingSetup 首次运行时询问是否启用日志，不阻塞启动
+func (a *App) promptLoggingSetup(ctx context.Context, existingLogFile bool) {
+	if existingLogFile || a.debugMode {
+		a.enableLogs = true
+		fmt.Println("Debug: Logging automatically enabled due to existing log file or debug mode")
+		return
+	}
+
+	fmt.Println("Debug: Not in debug mode and no existing log file, showing dialog")
+	go func() {
+		result, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+			Type:          runtime.QuestionDialog,
+			Title:         "日志设置",
+			Message:       "是否启用应用程序日志记录？",
+			Buttons:       []string{"Yes", "No"},
+			DefaultButton: "No",
+			CancelButton:  "No",
+		})
+		if err != nil {
+			fmt.Printf("Debug: Error showing dialog: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Debug: Dialog result: %s\n", result)
+		if result == "Yes" {
+			fmt.Println("Debug: User selected 'Yes', enabling logs")
+			a.enableLogs = true
+			a.logToFile("Application startup initiated")
+		} else {
+			fmt.Println("Debug: User selected 'No', logs will be disabled")
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SwitchImpact summarizes what changes when switching from the current version to a target,
+// for the confirmation screen shown before a switch
+// SwitchImpact 汇总从当前版本切换到目标版本时发生的变化，用于切换前的确认界面
+type SwitchImpact struct {
+	Current          string
+	Target           string
+	CurrentNpm       string
+	TargetNpm        string
+	NpmChanged       bool
+	MissingGlobals   []string // 仅在当前版本中安装的全局包 / Global packages installed only on the current version
+	PinnedProjects   []Project
+	RunningProcesses []NodeProcess
+	TargetInstalled  bool
+	EngineWarnings   []EngineWarning // 不接受目标版本的工具 / tools whose engines reject the target
+}
+
+// globalPackageNames returns the names of the global packages of a version, without their versions
+// globalPackageNames 返回某个版本全局包的名称（不含版本号）
+func globalPackageNames(dir string) map[string]bool {
+	names := map[string]bool{}
+	for spec := range globalPackageDirs(dir) {
+		if i := strings.LastIndex(spec, "@"); i > 0 {
+			spec = spec[:i]
+		}
+		names[spec] = true
+	}
+	return names
+}
+
+// GetSwitchImpact computes what switching to target changes: the npm version, the global packages left
+// behind on the current version, the projects pinned to the current version, its running node processes
+// and the tools that will refuse to run on target
+// GetSwitchImpact 计算切换到目标版本带来的变化：npm 版本、留在当前版本中的全局包、
+// 固定在当前版本的项目、当前版本正在运行的 node 进程以及将拒绝在目标版本上运行的工具
+func (a *App) GetSwitchImpact(target string) (SwitchImpact, error) {
+	target = strings.TrimPrefix(strings.TrimSpace(target), "v")
+	impact := SwitchImpact{Target: target}
+	root := a.nvmRoot()
+	if root == "" {
+		return impact, fmt.Errorf("nvm root not found")
+	}
+
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return impact, err
+	}
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		if v.IsCurrent {
+			impact.Current = version
+		}
+		if version == target {
+			impact.TargetInstalled = true
+		}
+	}
+	if impact.TargetInstalled {
+		impact.TargetNpm = bundledNpmVersion(a.fs, versionDir(root, target))
+	}
+	impact.EngineWarnings, _ = a.GetEngineWarnings(target)
+	if impact.Current == "" || impact.Current == target {
+		return impact, nil
+	}
+
+	currentDir := versionDir(root, impact.Current)
+	impact.CurrentNpm = bundledNpmVersion(a.fs, currentDir)
+	impact.NpmChanged = impact.CurrentNpm != impact.TargetNpm
+
+	targetGlobals := map[string]bool{}
+	if impact.TargetInstalled {
+		targetGlobals = globalPackageNames(versionDir(root, target))
+	}
+	for name := range globalPackageNames(currentDir) {
+		if !targetGlobals[name] {
+			impact.MissingGlobals = append(impact.MissingGlobals, name)
+		}
+	}
+	sort.Strings(impact.MissingGlobals)
+
+	for _, p := range a.GetProjects() {
+		if version, err := a.resolveProjectVersion(p); err == nil && version == impact.Current {
+			impact.PinnedProjects = append(impact.PinnedProjects, p)
+		}
+	}
+	impact.RunningProcesses = a.processesUsingVersion(impact.Current)
+	return impact, nil
+}
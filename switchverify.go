@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SwitchFailure describes a switch whose verification failed and what was done about it
+// SwitchFailure 描述一次验证失败的切换及其处理结果
+type SwitchFailure struct {
+	Target      string
+	Previous    string
+	Observed    string // 切换后 node -v 的输出 / Output of node -v after the switch
+	Diagnosis   string
+	Reverted    bool
+	RevertError string
+	Time        time.Time
+}
+
+// verifySwitch runs node -v through the nvm symlink and returns the reported version with a diagnosis
+// when it is not the target
+// verifySwitch 通过 nvm 符号链接运行 node -v，返回其报告的版本，与目标不符时同时返回诊断信息
+func (a *App) verifySwitch(target string) (string, string) {
+	target = strings.TrimPrefix(target, "v")
+	symlink := nvmEnvPath("NVM_SYMLINK")
+	if symlink == "" {
+		return "", "未设置 NVM_SYMLINK / NVM_SYMLINK is not set"
+	}
+	if _, err := os.Lstat(symlink); err != nil {
+		return "", fmt.Sprintf("符号链接 %s 不存在，可能缺少创建权限 / The symlink %s does not exist, creating it may need elevation", symlink, symlink)
+	}
+
+	output, err := a.executor.CombinedOutput(CommandSpec{Name: filepath.Join(symlink, "node.exe"), Args: []string{"-v"}, Hidden: true})
+	observed := strings.TrimPrefix(strings.TrimSpace(string(output)), "v")
+	if err != nil {
+		return observed, fmt.Sprintf("无法运行 %s: %v / node.exe could not be run", filepath.Join(symlink, "node.exe"), err)
+	}
+	if observed == target {
+		return observed, ""
+	}
+	if root := a.nvmRoot(); root != "" {
+		if dest, err := os.Readlink(symlink); err == nil && !samePath(dest, versionDir(root, target)) {
+			return observed, fmt.Sprintf("符号链接仍指向 %s，可能是权限不足或文件被占用 / The symlink still points to %s, permissions or a locked file may have prevented the update", dest, dest)
+		}
+	}
+	return observed, fmt.Sprintf("node -v 报告 %s 而不是 %s / node -v reports %s instead of %s", observed, target, observed, target)
+}
+
+// revertFailedSwitch switches back to previous after a failed verification and records the failure
+// revertFailedSwitch 在验证失败后切换回之前的版本，并记录失败信息
+func (a *App) revertFailedSwitch(target, previous, observed, diagnosis string) SwitchFailure {
+	failure := SwitchFailure{Target: target, Previous: previous, Observed: observed, Diagnosis: diagnosis, Time: a.clock.Now()}
+	if previous != "" && previous != target {
+		if output, err := a.executeNvmCommand("use", previous); err != nil {
+			failure.RevertError = strings.TrimSpace(string(output))
+		} else if _, problem := a.verifySwitch(previous); problem != "" {
+			failure.RevertError = problem
+		} else {
+			failure.Reverted = true
+		}
+		a.clearVersionDetails()
+	}
+
+	a.switchFailureMu.Lock()
+	a.lastSwitchFailure = &failure
+	a.switchFailureMu.Unlock()
+
+	outcome := "reverted"
+	if !failure.Reverted {
+		outcome = "not reverted"
+	}
+	a.audit("switch-verify", fmt.Sprintf("%s: %s", target, diagnosis), outcome)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "switch-failed", failure)
+	}
+	return failure
+}
+
+// GetLastSwitchFailure returns the last switch that failed verification, or nil
+// GetLastSwitchFailure 返回最近一次验证失败的切换，没有时返回 nil
+func (a *App) GetLastSwitchFailure() *SwitchFailure {
+	a.switchFailureMu.Lock()
+	defer a.switchFailureMu.Unlock()
+	return a.lastSwitchFailure
+}
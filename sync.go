@@ -0,0 +1,366 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Conflict resolution strategies for SyncNow
+// SyncNow 的冲突解决策略
+const (
+	SyncMerge        = "merge"
+	SyncPreferLocal  = "prefer-local"
+	SyncPreferRemote = "prefer-remote"
+)
+
+// syncKeyIterations is the PBKDF2 iteration count used to derive the encryption key from the passphrase
+// syncKeyIterations 是从口令派生加密密钥时使用的 PBKDF2 迭代次数
+const syncKeyIterations = 200000
+
+// syncSaveSlack ignores the settings write made by the sync itself when detecting local changes
+// syncSaveSlack 在检测本地修改时忽略同步本身写入设置文件造成的时间差
+const syncSaveSlack = 5 * time.Second
+
+// SyncConfig configures the optional settings sync
+// SyncConfig 配置可选的设置同步
+type SyncConfig struct {
+	Enabled      bool      `json:"enabled"`
+	Provider     string    `json:"provider"` // gist 或 webdav
+	Endpoint     string    `json:"endpoint"` // Gist ID 或 WebDAV 文件地址
+	Username     string    `json:"username"`
+	Token        string    `json:"token,omitempty"` // 旧版本的明文令牌，启动时迁移到 SecretSyncToken
+	LastSyncedAt time.Time `json:"lastSyncedAt"`
+}
+
+// syncedSettings is the part of the settings shared between machines
+// syncedSettings 是在多台机器之间共享的设置部分
+type syncedSettings struct {
+	Projects           []Project    `json:"projects"`
+	Mirrors            []string     `json:"mirrors"`
+	DistSources        []DistSource `json:"distSources"`
+	RebuildAfterSwitch []string     `json:"rebuildAfterSwitch"`
+	MinFreeDiskMB      int          `json:"minFreeDiskMb"`
+	UpdateChannel      string       `json:"updateChannel"`
+}
+
+// syncSecrets are encrypted end to end before leaving the machine
+// syncSecrets 在离开本机之前进行端到端加密
+type syncSecrets struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+}
+
+// syncDocument is what is stored at the sync provider
+// syncDocument 是保存在同步服务上的内容
+type syncDocument struct {
+	UpdatedAt time.Time      `json:"updatedAt"`
+	Machine   string         `json:"machine"`
+	Settings  syncedSettings `json:"settings"`
+	Salt      string         `json:"salt"`
+	Secrets   string         `json:"secrets"`
+}
+
+// SyncResult reports the outcome of a sync
+// SyncResult 报告一次同步的结果
+type SyncResult struct {
+	Pulled    bool
+	Pushed    bool
+	Conflict  bool
+	Strategy  string
+	Remote    string
+	UpdatedAt time.Time
+}
+
+// pbkdf2SHA256 derives a key from a passphrase as specified in RFC 8018
+// pbkdf2SHA256 按 RFC 8018 从口令派生密钥
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// syncCipher returns the AES-GCM cipher for a passphrase and salt
+// syncCipher 返回由口令和盐生成的 AES-GCM 加密器
+func syncCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, syncKeyIterations, 32))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealSecrets encrypts the secrets with a key derived from the passphrase
+// sealSecrets 使用从口令派生的密钥加密敏感信息
+func sealSecrets(secrets syncSecrets, passphrase string) (string, string, error) {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return "", "", err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", "", err
+	}
+	aead, err := syncCipher(passphrase, salt)
+	if err != nil {
+		return "", "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, nil)
+	return base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecrets decrypts secrets sealed by sealSecrets
+// openSecrets 解密由 sealSecrets 加密的敏感信息
+func openSecrets(doc syncDocument, passphrase string) (syncSecrets, error) {
+	var secrets syncSecrets
+	if doc.Secrets == "" {
+		return secrets, nil
+	}
+	salt, err := base64.StdEncoding.DecodeString(doc.Salt)
+	if err != nil {
+		return secrets, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(doc.Secrets)
+	if err != nil {
+		return secrets, err
+	}
+	aead, err := syncCipher(passphrase, salt)
+	if err != nil {
+		return secrets, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return secrets, fmt.Errorf("sync secrets are truncated")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return secrets, fmt.Errorf("wrong sync passphrase or corrupted secrets")
+	}
+	err = json.Unmarshal(plain, &secrets)
+	return secrets, err
+}
+
+// mergeProjects combines two project registries by path; preferred wins when both contain a project
+// mergeProjects 按路径合并两个项目列表，两边都存在同一项目时以 preferred 为准
+func mergeProjects(preferred, other []Project) []Project {
+	merged := append([]Project{}, preferred...)
+	seen := map[string]bool{}
+	for _, p := range preferred {
+		seen[strings.ToLower(filepath.Clean(p.Path))] = true
+	}
+	for _, p := range other {
+		if !seen[strings.ToLower(filepath.Clean(p.Path))] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// mergeStrings returns the union of two lists, keeping the order of preferred first
+// mergeStrings 返回两个列表的并集，优先保留 preferred 的顺序
+func mergeStrings(preferred, other []string) []string {
+	merged := append([]string{}, preferred...)
+	seen := map[string]bool{}
+	for _, s := range preferred {
+		seen[s] = true
+	}
+	for _, s := range other {
+		if !seen[s] {
+			seen[s] = true
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// mergeSynced merges remote into local; the newer side wins scalar fields and duplicate entries
+// mergeSynced 将远程设置合并到本地，较新的一方在标量字段和重复条目上优先
+func mergeSynced(local, remote syncedSettings, localSecrets, remoteSecrets syncSecrets, remoteNewer bool) (syncedSettings, syncSecrets) {
+	newer, older := local, remote
+	newerSecrets, olderSecrets := localSecrets, remoteSecrets
+	if remoteNewer {
+		newer, older = remote, local
+		newerSecrets, olderSecrets = remoteSecrets, localSecrets
+	}
+
+	merged := newer
+	merged.Projects = mergeProjects(newer.Projects, older.Projects)
+	merged.Mirrors = mergeStrings(newer.Mirrors, older.Mirrors)
+	merged.RebuildAfterSwitch = mergeStrings(newer.RebuildAfterSwitch, older.RebuildAfterSwitch)
+	merged.DistSources = append([]DistSource{}, newer.DistSources...)
+	for _, s := range older.DistSources {
+		found := false
+		for _, n := range merged.DistSources {
+			found = found || n.URL == s.URL
+		}
+		if !found {
+			merged.DistSources = append(merged.DistSources, s)
+		}
+	}
+
+	secrets := syncSecrets{Webhooks: append([]WebhookConfig{}, newerSecrets.Webhooks...)}
+	for _, w := range olderSecrets.Webhooks {
+		found := false
+		for _, n := range secrets.Webhooks {
+			found = found || n.Name == w.Name
+		}
+		if !found {
+			secrets.Webhooks = append(secrets.Webhooks, w)
+		}
+	}
+	return merged, secrets
+}
+
+// syncedFrom extracts the shared part of the settings
+// syncedFrom 提取设置中共享的部分
+func syncedFrom(s Settings) (syncedSettings, syncSecrets) {
+	return syncedSettings{
+		Projects:           s.Projects,
+		Mirrors:            s.Mirrors,
+		DistSources:        s.DistSources,
+		RebuildAfterSwitch: s.RebuildAfterSwitch,
+		MinFreeDiskMB:      s.MinFreeDiskMB,
+		UpdateChannel:      s.UpdateChannel,
+	}, syncSecrets{Webhooks: s.Webhooks}
+}
+
+// applySynced writes the shared part back into the settings
+// applySynced 将共享部分写回设置
+func applySynced(s Settings, synced syncedSettings, secrets syncSecrets) Settings {
+	s.Projects = synced.Projects
+	s.Mirrors = synced.Mirrors
+	s.DistSources = synced.DistSources
+	s.RebuildAfterSwitch = synced.RebuildAfterSwitch
+	s.MinFreeDiskMB = synced.MinFreeDiskMB
+	s.UpdateChannel = synced.UpdateChannel
+	s.Webhooks = secrets.Webhooks
+	return s
+}
+
+// settingsModifiedAt returns when the settings file was last written
+// settingsModifiedAt 返回设置文件最后一次写入的时间
+func (a *App) settingsModifiedAt() time.Time {
+	if info, err := os.Stat(a.settingsPath); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// SyncNow pulls the remote settings, resolves conflicts with the given strategy and pushes the result.
+// Webhooks and other secrets are encrypted with the passphrase before they are uploaded
+// SyncNow 拉取远程设置，按指定策略解决冲突并推送结果。
+// Webhook 等敏感信息在上传前使用口令加密
+func (a *App) SyncNow(passphrase, strategy string) (SyncResult, error) {
+	if a.safeMode {
+		return SyncResult{}, fmt.Errorf("networking disabled in safe mode")
+	}
+	if strategy == "" {
+		strategy = SyncMerge
+	}
+	settings := a.currentSettings()
+	config := settings.Sync
+	if token, err := a.getSecret(SecretSyncToken); err == nil && token != "" {
+		config.Token = token
+	}
+	if !config.Enabled {
+		return SyncResult{}, fmt.Errorf("sync is not enabled")
+	}
+	if passphrase == "" {
+		return SyncResult{}, fmt.Errorf("a sync passphrase is required to encrypt secrets")
+	}
+	provider, err := newSyncProvider(config, a.client(30*time.Second))
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	result := SyncResult{Strategy: strategy}
+	local, localSecrets := syncedFrom(settings)
+	merged, mergedSecrets := local, localSecrets
+
+	data, err := provider.pull()
+	if err != nil {
+		a.audit("sync", config.Provider, "failed")
+		return result, fmt.Errorf("Error pulling sync data: %v", err)
+	}
+	if data != nil {
+		var remote syncDocument
+		if err := json.Unmarshal(data, &remote); err != nil {
+			return result, fmt.Errorf("Error parsing sync data: %v", err)
+		}
+		remoteSecrets, err := openSecrets(remote, passphrase)
+		if err != nil {
+			return result, err
+		}
+		result.Remote = remote.Machine
+
+		// 两边在上次同步后都有修改即为冲突
+		// It is a conflict when both sides changed since the last sync
+		remoteChanged := remote.UpdatedAt.After(config.LastSyncedAt)
+		localChanged := a.settingsModifiedAt().After(config.LastSyncedAt.Add(syncSaveSlack))
+		result.Conflict = remoteChanged && localChanged
+
+		switch {
+		case strategy == SyncPreferRemote || (remoteChanged && !localChanged):
+			merged, mergedSecrets = remote.Settings, remoteSecrets
+			result.Pulled = true
+		case strategy == SyncPreferLocal || !remoteChanged:
+		default:
+			merged, mergedSecrets = mergeSynced(local, remote.Settings, localSecrets, remoteSecrets, remote.UpdatedAt.After(a.settingsModifiedAt()))
+			result.Pulled = true
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	doc := syncDocument{UpdatedAt: a.clock.Now(), Machine: hostname, Settings: merged}
+	if doc.Salt, doc.Secrets, err = sealSecrets(mergedSecrets, passphrase); err != nil {
+		return result, fmt.Errorf("Error encrypting secrets: %v", err)
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	if err := provider.push(out); err != nil {
+		a.audit("sync", config.Provider, "failed")
+		return result, fmt.Errorf("Error pushing sync data: %v", err)
+	}
+	result.Pushed = true
+	result.UpdatedAt = doc.UpdatedAt
+
+	settings = applySynced(a.currentSettings(), merged, mergedSecrets)
+	settings.Sync.LastSyncedAt = doc.UpdatedAt
+	if err := a.SetSettings(settings); err != nil {
+		return result, err
+	}
+	a.audit("sync", fmt.Sprintf("%s (%s)", config.Provider, strategy), "success")
+	return result, nil
+}
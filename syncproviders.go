@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sync provider kinds
+// 同步服务类型
+const (
+	SyncProviderGist   = "gist"
+	SyncProviderWebDAV = "webdav"
+)
+
+// syncFileName is the file holding the sync document in a gist
+// syncFileName 是 Gist 中保存同步文档的文件名
+const syncFileName = "node-version-switcher-sync.json"
+
+// syncProvider stores the sync document somewhere reachable from every machine
+// syncProvider 将同步文档保存在所有机器都能访问的位置
+type syncProvider interface {
+	// pull returns the remote document, or nil when nothing has been pushed yet
+	// pull 返回远程文档，尚未推送过时返回 nil
+	pull() ([]byte, error)
+	// push replaces the remote document
+	// push 替换远程文档
+	push(data []byte) error
+}
+
+// gistProvider syncs through a file in a GitHub gist
+// gistProvider 通过 GitHub Gist 中的文件进行同步
+type gistProvider struct {
+	client *http.Client
+	id     string
+	token  string
+}
+
+// webdavProvider syncs through a file on a WebDAV server
+// webdavProvider 通过 WebDAV 服务器上的文件进行同步
+type webdavProvider struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+// newSyncProvider returns the provider configured in the sync settings, sending its requests with client
+// newSyncProvider 返回同步设置中配置的同步服务，并使用 client 发送请求
+func newSyncProvider(config SyncConfig, client *http.Client) (syncProvider, error) {
+	switch config.Provider {
+	case SyncProviderGist:
+		if config.Endpoint == "" || config.Token == "" {
+			return nil, fmt.Errorf("gist sync needs a gist id and a token")
+		}
+		return &gistProvider{client: client, id: config.Endpoint, token: config.Token}, nil
+	case SyncProviderWebDAV:
+		if config.Endpoint == "" {
+			return nil, fmt.Errorf("WebDAV sync needs a file URL")
+		}
+		return &webdavProvider{client: client, url: config.Endpoint, username: config.Username, password: config.Token}, nil
+	default:
+		return nil, fmt.Errorf("unknown sync provider %q", config.Provider)
+	}
+}
+
+// request sends an authenticated request to the GitHub gist API
+// request 向 GitHub Gist 接口发送带认证的请求
+func (g *gistProvider) request(method string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, "https://api.github.com/gists/"+g.id, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	return g.client.Do(req)
+}
+
+func (g *gistProvider) pull() ([]byte, error) {
+	resp, err := g.request(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var gist struct {
+		Files map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return nil, fmt.Errorf("Error parsing gist: %v", err)
+	}
+	file, ok := gist.Files[syncFileName]
+	if !ok || file.Content == "" {
+		return nil, nil
+	}
+	return []byte(file.Content), nil
+}
+
+func (g *gistProvider) push(data []byte) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"files": map[string]interface{}{
+			syncFileName: map[string]string{"content": string(data)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := g.request(http.MethodPatch, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// request sends a request to the WebDAV file with basic authentication
+// request 使用基本认证向 WebDAV 文件发送请求
+func (w *webdavProvider) request(method string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, w.url, body)
+	if err != nil {
+		return nil, err
+	}
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return w.client.Do(req)
+}
+
+func (w *webdavProvider) pull() ([]byte, error) {
+	resp, err := w.request(http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+func (w *webdavProvider) push(data []byte) error {
+	resp, err := w.request(http.MethodPut, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
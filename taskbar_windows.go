@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	clsidTaskbarList = syscall.GUID{Data1: 0x56fdf344, Data2: 0xfd6d, Data3: 0x11d0, Data4: [8]byte{0x95, 0x8a, 0x00, 0x60, 0x97, 0xc9, 0xa0, 0x90}}
+	iidITaskbarList3 = syscall.GUID{Data1: 0xea1afb91, Data2: 0x9e28, Data3: 0x4b86, Data4: [8]byte{0x90, 0xe9, 0x9e, 0x9f, 0x8a, 0x5e, 0xef, 0xaf}}
+
+	moduser32                    = syscall.NewLazyDLL("user32.dll")
+	procFindWindowW              = moduser32.NewProc("FindWindowW")
+	procGetWindowThreadProcessId = moduser32.NewProc("GetWindowThreadProcessId")
+)
+
+// ITaskbarList3 vtable indices and progress flags
+// ITaskbarList3 虚函数表索引和进度状态标志
+const (
+	tblHrInit           = 3
+	tblSetProgressValue = 9
+	tblSetProgressState = 10
+
+	tbpfNoProgress    = 0x0
+	tbpfIndeterminate = 0x1
+	tbpfNormal        = 0x2
+	tbpfError         = 0x4
+)
+
+// mainWindowHandle finds the main window of this process by its title
+// mainWindowHandle 通过标题查找本进程的主窗口
+func mainWindowHandle() (uintptr, error) {
+	title, _ := syscall.UTF16PtrFromString(appTitle)
+	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(title)))
+	if hwnd == 0 {
+		return 0, fmt.Errorf("main window not found")
+	}
+
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if int(pid) != os.Getpid() {
+		return 0, fmt.Errorf("window %q belongs to another process", appTitle)
+	}
+	return hwnd, nil
+}
+
+// setTaskbarProgress sets the progress state and value of the taskbar button
+// setTaskbarProgress 设置任务栏按钮的进度状态和进度值
+func setTaskbarProgress(state uintptr, percent int) error {
+	hwnd, err := mainWindowHandle()
+	if err != nil {
+		return err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	return withCOM(func() error {
+		taskbar, err := coCreateInstance(&clsidTaskbarList, &iidITaskbarList3)
+		if err != nil {
+			return err
+		}
+		defer taskbar.release()
+
+		if err := taskbar.call(tblHrInit); err != nil {
+			return err
+		}
+		if err := taskbar.call(tblSetProgressState, hwnd, state); err != nil {
+			return err
+		}
+		if state == tbpfNormal || state == tbpfError {
+			return taskbar.call(tblSetProgressValue, hwnd, uintptr(percent), 100)
+		}
+		return nil
+	})
+}
+
+// updateTaskbarProgress reflects an install progress update on the taskbar button
+// updateTaskbarProgress 在任务栏按钮上显示安装进度
+func (a *App) updateTaskbarProgress(progress InstallProgress) {
+	var err error
+	switch {
+	case progress.Phase == PhaseCompleted:
+		err = setTaskbarProgress(tbpfNoProgress, 0)
+	case progress.Phase == PhaseFailed:
+		err = setTaskbarProgress(tbpfError, 100)
+	case progress.Percent < 0:
+		err = setTaskbarProgress(tbpfIndeterminate, 0)
+	default:
+		err = setTaskbarProgress(tbpfNormal, progress.Percent)
+	}
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error updating taskbar progress: %v", err))
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task priorities, lower values are dispatched first
+// 任务优先级，数值越小越先执行
+const (
+	TaskPriorityInteractive = 0
+	TaskPriorityNormal      = 1
+	TaskPriorityBackground  = 2
+)
+
+// Task states
+// 任务状态
+const (
+	TaskQueued  = "queued"
+	TaskRunning = "running"
+)
+
+// taskWorkers is the number of workers running queued tasks
+// taskWorkers 是执行队列任务的工作协程数量
+const taskWorkers = 2
+
+// TaskInfo describes a queued or running task
+// TaskInfo 描述一个排队中或正在执行的任务
+type TaskInfo struct {
+	ID        int64
+	Name      string
+	Priority  int
+	State     string
+	QueuedAt  time.Time
+	StartedAt time.Time
+}
+
+// task is a unit of work in the pool
+// task 是工作池中的一个工作单元
+type task struct {
+	info TaskInfo
+	fn   func()
+}
+
+// taskPool runs housekeeping on a few workers. While an interactive action such as a switch runs,
+// background tasks are not started and running ones pause at their yield points. On battery power
+// background tasks are not started either
+// taskPool 使用少量工作协程执行后台维护任务。交互操作（如切换版本）执行期间不会启动后台任务，
+// 正在执行的后台任务会在让出点暂停。使用电池供电时同样不会启动后台任务
+type taskPool struct {
+	mu          sync.Mutex
+	cond        *sync.Cond
+	queue       []*task
+	running     map[int64]*task
+	nextID      int64
+	interactive int
+	powerSaving bool
+	started     bool
+}
+
+// init prepares the pool and starts the workers, the caller holds p.mu
+// init 初始化工作池并启动工作协程，调用方需持有 p.mu
+func (p *taskPool) init(a *App) {
+	if p.started {
+		return
+	}
+	p.started = true
+	p.cond = sync.NewCond(&p.mu)
+	p.running = map[int64]*task{}
+	for i := 0; i < taskWorkers; i++ {
+		go p.worker(a)
+	}
+}
+
+// submitTask queues fn under name; a task with the same name already waiting is not queued twice
+// submitTask 以 name 将 fn 加入队列；已有同名任务在等待时不会重复加入
+func (a *App) submitTask(name string, priority int, fn func()) {
+	p := &a.tasks
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.init(a)
+	for _, t := range p.queue {
+		if t.info.Name == name {
+			return
+		}
+	}
+	p.nextID++
+	p.queue = append(p.queue, &task{
+		info: TaskInfo{ID: p.nextID, Name: name, Priority: priority, State: TaskQueued, QueuedAt: a.clock.Now()},
+		fn:   fn,
+	})
+	// 同优先级内保持先进先出
+	// Keep FIFO order within the same priority
+	sort.SliceStable(p.queue, func(i, j int) bool { return p.queue[i].info.Priority < p.queue[j].info.Priority })
+	p.cond.Broadcast()
+}
+
+// next blocks until a task may run: background tasks wait while an interactive action is in progress
+// or the machine runs on battery
+// next 阻塞直到有任务可以执行：交互操作进行期间或使用电池供电时后台任务保持等待
+func (p *taskPool) next() *task {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.queue) == 0 || ((p.interactive > 0 || p.powerSaving) && p.queue[0].info.Priority >= TaskPriorityBackground) {
+		p.cond.Wait()
+	}
+	t := p.queue[0]
+	p.queue = p.queue[1:]
+	t.info.State = TaskRunning
+	t.info.StartedAt = time.Now()
+	p.running[t.info.ID] = t
+	return t
+}
+
+// worker runs queued tasks until the application exits
+// worker 持续执行队列中的任务直到应用程序退出
+func (p *taskPool) worker(a *App) {
+	for {
+		t := p.next()
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					a.logToFile(fmt.Sprintf("Task %s panicked: %v", t.info.Name, r))
+				}
+			}()
+			t.fn()
+		}()
+		p.mu.Lock()
+		delete(p.running, t.info.ID)
+		p.mu.Unlock()
+	}
+}
+
+// beginInteractive marks an interactive action as running and returns the function ending it
+// beginInteractive 标记交互操作开始执行，并返回结束该操作的函数
+func (a *App) beginInteractive(name string) func() {
+	p := &a.tasks
+	p.mu.Lock()
+	p.init(a)
+	p.nextID++
+	t := &task{info: TaskInfo{ID: p.nextID, Name: name, Priority: TaskPriorityInteractive, State: TaskRunning,
+		QueuedAt: a.clock.Now(), StartedAt: a.clock.Now()}}
+	p.running[t.info.ID] = t
+	p.interactive++
+	p.mu.Unlock()
+
+	return func() {
+		p.mu.Lock()
+		delete(p.running, t.info.ID)
+		p.interactive--
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	}
+}
+
+// setPowerSaving holds background tasks back while on battery and releases them on AC power
+// setPowerSaving 在使用电池时暂缓后台任务，接通电源后放行
+func (p *taskPool) setPowerSaving(a *App, saving bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.init(a)
+	if p.powerSaving != saving {
+		p.powerSaving = saving
+		p.cond.Broadcast()
+	}
+}
+
+// yieldToInteractive lets long background loops pause while an interactive action runs
+// yieldToInteractive 使耗时的后台循环在交互操作执行期间暂停
+func (a *App) yieldToInteractive() {
+	p := &a.tasks
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.started && p.interactive > 0 {
+		p.cond.Wait()
+	}
+}
+
+// GetTaskQueue returns the running tasks followed by the queued ones in dispatch order
+// GetTaskQueue 返回正在执行的任务，以及按执行顺序排列的排队任务
+func (a *App) GetTaskQueue() []TaskInfo {
+	p := &a.tasks
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tasks := make([]TaskInfo, 0, len(p.running)+len(p.queue))
+	for _, t := range p.running {
+		tasks = append(tasks, t.info)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	for _, t := range p.queue {
+		tasks = append(tasks, t.info)
+	}
+	return tasks
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// teamConfigFile is the team config committed to project repositories
+// teamConfigFile 是提交到项目仓库中的团队配置文件
+const teamConfigFile = ".nvs-team.json"
+
+// TeamConfig lists the versions and mirrors a team agreed on for a project
+// TeamConfig 列出团队为项目约定的版本和镜像
+type TeamConfig struct {
+	AllowedVersions    []string `json:"allowedVersions"`    // 完整版本号或 "18"、"20.11" 这样的前缀 / full versions or prefixes such as "18" or "20.11"
+	RecommendedVersion string   `json:"recommendedVersion"` // 推荐使用的版本 / version to use by default
+	Mirrors            []string `json:"mirrors"`            // 团队使用的下载镜像 / download mirrors the team uses
+}
+
+// TeamDrift reports where the local environment differs from a project's team config
+// TeamDrift 报告本地环境与项目团队配置的差异
+type TeamDrift struct {
+	Project  string
+	Config   *TeamConfig
+	Warnings []string
+}
+
+// readTeamConfig reads the team config of a project directory, returning nil when there is none
+// readTeamConfig 读取项目目录中的团队配置，不存在时返回 nil
+func readTeamConfig(dir string) (*TeamConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, teamConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var config TeamConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %v", teamConfigFile, err)
+	}
+	return &config, nil
+}
+
+// versionAllowed reports whether a version matches one of the allowed versions or prefixes; an empty list allows all
+// versionAllowed 判断版本是否匹配允许的版本或前缀之一，列表为空时全部允许
+func versionAllowed(version string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	version = strings.TrimPrefix(version, "v")
+	for _, entry := range allowed {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), "v")
+		if version == entry || strings.HasPrefix(version, entry+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTeamConfig compares the project's pin, the installed versions, the active version and the mirrors
+// with the project's team config
+// CheckTeamConfig 将项目的固定版本、已安装版本、当前版本和镜像与项目的团队配置进行比较
+func (a *App) CheckTeamConfig(projectPath string) (TeamDrift, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return TeamDrift{}, err
+	}
+	drift := TeamDrift{Project: project.Path}
+	config, err := readTeamConfig(project.Path)
+	if err != nil || config == nil {
+		return drift, err
+	}
+	drift.Config = config
+
+	if project.PinnedVersion != "" && !versionAllowed(project.PinnedVersion, config.AllowedVersions) {
+		drift.Warnings = append(drift.Warnings, fmt.Sprintf("%s 固定的版本 %s 不在团队允许的版本中 / The version %s pinned in %s is not allowed by the team",
+			project.PinSource, project.PinnedVersion, project.PinnedVersion, project.PinSource))
+	}
+
+	installed, err := a.GetInstalledNodeVersions()
+	if err == nil {
+		recommended := strings.TrimPrefix(config.RecommendedVersion, "v")
+		found := recommended == ""
+		for _, v := range installed {
+			if v.Version == recommended {
+				found = true
+			}
+			if v.IsCurrent && !versionAllowed(v.Version, config.AllowedVersions) {
+				drift.Warnings = append(drift.Warnings, fmt.Sprintf("当前版本 %s 不在团队允许的版本中 / The active version %s is not allowed by the team", v.Version, v.Version))
+			}
+		}
+		if !found {
+			drift.Warnings = append(drift.Warnings, fmt.Sprintf("未安装团队推荐的版本 %s / The team's recommended version %s is not installed", recommended, recommended))
+		}
+	}
+
+	local := a.currentSettings().Mirrors
+	for _, mirror := range config.Mirrors {
+		known := false
+		for _, m := range local {
+			if strings.EqualFold(strings.TrimRight(m, "/"), strings.TrimRight(mirror, "/")) {
+				known = true
+			}
+		}
+		if !known {
+			drift.Warnings = append(drift.Warnings, fmt.Sprintf("未配置团队镜像 %s / The team mirror %s is not configured", mirror, mirror))
+		}
+	}
+	return drift, nil
+}
+
+// reportTeamDrift checks a newly registered project against its team config and emits any drift
+// reportTeamDrift 检查新登记的项目与其团队配置的差异并发送结果
+func (a *App) reportTeamDrift(projectPath string) {
+	drift, err := a.CheckTeamConfig(projectPath)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Error reading team config of %s: %v", projectPath, err))
+		return
+	}
+	if len(drift.Warnings) == 0 {
+		return
+	}
+	a.logToFile(fmt.Sprintf("Team config drift in %s: %s", projectPath, strings.Join(drift.Warnings, "; ")))
+	a.notify("team-drift", drift)
+}
+
+// ExportTeamConfig writes a team config for the project with the given versions and the local mirrors,
+// for team leads to commit to the repository
+// ExportTeamConfig 使用指定版本和本地镜像为项目写入团队配置，供团队负责人提交到仓库
+func (a *App) ExportTeamConfig(projectPath string, allowed []string, recommended string) (string, error) {
+	project, err := a.findProject(projectPath)
+	if err != nil {
+		return "", err
+	}
+	if recommended == "" {
+		recommended = project.PinnedVersion
+	}
+	config := TeamConfig{
+		AllowedVersions:    allowed,
+		RecommendedVersion: strings.TrimPrefix(recommended, "v"),
+		Mirrors:            a.currentSettings().Mirrors,
+	}
+	if config.RecommendedVersion != "" && !versionAllowed(config.RecommendedVersion, allowed) {
+		return "", fmt.Errorf("The recommended version %s is not among the allowed versions", config.RecommendedVersion)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(project.Path, teamConfigFile)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("Error writing %s: %v", teamConfigFile, err)
+	}
+	a.logToFile(fmt.Sprintf("Exported team config to %s", path))
+	return path, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// IsFrameless reports whether the window runs without the native title bar
+// IsFrameless 判断窗口是否以无边框模式运行（不使用系统标题栏）
+func (a *App) IsFrameless() bool {
+	return a.currentSettings().Frameless
+}
+
+// SetFrameless saves the frameless preference; it takes effect after a restart, which is done when restart is set
+// SetFrameless 保存无边框窗口偏好，重启后生效，restart 为真时立即重启
+func (a *App) SetFrameless(enabled, restart bool) error {
+	settings := a.currentSettings()
+	if settings.Frameless != enabled {
+		settings.Frameless = enabled
+		if err := a.SetSettings(settings); err != nil {
+			return err
+		}
+	}
+	if restart {
+		return a.restartApp()
+	}
+	return nil
+}
+
+// WindowMinimise minimises the window from the custom title bar
+// WindowMinimise 从自定义标题栏最小化窗口
+func (a *App) WindowMinimise() {
+	runtime.WindowMinimise(a.ctx)
+}
+
+// WindowToggleMaximise maximises or restores the window from the custom title bar
+// WindowToggleMaximise 从自定义标题栏最大化或还原窗口
+func (a *App) WindowToggleMaximise() {
+	runtime.WindowToggleMaximise(a.ctx)
+}
+
+// WindowIsMaximised tells the custom title bar which maximise icon to show
+// WindowIsMaximised 告诉自定义标题栏应显示哪个最大化图标
+func (a *App) WindowIsMaximised() bool {
+	return runtime.WindowIsMaximised(a.ctx)
+}
+
+// WindowClose closes the application from the custom title bar
+// WindowClose 从自定义标题栏关闭应用程序
+func (a *App) WindowClose() {
+	a.saveWindowGeometry()
+	runtime.Quit(a.ctx)
+}
+
+// WindowStartDrag starts moving the window with the mouse, for title bar areas that cannot use the
+// --wails-draggable CSS property; it must be called while the left mouse button is down
+// WindowStartDrag 开始用鼠标拖动窗口，用于无法使用 --wails-draggable CSS 属性的标题栏区域，
+// 必须在鼠标左键按下时调用
+func (a *App) WindowStartDrag() error {
+	if err := startWindowDrag(); err != nil {
+		a.logToFile(fmt.Sprintf("Error starting window drag: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// minNpxNpmVersion is the first npm release shipping npx
+// minNpxNpmVersion 是第一个附带 npx 的 npm 版本
+const minNpxNpmVersion = "5.2.0"
+
+// npxBundled reports whether the npm version ships npx
+// npxBundled 判断该 npm 版本是否附带 npx
+func npxBundled(npmVersion string) bool {
+	return npmVersion != "" && npmVersion != "unknown" && compareSemver(npmVersion, minNpxNpmVersion) >= 0
+}
+
+// installedToolVersions asks an installed node.exe for its V8 and OpenSSL versions
+// installedToolVersions 从已安装的 node.exe 读取其 V8 和 OpenSSL 版本
+func (a *App) installedToolVersions(version string) (string, string) {
+	home, _ := os.UserHomeDir()
+	output, err := a.runWithNodeVersion(version, home, "node.exe", "-p", "JSON.stringify(process.versions)")
+	if err != nil {
+		return "", ""
+	}
+	var versions struct {
+		V8      string `json:"v8"`
+		OpenSSL string `json:"openssl"`
+	}
+	if json.Unmarshal([]byte(strings.TrimSpace(string(output))), &versions) != nil {
+		return "", ""
+	}
+	return versions.V8, versions.OpenSSL
+}
+
+// withInstalledToolVersions replaces the index data of installed versions with what their binaries report,
+// using the cached version metadata
+// withInstalledToolVersions 使用缓存的版本元数据，将已安装版本的索引数据替换为其二进制文件实际报告的内容
+func (a *App) withInstalledToolVersions(versions []NodeVersionInfo) []NodeVersionInfo {
+	metadata, err := a.GetVersionMetadata()
+	if err != nil {
+		return versions
+	}
+	byVersion := make(map[string]VersionMetadata, len(metadata))
+	for _, meta := range metadata {
+		byVersion[meta.Version] = meta
+	}
+	for i := range versions {
+		meta, ok := byVersion[versions[i].Version]
+		if !ok {
+			continue
+		}
+		if meta.V8 != "" {
+			versions[i].V8 = meta.V8
+		}
+		if meta.OpenSSL != "" {
+			versions[i].OpenSSL = meta.OpenSSL
+		}
+		versions[i].Corepack = meta.Corepack
+		versions[i].Npx = meta.Npx
+	}
+	return versions
+}
+
+// bundledTool reports whether a tool such as corepack.cmd exists in a version directory
+// bundledTool 判断版本目录中是否存在 corepack.cmd 等工具
+func bundledTool(dir, tool string) bool {
+	_, err := os.Stat(filepath.Join(dir, tool))
+	return err == nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultUninstallRetentionDays is how long an uninstalled version can be restored from the Recycle Bin
+// defaultUninstallRetentionDays 是已卸载版本可以从回收站恢复的天数
+const defaultUninstallRetentionDays = 30
+
+// TrashedVersion is an uninstalled version whose directory was sent to the Recycle Bin
+// TrashedVersion 表示目录已移入回收站的已卸载版本
+type TrashedVersion struct {
+	Version   string    `json:"version"`
+	Path      string    `json:"path"`
+	RemovedAt time.Time `json:"removedAt"`
+	ExpiresAt time.Time `json:"expiresAt"` // 读取时计算 / computed when read
+}
+
+// uninstallRetention returns the configured restore window
+// uninstallRetention 返回配置的可恢复期限
+func (a *App) uninstallRetention() time.Duration {
+	days := a.currentSettings().UninstallRetentionDays
+	if days <= 0 {
+		days = defaultUninstallRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// trashVersion sends the directory of an installed version other than the current one to the Recycle Bin and
+// remembers it for restoring
+// trashVersion 将当前版本以外的已安装版本目录移入回收站，并记录下来以便恢复
+func (a *App) trashVersion(version string) error {
+	root := a.nvmRoot()
+	if root == "" {
+		return fmt.Errorf("nvm root not found")
+	}
+	// nvm 的符号链接指向当前版本，移走其目录会留下失效的链接，因此交由 nvm 处理
+	// The nvm symlink points at the current version and would dangle once its directory is moved, so nvm
+	// handles that version
+	if current, err := a.currentNodeVersion(); err == nil && strings.TrimPrefix(current, "v") == version {
+		return fmt.Errorf("Node.js %s is currently in use", version)
+	}
+	dir := versionDir(root, version)
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	if err := recycleDir(dir); err != nil {
+		return err
+	}
+
+	// 同时丢弃已超过可恢复期限的记录
+	// Records past the restore window are dropped at the same time
+	settings := a.currentSettings()
+	trashed := []TrashedVersion{{Version: version, Path: dir, RemovedAt: a.clock.Now()}}
+	for _, t := range settings.TrashedVersions {
+		if t.Version != version && a.clock.Now().Sub(t.RemovedAt) < a.uninstallRetention() {
+			trashed = append(trashed, t)
+		}
+	}
+	settings.TrashedVersions = trashed
+	if err := a.SetSettings(settings); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving settings: %v", err))
+	}
+	return nil
+}
+
+// GetTrashedVersions returns the uninstalled versions that can still be restored, newest first
+// GetTrashedVersions 返回仍可恢复的已卸载版本，最近卸载的在前
+func (a *App) GetTrashedVersions() []TrashedVersion {
+	retention := a.uninstallRetention()
+	var result []TrashedVersion
+	for _, t := range a.currentSettings().TrashedVersions {
+		t.ExpiresAt = t.RemovedAt.Add(retention)
+		if a.clock.Now().Before(t.ExpiresAt) {
+			result = append(result, t)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RemovedAt.After(result[j].RemovedAt) })
+	return result
+}
+
+// RestoreUninstalledVersion moves a version uninstalled within the retention window back from the Recycle Bin
+// RestoreUninstalledVersion 将在可恢复期限内卸载的版本从回收站移回
+func (a *App) RestoreUninstalledVersion(version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var trashed *TrashedVersion
+	for _, t := range a.GetTrashedVersions() {
+		if t.Version == version {
+			trashed = &t
+			break
+		}
+	}
+	if trashed == nil {
+		return "", fmt.Errorf("Node.js %s cannot be restored: it was not uninstalled within the last %d days", version, int(a.uninstallRetention().Hours()/24))
+	}
+	if _, err := os.Stat(trashed.Path); err == nil {
+		return "", fmt.Errorf("Node.js %s is already installed", version)
+	}
+	if err := restoreRecycled(trashed.Path); err != nil {
+		a.audit("restore-version", version, "failed")
+		return "", fmt.Errorf("Error restoring Node.js %s: %v", version, err)
+	}
+
+	settings := a.currentSettings()
+	var remaining []TrashedVersion
+	for _, t := range settings.TrashedVersions {
+		if t.Version != version {
+			remaining = append(remaining, t)
+		}
+	}
+	settings.TrashedVersions = remaining
+	if err := a.SetSettings(settings); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving settings: %v", err))
+	}
+
+	a.audit("restore-version", version, "success")
+	a.invalidateAvailableVersions()
+	a.invalidateVersionMetadata(version)
+	a.submitTask("jump-list", TaskPriorityBackground, a.refreshJumpList)
+	a.submitTask("project-shims", TaskPriorityBackground, a.refreshProjectShims)
+	return fmt.Sprintf("Restored Node.js %s", version), nil
+}
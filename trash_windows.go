@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSHFileOperationW = modshell32.NewProc("SHFileOperationW")
+
+// SHFileOperation function and flags
+// SHFileOperation 的操作及标志
+const (
+	foDelete           = 0x0003
+	fofSilent          = 0x0004
+	fofNoConfirmation  = 0x0010
+	fofAllowUndo       = 0x0040
+	fofNoErrorUI       = 0x0400
+	fofWantNukeWarning = 0x4000
+	recycleBinDirName  = "$Recycle.Bin"
+	recycleInfoVersion = 2
+)
+
+// shFileOpStruct mirrors SHFILEOPSTRUCTW on 64-bit Windows
+// shFileOpStruct 对应 64 位 Windows 上的 SHFILEOPSTRUCTW
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+// recycleDir sends a directory to the Recycle Bin without showing any dialog, unless the directory would be
+// deleted permanently instead, e.g. because it is too large for the Recycle Bin
+// recycleDir 将目录移入回收站，不显示任何对话框；只有目录将被永久删除时（例如超出回收站容量）才会提示
+func recycleDir(path string) error {
+	// pFrom 是以两个空字符结尾的路径列表
+	// pFrom is a list of paths ending with two null characters
+	from := utf16.Encode([]rune(path + "\x00\x00"))
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofSilent | fofNoErrorUI | fofWantNukeWarning,
+	}
+	if r, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op))); r != 0 {
+		return fmt.Errorf("SHFileOperation failed with code 0x%x", r)
+	}
+	if op.fAnyOperationsAborted != 0 {
+		return fmt.Errorf("moving %s to the Recycle Bin was aborted", path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s is still present after moving it to the Recycle Bin", path)
+	}
+	// 确认目录确实进入了回收站，而不是被永久删除
+	// Make sure the directory landed in the Recycle Bin rather than being deleted permanently
+	if _, err := findRecycled(path); err != nil {
+		return fmt.Errorf("%s was deleted permanently instead of being moved to the Recycle Bin", path)
+	}
+	return nil
+}
+
+// userRecycleBin returns the current user's Recycle Bin folder on the drive of path
+// userRecycleBin 返回 path 所在磁盘上当前用户的回收站目录
+func userRecycleBin(path string) (string, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.VolumeName(path)+`\`, recycleBinDirName, user.User.Sid.String()), nil
+}
+
+// readRecycleInfo returns the original path and the deletion time stored in a $I file of the Recycle Bin
+// readRecycleInfo 返回回收站 $I 文件中保存的原始路径及删除时间
+func readRecycleInfo(path string) (string, uint64) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) < 24 {
+		return "", 0
+	}
+	// 头部为版本、大小和删除时间各 8 字节；版本 2 随后是 4 字节的长度，版本 1 是固定 260 个字符
+	// The header holds the version, size and deletion time in 8 bytes each; version 2 continues with
+	// a 4 byte length, version 1 with a fixed 260 characters
+	deleted := binary.LittleEndian.Uint64(data[16:])
+	name := data[24:]
+	if binary.LittleEndian.Uint64(data) == recycleInfoVersion {
+		if len(name) < 4 {
+			return "", 0
+		}
+		name = name[4:]
+	}
+	chars := make([]uint16, len(name)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(name[i*2:])
+	}
+	return windows.UTF16ToString(chars), deleted
+}
+
+// findRecycled returns the $I file of the item most recently recycled from originalPath
+// findRecycled 返回最近一次从 originalPath 移入回收站的项目的 $I 文件
+func findRecycled(originalPath string) (string, error) {
+	bin, err := userRecycleBin(originalPath)
+	if err != nil {
+		return "", err
+	}
+	infos, err := filepath.Glob(filepath.Join(bin, "$I*"))
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestTime uint64
+	for _, info := range infos {
+		if path, deleted := readRecycleInfo(info); samePath(path, originalPath) && deleted >= newestTime {
+			newest, newestTime = info, deleted
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("%s was not found in the Recycle Bin", originalPath)
+	}
+	return newest, nil
+}
+
+// restoreRecycled moves the item most recently recycled from originalPath back to where it was
+// restoreRecycled 将最近一次从 originalPath 移入回收站的项目移回原位置
+func restoreRecycled(originalPath string) error {
+	info, err := findRecycled(originalPath)
+	if err != nil {
+		return err
+	}
+	// $R 项保存内容，名称与 $I 文件相同
+	// The $R item holds the contents under the same name as the $I file
+	data := filepath.Join(filepath.Dir(info), "$R"+strings.TrimPrefix(filepath.Base(info), "$I"))
+	if err := os.Rename(data, originalPath); err != nil {
+		return err
+	}
+	os.Remove(info)
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionDetailsTTL is how long the details of a version are reused before they are gathered again
+// versionDetailsTTL 是版本详情在重新收集之前的复用时长
+const versionDetailsTTL = 5 * time.Minute
+
+// Links shown in the details drawer
+// 详情抽屉中显示的链接
+const (
+	nodeChangelogPageURL = "https://github.com/nodejs/node/blob/main/doc/changelogs/CHANGELOG_V%d.md#%s"
+	nodeDocsURL          = "https://nodejs.org/docs/v%s/api/"
+	nodeDistDirURL       = "https://nodejs.org/dist/v%s/"
+)
+
+// GlobalPackage is a package installed globally with npm in a version
+// GlobalPackage 表示某个版本中通过 npm 全局安装的包
+type GlobalPackage struct {
+	Name    string
+	Version string
+}
+
+// VersionLinks are the external pages about a version
+// VersionLinks 是与某个版本相关的外部页面
+type VersionLinks struct {
+	Changelog string
+	Docs      string
+	Download  string
+}
+
+// VersionDetails gathers everything the details drawer shows about one version in a single call
+// VersionDetails 在一次调用中汇总详情抽屉显示的某个版本的全部信息
+type VersionDetails struct {
+	Version        string
+	Dist           *NodeVersionInfo // 版本索引中的信息，未列出时为空 / index metadata, nil when not listed
+	Installed      bool
+	Current        bool
+	Path           string
+	Local          *VersionMetadata // 已安装时的本地信息 / local information when installed
+	GlobalPackages []GlobalPackage
+	ReleaseLine    *ReleaseLine // 所属主版本线及其阶段 / the major line and its phase
+	EndOfLife      bool
+	Summary        *ReleaseSummary // 已缓存时的更新摘要 / release summary when already cached
+	Links          VersionLinks
+	Warnings       []string
+	GatheredAt     time.Time
+}
+
+// versionDetailsCache keeps the gathered details by version
+// versionDetailsCache 按版本保存已收集的详情
+type versionDetailsCache struct {
+	mu      sync.Mutex
+	entries map[string]VersionDetails
+}
+
+// globalPackages lists the global packages of a version directory by name
+// globalPackages 按名称列出某个版本目录中的全局包
+func globalPackages(dir string) []GlobalPackage {
+	var packages []GlobalPackage
+	for spec := range globalPackageDirs(dir) {
+		pkg := GlobalPackage{Name: spec}
+		if i := strings.LastIndex(spec, "@"); i > 0 {
+			pkg.Name, pkg.Version = spec[:i], spec[i+1:]
+		}
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages
+}
+
+// cachedReleaseSummary returns the release summary of a version if it is cached, without downloading anything
+// cachedReleaseSummary 在已缓存时返回某个版本的更新摘要，不进行任何下载
+func (a *App) cachedReleaseSummary(version string) *ReleaseSummary {
+	s := &a.summaries
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(a.fs)
+	summary, ok := s.entries[version]
+	if !ok {
+		return nil
+	}
+	summary = localizeSummary(summary, a.formatter())
+	return &summary
+}
+
+// gatherVersionDetails collects the details of a version from the index, the disk, the release schedule and
+// the cached release summaries. Parts that fail are reported as warnings
+// gatherVersionDetails 从版本索引、磁盘、发布计划和已缓存的更新摘要中收集某个版本的详情，失败的部分作为警告返回
+func (a *App) gatherVersionDetails(version string) VersionDetails {
+	major := versionMajor(version)
+	details := VersionDetails{
+		Version: version,
+		Links: VersionLinks{
+			Changelog: fmt.Sprintf(nodeChangelogPageURL, major, version),
+			Docs:      fmt.Sprintf(nodeDocsURL, version),
+			Download:  fmt.Sprintf(nodeDistDirURL, version),
+		},
+		GatheredAt: a.clock.Now(),
+	}
+
+	if available, err := a.cachedAvailableVersions(); err == nil {
+		for _, v := range available {
+			if v.Version == version {
+				v := v
+				details.Dist = &v
+				break
+			}
+		}
+	} else {
+		details.Warnings = append(details.Warnings, err.Error())
+	}
+
+	if installed, err := a.GetInstalledNodeVersions(); err == nil {
+		for _, v := range installed {
+			if strings.TrimPrefix(v.Version, "v") == version {
+				details.Installed, details.Current = true, v.IsCurrent
+			}
+		}
+	} else {
+		details.Warnings = append(details.Warnings, err.Error())
+	}
+	if details.Installed {
+		if root := a.nvmRoot(); root != "" {
+			details.Path = versionDir(root, version)
+			details.GlobalPackages = globalPackages(details.Path)
+		}
+		if metadata, err := a.GetVersionMetadata(); err == nil {
+			for _, meta := range metadata {
+				if meta.Version == version {
+					meta := meta
+					details.Local = &meta
+				}
+			}
+		} else {
+			details.Warnings = append(details.Warnings, err.Error())
+		}
+	}
+
+	if schedule, err := a.GetReleaseSchedule(); err == nil {
+		for _, line := range schedule.Lines {
+			if line.Major == major {
+				line := line
+				details.ReleaseLine = &line
+				details.EndOfLife = line.Phase == PhaseEOL
+				break
+			}
+		}
+	} else {
+		details.Warnings = append(details.Warnings, err.Error())
+	}
+
+	details.Summary = a.cachedReleaseSummary(version)
+	return details
+}
+
+// GetVersionDetails returns the index metadata, local install, global packages, release phase, cached release
+// summary and links of a version for the details drawer, reusing details gathered in the last few minutes
+// GetVersionDetails 为详情抽屉返回某个版本的索引信息、本地安装、全局包、发布阶段、已缓存的更新摘要和链接，
+// 并复用最近几分钟内收集的详情
+func (a *App) GetVersionDetails(version string) (VersionDetails, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return VersionDetails{}, fmt.Errorf("version is required")
+	}
+
+	c := &a.versionDetails
+	c.mu.Lock()
+	cached, ok := c.entries[version]
+	c.mu.Unlock()
+	if ok && a.clock.Now().Sub(cached.GatheredAt) < versionDetailsTTL {
+		return cached, nil
+	}
+
+	details := a.gatherVersionDetails(version)
+	// 部分信息获取失败时不缓存，下次打开详情时重新收集
+	// Details with failed parts are not cached, so the next opening gathers them again
+	if len(details.Warnings) > 0 {
+		return details, nil
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]VersionDetails{}
+	}
+	c.entries[version] = details
+	c.mu.Unlock()
+	return details, nil
+}
+
+// invalidateVersionDetails drops the cached details of a version, e.g. after it was installed or removed
+// invalidateVersionDetails 删除某个版本的缓存详情，例如在安装或删除之后
+func (a *App) invalidateVersionDetails(version string) {
+	c := &a.versionDetails
+	c.mu.Lock()
+	delete(c.entries, strings.TrimPrefix(version, "v"))
+	c.mu.Unlock()
+}
+
+// clearVersionDetails drops all cached details, e.g. after a switch changed which version is current
+// clearVersionDetails 删除全部缓存详情，例如在切换改变了当前版本之后
+func (a *App) clearVersionDetails() {
+	c := &a.versionDetails
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
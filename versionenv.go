@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// compareSemver compares two x.y.z versions numerically
+// compareSemver 按数值比较两个 x.y.z 版本号
+func compareSemver(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < 3; i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionEnv returns an environment whose PATH resolves node and npm to the given installed version
+// versionEnv 返回一个环境变量集合，使 PATH 中的 node 和 npm 指向指定的已安装版本
+func (a *App) versionEnv(version string) ([]string, string, error) {
+	root := a.nvmRoot()
+	if root == "" {
+		return nil, "", fmt.Errorf("Unable to locate the nvm root directory")
+	}
+	dir := versionDir(root, version)
+	if _, err := os.Stat(filepath.Join(dir, "node.exe")); err != nil {
+		return nil, "", fmt.Errorf("Node.js %s is not installed", version)
+	}
+
+	env := []string{}
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(strings.ToUpper(kv), "PATH=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env, "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return env, dir, nil
+}
+
+// nodeVersionCommand describes a command for a tool from the given Node.js version's directory (e.g. npm.cmd)
+// nodeVersionCommand 描述运行指定 Node.js 版本目录下工具（如 npm.cmd）的命令
+func (a *App) nodeVersionCommand(version, workDir, tool string, args ...string) (CommandSpec, error) {
+	env, dir, err := a.versionEnv(version)
+	if err != nil {
+		return CommandSpec{}, err
+	}
+	return CommandSpec{Name: filepath.Join(dir, tool), Args: args, Dir: workDir, Env: env, Hidden: !a.debugMode}, nil
+}
+
+// runWithNodeVersion runs a tool from the given Node.js version's directory (e.g. npm.cmd) in workDir
+// without switching the global version
+// runWithNodeVersion 在 workDir 中运行指定 Node.js 版本目录下的工具（如 npm.cmd），不切换全局版本
+func (a *App) runWithNodeVersion(version, workDir, tool string, args ...string) ([]byte, error) {
+	spec, err := a.nodeVersionCommand(version, workDir, tool, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = a.executor.Run(context.Background(), spec, &stdout, &stderr)
+	output := decodeConsoleOutput(stdout.Bytes())
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Command failed: %s %v (Node.js %s, in %s)\nError: %v\nStderr: %s\n", tool, args, version, workDir, err, string(decodeConsoleOutput(stderr.Bytes()))))
+	}
+	return output, err
+}
+
+// runWithNodeVersionStreaming is runWithNodeVersion with combined output passed line by line to onLine
+// runWithNodeVersionStreaming 与 runWithNodeVersion 相同，但会将合并后的输出逐行传给 onLine
+func (a *App) runWithNodeVersionStreaming(onLine func(string), version, workDir, tool string, args ...string) ([]byte, error) {
+	spec, err := a.nodeVersionCommand(version, workDir, tool, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := a.runStreaming(a.baseContext(), spec, onLine)
+	if err != nil {
+		a.logToFile(fmt.Sprintf("Command failed: %s %v (Node.js %s, in %s)\nError: %v\nOutput: %s\n", tool, args, version, workDir, err, string(output)))
+	}
+	return output, err
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionMetadataFile is the cache file holding the metadata of installed versions
+// versionMetadataFile 是保存已安装版本元数据的缓存文件
+const versionMetadataFile = "version-metadata.json"
+
+// versionMetadataSchema is the layout of the cache file; raising it drops the cached entries so fields added since,
+// such as Corepack and Npx, are collected for every version
+// versionMetadataSchema 是缓存文件的格式版本；提高该值会丢弃已缓存的条目，使之后新增的字段（例如 Corepack 和 Npx）
+// 为每个版本重新收集
+const versionMetadataSchema = 2
+
+// VersionMetadata is the rarely changing information about one installed version
+// VersionMetadata 是一个已安装版本中很少变化的信息
+type VersionMetadata struct {
+	Version     string
+	NpmVersion  string
+	Arch        string
+	V8          string
+	OpenSSL     string
+	Corepack    bool
+	Npx         bool
+	Size        int64
+	SizeText    string
+	InstalledAt time.Time
+	RefreshedAt time.Time
+}
+
+// versionMetadataCache is the content of the cache file
+// versionMetadataCache 是缓存文件的内容
+type versionMetadataCache struct {
+	Schema  int                        `json:"schema"`
+	Entries map[string]VersionMetadata `json:"entries"`
+}
+
+// versionMetadataStore caches VersionMetadata by version, persisted as JSON in the cache directory
+// versionMetadataStore 按版本缓存 VersionMetadata，并以 JSON 形式保存在缓存目录中
+type versionMetadataStore struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]VersionMetadata
+}
+
+// bundledNpmVersion reads the npm version shipped in a version directory from npm's package.json
+// bundledNpmVersion 从 npm 的 package.json 读取版本目录中附带的 npm 版本
+func bundledNpmVersion(fsys FileSystem, dir string) string {
+	data, err := fsys.ReadFile(filepath.Join(dir, "node_modules", "npm", "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// collectVersionMetadata inspects an installed version on disk
+// collectVersionMetadata 从磁盘读取一个已安装版本的信息
+func (a *App) collectVersionMetadata(root, version string) VersionMetadata {
+	dir := versionDir(root, version)
+	v8, openssl := a.installedToolVersions(version)
+	return VersionMetadata{
+		Version:     version,
+		NpmVersion:  bundledNpmVersion(a.fs, dir),
+		Arch:        peArch(filepath.Join(dir, "node.exe")),
+		V8:          v8,
+		OpenSSL:     openssl,
+		Corepack:    bundledTool(dir, "corepack.cmd"),
+		Npx:         bundledTool(dir, "npx.cmd"),
+		Size:        dirSize(dir),
+		InstalledAt: fileCreationTime(dir),
+		RefreshedAt: a.clock.Now(),
+	}
+}
+
+// load reads the store from disk once, the caller holds s.mu. A file of an older schema, including the plain
+// map written before the schema existed, is discarded
+// load 从磁盘读取一次缓存，调用方需持有 s.mu。旧格式的文件（包括引入格式版本之前写入的普通映射）会被丢弃
+func (s *versionMetadataStore) load(fsys FileSystem) {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	s.entries = map[string]VersionMetadata{}
+	var cache versionMetadataCache
+	if data, err := fsys.ReadFile(cacheFilePath(versionMetadataFile)); err == nil && json.Unmarshal(data, &cache) == nil &&
+		cache.Schema == versionMetadataSchema && cache.Entries != nil {
+		s.entries = cache.Entries
+	}
+}
+
+// save writes the store to disk, the caller holds s.mu
+// save 将缓存写入磁盘，调用方需持有 s.mu
+func (s *versionMetadataStore) save(fsys FileSystem) error {
+	data, err := json.MarshalIndent(versionMetadataCache{Schema: versionMetadataSchema, Entries: s.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(cacheDir(), 0755); err != nil {
+		return err
+	}
+	return fsys.WriteFile(cacheFilePath(versionMetadataFile), data, 0644)
+}
+
+// invalidateVersionMetadata drops the cached metadata of a version so it is collected again on the next read
+// invalidateVersionMetadata 删除某个版本的缓存元数据，使其在下次读取时重新收集
+func (a *App) invalidateVersionMetadata(version string) {
+	version = strings.TrimPrefix(version, "v")
+	a.forgetNpmVersion(version)
+	a.invalidateVersionDetails(version)
+	s := &a.versionMeta
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(a.fs)
+	if _, ok := s.entries[version]; !ok {
+		return
+	}
+	delete(s.entries, version)
+	if err := s.save(a.fs); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving version metadata: %v", err))
+	}
+}
+
+// GetVersionMetadata returns the metadata of every installed version, collecting only the versions not cached yet
+// and dropping versions that are no longer installed
+// GetVersionMetadata 返回每个已安装版本的元数据，仅为尚未缓存的版本收集信息，并移除已不再安装的版本
+func (a *App) GetVersionMetadata() ([]VersionMetadata, error) {
+	installed, err := a.GetInstalledNodeVersions()
+	if err != nil {
+		return nil, err
+	}
+	root := a.nvmRoot()
+	if root == "" {
+		return nil, fmt.Errorf("nvm root not found")
+	}
+
+	s := &a.versionMeta
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load(a.fs)
+
+	changed := false
+	seen := map[string]bool{}
+	for _, v := range installed {
+		version := strings.TrimPrefix(v.Version, "v")
+		seen[version] = true
+		if _, ok := s.entries[version]; !ok {
+			s.entries[version] = a.collectVersionMetadata(root, version)
+			changed = true
+		}
+	}
+	for version := range s.entries {
+		if !seen[version] {
+			delete(s.entries, version)
+			changed = true
+		}
+	}
+	if changed {
+		if err := s.save(a.fs); err != nil {
+			a.logToFile(fmt.Sprintf("Error saving version metadata: %v", err))
+		}
+	}
+
+	f := a.formatter()
+	result := make([]VersionMetadata, 0, len(s.entries))
+	for _, meta := range s.entries {
+		meta.SizeText = f.size(meta.Size)
+		result = append(result, meta)
+	}
+	sort.Slice(result, func(i, j int) bool { return compareSemver(result[i].Version, result[j].Version) > 0 })
+	return result, nil
+}
+
+// RefreshVersionMetadata collects the metadata of a version again, or of all versions when version is empty
+// RefreshVersionMetadata 重新收集某个版本的元数据，version 为空时重新收集所有版本
+func (a *App) RefreshVersionMetadata(version string) ([]VersionMetadata, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	s := &a.versionMeta
+	s.mu.Lock()
+	s.load(a.fs)
+	if version == "" {
+		s.entries = map[string]VersionMetadata{}
+		a.npmVersions.mu.Lock()
+		a.npmVersions.entries = nil
+		a.npmVersions.mu.Unlock()
+	} else {
+		delete(s.entries, version)
+		a.forgetNpmVersion(version)
+	}
+	s.mu.Unlock()
+	return a.GetVersionMetadata()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVersionMetadataStoreDropsOlderSchema(t *testing.T) {
+	files := &mockFS{}
+	legacy, _ := json.Marshal(map[string]VersionMetadata{"20.11.0": {Version: "20.11.0", NpmVersion: "10.2.4"}})
+	files.WriteFile(cacheFilePath(versionMetadataFile), legacy, 0644)
+
+	var s versionMetadataStore
+	s.load(files)
+	if len(s.entries) != 0 {
+		t.Fatalf("entries of the legacy file were kept: %+v", s.entries)
+	}
+
+	s.entries["20.11.0"] = VersionMetadata{Version: "20.11.0", Corepack: true, Npx: true}
+	if err := s.save(files); err != nil {
+		t.Fatal(err)
+	}
+	var reloaded versionMetadataStore
+	reloaded.load(files)
+	if meta, ok := reloaded.entries["20.11.0"]; !ok || !meta.Corepack || !meta.Npx {
+		t.Errorf("saved entries were not read back: %+v", reloaded.entries)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileCreationTime returns when path was created, falling back to its modification time
+// fileCreationTime 返回 path 的创建时间，无法获取时回退为修改时间
+func fileCreationTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.CreationTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
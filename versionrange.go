@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// availableVersionsTTL is how long the available version list is reused between range queries
+// availableVersionsTTL 是范围查询之间复用可用版本列表的时长
+const availableVersionsTTL = 5 * time.Minute
+
+// VersionFilter narrows the available version list; empty fields match everything
+// VersionFilter 用于筛选可用版本列表，空字段匹配全部
+type VersionFilter struct {
+	Query  string // 版本号包含的文本，例如 "18." / Text the version contains, e.g. "18."
+	Status string // "Installed" 或 "Not Installed" / "Installed" or "Not Installed"
+	Source string // 版本信息来源 / Source the version was listed by
+}
+
+// availableVersionsCache keeps the last available version list for range queries
+// availableVersionsCache 保存最近一次获取的可用版本列表，供范围查询使用
+type availableVersionsCache struct {
+	mu       sync.Mutex
+	versions []NodeVersionInfo
+	fetched  time.Time
+}
+
+// matches reports whether a version passes the filter
+// matches 判断版本是否符合筛选条件
+func (f VersionFilter) matches(v NodeVersionInfo) bool {
+	if q := strings.TrimPrefix(strings.TrimSpace(f.Query), "v"); q != "" && !strings.Contains(v.Version, q) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(f.Status, v.Status) {
+		return false
+	}
+	if f.Source != "" && !strings.EqualFold(f.Source, v.Source) {
+		return false
+	}
+	return true
+}
+
+// cachedAvailableVersions returns the available version list, fetching it again once the cache has expired
+// cachedAvailableVersions 返回可用版本列表，缓存过期后重新获取
+func (a *App) cachedAvailableVersions() ([]NodeVersionInfo, error) {
+	a.availableCache.mu.Lock()
+	defer a.availableCache.mu.Unlock()
+	if a.availableCache.versions != nil && a.clock.Now().Sub(a.availableCache.fetched) < availableVersionsTTL {
+		return a.availableCache.versions, nil
+	}
+	versions, err := a.GetAvailableNodeVersions()
+	if err != nil {
+		return nil, err
+	}
+	a.availableCache.versions = versions
+	a.availableCache.fetched = a.clock.Now()
+	return versions, nil
+}
+
+// invalidateAvailableVersions drops the cached list so install status is re-read on the next query
+// invalidateAvailableVersions 清除缓存的列表，使下次查询重新读取安装状态
+func (a *App) invalidateAvailableVersions() {
+	a.availableCache.mu.Lock()
+	a.availableCache.versions = nil
+	a.availableCache.mu.Unlock()
+}
+
+// filterVersions returns the versions matching the filter
+// filterVersions 返回符合筛选条件的版本
+func filterVersions(versions []NodeVersionInfo, filter VersionFilter) []NodeVersionInfo {
+	if filter == (VersionFilter{}) {
+		return versions
+	}
+	var matched []NodeVersionInfo
+	for _, v := range versions {
+		if filter.matches(v) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+// GetAvailableVersionsCount returns how many available versions match the filter, for sizing a virtualized list
+// GetAvailableVersionsCount 返回符合筛选条件的可用版本数量，用于确定虚拟列表的大小
+func (a *App) GetAvailableVersionsCount(filter VersionFilter) (int, error) {
+	versions, err := a.cachedAvailableVersions()
+	if err != nil {
+		return 0, err
+	}
+	return len(filterVersions(versions, filter)), nil
+}
+
+// GetAvailableVersionsRange returns at most limit matching versions starting at offset, so the frontend
+// only transfers the rows it renders
+// GetAvailableVersionsRange 返回从 offset 开始最多 limit 个符合条件的版本，前端只需传输正在显示的行
+func (a *App) GetAvailableVersionsRange(offset, limit int, filter VersionFilter) ([]NodeVersionInfo, error) {
+	versions, err := a.cachedAvailableVersions()
+	if err != nil {
+		return nil, err
+	}
+	matched := filterVersions(versions, filter)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matched) || limit <= 0 {
+		return []NodeVersionInfo{}, nil
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return append([]NodeVersionInfo{}, matched[offset:end]...), nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxSearchResults limits how many matches SearchVersions returns
+// maxSearchResults 限制 SearchVersions 返回的匹配数量
+const maxSearchResults = 100
+
+// Fields a search term can match
+// 搜索词可以匹配的字段
+const (
+	MatchVersion  = "version"
+	MatchCodename = "codename"
+	MatchNpm      = "npm"
+	MatchLabel    = "label"
+	MatchNote     = "note"
+	MatchStatus   = "status"
+)
+
+// VersionSearchResult is one version matching a search, with its relevance and the fields that matched
+// VersionSearchResult 是一个匹配搜索的版本，包含其相关度及匹配的字段
+type VersionSearchResult struct {
+	Version NodeVersionInfo
+	Score   int
+	Matches []string
+}
+
+// isVersionTerm reports whether a search term looks like a version such as "18", "v18.17" or "20.1.0"
+// isVersionTerm 判断搜索词是否类似版本号，例如 "18"、"v18.17" 或 "20.1.0"
+func isVersionTerm(term string) bool {
+	term = strings.TrimPrefix(term, "v")
+	return term != "" && strings.IndexFunc(term, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' }) < 0
+}
+
+// matchVersionNumber scores a version-like term against a version: exact, whole-component prefix or substring
+// matchVersionNumber 对类似版本号的搜索词与版本进行评分：完全相同、按分量的前缀或子串
+func matchVersionNumber(term, version string) int {
+	term = strings.TrimSuffix(strings.TrimPrefix(term, "v"), ".")
+	version = strings.TrimPrefix(version, "v")
+	switch {
+	case term == "" || version == "":
+		return 0
+	case version == term:
+		return 100
+	case strings.HasPrefix(version, term+"."):
+		return 80
+	case strings.HasPrefix(version, term):
+		return 50
+	case strings.Contains(version, term):
+		return 30
+	}
+	return 0
+}
+
+// isSubsequence reports whether the characters of term appear in s in order, e.g. "hdrg" in "hydrogen"
+// isSubsequence 判断 term 的字符是否按顺序出现在 s 中，例如 "hdrg" 出现在 "hydrogen" 中
+func isSubsequence(term, s string) bool {
+	want := []rune(term)
+	i := 0
+	for _, r := range s {
+		if i < len(want) && r == want[i] {
+			i++
+		}
+	}
+	return i == len(want)
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion, deletion or substitution
+// withinOneEdit 判断 a 与 b 是否最多相差一次插入、删除或替换
+func withinOneEdit(a, b []rune) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			if len(a) == len(b) {
+				return string(a[i+1:]) == string(b[i+1:])
+			}
+			return string(a[i:]) == string(b[i+1:])
+		}
+	}
+	return true
+}
+
+// typoPrefix reports whether word starts with term apart from one typo, e.g. "hidro" for "hydrogen"
+// typoPrefix 判断 word 是否在一处拼写错误之外以 term 开头，例如 "hidro" 对应 "hydrogen"
+func typoPrefix(term, word string) bool {
+	t, w := []rune(term), []rune(word)
+	for _, n := range []int{len(t) - 1, len(t), len(t) + 1} {
+		if n > 0 && n <= len(w) && withinOneEdit(t, w[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchText scores a term against free text: prefix of a word, substring, a typo in a word's prefix or the
+// letters in order
+// matchText 对搜索词与文本进行评分：单词前缀、子串、单词前缀中的一处拼写错误或按顺序出现的字母
+func matchText(term, text string) int {
+	text = strings.ToLower(text)
+	if term == "" || text == "" {
+		return 0
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return 70
+		}
+	}
+	if strings.Contains(text, term) {
+		return 50
+	}
+	// 较短的词只允许精确匹配，避免误报
+	// Short terms only match exactly, to avoid false hits
+	if len([]rune(term)) >= 4 {
+		for _, word := range words {
+			if typoPrefix(term, word) {
+				return 30
+			}
+		}
+	}
+	if len([]rune(term)) >= 3 && isSubsequence(term, text) {
+		return 15
+	}
+	return 0
+}
+
+// scoreTerm returns the best score of one search term against a version and the field it matched
+// scoreTerm 返回一个搜索词与版本的最佳得分及匹配的字段
+func scoreTerm(term string, v NodeVersionInfo) (int, string) {
+	best, field := 0, ""
+	consider := func(score int, name string) {
+		if score > best {
+			best, field = score, name
+		}
+	}
+
+	switch term {
+	case "lts":
+		if v.LTS != "" {
+			consider(60, MatchCodename)
+		}
+	case "installed":
+		if v.Status == "Installed" {
+			consider(60, MatchStatus)
+		}
+	}
+
+	if isVersionTerm(term) {
+		consider(matchVersionNumber(term, v.Version), MatchVersion)
+		// npm 版本的得分低于 Node.js 版本本身
+		// npm versions score lower than the Node.js version itself
+		consider(matchVersionNumber(term, v.NpmVersion)/2, MatchNpm)
+	}
+	if rest := strings.TrimLeft(strings.TrimPrefix(term, "npm"), "@"); rest != term && isVersionTerm(rest) {
+		consider(matchVersionNumber(rest, v.NpmVersion), MatchNpm)
+	}
+
+	consider(matchText(term, v.LTS), MatchCodename)
+	for _, label := range v.Labels {
+		consider(matchText(term, label.Text)*4/5, MatchLabel)
+		consider(matchText(term, label.ID)*4/5, MatchLabel)
+	}
+	consider(matchText(term, v.Note)/2, MatchNote)
+	consider(matchText(term, v.StatusText)/2, MatchStatus)
+	return best, field
+}
+
+// SearchVersions returns the available versions matching every term of query, most relevant first. Terms match
+// the version ("18", "v20.11"), the LTS codename with typos tolerated ("hydro"), the npm version ("npm@10"),
+// labels, notes and the words "lts" and "installed", so "18 lts" finds the 18.x LTS releases
+// SearchVersions 返回匹配 query 中所有搜索词的可用版本，相关度高的在前。搜索词可匹配版本号（"18"、"v20.11"）、
+// 可容忍拼写错误的 LTS 代号（"hydro"）、npm 版本（"npm@10"）、标签、备注以及 "lts" 和 "installed"，
+// 因此 "18 lts" 会找到 18.x 的 LTS 版本
+func (a *App) SearchVersions(query string) ([]VersionSearchResult, error) {
+	versions, err := a.cachedAvailableVersions()
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return []VersionSearchResult{}, nil
+	}
+
+	results := []VersionSearchResult{}
+	for _, v := range versions {
+		result := VersionSearchResult{Version: v}
+		for _, term := range terms {
+			score, field := scoreTerm(term, v)
+			if score == 0 {
+				result.Score = 0
+				break
+			}
+			result.Score += score
+			result.Matches = append(result.Matches, field)
+		}
+		if result.Score > 0 {
+			results = append(results, result)
+		}
+	}
+
+	// 同分时保持列表原有顺序，即用户选择的排序方式
+	// Ties keep the listing order, which follows the user's sort preference
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	return results, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version list orders
+// 版本列表排序方向
+const (
+	SortDescending = "desc"
+	SortAscending  = "asc"
+)
+
+// VersionSort is the user's preferred order of the version lists, applied by the backend so every view agrees
+// VersionSort 是用户偏好的版本列表排序方式，由后端统一应用，使各个视图保持一致
+type VersionSort struct {
+	Order        string `json:"order"`        // desc 或 asc，为空时为 desc / desc or asc, empty means desc
+	GroupByMajor bool   `json:"groupByMajor"` // 按主版本分组并填写分组标题 / group by major and fill in the group titles
+	LTSFirst     bool   `json:"ltsFirst"`     // LTS 版本排在前面 / LTS versions come first
+}
+
+// versionGroupTitle returns the localized title of a major group, such as "Node.js 20 (LTS Iron)"
+// versionGroupTitle 返回主版本分组的本地化标题，例如 "Node.js 20（LTS Iron）"
+func versionGroupTitle(major int, lts string, f formatter) string {
+	if lts == "" {
+		return fmt.Sprintf("Node.js %d", major)
+	}
+	if f.locale == LocaleZhCN {
+		return fmt.Sprintf("Node.js %d（LTS %s）", major, lts)
+	}
+	return fmt.Sprintf("Node.js %d (LTS %s)", major, lts)
+}
+
+// sortVersions orders a listing by the preference. Favorites are moved to the top afterwards by withFavorites
+// sortVersions 按偏好对版本列表排序，之后由 withFavorites 将收藏的版本移到最前
+func sortVersions(versions []NodeVersionInfo, pref VersionSort, f formatter) []NodeVersionInfo {
+	ascending := pref.Order == SortAscending
+	// 同一主版本线中只要有一个版本是 LTS，整条线都视为 LTS 线
+	// A major line counts as LTS once any of its versions is
+	ltsLines := map[int]string{}
+	for _, v := range versions {
+		if v.LTS != "" {
+			ltsLines[versionMajor(v.Version)] = v.LTS
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if pref.LTSFirst {
+			ltsA, ltsB := ltsLines[versionMajor(a.Version)] != "", ltsLines[versionMajor(b.Version)] != ""
+			if ltsA != ltsB {
+				return ltsA
+			}
+		}
+		// 按语义化版本比较，rc 等预发布版本排在正式版本之前
+		// Compare by semver, pre-releases such as rc sort before their release
+		c := compareAppVersion(a.Version, b.Version)
+		if ascending {
+			return c < 0
+		}
+		return c > 0
+	})
+
+	for i := range versions {
+		versions[i].Group = ""
+		if pref.GroupByMajor {
+			major := versionMajor(versions[i].Version)
+			versions[i].Group = versionGroupTitle(major, ltsLines[major], f)
+		}
+	}
+	return versions
+}
+
+// sortInstalled orders the installed versions by semver in the preferred direction, with the LTS lines
+// first when preferred; ltsLines maps the majors of the LTS lines to their codenames
+// sortInstalled 按偏好的方向以语义化版本顺序排列已安装版本，偏好时 LTS 线排在前面；ltsLines 为 LTS 主版本到其代号的映射
+func sortInstalled(versions []NodeVersion, pref VersionSort, ltsLines map[int]string) []NodeVersion {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if pref.LTSFirst {
+			ltsA, ltsB := ltsLines[versionMajor(versions[i].Version)] != "", ltsLines[versionMajor(versions[j].Version)] != ""
+			if ltsA != ltsB {
+				return ltsA
+			}
+		}
+		c := compareAppVersion(versions[i].Version, versions[j].Version)
+		if pref.Order == SortAscending {
+			return c < 0
+		}
+		return c > 0
+	})
+	return versions
+}
+
+// installedLTSLines returns the LTS lines of the cached dist index for sorting the installed versions, or nil
+// when the preference does not need them or no index is cached yet. It never fetches the index, so listing the
+// installed versions does not wait for the network
+// installedLTSLines 返回缓存的发布索引中的 LTS 线，用于排列已安装版本；偏好不需要或尚无缓存的索引时返回 nil。
+// 它从不获取索引，因此列出已安装版本不会等待网络
+func (a *App) installedLTSLines(pref VersionSort) map[int]string {
+	if !pref.LTSFirst {
+		return nil
+	}
+	// 正在获取索引时锁被占用，此时同样不分组
+	// The lock is held while the index is being fetched, which also means no grouping
+	if !a.indexCache.mu.TryLock() {
+		return nil
+	}
+	entries := a.indexCache.entries
+	a.indexCache.mu.Unlock()
+	if entries == nil {
+		return nil
+	}
+	lines := map[int]string{}
+	for _, e := range entries {
+		if e.LTS != "" {
+			lines[versionMajor(e.Version)] = e.LTS
+		}
+	}
+	return lines
+}
+
+// withVersionSort applies the saved sort preference to a listing
+// withVersionSort 对版本列表应用已保存的排序偏好
+func (a *App) withVersionSort(versions []NodeVersionInfo) []NodeVersionInfo {
+	return sortVersions(versions, a.currentSettings().VersionSort, a.formatter())
+}
+
+// GetVersionSort returns the saved sort preference
+// GetVersionSort 返回已保存的排序偏好
+func (a *App) GetVersionSort() VersionSort {
+	return a.currentSettings().VersionSort
+}
+
+// SetVersionSort saves the sort preference of the version lists
+// SetVersionSort 保存版本列表的排序偏好
+func (a *App) SetVersionSort(pref VersionSort) error {
+	pref.Order = strings.ToLower(strings.TrimSpace(pref.Order))
+	switch pref.Order {
+	case "":
+		pref.Order = SortDescending
+	case SortDescending, SortAscending:
+	default:
+		return fmt.Errorf("Unknown sort order: %s", pref.Order)
+	}
+	settings := a.currentSettings()
+	settings.VersionSort = pref
+	if err := a.SetSettings(settings); err != nil {
+		return err
+	}
+	// 分页使用的缓存按旧顺序保存，需要重新生成
+	// The cache behind the paged listing holds the old order and has to be rebuilt
+	a.invalidateAvailableVersions()
+	go a.refreshTrayVersions()
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortInstalledLTSFirst(t *testing.T) {
+	installed := func() []NodeVersion {
+		return []NodeVersion{{Version: "18.19.0"}, {Version: "21.6.1"}, {Version: "20.11.0"}, {Version: "19.9.0"}}
+	}
+	lts := map[int]string{18: "Hydrogen", 20: "Iron"}
+	tests := []struct {
+		name string
+		pref VersionSort
+		want []string
+	}{
+		{"descending", VersionSort{}, []string{"21.6.1", "20.11.0", "19.9.0", "18.19.0"}},
+		{"LTS first", VersionSort{LTSFirst: true}, []string{"20.11.0", "18.19.0", "21.6.1", "19.9.0"}},
+		{"LTS first ascending", VersionSort{Order: SortAscending, LTSFirst: true}, []string{"18.19.0", "20.11.0", "19.9.0", "21.6.1"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, v := range sortInstalled(installed(), tt.pref, lts) {
+			got = append(got, v.Version)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
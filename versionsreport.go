@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Report formats accepted by ExportVersionsReport
+// ExportVersionsReport 支持的报告格式
+const (
+	ReportCSV      = "csv"
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+)
+
+// versionsReportHeader are the columns of the installed versions report
+// versionsReportHeader 是已安装版本报告的列
+var versionsReportHeader = []string{"Version", "Current", "npm", "Size", "Release phase", "End of life", "Last used"}
+
+// versionsReportRows collects one row per installed version, newest first
+// versionsReportRows 为每个已安装版本收集一行数据，最新版本在前
+func (a *App) versionsReportRows() ([][]string, error) {
+	metadata, err := a.GetVersionMetadata()
+	if err != nil {
+		return nil, err
+	}
+	current, _ := a.currentNodeVersion()
+	current = strings.TrimPrefix(current, "v")
+
+	// 发布计划不可用时阶段列留空
+	// The phase columns stay empty when the release schedule is unavailable
+	lines := map[int]ReleaseLine{}
+	if schedule, err := a.GetReleaseSchedule(); err == nil {
+		for _, line := range schedule.Lines {
+			lines[line.Major] = line
+		}
+	}
+
+	f := a.formatter()
+	lastUsed := a.currentSettings().LastUsed
+	rows := make([][]string, 0, len(metadata))
+	for _, meta := range metadata {
+		major, _ := strconv.Atoi(strings.Split(meta.Version, ".")[0])
+		line := lines[major]
+		used := ""
+		if t, ok := lastUsed[meta.Version]; ok {
+			used = f.date(t)
+		}
+		isCurrent := ""
+		if meta.Version == current {
+			isCurrent = "yes"
+		}
+		rows = append(rows, []string{meta.Version, isCurrent, meta.NpmVersion, meta.SizeText, line.Phase, line.End, used})
+	}
+	return rows, nil
+}
+
+// ExportVersionsReport renders the installed versions with their size, npm version, end-of-life status and
+// last use as CSV, Markdown or HTML, for team wikis or IT inventories
+// ExportVersionsReport 将已安装版本及其大小、npm 版本、生命周期状态和最近使用时间输出为 CSV、Markdown 或 HTML，
+// 供团队 Wiki 或 IT 资产清点使用
+func (a *App) ExportVersionsReport(format string) (string, error) {
+	rows, err := a.versionsReportRows()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	switch strings.ToLower(format) {
+	case ReportCSV:
+		w := csv.NewWriter(&b)
+		w.Write(versionsReportHeader)
+		w.WriteAll(rows)
+		if err := w.Error(); err != nil {
+			return "", err
+		}
+	case ReportMarkdown, "md", "":
+		fmt.Fprintf(&b, "# Node.js versions (%s)\n\n", a.clock.Now().Format("2006-01-02"))
+		b.WriteString("| " + strings.Join(versionsReportHeader, " | ") + " |\n")
+		b.WriteString(strings.Repeat("| --- ", len(versionsReportHeader)) + "|\n")
+		for _, row := range rows {
+			b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	case ReportHTML:
+		b.WriteString("<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>Node.js versions</title></head>\n<body>\n")
+		fmt.Fprintf(&b, "<h1>Node.js versions (%s)</h1>\n<table>\n<tr>", a.clock.Now().Format("2006-01-02"))
+		for _, h := range versionsReportHeader {
+			fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+		}
+		b.WriteString("</tr>\n")
+		for _, row := range rows {
+			b.WriteString("<tr>")
+			for _, cell := range row {
+				fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n</body>\n</html>\n")
+	default:
+		return "", fmt.Errorf("Unsupported report format: %s", format)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+)
+
+// Webhook kinds supported by the notifier
+// 通知器支持的 Webhook 类型
+const (
+	WebhookKindGeneric = "generic"
+	WebhookKindSlack   = "slack"
+	WebhookKindTeams   = "teams"
+)
+
+// Webhook event names
+// Webhook 事件名称
+const (
+	WebhookEventInstall = "install"
+	WebhookEventSwitch  = "switch"
+)
+
+const (
+	webhookMaxAttempts = 5
+	webhookQueueSize   = 100
+)
+
+// WebhookConfig describes one outbound webhook target
+// WebhookConfig 描述一个外发 Webhook 目标
+type WebhookConfig struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Kind     string   `json:"kind"`     // generic, slack 或 teams
+	Events   []string `json:"events"`   // 为空表示订阅所有事件
+	Template string   `json:"template"` // 可选的 text/template 请求体模板
+	Enabled  bool     `json:"enabled"`
+}
+
+// WebhookEvent is the data passed to payload templates
+// WebhookEvent 是传递给请求体模板的数据
+type WebhookEvent struct {
+	Event    string    `json:"event"`
+	Version  string    `json:"version"`
+	Success  bool      `json:"success"`
+	Message  string    `json:"message"`
+	Hostname string    `json:"hostname"`
+	Time     time.Time `json:"time"`
+}
+
+// webhookDelivery is a pending delivery in the retry queue
+// webhookDelivery 是重试队列中的一个待发送任务
+type webhookDelivery struct {
+	hook    WebhookConfig
+	body    []byte
+	attempt int
+}
+
+// subscribes reports whether the webhook wants the given event
+// subscribes 判断该 Webhook 是否订阅了指定事件
+func (w WebhookConfig) subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// renderWebhookPayload builds the request body for a webhook and event
+// renderWebhookPayload 为指定 Webhook 和事件生成请求体
+func renderWebhookPayload(hook WebhookConfig, event WebhookEvent) ([]byte, error) {
+	if hook.Template != "" {
+		tmpl, err := template.New(hook.Name).Parse(hook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing webhook template: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, event); err != nil {
+			return nil, fmt.Errorf("Error executing webhook template: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	status := "succeeded"
+	if !event.Success {
+		status = "failed"
+	}
+	text := fmt.Sprintf("[%s] Node.js %s %s %s: %s", event.Hostname, event.Version, event.Event, status, event.Message)
+
+	switch hook.Kind {
+	case WebhookKindSlack, WebhookKindTeams:
+		return json.Marshal(map[string]string{"text": text})
+	default:
+		return json.Marshal(event)
+	}
+}
+
+// notifyWebhooks queues a delivery for every enabled webhook subscribed to the event and publishes it to the event stream
+// notifyWebhooks 为所有订阅了该事件的已启用 Webhook 加入发送队列，并发布到事件流
+func (a *App) notifyWebhooks(event, version string, success bool, message string) {
+	hostname, _ := os.Hostname()
+	data := WebhookEvent{
+		Event:    event,
+		Version:  version,
+		Success:  success,
+		Message:  message,
+		Hostname: hostname,
+		Time:     a.clock.Now(),
+	}
+
+	// 同时推送给本地接口的事件流订阅者
+	// Also push the event to the local API event stream subscribers
+	a.eventHub.publish(data)
+	if a.safeMode {
+		return
+	}
+
+	for _, hook := range a.currentSettings().Webhooks {
+		if !hook.Enabled || hook.URL == "" || !hook.subscribes(event) {
+			continue
+		}
+		body, err := renderWebhookPayload(hook, data)
+		if err != nil {
+			a.logToFile(fmt.Sprintf("Webhook %s skipped: %v", hook.Name, err))
+			continue
+		}
+		a.enqueueWebhook(webhookDelivery{hook: hook, body: body})
+	}
+}
+
+// enqueueWebhook adds a delivery to the queue without blocking the caller
+// enqueueWebhook 将发送任务加入队列，不阻塞调用方
+func (a *App) enqueueWebhook(d webhookDelivery) {
+	select {
+	case a.webhookQueue <- d:
+	default:
+		a.logToFile(fmt.Sprintf("Webhook queue full, dropping delivery to %s", d.hook.Name))
+	}
+}
+
+// runWebhookWorker delivers queued webhooks and reschedules failed ones with backoff
+// runWebhookWorker 发送队列中的 Webhook，失败时按退避策略重新排队
+func (a *App) runWebhookWorker(ctx context.Context) {
+	client := a.client(10 * time.Second)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-a.webhookQueue:
+			err := deliverWebhook(ctx, client, d)
+			if err == nil {
+				a.logToFile(fmt.Sprintf("Webhook %s delivered", d.hook.Name))
+				continue
+			}
+
+			d.attempt++
+			if d.attempt >= webhookMaxAttempts {
+				a.logToFile(fmt.Sprintf("Webhook %s failed after %d attempts: %v", d.hook.Name, d.attempt, err))
+				continue
+			}
+
+			// Exponential backoff: 2s, 4s, 8s, 16s
+			// 指数退避：2 秒、4 秒、8 秒、16 秒
+			delay := time.Duration(1<<d.attempt) * time.Second
+			a.logToFile(fmt.Sprintf("Webhook %s failed (attempt %d), retrying in %s: %v", d.hook.Name, d.attempt, delay, err))
+			time.AfterFunc(delay, func() {
+				if ctx.Err() == nil {
+					a.enqueueWebhook(d)
+				}
+			})
+		}
+	}
+}
+
+// deliverWebhook performs a single HTTP POST of the payload
+// deliverWebhook 执行一次请求体的 HTTP POST 发送
+func deliverWebhook(ctx context.Context, client *http.Client, d webhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
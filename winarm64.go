@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// firstWinARM64Version is the first release with an official win-arm64 build, used when the dist index is unavailable
+// firstWinARM64Version 是第一个提供官方 win-arm64 构建的版本，在无法获取发布索引时使用
+const firstWinARM64Version = "19.9.0"
+
+// winARM64File is the dist index file tag of the win-arm64 zip archive
+// winARM64File 是发布索引中 win-arm64 压缩包的文件标记
+const winARM64File = "win-arm64-zip"
+
+// nvmArchArg maps an Arch constant to the architecture argument of nvm install
+// nvmArchArg 将架构常量转换为 nvm install 的架构参数
+func nvmArchArg(arch string) string {
+	switch arch {
+	case ArchARM64:
+		return "arm64"
+	case ArchX86:
+		return "32"
+	default:
+		return "64"
+	}
+}
+
+// cachedIndexEntry looks a version up in the last fetched dist index without fetching it
+// cachedIndexEntry 在最近获取的发布索引中查找版本，不会重新获取
+func (a *App) cachedIndexEntry(version string) (indexEntry, bool) {
+	a.indexCache.mu.Lock()
+	defer a.indexCache.mu.Unlock()
+	for _, entry := range a.indexCache.entries {
+		if entry.Version == version {
+			return entry, true
+		}
+	}
+	return indexEntry{}, false
+}
+
+// installArch picks the architecture to install on the host. ARM64 hosts get the arm64 build when one exists
+// and otherwise the x64 build, which Windows runs under emulation, with a warning. Other hosts leave
+// the choice to nvm, signalled by an empty arch
+// installArch 选择在本机上安装的架构。ARM64 主机在有 arm64 构建时使用该构建，否则使用由 Windows 模拟运行的
+// x64 构建并给出警告。其他主机返回空架构，由 nvm 自行选择
+func (a *App) installArch(version string) (string, string) {
+	if hostArch() != ArchARM64 {
+		return "", ""
+	}
+	version = strings.TrimPrefix(version, "v")
+	available := compareSemver(version, firstWinARM64Version) >= 0
+	if entry, ok := a.cachedIndexEntry(version); ok {
+		available = entry.ARM64
+	}
+	if available {
+		return ArchARM64, ""
+	}
+	return ArchX64, fmt.Sprintf("Node.js %s 没有 ARM64 版本，将安装 x64 版本并通过模拟运行 / Node.js %s has no ARM64 build, the x64 build is installed and runs under emulation",
+		version, version)
+}
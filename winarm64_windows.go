@@ -0,0 +1,27 @@
+package main
+
+import (
+	"debug/pe"
+
+	"golang.org/x/sys/windows"
+)
+
+// nativeMachineArch asks Windows for the native machine, which x64 emulation on ARM64 cannot hide;
+// it returns ArchUnknown on systems older than Windows 10 1709
+// nativeMachineArch 向 Windows 查询本机架构，ARM64 上的 x64 模拟无法掩盖该信息；
+// 早于 Windows 10 1709 的系统返回 ArchUnknown
+func nativeMachineArch() string {
+	var processMachine, nativeMachine uint16
+	if err := windows.IsWow64Process2(windows.CurrentProcess(), &processMachine, &nativeMachine); err != nil {
+		return ArchUnknown
+	}
+	switch nativeMachine {
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return ArchARM64
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return ArchX64
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return ArchX86
+	}
+	return ArchUnknown
+}
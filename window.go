@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WindowGeometry is the saved position and size of the main window in screen pixels
+// WindowGeometry 表示以屏幕像素保存的主窗口位置和大小
+type WindowGeometry struct {
+	X         int  `json:"x"`
+	Y         int  `json:"y"`
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	Maximised bool `json:"maximised"`
+}
+
+// saveWindowGeometry remembers the window position and size for the next start
+// saveWindowGeometry 保存窗口位置和大小，供下次启动时使用
+func (a *App) saveWindowGeometry() {
+	geometry, err := getWindowGeometry()
+	if err != nil || geometry.Width <= 0 || geometry.Height <= 0 {
+		return
+	}
+	settings := a.currentSettings()
+	// 最大化时保留上次的普通窗口大小
+	// Keep the previous normal size while maximised
+	if geometry.Maximised && settings.Window != nil {
+		previous := *settings.Window
+		previous.Maximised = true
+		geometry = previous
+	}
+	if settings.Window != nil && *settings.Window == geometry {
+		return
+	}
+	settings.Window = &geometry
+	if err := a.SetSettings(settings); err != nil {
+		a.logToFile(fmt.Sprintf("Error saving window geometry: %v", err))
+	}
+}
+
+// restoreWindowGeometry moves the window back to its saved position, validated against the current monitors
+// restoreWindowGeometry 将窗口恢复到保存的位置，并根据当前显示器布局进行校验
+func (a *App) restoreWindowGeometry() {
+	geometry := a.currentSettings().Window
+	if geometry == nil || geometry.Width <= 0 || geometry.Height <= 0 {
+		return
+	}
+
+	// 启动时窗口可能尚未创建，稍作重试
+	// The window may not exist yet during startup, retry briefly
+	for attempt := 0; attempt < 10; attempt++ {
+		if err := setWindowGeometry(*geometry); err == nil {
+			if geometry.Maximised {
+				runtime.WindowMaximise(a.ctx)
+			}
+			return
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	a.logToFile("Unable to restore window geometry: main window not found")
+}
+
+// MoveToActiveMonitor brings the window onto the monitor under the mouse cursor if it is not visible there
+// MoveToActiveMonitor 当窗口不在鼠标所在的显示器上可见时，将其移动到该显示器
+func (a *App) MoveToActiveMonitor() error {
+	if err := moveWindowToCursorMonitor(); err != nil {
+		a.logToFile(fmt.Sprintf("Error moving window to the active monitor: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+var (
+	procGetWindowRect   = moduser32.NewProc("GetWindowRect")
+	procSetWindowPos    = moduser32.NewProc("SetWindowPos")
+	procIsZoomed        = moduser32.NewProc("IsZoomed")
+	procMonitorFromRect = moduser32.NewProc("MonitorFromRect")
+	procGetMonitorInfoW = moduser32.NewProc("GetMonitorInfoW")
+	procGetCursorPos    = moduser32.NewProc("GetCursorPos")
+	procReleaseCapture  = moduser32.NewProc("ReleaseCapture")
+	procPostMessageW    = moduser32.NewProc("PostMessageW")
+)
+
+const (
+	monitorDefaultToNearest = 0x2
+
+	swpNoZOrder   = 0x4
+	swpNoActivate = 0x10
+
+	wmNCLButtonDown = 0xA1
+	htCaption       = 0x2
+)
+
+// rect is the Win32 RECT structure
+// rect 对应 Win32 的 RECT 结构
+type rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// monitorInfo is the Win32 MONITORINFO structure
+// monitorInfo 对应 Win32 的 MONITORINFO 结构
+type monitorInfo struct {
+	CbSize  uint32
+	Monitor rect
+	Work    rect
+	Flags   uint32
+}
+
+// workAreaNear returns the work area (without the taskbar) of the monitor nearest to r
+// workAreaNear 返回距离 r 最近的显示器的工作区（不含任务栏）
+func workAreaNear(r rect) (rect, error) {
+	monitor, _, _ := procMonitorFromRect.Call(uintptr(unsafe.Pointer(&r)), monitorDefaultToNearest)
+	if monitor == 0 {
+		return rect{}, fmt.Errorf("no monitor found")
+	}
+	info := monitorInfo{CbSize: uint32(unsafe.Sizeof(monitorInfo{}))}
+	if ok, _, err := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return rect{}, err
+	}
+	return info.Work, nil
+}
+
+// clampToWorkArea moves and if necessary shrinks g so it lies fully inside the work area
+// clampToWorkArea 移动并在必要时缩小 g，使其完全位于工作区内
+func clampToWorkArea(g WindowGeometry, work rect) WindowGeometry {
+	workWidth := int(work.Right - work.Left)
+	workHeight := int(work.Bottom - work.Top)
+	if g.Width > workWidth {
+		g.Width = workWidth
+	}
+	if g.Height > workHeight {
+		g.Height = workHeight
+	}
+	if g.X < int(work.Left) {
+		g.X = int(work.Left)
+	}
+	if g.Y < int(work.Top) {
+		g.Y = int(work.Top)
+	}
+	if g.X+g.Width > int(work.Right) {
+		g.X = int(work.Right) - g.Width
+	}
+	if g.Y+g.Height > int(work.Bottom) {
+		g.Y = int(work.Bottom) - g.Height
+	}
+	return g
+}
+
+// getWindowGeometry returns the position and size of the main window in screen pixels
+// getWindowGeometry 返回主窗口以屏幕像素表示的位置和大小
+func getWindowGeometry() (WindowGeometry, error) {
+	hwnd, err := mainWindowHandle()
+	if err != nil {
+		return WindowGeometry{}, err
+	}
+	var r rect
+	if ok, _, err := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&r))); ok == 0 {
+		return WindowGeometry{}, err
+	}
+	zoomed, _, _ := procIsZoomed.Call(hwnd)
+	return WindowGeometry{
+		X:         int(r.Left),
+		Y:         int(r.Top),
+		Width:     int(r.Right - r.Left),
+		Height:    int(r.Bottom - r.Top),
+		Maximised: zoomed != 0,
+	}, nil
+}
+
+// setWindowGeometry places the main window at g, clamped into the work area of the nearest monitor
+// so it is never off-screen after the monitor layout changed
+// setWindowGeometry 将主窗口放到 g 处，并限制在最近显示器的工作区内，
+// 使显示器布局变化后窗口不会出现在屏幕之外
+func setWindowGeometry(g WindowGeometry) error {
+	hwnd, err := mainWindowHandle()
+	if err != nil {
+		return err
+	}
+	work, err := workAreaNear(rect{Left: int32(g.X), Top: int32(g.Y), Right: int32(g.X + g.Width), Bottom: int32(g.Y + g.Height)})
+	if err != nil {
+		return err
+	}
+	g = clampToWorkArea(g, work)
+	if ok, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(g.X), uintptr(g.Y), uintptr(g.Width), uintptr(g.Height), swpNoZOrder|swpNoActivate); ok == 0 {
+		return err
+	}
+	return nil
+}
+
+// moveWindowToCursorMonitor centers the main window on the monitor under the mouse cursor,
+// unless it already lies fully on that monitor
+// moveWindowToCursorMonitor 将主窗口居中到鼠标所在的显示器，窗口已完全位于该显示器时不移动
+func moveWindowToCursorMonitor() error {
+	var cursor struct{ X, Y int32 }
+	if ok, _, err := procGetCursorPos.Call(uintptr(unsafe.Pointer(&cursor))); ok == 0 {
+		return err
+	}
+	work, err := workAreaNear(rect{Left: cursor.X, Top: cursor.Y, Right: cursor.X + 1, Bottom: cursor.Y + 1})
+	if err != nil {
+		return err
+	}
+	g, err := getWindowGeometry()
+	if err != nil {
+		return err
+	}
+	if g.Maximised {
+		return nil
+	}
+	if g.X >= int(work.Left) && g.Y >= int(work.Top) && g.X+g.Width <= int(work.Right) && g.Y+g.Height <= int(work.Bottom) {
+		return nil
+	}
+
+	g.X = int(work.Left) + (int(work.Right-work.Left)-g.Width)/2
+	g.Y = int(work.Top) + (int(work.Bottom-work.Top)-g.Height)/2
+	return setWindowGeometry(g)
+}
+
+// startWindowDrag hands the current mouse press to the system as a title bar drag
+// startWindowDrag 将当前的鼠标按下交给系统作为标题栏拖动处理
+func startWindowDrag() error {
+	hwnd, err := mainWindowHandle()
+	if err != nil {
+		return err
+	}
+	procReleaseCapture.Call()
+	procPostMessageW.Call(hwnd, wmNCLButtonDown, htCaption, 0)
+	return nil
+}